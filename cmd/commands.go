@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"tigerhall_kittens/internal/config"
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/service"
+)
+
+// commands are one-off admin tasks run with the binary instead of starting the server, as in
+// `tigerhall_kittens recompute-last-seen`
+var commands = map[string]func(ctx context.Context) error{
+	"recompute-last-seen": recomputeLastSeen,
+}
+
+// runCommand connects to the database and runs the named command
+func runCommand(ctx context.Context, name string) error {
+	command, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	config.SetupDBConnection(ctx)
+	defer db.Close()
+
+	return command(ctx)
+}
+
+// recomputeLastSeen rebuilds the last seen fields of every tiger from the sightings table
+func recomputeLastSeen(ctx context.Context) error {
+	updated, err := service.NewTigerService().RecomputeLastSeen(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated the last seen fields of %d tigers\n", updated)
+
+	return nil
+}
@@ -3,15 +3,19 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"runtime/debug"
+	"syscall"
 
 	"github.com/julienschmidt/httprouter"
 
 	"tigerhall_kittens/cmd/notification_worker"
 	"tigerhall_kittens/internal/config"
 	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/feed"
+	"tigerhall_kittens/internal/lifecycle"
 	"tigerhall_kittens/internal/logger"
 	"tigerhall_kittens/internal/routes"
 	"tigerhall_kittens/internal/web"
@@ -19,11 +23,10 @@ import (
 
 func main() {
 	defer logger.Sync()
-	defer db.Close()
 
-	// TODO: improve this
-	ctx, cancel := context.WithCancel(context.Background())
-	defer handlePanic(ctx, cancel)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	defer handlePanic(ctx)
 
 	if err := config.LoadEnv(); err != nil {
 		panic(err)
@@ -31,17 +34,127 @@ func main() {
 
 	config.SetupLogger(config.Env.Environment)
 
-	config.SetupDBConnection(context.Background())
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, os.Args[1]); err != nil {
+			logger.E(ctx, err, "Command failed", logger.Field("command", os.Args[1]))
+			logger.Sync()
+			os.Exit(1)
+		}
+		return
+	}
 
 	router := httprouter.New()
-	routes.Init(router)
+	server := &http.Server{Addr: config.Env.Port, Handler: router}
+	// streams only end when their subscription does, ending them lets the server drain
+	server.RegisterOnShutdown(func() {
+		feed.Get().Close()
+	})
 
-	go notification_worker.SetupNotificationWorker()
+	app := lifecycle.New(config.Env.ShutdownTimeout)
+	app.Append(
+		databaseComponent(),
+		feedComponent(),
+		loginThrottleComponent(),
+		signingKeysComponent(),
+		notificationSecretKeyComponent(),
+		oidcProvidersComponent(),
+		routesComponent(router),
+		notification_worker.NewNotificationWorker(),
+		lifecycle.NewHTTPServer(server, app.Fail),
+	)
 
-	log.Fatal(http.ListenAndServe(config.Env.Port, router))
+	if err := app.Run(ctx); err != nil {
+		logger.E(ctx, err, "Application stopped with errors")
+		logger.Sync()
+		os.Exit(1)
+	}
 }
 
-func handlePanic(ctx context.Context, cancel context.CancelFunc) {
+func databaseComponent() lifecycle.Component {
+	return lifecycle.Hook{
+		HookName: "database",
+		OnStart: func(ctx context.Context) error {
+			config.SetupDBConnection(ctx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			db.Close()
+			return nil
+		},
+	}
+}
+
+func feedComponent() lifecycle.Component {
+	var cancel context.CancelFunc
+
+	return lifecycle.Hook{
+		HookName: "sighting feed",
+		OnStart: func(ctx context.Context) error {
+			ctx, cancel = context.WithCancel(ctx)
+			config.SetupFeedBroker(ctx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			feed.Get().Close()
+			cancel()
+			return nil
+		},
+	}
+}
+
+func loginThrottleComponent() lifecycle.Component {
+	return lifecycle.Hook{
+		HookName: "login throttle",
+		OnStart: func(ctx context.Context) error {
+			config.SetupLoginThrottle(ctx)
+			return nil
+		},
+	}
+}
+
+func signingKeysComponent() lifecycle.Component {
+	return lifecycle.Hook{
+		HookName: "signing keys",
+		OnStart: func(ctx context.Context) error {
+			config.SetupSigningKeys(ctx)
+			return nil
+		},
+	}
+}
+
+func notificationSecretKeyComponent() lifecycle.Component {
+	return lifecycle.Hook{
+		HookName: "notification secret key",
+		OnStart: func(ctx context.Context) error {
+			config.SetupNotificationSecretKey(ctx)
+			return nil
+		},
+	}
+}
+
+func oidcProvidersComponent() lifecycle.Component {
+	return lifecycle.Hook{
+		HookName: "identity providers",
+		OnStart: func(ctx context.Context) error {
+			config.SetupOIDCProviders(ctx)
+			return nil
+		},
+	}
+}
+
+// routesComponent registers the routes once the database and the feed broker are set up,
+// handlers pick up the connection and broker when they are built
+func routesComponent(router *httprouter.Router) lifecycle.Component {
+	return lifecycle.Hook{
+		HookName: "routes",
+		OnStart: func(ctx context.Context) error {
+			routes.Init(router)
+			return nil
+		},
+	}
+}
+
+func handlePanic(ctx context.Context) {
 	if recvr := recover(); recvr != nil {
 		errorMessage := fmt.Sprintf("%v", recvr)
 		err := web.ErrInternalServerError(errorMessage)
@@ -50,6 +163,4 @@ func handlePanic(ctx context.Context, cancel context.CancelFunc) {
 			logger.Field("stack", string(debug.Stack())),
 		)
 	}
-
-	cancel()
 }
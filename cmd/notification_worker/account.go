@@ -0,0 +1,96 @@
+package notification_worker
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/secretbox"
+)
+
+// VerifyEmail is the outbox payload of EmailNotificationSubjectVerifyEmail. VerifyURL is empty
+// when no verification page is configured, the user is then given the token to submit.
+type VerifyEmail struct {
+	UserID    uuid.UUID   `json:"user_id"`
+	Username  string      `json:"username"`
+	Token     SealedToken `json:"token"`
+	VerifyURL SealedToken `json:"verify_url,omitempty"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// ResetPasswordEmail is the outbox payload of EmailNotificationSubjectResetPassword. ResetURL is
+// empty when no reset page is configured, the user is then given the token to submit.
+type ResetPasswordEmail struct {
+	UserID    uuid.UUID   `json:"user_id"`
+	Username  string      `json:"username"`
+	Token     SealedToken `json:"token"`
+	ResetURL  SealedToken `json:"reset_url,omitempty"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// ConfirmEmailChangeEmail is the outbox payload of EmailNotificationSubjectConfirmEmailChange. It is
+// sent to Recipient, the new address, instead of the current email of the user. ConfirmURL is
+// empty when no verification page is configured, the user is then given the token to submit.
+type ConfirmEmailChangeEmail struct {
+	UserID     uuid.UUID   `json:"user_id"`
+	Username   string      `json:"username"`
+	Recipient  string      `json:"recipient"`
+	Token      SealedToken `json:"token"`
+	ConfirmURL SealedToken `json:"confirm_url,omitempty"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+}
+
+// DataExportReadyEmail is the outbox payload of EmailNotificationSubjectDataExportReady
+type DataExportReadyEmail struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	ExportID  uuid.UUID `json:"export_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SealedToken is a one-time token, or a link carrying one, that is sealed with secretbox when its
+// payload is stored and opened when it is read back, so the outbox never holds it in the clear.
+// The outbox drops the sealed fields once a notification is sent or dead.
+type SealedToken string
+
+func (t SealedToken) MarshalJSON() ([]byte, error) {
+	sealed, err := secretbox.Seal(string(t))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(sealed)
+}
+
+func (t *SealedToken) UnmarshalJSON(data []byte) error {
+	var sealed string
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return err
+	}
+
+	if sealed == "" {
+		*t = ""
+		return nil
+	}
+
+	opened, err := secretbox.Open(sealed)
+	if err != nil {
+		return err
+	}
+
+	*t = SealedToken(opened)
+
+	return nil
+}
+
+// payloadRecipient is the address a notification has to be sent to instead of the email of its
+// user, empty for all but a few account notifications
+func payloadRecipient(payload json.RawMessage) string {
+	var recipient struct {
+		Recipient string `json:"recipient"`
+	}
+	_ = json.Unmarshal(payload, &recipient)
+
+	return recipient.Recipient
+}
@@ -0,0 +1,301 @@
+package notification_worker
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TemplateTigerSighting      = "tiger_sighting"
+	TemplateGeofenceSighting   = "geofence_sighting"
+	TemplateDigest             = "digest"
+	TemplateVerifyEmail        = "verify_email"
+	TemplateResetPassword      = "reset_password"
+	TemplateConfirmEmailChange = "confirm_email_change"
+	TemplateDataExportReady    = "data_export_ready"
+
+	DefaultLocale = "en"
+)
+
+var ErrTemplateNotFound = errors.New("notification template not found")
+
+// Every template lives in templates/<locale>/<name>.txt, defining "subject" and "body",
+// with an html/template variant of the body in templates/<locale>/<name>.html.
+//
+//go:embed templates
+var templateFiles embed.FS
+
+type subjectTemplate struct {
+	template string
+	payload  func() interface{}
+	// transactional notifications are about the account itself, they are always emailed right
+	// away regardless of the recipient's notification preferences
+	transactional bool
+	// sealedToken notifications carry a one-time token, see SealedToken, which is dropped from the
+	// outbox once the notification is sent or dead
+	sealedToken bool
+}
+
+// subjectTemplates picks the template, and through NOTIFICATION_ROUTES the channel,
+// for every subject, along with the payload type stored in the outbox for it
+var subjectTemplates = map[string]subjectTemplate{
+	EmailNotificationSubjectTigerSighting: {
+		template: TemplateTigerSighting,
+		payload:  func() interface{} { return &TigerSightingEmail{} },
+	},
+	EmailNotificationSubjectGeofenceSighting: {
+		template: TemplateGeofenceSighting,
+		payload:  func() interface{} { return &GeofenceSightingEmail{} },
+	},
+	EmailNotificationSubjectDigest: {
+		template: TemplateDigest,
+		payload:  func() interface{} { return &DigestEmail{} },
+	},
+	EmailNotificationSubjectVerifyEmail: {
+		template:      TemplateVerifyEmail,
+		payload:       func() interface{} { return &VerifyEmail{} },
+		transactional: true,
+		sealedToken:   true,
+	},
+	EmailNotificationSubjectResetPassword: {
+		template:      TemplateResetPassword,
+		payload:       func() interface{} { return &ResetPasswordEmail{} },
+		transactional: true,
+		sealedToken:   true,
+	},
+	EmailNotificationSubjectConfirmEmailChange: {
+		template:      TemplateConfirmEmailChange,
+		payload:       func() interface{} { return &ConfirmEmailChangeEmail{} },
+		transactional: true,
+		sealedToken:   true,
+	},
+	EmailNotificationSubjectDataExportReady: {
+		template:      TemplateDataExportReady,
+		payload:       func() interface{} { return &DataExportReadyEmail{} },
+		transactional: true,
+	},
+}
+
+// templateSamples is the data admins preview templates with, and what the golden files are rendered from
+var templateSamples = map[string]interface{}{
+	TemplateTigerSighting: sampleTigerSighting,
+	TemplateGeofenceSighting: GeofenceSightingEmail{
+		TigerSightingEmail: sampleTigerSighting,
+		GeofenceID:         uuid.MustParse("c3d9a0e2-4b7f-4f6a-8e21-9a5d6c2b7e48"),
+		GeofenceName:       "Rajbagh lake",
+	},
+	TemplateDigest: DigestEmail{
+		UserID: uuid.MustParse("7f1b7a52-2d54-4a8c-9d7e-0d3c7b6a1f10"),
+		Items: []DigestItem{
+			{Title: "Machli was sighted again", CreatedAt: time.Date(2024, 3, 16, 5, 42, 0, 0, time.UTC)},
+			{Title: "Machli was sighted in Rajbagh lake", CreatedAt: time.Date(2024, 3, 16, 7, 15, 0, 0, time.UTC)},
+		},
+	},
+	TemplateVerifyEmail: VerifyEmail{
+		UserID:    uuid.MustParse("7f1b7a52-2d54-4a8c-9d7e-0d3c7b6a1f10"),
+		Username:  "ranger_ravi",
+		Token:     "q8Xf3n1cV0d2kL7sT9wY4bR6uE5hJ2mA",
+		VerifyURL: "https://tigerhall.io/verify-email?token=q8Xf3n1cV0d2kL7sT9wY4bR6uE5hJ2mA",
+		ExpiresAt: time.Date(2024, 3, 17, 5, 42, 0, 0, time.UTC),
+	},
+	TemplateResetPassword: ResetPasswordEmail{
+		UserID:    uuid.MustParse("7f1b7a52-2d54-4a8c-9d7e-0d3c7b6a1f10"),
+		Username:  "ranger_ravi",
+		Token:     "Zp4Kc9Wm2Rt7Lx1Qv8Nb3Hs6Ty0Ej5Gd",
+		ResetURL:  "https://tigerhall.io/reset-password?token=Zp4Kc9Wm2Rt7Lx1Qv8Nb3Hs6Ty0Ej5Gd",
+		ExpiresAt: time.Date(2024, 3, 16, 6, 42, 0, 0, time.UTC),
+	},
+	TemplateConfirmEmailChange: ConfirmEmailChangeEmail{
+		UserID:     uuid.MustParse("7f1b7a52-2d54-4a8c-9d7e-0d3c7b6a1f10"),
+		Username:   "ranger_ravi",
+		Recipient:  "ravi@wwf.example.org",
+		Token:      "Hn7Vb2Qx5Ks9Fw3Lp8Rd1Tc6Mz4Ya0Ue",
+		ConfirmURL: "https://tigerhall.io/verify-email?token=Hn7Vb2Qx5Ks9Fw3Lp8Rd1Tc6Mz4Ya0Ue",
+		ExpiresAt:  time.Date(2024, 3, 17, 5, 42, 0, 0, time.UTC),
+	},
+	TemplateDataExportReady: DataExportReadyEmail{
+		UserID:    uuid.MustParse("7f1b7a52-2d54-4a8c-9d7e-0d3c7b6a1f10"),
+		Username:  "ranger_ravi",
+		ExportID:  uuid.MustParse("5d2c8e1a-7b3f-4e9a-a6c4-1f0b9d8e7c25"),
+		ExpiresAt: time.Date(2024, 3, 23, 5, 42, 0, 0, time.UTC),
+	},
+}
+
+var sampleTigerSighting = TigerSightingEmail{
+	UserID:             uuid.MustParse("7f1b7a52-2d54-4a8c-9d7e-0d3c7b6a1f10"),
+	TigerID:            42,
+	TigerName:          "Machli",
+	SightingID:         uuid.MustParse("0b6e3f5e-8a1c-4c7e-9f0a-5b2d8e1c3a77"),
+	SightedAt:          time.Date(2024, 3, 16, 5, 42, 0, 0, time.UTC),
+	Lat:                26.017535,
+	Lon:                76.502426,
+	ReportedByUsername: "ranger_ravi",
+	ImageURL:           "https://images.tigerhall.io/sightings/0b6e3f5e.jpg",
+}
+
+// Content is a notification rendered for one recipient
+type Content struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+type localizedTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templates is keyed by locale and then by template name
+var templates = mustLoadTemplates(templateFiles)
+
+func mustLoadTemplates(files fs.FS) map[string]map[string]localizedTemplate {
+	loaded := map[string]map[string]localizedTemplate{}
+
+	textFiles, err := fs.Glob(files, "templates/*/*.txt")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, textFile := range textFiles {
+		locale := path.Base(path.Dir(textFile))
+		name := strings.TrimSuffix(path.Base(textFile), ".txt")
+
+		text := texttemplate.Must(texttemplate.ParseFS(files, textFile))
+		html := htmltemplate.Must(htmltemplate.ParseFS(files, strings.TrimSuffix(textFile, ".txt")+".html"))
+
+		if text.Lookup("subject") == nil || text.Lookup("body") == nil {
+			panic(fmt.Sprintf("template %s must define subject and body", textFile))
+		}
+
+		if loaded[locale] == nil {
+			loaded[locale] = map[string]localizedTemplate{}
+		}
+		loaded[locale][name] = localizedTemplate{text: text, html: html}
+	}
+
+	return loaded
+}
+
+func templateForSubject(subject string) string {
+	return subjectTemplates[subject].template
+}
+
+func isTransactional(subject string) bool {
+	return subjectTemplates[subject].transactional
+}
+
+// CarriesSealedToken tells whether notifications with the subject carry a one-time token. A dead
+// one can not be replayed, its token is gone and the user has to request a new one.
+func CarriesSealedToken(subject string) bool {
+	return subjectTemplates[subject].sealedToken
+}
+
+// decodePayload turns a stored outbox payload into the typed data its subject's template expects
+func decodePayload(subject string, data interface{}) (interface{}, error) {
+	raw, ok := data.(json.RawMessage)
+	if !ok {
+		return data, nil
+	}
+
+	st, ok := subjectTemplates[subject]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+
+	payload := st.payload()
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// resolveLocale falls back from a regional locale to its language, and from there to DefaultLocale
+func resolveLocale(name, locale string) (localizedTemplate, bool) {
+	candidates := []string{locale}
+	if language, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, language)
+	}
+	candidates = append(candidates, DefaultLocale)
+
+	for _, candidate := range candidates {
+		if tmpl, ok := templates[strings.ToLower(candidate)][name]; ok {
+			return tmpl, true
+		}
+	}
+
+	return localizedTemplate{}, false
+}
+
+// RenderTemplate renders the subject, text and HTML variants of a template in the given locale
+func RenderTemplate(name, locale string, data interface{}) (*Content, error) {
+	tmpl, ok := resolveLocale(name, locale)
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	return &Content{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// PreviewTemplate renders a template with its sample data
+func PreviewTemplate(name, locale string) (*Content, error) {
+	sample, ok := templateSamples[name]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+
+	return RenderTemplate(name, locale, sample)
+}
+
+// TemplateNames lists every template that has sample data to preview
+func TemplateNames() []string {
+	names := make([]string, 0, len(templateSamples))
+	for name := range templateSamples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// TemplateLocales lists the locales a template is translated to
+func TemplateLocales(name string) []string {
+	var locales []string
+	for locale, localeTemplates := range templates {
+		if _, ok := localeTemplates[name]; ok {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+
+	return locales
+}
@@ -0,0 +1,110 @@
+package notification_worker
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// TestTemplates_Golden renders every template in every locale with its sample data
+// and compares the output with testdata/golden. Run with -update after changing a template.
+func TestTemplates_Golden(t *testing.T) {
+	for _, name := range TemplateNames() {
+		for _, locale := range TemplateLocales(name) {
+			name, locale := name, locale
+			t.Run(name+"/"+locale, func(t *testing.T) {
+				content, err := PreviewTemplate(name, locale)
+				assert.Nil(t, err)
+
+				assertGolden(t, filepath.Join("testdata", "golden", name+"."+locale+".txt"), "Subject: "+content.Subject+"\n\n"+content.Text)
+				assertGolden(t, filepath.Join("testdata", "golden", name+"."+locale+".html"), content.HTML)
+			})
+		}
+	}
+}
+
+func assertGolden(t *testing.T, path string, actual string) {
+	t.Helper()
+
+	if *updateGolden {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(t, os.WriteFile(path, []byte(actual), 0o644))
+	}
+
+	expected, err := os.ReadFile(path)
+	assert.Nil(t, err, "missing golden file, run the test with -update")
+	assert.Equal(t, string(expected), actual)
+}
+
+func TestTemplates_Coverage(t *testing.T) {
+	t.Run("every subject template should have sample data and a default locale variant", func(t *testing.T) {
+		for subject, st := range subjectTemplates {
+			_, ok := templateSamples[st.template]
+			assert.True(t, ok, "no sample data for %s", subject)
+			assert.Contains(t, TemplateLocales(st.template), DefaultLocale)
+		}
+	})
+
+	t.Run("every template should be translated to the same locales", func(t *testing.T) {
+		for _, name := range TemplateNames() {
+			assert.Equal(t, TemplateLocales(TemplateTigerSighting), TemplateLocales(name), name)
+		}
+	})
+}
+
+func TestRenderTemplate(t *testing.T) {
+	sample := templateSamples[TemplateTigerSighting]
+
+	t.Run("should fall back from region to language", func(t *testing.T) {
+		content, err := RenderTemplate(TemplateTigerSighting, "hi-IN", sample)
+		assert.Nil(t, err)
+		assert.Equal(t, "Machli फिर से देखा गया", content.Subject)
+	})
+
+	t.Run("should fall back to the default locale", func(t *testing.T) {
+		content, err := RenderTemplate(TemplateTigerSighting, "fr", sample)
+		assert.Nil(t, err)
+		assert.Equal(t, "Machli was sighted again", content.Subject)
+	})
+
+	t.Run("should escape user input in the html variant", func(t *testing.T) {
+		data := sample.(TigerSightingEmail)
+		data.ReportedByUsername = "<script>alert(1)</script>"
+
+		content, err := RenderTemplate(TemplateTigerSighting, "en", data)
+		assert.Nil(t, err)
+		assert.NotContains(t, content.HTML, "<script>")
+		assert.Contains(t, content.Text, "<script>alert(1)</script>")
+	})
+
+	t.Run("should return error for unknown template", func(t *testing.T) {
+		_, err := RenderTemplate("unknown", "en", sample)
+		assert.Equal(t, ErrTemplateNotFound, err)
+	})
+}
+
+func TestSealedToken(t *testing.T) {
+	t.Run("should only store the token sealed and open it for the template", func(t *testing.T) {
+		notification, err := NewOutboxNotification(EmailNotificationSubjectResetPassword, uuid.New(), ResetPasswordEmail{
+			Username: "ranger_ravi",
+			Token:    "Zp4Kc9Wm2Rt7Lx1Qv8Nb3Hs6Ty0Ej5Gd",
+			ResetURL: "https://tigerhall.io/reset-password?token=Zp4Kc9Wm2Rt7Lx1Qv8Nb3Hs6Ty0Ej5Gd",
+		})
+		assert.Nil(t, err)
+		assert.NotContains(t, string(notification.Payload), "Zp4Kc9Wm2Rt7Lx1Qv8Nb3Hs6Ty0Ej5Gd")
+
+		data, err := decodePayload(notification.Subject, notification.Payload)
+		assert.Nil(t, err)
+		assert.Equal(t, SealedToken("Zp4Kc9Wm2Rt7Lx1Qv8Nb3Hs6Ty0Ej5Gd"), data.(*ResetPasswordEmail).Token)
+
+		content, err := RenderTemplate(TemplateResetPassword, DefaultLocale, data)
+		assert.Nil(t, err)
+		assert.Contains(t, content.HTML, "https://tigerhall.io/reset-password?token=Zp4Kc9Wm2Rt7Lx1Qv8Nb3Hs6Ty0Ej5Gd")
+	})
+}
@@ -0,0 +1,343 @@
+package notification_worker
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/config"
+	"tigerhall_kittens/internal/lifecycle"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+// exportInboxPageSize is how many inbox notifications are read at a time while building an export
+const exportInboxPageSize = 500
+
+type DataExportConfig struct {
+	WorkerConfig
+	// TTL is how long a ready export can be downloaded
+	TTL time.Duration
+}
+
+type DataExportWorker interface {
+	Run(ctx context.Context)
+	ProcessBatch(ctx context.Context) int
+}
+
+type dataExportWorker struct {
+	dataExportRepo repository.DataExportRepo
+	userRepo       repository.UserRepo
+	sightingRepo   repository.SightingRepo
+	inboxRepo      repository.InboxRepo
+	auditRepo      repository.AuditRepo
+	config         DataExportConfig
+}
+
+type DataExportWorkerOption func(w *dataExportWorker)
+
+func NewDataExportWorker(options ...DataExportWorkerOption) DataExportWorker {
+	w := &dataExportWorker{
+		dataExportRepo: repository.NewDataExportRepo(),
+		userRepo:       repository.NewUserRepo(),
+		sightingRepo:   repository.NewSightingRepo(),
+		inboxRepo:      repository.NewInboxRepo(),
+		auditRepo:      repository.NewAuditRepo(),
+		config: DataExportConfig{
+			WorkerConfig: WorkerConfig{
+				PollInterval:   config.Env.NotificationPollInterval,
+				BatchSize:      config.Env.NotificationBatchSize,
+				MaxAttempts:    config.Env.NotificationMaxAttempts,
+				RetryBaseDelay: config.Env.NotificationRetryBaseDelay,
+				ClaimLease:     config.Env.NotificationClaimLease,
+			},
+			TTL: config.Env.DataExportTTL,
+		},
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+func WithDataExportRepo(repo repository.DataExportRepo) DataExportWorkerOption {
+	return func(w *dataExportWorker) {
+		w.dataExportRepo = repo
+	}
+}
+
+func WithUserRepoForDataExport(repo repository.UserRepo) DataExportWorkerOption {
+	return func(w *dataExportWorker) {
+		w.userRepo = repo
+	}
+}
+
+func WithSightingRepoForDataExport(repo repository.SightingRepo) DataExportWorkerOption {
+	return func(w *dataExportWorker) {
+		w.sightingRepo = repo
+	}
+}
+
+func WithInboxRepoForDataExport(repo repository.InboxRepo) DataExportWorkerOption {
+	return func(w *dataExportWorker) {
+		w.inboxRepo = repo
+	}
+}
+
+func WithAuditRepoForDataExport(repo repository.AuditRepo) DataExportWorkerOption {
+	return func(w *dataExportWorker) {
+		w.auditRepo = repo
+	}
+}
+
+func WithDataExportConfig(cfg DataExportConfig) DataExportWorkerOption {
+	return func(w *dataExportWorker) {
+		w.config = cfg
+	}
+}
+
+// Run builds pending exports until ctx is cancelled, like the notification worker
+func (w *dataExportWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed := w.ProcessBatch(lifecycle.Detach(ctx))
+		if claimed > 0 && claimed == w.config.BatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch deletes the expired exports, then claims and builds one batch of pending exports
+// and returns how many were claimed. Exports are built one after another, each one reads
+// everything its user ever reported.
+func (w *dataExportWorker) ProcessBatch(ctx context.Context) int {
+	_, _ = w.dataExportRepo.DeleteExpiredExports(ctx, time.Now())
+
+	exports, err := w.dataExportRepo.ClaimExports(ctx, repository.ClaimOutboxOpts{
+		Limit: w.config.BatchSize,
+		Lease: w.config.ClaimLease,
+	})
+	if err != nil {
+		return 0
+	}
+
+	for i := range exports {
+		w.process(ctx, &exports[i])
+	}
+
+	return len(exports)
+}
+
+// process builds the archive of an export and mails its user once it is ready. A failed export
+// is retried with backoff until the attempts run out.
+func (w *dataExportWorker) process(ctx context.Context, export *model.DataExport) {
+	user, err := w.userRepo.GetUser(ctx, repository.GetUserOpts{ID: export.UserID})
+	if err == nil {
+		export.Archive, err = w.buildArchive(ctx, user)
+	}
+
+	var notification *model.OutboxNotification
+	if err == nil {
+		now := time.Now()
+		expiresAt := now.Add(w.config.TTL)
+		export.CompletedAt = &now
+		export.ExpiresAt = &expiresAt
+
+		notification, err = NewOutboxNotification(EmailNotificationSubjectDataExportReady, user.ID, DataExportReadyEmail{
+			UserID:    user.ID,
+			Username:  user.Username,
+			ExportID:  export.ID,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	if err == nil {
+		err = w.dataExportRepo.CompleteExport(ctx, export, notification)
+	}
+
+	if err == nil {
+		logger.I(ctx, "Built data export", logger.Field("export_id", export.ID), logger.Field("user_id", export.UserID))
+		return
+	}
+
+	export.Archive = nil
+	export.LastError = err.Error()
+	export.Status = model.DataExportStatusPending
+	export.NextAttemptAt = time.Now().Add(retryDelay(w.config.RetryBaseDelay, export.Attempts))
+	if export.Attempts >= w.config.MaxAttempts {
+		export.Status = model.DataExportStatusFailed
+	}
+
+	logger.W(ctx, "Failed to build data export",
+		logger.Field("export_id", export.ID),
+		logger.Field("attempts", export.Attempts),
+		logger.Field("status", export.Status),
+		logger.Field("error", err.Error()))
+
+	_ = w.dataExportRepo.FailExport(ctx, export)
+}
+
+// exportedProfile is the account of the user as it appears in profile.json, secrets left out
+type exportedProfile struct {
+	ID              uuid.UUID  `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	PendingEmail    *string    `json:"pending_email,omitempty"`
+	DisplayName     string     `json:"display_name"`
+	Organisation    string     `json:"organisation"`
+	TimeZone        string     `json:"time_zone"`
+	Locale          string     `json:"locale"`
+	Role            model.Role `json:"role"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// buildArchive zips the personal data of the user: their profile and inbox as JSON, and the
+// sightings they reported and the audit trail of their account as CSV
+func (w *dataExportWorker) buildArchive(ctx context.Context, user *model.User) ([]byte, error) {
+	sightings, err := w.sightingRepo.GetUserSightings(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := w.inboxNotifications(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := w.auditRepo.GetUserEvents(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := exportedProfile{
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		PendingEmail:    user.PendingEmail,
+		DisplayName:     user.DisplayName,
+		Organisation:    user.Organisation,
+		TimeZone:        user.TimeZone,
+		Locale:          user.Locale,
+		Role:            user.Role,
+		MFAEnabled:      user.MFAEnabledAt != nil,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+
+	sightingRows := [][]string{{"id", "tiger_id", "lat", "lon", "sighted_at", "image_url"}}
+	for _, sighting := range sightings {
+		sightingRows = append(sightingRows, []string{
+			sighting.ID.String(),
+			strconv.FormatUint(uint64(sighting.TigerID), 10),
+			strconv.FormatFloat(sighting.Lat, 'f', -1, 64),
+			strconv.FormatFloat(sighting.Lon, 'f', -1, 64),
+			sighting.SightedAt.UTC().Format(time.RFC3339),
+			sighting.ImageURL,
+		})
+	}
+
+	eventRows := [][]string{{"id", "type", "actor_id", "ip", "reason", "created_at"}}
+	for _, event := range events {
+		actorID := ""
+		if event.ActorID != nil {
+			actorID = event.ActorID.String()
+		}
+
+		eventRows = append(eventRows, []string{
+			event.ID.String(),
+			string(event.Type),
+			actorID,
+			event.IP,
+			event.Reason,
+			event.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	var archive bytes.Buffer
+	files := zip.NewWriter(&archive)
+
+	if err := writeJSONFile(files, "profile.json", profile); err != nil {
+		return nil, err
+	}
+
+	if err := writeJSONFile(files, "notifications.json", notifications); err != nil {
+		return nil, err
+	}
+
+	if err := writeCSVFile(files, "sightings.csv", sightingRows); err != nil {
+		return nil, err
+	}
+
+	if err := writeCSVFile(files, "audit_events.csv", eventRows); err != nil {
+		return nil, err
+	}
+
+	if err := files.Close(); err != nil {
+		return nil, err
+	}
+
+	return archive.Bytes(), nil
+}
+
+// inboxNotifications pages through the whole inbox of the user
+func (w *dataExportWorker) inboxNotifications(ctx context.Context, userID uuid.UUID) ([]model.InboxNotification, error) {
+	notifications := []model.InboxNotification{}
+	opts := repository.ListInboxOpts{UserID: userID, Limit: exportInboxPageSize}
+
+	for {
+		page, err := w.inboxRepo.GetNotifications(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, page...)
+		if len(page) < exportInboxPageSize {
+			return notifications, nil
+		}
+
+		last := page[len(page)-1]
+		opts.After = &repository.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func writeJSONFile(files *zip.Writer, name string, data interface{}) error {
+	file, err := files.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(data)
+}
+
+func writeCSVFile(files *zip.Writer, name string, rows [][]string) error {
+	file, err := files.Create(name)
+	if err != nil {
+		return err
+	}
+
+	return csv.NewWriter(file).WriteAll(rows)
+}
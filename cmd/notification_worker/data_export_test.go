@@ -0,0 +1,177 @@
+package notification_worker
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestDataExportWorker_ProcessBatch(t *testing.T) {
+	exportConfig := DataExportConfig{
+		WorkerConfig: WorkerConfig{
+			BatchSize:      10,
+			MaxAttempts:    3,
+			RetryBaseDelay: time.Minute,
+			ClaimLease:     time.Minute,
+		},
+		TTL: 7 * 24 * time.Hour,
+	}
+	claimOpts := repository.ClaimOutboxOpts{Limit: 10, Lease: time.Minute}
+
+	user := &model.User{
+		ID:           uuid.New(),
+		Username:     "ranger_ravi",
+		Password:     "hash",
+		Email:        "ravi@example.com",
+		Organisation: "WWF India",
+		TOTPSecret:   "secret",
+		Role:         model.RoleReporter,
+	}
+
+	t.Run("should zip the data of the user and mail them once it is ready", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		export := model.DataExport{ID: uuid.New(), UserID: user.ID, Status: model.DataExportStatusPending, Attempts: 1}
+		sightedAt := time.Date(2024, 3, 16, 5, 42, 0, 0, time.UTC)
+
+		mockDataExportRepo := mock_repository.NewMockDataExportRepo(ctrl)
+		mockDataExportRepo.EXPECT().DeleteExpiredExports(ctx, gomock.Any()).Return(int64(0), nil)
+		mockDataExportRepo.EXPECT().ClaimExports(ctx, claimOpts).Return([]model.DataExport{export}, nil)
+		mockDataExportRepo.EXPECT().CompleteExport(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, completed *model.DataExport, notification *model.OutboxNotification) error {
+				assert.Equal(t, export.ID, completed.ID)
+				assert.NotNil(t, completed.ExpiresAt)
+				assert.Equal(t, EmailNotificationSubjectDataExportReady, notification.Subject)
+				assert.Equal(t, user.ID, notification.UserID)
+
+				files := readArchive(t, completed.Archive)
+				assert.Len(t, files, 4)
+
+				var profile map[string]interface{}
+				assert.Nil(t, json.Unmarshal([]byte(files["profile.json"]), &profile))
+				assert.Equal(t, "ranger_ravi", profile["username"])
+				assert.Equal(t, "WWF India", profile["organisation"])
+				assert.NotContains(t, files["profile.json"], "secret")
+				assert.NotContains(t, files["profile.json"], "hash")
+
+				assert.Equal(t, "id,tiger_id,lat,lon,sighted_at,image_url\n"+
+					"0b6e3f5e-8a1c-4c7e-9f0a-5b2d8e1c3a77,42,26.017535,76.502426,2024-03-16T05:42:00Z,\n", files["sightings.csv"])
+				assert.Contains(t, files["notifications.json"], "Machli was sighted again")
+				assert.True(t, strings.HasPrefix(files["audit_events.csv"], "id,type,actor_id,ip,reason,created_at\n"))
+				assert.Contains(t, files["audit_events.csv"], "user.data_export_requested")
+				return nil
+			})
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
+		mockSightingRepo.EXPECT().GetUserSightings(ctx, user.ID).Return([]model.Sighting{{
+			ID:               uuid.MustParse("0b6e3f5e-8a1c-4c7e-9f0a-5b2d8e1c3a77"),
+			TigerID:          42,
+			ReportedByUserID: user.ID,
+			Lat:              26.017535,
+			Lon:              76.502426,
+			SightedAt:        sightedAt,
+		}}, nil)
+
+		mockInboxRepo := mock_repository.NewMockInboxRepo(ctrl)
+		mockInboxRepo.EXPECT().GetNotifications(ctx, repository.ListInboxOpts{UserID: user.ID, Limit: exportInboxPageSize}).
+			Return([]model.InboxNotification{{ID: uuid.New(), UserID: user.ID, Title: "Machli was sighted again"}}, nil)
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().GetUserEvents(ctx, user.ID).Return([]model.AuditEvent{{
+			ID:     uuid.New(),
+			Type:   model.AuditEventDataExportRequested,
+			UserID: &user.ID,
+			Reason: export.ID.String(),
+		}}, nil)
+
+		worker := NewDataExportWorker(
+			WithDataExportRepo(mockDataExportRepo),
+			WithUserRepoForDataExport(mockUserRepo),
+			WithSightingRepoForDataExport(mockSightingRepo),
+			WithInboxRepoForDataExport(mockInboxRepo),
+			WithAuditRepoForDataExport(mockAuditRepo),
+			WithDataExportConfig(exportConfig),
+		)
+
+		assert.Equal(t, 1, worker.ProcessBatch(ctx))
+	})
+
+	t.Run("should retry a failed export until the attempts run out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		retried := model.DataExport{ID: uuid.New(), UserID: user.ID, Status: model.DataExportStatusPending, Attempts: 1}
+		failed := model.DataExport{ID: uuid.New(), UserID: user.ID, Status: model.DataExportStatusPending, Attempts: 3}
+
+		mockDataExportRepo := mock_repository.NewMockDataExportRepo(ctrl)
+		mockDataExportRepo.EXPECT().DeleteExpiredExports(ctx, gomock.Any()).Return(int64(0), nil)
+		mockDataExportRepo.EXPECT().ClaimExports(ctx, claimOpts).Return([]model.DataExport{retried, failed}, nil)
+		gomock.InOrder(
+			mockDataExportRepo.EXPECT().FailExport(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, export *model.DataExport) error {
+				assert.Equal(t, model.DataExportStatusPending, export.Status)
+				assert.Equal(t, "some db error", export.LastError)
+				assert.True(t, export.NextAttemptAt.After(time.Now()))
+				return nil
+			}),
+			mockDataExportRepo.EXPECT().FailExport(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, export *model.DataExport) error {
+				assert.Equal(t, model.DataExportStatusFailed, export.Status)
+				return nil
+			}),
+		)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil).Times(2)
+
+		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
+		mockSightingRepo.EXPECT().GetUserSightings(ctx, user.ID).Return(nil, errors.New("some db error")).Times(2)
+
+		worker := NewDataExportWorker(
+			WithDataExportRepo(mockDataExportRepo),
+			WithUserRepoForDataExport(mockUserRepo),
+			WithSightingRepoForDataExport(mockSightingRepo),
+			WithDataExportConfig(exportConfig),
+		)
+
+		assert.Equal(t, 2, worker.ProcessBatch(ctx))
+	})
+}
+
+// readArchive returns the files of a ZIP archive by name
+func readArchive(t *testing.T, archive []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.Nil(t, err)
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		opened, err := file.Open()
+		assert.Nil(t, err)
+
+		content, err := io.ReadAll(opened)
+		assert.Nil(t, err)
+		_ = opened.Close()
+
+		files[file.Name] = string(content)
+	}
+
+	return files
+}
@@ -0,0 +1,160 @@
+package notification_worker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/config"
+	"tigerhall_kittens/internal/lifecycle"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+// DigestEmail is the outbox payload of EmailNotificationSubjectDigest
+type DigestEmail struct {
+	UserID uuid.UUID    `json:"user_id"`
+	Items  []DigestItem `json:"items"`
+}
+
+// DigestItem is one held back notification, summarised by its rendered subject
+type DigestItem struct {
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DigestConfig struct {
+	Interval  time.Duration
+	DailyHour int
+}
+
+type DigestScheduler interface {
+	Run(ctx context.Context)
+	ScheduleDigests(ctx context.Context) int
+}
+
+type digestScheduler struct {
+	outboxRepo      repository.OutboxRepo
+	userRepo        repository.UserRepo
+	preferencesRepo repository.PreferencesRepo
+	config          DigestConfig
+}
+
+type DigestSchedulerOption func(s *digestScheduler)
+
+func NewDigestScheduler(options ...DigestSchedulerOption) DigestScheduler {
+	s := &digestScheduler{
+		outboxRepo:      repository.NewOutboxRepo(),
+		userRepo:        repository.NewUserRepo(),
+		preferencesRepo: repository.NewPreferencesRepo(),
+		config: DigestConfig{
+			Interval:  config.Env.NotificationDigestInterval,
+			DailyHour: config.Env.NotificationDailyDigestHour,
+		},
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+func WithOutboxRepoForDigest(repo repository.OutboxRepo) DigestSchedulerOption {
+	return func(s *digestScheduler) {
+		s.outboxRepo = repo
+	}
+}
+
+func WithUserRepoForDigest(repo repository.UserRepo) DigestSchedulerOption {
+	return func(s *digestScheduler) {
+		s.userRepo = repo
+	}
+}
+
+func WithPreferencesRepoForDigest(repo repository.PreferencesRepo) DigestSchedulerOption {
+	return func(s *digestScheduler) {
+		s.preferencesRepo = repo
+	}
+}
+
+func WithDigestConfig(cfg DigestConfig) DigestSchedulerOption {
+	return func(s *digestScheduler) {
+		s.config = cfg
+	}
+}
+
+// Run builds the digests that are due every Interval until ctx is cancelled
+func (s *digestScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.ScheduleDigests(lifecycle.Detach(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScheduleDigests batches the notifications held back for every user whose digest is due
+// into a single digest notification, and returns how many digests were queued. The digest
+// goes through the outbox like any other notification, quiet hours included.
+func (s *digestScheduler) ScheduleDigests(ctx context.Context) int {
+	userIDs, err := s.outboxRepo.GetDigestUserIDs(ctx)
+	if err != nil {
+		return 0
+	}
+
+	var scheduled int
+	for _, userID := range userIDs {
+		now := time.Now()
+		preferences, err := s.preferencesRepo.GetPreferences(ctx, userID)
+		if err != nil || !digestDue(preferences, now, s.config.DailyHour) {
+			continue
+		}
+
+		user, err := s.userRepo.GetUser(ctx, repository.GetUserOpts{ID: userID})
+		if err != nil {
+			continue
+		}
+
+		created, err := s.outboxRepo.CreateDigest(ctx, userID, func(held []model.OutboxNotification) (*model.OutboxNotification, error) {
+			return buildDigest(user, held)
+		})
+		if err != nil || !created {
+			continue
+		}
+
+		_ = s.preferencesRepo.MarkDigestSent(ctx, userID, now)
+		scheduled++
+
+		logger.D(ctx, "Scheduled notification digest", logger.Field("user_id", userID))
+	}
+
+	return scheduled
+}
+
+// buildDigest summarises every held back notification by its subject line in the user's locale
+func buildDigest(user *model.User, held []model.OutboxNotification) (*model.OutboxNotification, error) {
+	digest := DigestEmail{UserID: user.ID}
+
+	for _, notification := range held {
+		title := notification.Subject
+		if data, err := decodePayload(notification.Subject, json.RawMessage(notification.Payload)); err == nil {
+			if content, err := RenderTemplate(templateForSubject(notification.Subject), user.Locale, data); err == nil {
+				title = content.Subject
+			}
+		}
+
+		digest.Items = append(digest.Items, DigestItem{Title: title, CreatedAt: notification.CreatedAt})
+	}
+
+	return NewOutboxNotification(EmailNotificationSubjectDigest, user.ID, digest)
+}
@@ -0,0 +1,130 @@
+package notification_worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestDigestScheduler_ScheduleDigests(t *testing.T) {
+	digestConfig := DigestConfig{Interval: time.Minute, DailyHour: 8}
+
+	user := &model.User{ID: uuid.New(), Locale: "en"}
+	sightingPayload, _ := json.Marshal(templateSamples[TemplateTigerSighting])
+	held := []model.OutboxNotification{
+		{ID: uuid.New(), Subject: EmailNotificationSubjectTigerSighting, UserID: user.ID, Payload: sightingPayload, CreatedAt: time.Now()},
+	}
+
+	t.Run("should leave users whose digest is not due", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		lastDigestAt := time.Now().Add(-10 * time.Minute)
+		preferences := model.DefaultNotificationPreferences(user.ID)
+		preferences.Delivery = model.DeliveryHourlyDigest
+		preferences.LastDigestAt = &lastDigestAt
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetDigestUserIDs(ctx).Return([]uuid.UUID{user.ID}, nil)
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, user.ID).Return(preferences, nil)
+
+		scheduler := NewDigestScheduler(WithOutboxRepoForDigest(mockOutboxRepo), WithPreferencesRepoForDigest(mockPreferencesRepo),
+			WithUserRepoForDigest(mock_repository.NewMockUserRepo(ctrl)), WithDigestConfig(digestConfig))
+
+		assert.Equal(t, 0, scheduler.ScheduleDigests(ctx))
+	})
+
+	t.Run("should not record a digest that failed to be created", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetDigestUserIDs(ctx).Return([]uuid.UUID{user.ID}, nil)
+		mockOutboxRepo.EXPECT().CreateDigest(ctx, user.ID, gomock.Any()).Return(false, errors.New("some db error"))
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, user.ID).Return(model.DefaultNotificationPreferences(user.ID), nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		scheduler := NewDigestScheduler(WithOutboxRepoForDigest(mockOutboxRepo), WithPreferencesRepoForDigest(mockPreferencesRepo),
+			WithUserRepoForDigest(mockUserRepo), WithDigestConfig(digestConfig))
+
+		assert.Equal(t, 0, scheduler.ScheduleDigests(ctx))
+	})
+
+	t.Run("should not record a digest when nothing was held back", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetDigestUserIDs(ctx).Return([]uuid.UUID{user.ID}, nil)
+		mockOutboxRepo.EXPECT().CreateDigest(ctx, user.ID, gomock.Any()).Return(false, nil)
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, user.ID).Return(model.DefaultNotificationPreferences(user.ID), nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		scheduler := NewDigestScheduler(WithOutboxRepoForDigest(mockOutboxRepo), WithPreferencesRepoForDigest(mockPreferencesRepo),
+			WithUserRepoForDigest(mockUserRepo), WithDigestConfig(digestConfig))
+
+		assert.Equal(t, 0, scheduler.ScheduleDigests(ctx))
+	})
+
+	t.Run("should batch held notifications into a digest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		var digest *model.OutboxNotification
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetDigestUserIDs(ctx).Return([]uuid.UUID{user.ID}, nil)
+		mockOutboxRepo.EXPECT().CreateDigest(ctx, user.ID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uuid.UUID, build repository.DigestBuilder) (bool, error) {
+				var err error
+				digest, err = build(held)
+				return err == nil, err
+			})
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, user.ID).Return(model.DefaultNotificationPreferences(user.ID), nil)
+		mockPreferencesRepo.EXPECT().MarkDigestSent(ctx, user.ID, gomock.Any()).Return(nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		scheduler := NewDigestScheduler(WithOutboxRepoForDigest(mockOutboxRepo), WithPreferencesRepoForDigest(mockPreferencesRepo),
+			WithUserRepoForDigest(mockUserRepo), WithDigestConfig(digestConfig))
+
+		assert.Equal(t, 1, scheduler.ScheduleDigests(ctx))
+		assert.Equal(t, EmailNotificationSubjectDigest, digest.Subject)
+		assert.Equal(t, user.ID, digest.UserID)
+		assert.Equal(t, model.OutboxStatusPending, digest.Status)
+
+		var payload DigestEmail
+		_ = json.Unmarshal(digest.Payload, &payload)
+		assert.Len(t, payload.Items, 1)
+		assert.Equal(t, "Machli was sighted again", payload.Items[0].Title)
+	})
+}
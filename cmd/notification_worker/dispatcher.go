@@ -0,0 +1,247 @@
+package notification_worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"tigerhall_kittens/internal/config"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/repository"
+)
+
+type Dispatcher interface {
+	Dispatch(ctx context.Context, notification Notification) ([]string, error)
+}
+
+type channel struct {
+	notifier Notifier
+	slots    chan struct{}
+	timeout  time.Duration
+}
+
+type dispatcher struct {
+	channels       map[string]*channel
+	routes         map[string]string
+	defaultChannel string
+}
+
+type DispatcherOption func(d *dispatcher)
+
+func NewDispatcher(options ...DispatcherOption) Dispatcher {
+	d := &dispatcher{
+		channels:       map[string]*channel{},
+		routes:         map[string]string{},
+		defaultChannel: ChannelLog,
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	return d
+}
+
+// WithChannel registers a notifier under name. At most concurrency notifications
+// are in flight on the channel at once and each one is bounded by timeout.
+func WithChannel(name string, notifier Notifier, concurrency int, timeout time.Duration) DispatcherOption {
+	return func(d *dispatcher) {
+		if concurrency <= 0 {
+			concurrency = 1
+		}
+
+		d.channels[name] = &channel{
+			notifier: notifier,
+			slots:    make(chan struct{}, concurrency),
+			timeout:  timeout,
+		}
+	}
+}
+
+// WithRoutes sets which channel delivers each template
+func WithRoutes(routes map[string]string) DispatcherOption {
+	return func(d *dispatcher) {
+		d.routes = routes
+	}
+}
+
+// WithDefaultChannel sets the channel for templates without a route
+func WithDefaultChannel(name string) DispatcherOption {
+	return func(d *dispatcher) {
+		d.defaultChannel = name
+	}
+}
+
+// NewDispatcherFromConfig wires up every channel configured in the environment.
+// The log and in-app channels are always available, SMTP and webhook only when configured.
+func NewDispatcherFromConfig() (Dispatcher, error) {
+	routes, err := ParseRoutes(config.Env.NotificationRoutes)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []DispatcherOption{
+		WithRoutes(routes),
+		WithDefaultChannel(config.Env.NotificationDefaultChannel),
+		WithChannel(ChannelLog, NewLogNotifier(), config.Env.NotificationLogConcurrency, config.Env.NotificationLogTimeout),
+		WithChannel(ChannelInApp, NewInboxNotifier(repository.NewInboxRepo()), config.Env.NotificationInAppConcurrency, config.Env.NotificationInAppTimeout),
+	}
+
+	if config.Env.SMTPHost != "" {
+		options = append(options, WithChannel(ChannelSMTP, NewSMTPNotifier(SMTPConfig{
+			Host:     config.Env.SMTPHost,
+			Port:     config.Env.SMTPPort,
+			Username: config.Env.SMTPUsername,
+			Password: config.Env.SMTPPassword,
+			From:     config.Env.SMTPFrom,
+		}), config.Env.SMTPConcurrency, config.Env.SMTPTimeout))
+	}
+
+	if config.Env.NotificationWebhookURL != "" {
+		options = append(options, WithChannel(ChannelWebhook,
+			NewWebhookNotifier(config.Env.NotificationWebhookURL, &http.Client{}),
+			config.Env.NotificationWebhookConcurrency, config.Env.NotificationWebhookTimeout))
+	}
+
+	return NewDispatcher(options...), nil
+}
+
+// ParseRoutes parses a "template:channel,template:channel" list
+func ParseRoutes(routes string) (map[string]string, error) {
+	parsed := map[string]string{}
+
+	for _, route := range strings.Split(routes, ",") {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+
+		templateName, channelName, ok := strings.Cut(route, ":")
+		if !ok || templateName == "" || channelName == "" {
+			return nil, fmt.Errorf("invalid notification route %q", route)
+		}
+
+		parsed[strings.TrimSpace(templateName)] = strings.TrimSpace(channelName)
+	}
+
+	return parsed, nil
+}
+
+// Dispatch renders the notification with its subject's template in the recipient's locale and hands it to
+// each of its channels, waiting for a free slot on a channel if it is saturated. ChannelEmail stands for the
+// channel the template is routed to, the only one that must be configured; other channels the recipient
+// asked for are skipped when the deployment does not provide them.
+//
+// The channels in notification.Delivered are skipped as well. Dispatch returns the channels that delivered
+// the notification on this call, also when another channel failed, so that a retry resends it only on the
+// channels that failed.
+func (d *dispatcher) Dispatch(ctx context.Context, notification Notification) ([]string, error) {
+	notification.Template = templateForSubject(notification.Subject)
+	if notification.Template == "" {
+		return nil, fmt.Errorf("no template for subject %q", notification.Subject)
+	}
+
+	channels := notification.Channels
+	if len(channels) == 0 {
+		channels = []string{ChannelEmail}
+	}
+
+	var resolved []string
+	for _, channelName := range channels {
+		if channelName == ChannelEmail {
+			routed, ok := d.routes[notification.Template]
+			if !ok {
+				routed = d.defaultChannel
+			}
+
+			if _, ok := d.channels[routed]; !ok {
+				return nil, fmt.Errorf("notification channel %q is not configured", routed)
+			}
+
+			if !isDelivered(notification.Delivered, routed) {
+				resolved = append(resolved, routed)
+			}
+			continue
+		}
+
+		if isDelivered(notification.Delivered, channelName) {
+			continue
+		}
+
+		if _, ok := d.channels[channelName]; !ok {
+			logger.D(ctx, "Skipping notification channel that is not configured",
+				logger.Field("channel", channelName),
+				logger.Field("user_id", notification.UserID))
+			continue
+		}
+		resolved = append(resolved, channelName)
+	}
+
+	if len(resolved) == 0 {
+		return nil, nil
+	}
+
+	data, err := decodePayload(notification.Subject, notification.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := RenderTemplate(notification.Template, notification.Locale, data)
+	if err != nil {
+		return nil, err
+	}
+	notification.Content = *content
+
+	var delivered []string
+	var errs []error
+	for _, channelName := range resolved {
+		if err := d.send(ctx, channelName, notification); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delivered = append(delivered, channelName)
+	}
+
+	if len(errs) == 1 {
+		return delivered, errs[0]
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+func isDelivered(delivered []string, channelName string) bool {
+	for _, name := range delivered {
+		if name == channelName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *dispatcher) send(ctx context.Context, channelName string, notification Notification) error {
+	ch := d.channels[channelName]
+
+	select {
+	case ch.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-ch.slots }()
+
+	if ch.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ch.timeout)
+		defer cancel()
+	}
+
+	logger.D(ctx, "Dispatching notification",
+		logger.Field("subject", notification.Subject),
+		logger.Field("channel", channelName),
+		logger.Field("user_id", notification.UserID))
+
+	return ch.notifier.Process(ctx, notification)
+}
@@ -0,0 +1,204 @@
+package notification_worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type notifierFunc func(ctx context.Context, notification Notification) error
+
+func (f notifierFunc) Process(ctx context.Context, notification Notification) error {
+	return f(ctx, notification)
+}
+
+func TestParseRoutes(t *testing.T) {
+	t.Run("should parse template to channel pairs", func(t *testing.T) {
+		routes, err := ParseRoutes(" tiger_sighting:smtp, digest:webhook ,")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"tiger_sighting": "smtp", "digest": "webhook"}, routes)
+	})
+
+	t.Run("should reject malformed routes", func(t *testing.T) {
+		_, err := ParseRoutes("tiger_sighting")
+		assert.EqualError(t, err, `invalid notification route "tiger_sighting"`)
+	})
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	notification := Notification{
+		Subject: EmailNotificationSubjectTigerSighting,
+		UserID:  uuid.New(),
+		Locale:  "en-IN",
+		Data:    json.RawMessage(`{"tiger_id":7,"tiger_name":"Machli","sighted_at":"2024-03-16T05:42:00Z","reported_by_username":"ranger_ravi"}`),
+	}
+
+	t.Run("should route by subject template and render the body", func(t *testing.T) {
+		var received Notification
+		smtp := notifierFunc(func(ctx context.Context, n Notification) error {
+			received = n
+			return nil
+		})
+		log := notifierFunc(func(ctx context.Context, n Notification) error {
+			t.Fatal("log channel should not be used")
+			return nil
+		})
+
+		dispatcher := NewDispatcher(
+			WithRoutes(map[string]string{TemplateTigerSighting: ChannelSMTP}),
+			WithChannel(ChannelSMTP, smtp, 1, time.Second),
+			WithChannel(ChannelLog, log, 1, time.Second),
+		)
+
+		delivered, err := dispatcher.Dispatch(context.Background(), notification)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{ChannelSMTP}, delivered)
+		assert.Equal(t, TemplateTigerSighting, received.Template)
+		assert.Equal(t, "Machli was sighted again", received.Content.Subject)
+		assert.Contains(t, received.Content.Text, "was sighted again by ranger_ravi")
+		assert.Contains(t, received.Content.HTML, "<strong>Machli</strong>")
+	})
+
+	t.Run("should fall back to the default channel", func(t *testing.T) {
+		var used bool
+		dispatcher := NewDispatcher(
+			WithDefaultChannel(ChannelLog),
+			WithChannel(ChannelLog, notifierFunc(func(ctx context.Context, n Notification) error {
+				used = true
+				return nil
+			}), 1, time.Second),
+		)
+
+		_, err := dispatcher.Dispatch(context.Background(), notification)
+		assert.Nil(t, err)
+		assert.True(t, used)
+	})
+
+	t.Run("should fail for unknown subjects and unconfigured channels", func(t *testing.T) {
+		dispatcher := NewDispatcher(WithRoutes(map[string]string{TemplateTigerSighting: ChannelWebhook}))
+
+		_, err := dispatcher.Dispatch(context.Background(), Notification{Subject: "unknown"})
+		assert.EqualError(t, err, `no template for subject "unknown"`)
+
+		_, err = dispatcher.Dispatch(context.Background(), notification)
+		assert.EqualError(t, err, `notification channel "webhook" is not configured`)
+	})
+
+	t.Run("should deliver on every preferred channel that is configured", func(t *testing.T) {
+		var used []string
+		record := func(name string) Notifier {
+			return notifierFunc(func(ctx context.Context, n Notification) error {
+				used = append(used, name)
+				return nil
+			})
+		}
+
+		dispatcher := NewDispatcher(
+			WithRoutes(map[string]string{TemplateTigerSighting: ChannelSMTP}),
+			WithChannel(ChannelSMTP, record(ChannelSMTP), 1, time.Second),
+			WithChannel(ChannelWebhook, record(ChannelWebhook), 1, time.Second),
+		)
+
+		withChannels := notification
+		withChannels.Channels = []string{ChannelEmail, ChannelWebhook, ChannelInApp}
+
+		delivered, err := dispatcher.Dispatch(context.Background(), withChannels)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{ChannelSMTP, ChannelWebhook}, delivered)
+		assert.Equal(t, []string{ChannelSMTP, ChannelWebhook}, used)
+	})
+
+	t.Run("should report the channels that delivered when another one fails", func(t *testing.T) {
+		dispatcher := NewDispatcher(
+			WithRoutes(map[string]string{TemplateTigerSighting: ChannelSMTP}),
+			WithChannel(ChannelSMTP, notifierFunc(func(ctx context.Context, n Notification) error {
+				return errors.New("smtp down")
+			}), 1, time.Second),
+			WithChannel(ChannelWebhook, notifierFunc(func(ctx context.Context, n Notification) error {
+				return nil
+			}), 1, time.Second),
+		)
+
+		withChannels := notification
+		withChannels.Channels = []string{ChannelEmail, ChannelWebhook}
+
+		delivered, err := dispatcher.Dispatch(context.Background(), withChannels)
+		assert.EqualError(t, err, "smtp down")
+		assert.Equal(t, []string{ChannelWebhook}, delivered)
+	})
+
+	t.Run("should skip the channels an earlier attempt delivered on", func(t *testing.T) {
+		var used []string
+		record := func(name string) Notifier {
+			return notifierFunc(func(ctx context.Context, n Notification) error {
+				used = append(used, name)
+				return nil
+			})
+		}
+
+		dispatcher := NewDispatcher(
+			WithRoutes(map[string]string{TemplateTigerSighting: ChannelSMTP}),
+			WithChannel(ChannelSMTP, record(ChannelSMTP), 1, time.Second),
+			WithChannel(ChannelWebhook, record(ChannelWebhook), 1, time.Second),
+		)
+
+		retried := notification
+		retried.Channels = []string{ChannelEmail, ChannelWebhook}
+		retried.Delivered = []string{ChannelWebhook}
+
+		delivered, err := dispatcher.Dispatch(context.Background(), retried)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{ChannelSMTP}, delivered)
+		assert.Equal(t, []string{ChannelSMTP}, used)
+	})
+
+	t.Run("should bound each send by the channel timeout", func(t *testing.T) {
+		dispatcher := NewDispatcher(
+			WithChannel(ChannelLog, notifierFunc(func(ctx context.Context, n Notification) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}), 1, 20*time.Millisecond),
+		)
+
+		_, err := dispatcher.Dispatch(context.Background(), notification)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("should not exceed the channel concurrency limit", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		dispatcher := NewDispatcher(
+			WithChannel(ChannelLog, notifierFunc(func(ctx context.Context, n Notification) error {
+				current := atomic.AddInt32(&inFlight, 1)
+				for {
+					seen := atomic.LoadInt32(&maxInFlight)
+					if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&inFlight, -1)
+				return nil
+			}), 2, time.Second),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := dispatcher.Dispatch(context.Background(), notification)
+				assert.Nil(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, maxInFlight, int32(2))
+	})
+}
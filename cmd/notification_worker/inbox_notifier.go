@@ -0,0 +1,41 @@
+package notification_worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+type inboxNotifier struct {
+	inboxRepo repository.InboxRepo
+}
+
+// NewInboxNotifier returns the in-app channel, which stores notifications in the recipient's inbox
+func NewInboxNotifier(repo repository.InboxRepo) Notifier {
+	return &inboxNotifier{inboxRepo: repo}
+}
+
+func (n *inboxNotifier) Process(ctx context.Context, notification Notification) error {
+	data, ok := notification.Data.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(notification.Data); err != nil {
+			return err
+		}
+	}
+
+	return n.inboxRepo.CreateNotification(ctx, &model.InboxNotification{
+		ID:             uuid.New(),
+		UserID:         notification.UserID,
+		NotificationID: notification.ID,
+		Subject:        notification.Subject,
+		Template:       notification.Template,
+		Title:          notification.Content.Subject,
+		Body:           notification.Content.Text,
+		Data:           data,
+	})
+}
@@ -0,0 +1,57 @@
+package notification_worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestInboxNotifier_Process(t *testing.T) {
+	notification := Notification{
+		ID:       uuid.New(),
+		Subject:  EmailNotificationSubjectTigerSighting,
+		UserID:   uuid.New(),
+		Template: TemplateTigerSighting,
+		Data:     json.RawMessage(`{"tiger_id":42}`),
+		Content:  Content{Subject: "Machli was sighted again", Text: "body"},
+	}
+
+	t.Run("should store the rendered notification in the recipient's inbox", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInboxRepo := mock_repository.NewMockInboxRepo(ctrl)
+		mockInboxRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, inboxNotification *model.InboxNotification) error {
+				assert.Equal(t, notification.ID, inboxNotification.NotificationID)
+				assert.Equal(t, notification.UserID, inboxNotification.UserID)
+				assert.Equal(t, TemplateTigerSighting, inboxNotification.Template)
+				assert.Equal(t, "Machli was sighted again", inboxNotification.Title)
+				assert.Equal(t, "body", inboxNotification.Body)
+				assert.JSONEq(t, `{"tiger_id":42}`, string(inboxNotification.Data))
+				return nil
+			})
+
+		err := NewInboxNotifier(mockInboxRepo).Process(context.Background(), notification)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return error when the inbox cannot be written", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInboxRepo := mock_repository.NewMockInboxRepo(ctrl)
+		mockInboxRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+		err := NewInboxNotifier(mockInboxRepo).Process(context.Background(), notification)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,25 @@
+package notification_worker
+
+import (
+	"context"
+	"fmt"
+
+	"tigerhall_kittens/internal/logger"
+)
+
+type logNotifier struct{}
+
+// NewLogNotifier returns a sink that only logs notifications, useful in development
+// and for subjects nobody needs delivered for real yet.
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Process(ctx context.Context, notification Notification) error {
+	logger.I(ctx, fmt.Sprintf("Sending %s notification to user %s", notification.Subject, notification.UserID),
+		logger.Field("template", notification.Template),
+		logger.Field("locale", notification.Locale),
+		logger.Field("title", notification.Content.Subject),
+		logger.Field("body", notification.Content.Text))
+	return nil
+}
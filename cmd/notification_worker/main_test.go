@@ -0,0 +1,22 @@
+package notification_worker
+
+import (
+	"os"
+	"testing"
+
+	"tigerhall_kittens/internal/secretbox"
+	"tigerhall_kittens/test_helpers"
+)
+
+func TestMain(m *testing.M) {
+	test_helpers.InitializeLogger()
+
+	secretKey, err := secretbox.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	if err := secretbox.Set(secretKey); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
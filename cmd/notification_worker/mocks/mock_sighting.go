@@ -7,6 +7,8 @@ package mock_notification_worker
 import (
 	context "context"
 	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+	repository "tigerhall_kittens/internal/repository"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,16 +36,18 @@ func (m *MockSightingEmailNotifer) EXPECT() *MockSightingEmailNotiferMockRecorde
 	return m.recorder
 }
 
-// ReportSightingToAllUsers mocks base method.
-func (m *MockSightingEmailNotifer) ReportSightingToAllUsers(ctx context.Context, tigerID uint) error {
+// BuildSightingNotifications mocks base method.
+func (m *MockSightingEmailNotifer) BuildSightingNotifications(ctx context.Context, tiger *model.Tiger, sighting *model.Sighting) ([]model.OutboxNotification, repository.GeofenceAlertBuilder, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReportSightingToAllUsers", ctx, tigerID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "BuildSightingNotifications", ctx, tiger, sighting)
+	ret0, _ := ret[0].([]model.OutboxNotification)
+	ret1, _ := ret[1].(repository.GeofenceAlertBuilder)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
-// ReportSightingToAllUsers indicates an expected call of ReportSightingToAllUsers.
-func (mr *MockSightingEmailNotiferMockRecorder) ReportSightingToAllUsers(ctx, tigerID interface{}) *gomock.Call {
+// BuildSightingNotifications indicates an expected call of BuildSightingNotifications.
+func (mr *MockSightingEmailNotiferMockRecorder) BuildSightingNotifications(ctx, tiger, sighting interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSightingToAllUsers", reflect.TypeOf((*MockSightingEmailNotifer)(nil).ReportSightingToAllUsers), ctx, tigerID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildSightingNotifications", reflect.TypeOf((*MockSightingEmailNotifer)(nil).BuildSightingNotifications), ctx, tiger, sighting)
 }
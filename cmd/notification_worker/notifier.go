@@ -1,15 +1,53 @@
 package notification_worker
 
-import "github.com/google/uuid"
+import (
+	"context"
 
-const EmailNotificationSubjectTigerSighting = "Tiger Sighting Email"
+	"github.com/google/uuid"
+)
+
+const (
+	EmailNotificationSubjectTigerSighting    = "Tiger Sighting Email"
+	EmailNotificationSubjectGeofenceSighting = "Geofence Sighting Email"
+	EmailNotificationSubjectDigest           = "Notification Digest Email"
+	EmailNotificationSubjectVerifyEmail      = "Email Verification Email"
+	EmailNotificationSubjectResetPassword    = "Password Reset Email"
+	// EmailNotificationSubjectConfirmEmailChange is mailed to the new address of a user
+	EmailNotificationSubjectConfirmEmailChange = "Email Change Confirmation Email"
+	EmailNotificationSubjectDataExportReady    = "Data Export Ready Email"
+)
+
+const (
+	ChannelSMTP    = "smtp"
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+	ChannelInApp   = "in_app"
+
+	// ChannelEmail is not a notifier of its own, it is delivered over whichever
+	// channel NOTIFICATION_ROUTES picks for the template
+	ChannelEmail = "email"
+)
 
 type Notification struct {
+	// ID is the outbox row the notification is sent for
+	ID      uuid.UUID
 	Subject string
 	UserID  uuid.UUID
+	Email   string
+	Locale  string
 	Data    interface{}
+	// Channels the recipient wants the notification on, ChannelEmail when empty
+	Channels []string
+	// Delivered are the channels an earlier attempt already delivered the notification on
+	Delivered []string
+
+	// Template and Content are filled in by the dispatcher from the subject's route
+	Template string
+	Content  Content
 }
 
+// Notifier delivers a rendered notification over one channel. Implementations
+// must honour ctx, which carries the channel's timeout.
 type Notifier interface {
-	Process(userID uuid.UUID, data interface{}) error
+	Process(ctx context.Context, notification Notification) error
 }
@@ -0,0 +1,113 @@
+package notification_worker
+
+import (
+	"encoding/json"
+	"time"
+
+	"tigerhall_kittens/internal/model"
+)
+
+const quietHoursLayout = "15:04"
+
+// preferredChannels maps the channels a user enabled to the channels the dispatcher delivers on
+func preferredChannels(preferences *model.NotificationPreferences) []string {
+	var channels []string
+	if preferences.EmailEnabled {
+		channels = append(channels, ChannelEmail)
+	}
+
+	if preferences.WebhookEnabled {
+		channels = append(channels, ChannelWebhook)
+	}
+
+	if preferences.InAppEnabled {
+		channels = append(channels, ChannelInApp)
+	}
+
+	return channels
+}
+
+func preferencesLocation(preferences *model.NotificationPreferences) *time.Location {
+	location, err := time.LoadLocation(preferences.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// quietHoursEnd reports whether now falls in the user's quiet hours and, if so, when they end
+func quietHoursEnd(preferences *model.NotificationPreferences, now time.Time) (time.Time, bool) {
+	if preferences.QuietHoursStart == nil || preferences.QuietHoursEnd == nil {
+		return time.Time{}, false
+	}
+
+	start, err := time.Parse(quietHoursLayout, *preferences.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	end, err := time.Parse(quietHoursLayout, *preferences.QuietHoursEnd)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(preferencesLocation(preferences))
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	var quiet bool
+	switch {
+	case startMinute < endMinute:
+		quiet = minute >= startMinute && minute < endMinute
+	case startMinute > endMinute:
+		// the window wraps past midnight, e.g. 22:00 to 06:00
+		quiet = minute >= startMinute || minute < endMinute
+	}
+
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, local.Location())
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+
+	return until, true
+}
+
+// digestDue reports whether the user's next digest should be built at now. Daily digests go
+// out once dailyHour has passed in the user's time zone. The first digest of a user, and whatever
+// is still held back for a user who switched back to immediate delivery, goes out right away.
+func digestDue(preferences *model.NotificationPreferences, now time.Time, dailyHour int) bool {
+	if preferences.LastDigestAt == nil {
+		return true
+	}
+
+	switch preferences.Delivery {
+	case model.DeliveryHourlyDigest:
+		return now.Sub(*preferences.LastDigestAt) >= time.Hour
+	case model.DeliveryDailyDigest:
+		local := now.In(preferencesLocation(preferences))
+		due := time.Date(local.Year(), local.Month(), local.Day(), dailyHour, 0, 0, 0, local.Location())
+		if local.Before(due) {
+			due = due.AddDate(0, 0, -1)
+		}
+
+		return preferences.LastDigestAt.Before(due)
+	default:
+		return true
+	}
+}
+
+// payloadTigerID returns the tiger an outbox payload is about, 0 when it is not about a tiger
+func payloadTigerID(payload json.RawMessage) uint {
+	var tiger struct {
+		TigerID uint `json:"tiger_id"`
+	}
+	_ = json.Unmarshal(payload, &tiger)
+
+	return tiger.TigerID
+}
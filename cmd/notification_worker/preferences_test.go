@@ -0,0 +1,89 @@
+package notification_worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+)
+
+func TestQuietHoursEnd(t *testing.T) {
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+
+	preferences := func(start, end string) *model.NotificationPreferences {
+		p := model.DefaultNotificationPreferences(uuid.New())
+		p.QuietHoursStart, p.QuietHoursEnd = &start, &end
+		p.TimeZone = "Asia/Kolkata"
+		return p
+	}
+
+	t.Run("should not be quiet without quiet hours", func(t *testing.T) {
+		_, quiet := quietHoursEnd(model.DefaultNotificationPreferences(uuid.New()), time.Now())
+		assert.False(t, quiet)
+	})
+
+	t.Run("should honour a window within the day in the user's time zone", func(t *testing.T) {
+		now := time.Date(2024, 3, 16, 13, 30, 0, 0, kolkata)
+
+		until, quiet := quietHoursEnd(preferences("13:00", "15:00"), now.UTC())
+		assert.True(t, quiet)
+		assert.True(t, until.Equal(time.Date(2024, 3, 16, 15, 0, 0, 0, kolkata)))
+
+		_, quiet = quietHoursEnd(preferences("13:00", "15:00"), now.Add(2*time.Hour))
+		assert.False(t, quiet)
+	})
+
+	t.Run("should honour a window that wraps past midnight", func(t *testing.T) {
+		lateNight := time.Date(2024, 3, 16, 23, 0, 0, 0, kolkata)
+		until, quiet := quietHoursEnd(preferences("22:00", "06:00"), lateNight)
+		assert.True(t, quiet)
+		assert.True(t, until.Equal(time.Date(2024, 3, 17, 6, 0, 0, 0, kolkata)))
+
+		earlyMorning := time.Date(2024, 3, 17, 5, 0, 0, 0, kolkata)
+		until, quiet = quietHoursEnd(preferences("22:00", "06:00"), earlyMorning)
+		assert.True(t, quiet)
+		assert.True(t, until.Equal(time.Date(2024, 3, 17, 6, 0, 0, 0, kolkata)))
+
+		_, quiet = quietHoursEnd(preferences("22:00", "06:00"), time.Date(2024, 3, 17, 12, 0, 0, 0, kolkata))
+		assert.False(t, quiet)
+	})
+}
+
+func TestDigestDue(t *testing.T) {
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+
+	preferences := func(delivery model.DeliveryMode, lastDigestAt time.Time) *model.NotificationPreferences {
+		p := model.DefaultNotificationPreferences(uuid.New())
+		p.Delivery = delivery
+		p.TimeZone = "Asia/Kolkata"
+		p.LastDigestAt = &lastDigestAt
+		return p
+	}
+
+	t.Run("should send the first digest right away", func(t *testing.T) {
+		p := model.DefaultNotificationPreferences(uuid.New())
+		p.Delivery = model.DeliveryDailyDigest
+		assert.True(t, digestDue(p, time.Now(), 8))
+	})
+
+	t.Run("should send hourly digests an hour apart", func(t *testing.T) {
+		now := time.Now()
+		assert.False(t, digestDue(preferences(model.DeliveryHourlyDigest, now.Add(-30*time.Minute)), now, 8))
+		assert.True(t, digestDue(preferences(model.DeliveryHourlyDigest, now.Add(-time.Hour)), now, 8))
+	})
+
+	t.Run("should send daily digests once the digest hour passed in the user's time zone", func(t *testing.T) {
+		yesterday := time.Date(2024, 3, 15, 8, 0, 0, 0, kolkata)
+
+		assert.False(t, digestDue(preferences(model.DeliveryDailyDigest, yesterday), time.Date(2024, 3, 16, 7, 59, 0, 0, kolkata), 8))
+		assert.True(t, digestDue(preferences(model.DeliveryDailyDigest, yesterday), time.Date(2024, 3, 16, 8, 0, 0, 0, kolkata), 8))
+		assert.False(t, digestDue(preferences(model.DeliveryDailyDigest, time.Date(2024, 3, 16, 8, 1, 0, 0, kolkata)), time.Date(2024, 3, 16, 20, 0, 0, 0, kolkata), 8))
+	})
+
+	t.Run("should flush held notifications after switching back to immediate delivery", func(t *testing.T) {
+		assert.True(t, digestDue(preferences(model.DeliveryImmediate, time.Now()), time.Now(), 8))
+	})
+}
@@ -2,69 +2,189 @@ package notification_worker
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"tigerhall_kittens/internal/logger"
+	"time"
 
 	"github.com/google/uuid"
 
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
 )
 
 type sightingEmailNotifier struct {
-	sightingRepo repository.SightingRepo
+	sightingRepo     repository.SightingRepo
+	subscriptionRepo repository.SubscriptionRepo
+	userRepo         repository.UserRepo
 }
 
 type SightingEmailNotifer interface {
-	ReportSightingToAllUsers(ctx context.Context, tigerID uint) error
+	BuildSightingNotifications(ctx context.Context, tiger *model.Tiger, sighting *model.Sighting) ([]model.OutboxNotification, repository.GeofenceAlertBuilder, error)
+}
+
+type SightingEmailNotiferOption func(notifier *sightingEmailNotifier)
+
+func NewSightingEmailNotifer(options ...SightingEmailNotiferOption) SightingEmailNotifer {
+	notifier := &sightingEmailNotifier{
+		sightingRepo:     repository.NewSightingRepo(),
+		subscriptionRepo: repository.NewSubscriptionRepo(),
+		userRepo:         repository.NewUserRepo(),
+	}
+
+	for _, option := range options {
+		option(notifier)
+	}
+
+	return notifier
 }
 
-func NewSightingEmailNotifer() SightingEmailNotifer {
-	return &sightingEmailNotifier{
-		sightingRepo: repository.NewSightingRepo(),
+func WithSightingRepoForNotifier(repo repository.SightingRepo) SightingEmailNotiferOption {
+	return func(n *sightingEmailNotifier) {
+		n.sightingRepo = repo
 	}
 }
 
-func (e *sightingEmailNotifier) Process(userID uuid.UUID, data interface{}) {
-	notification := Notification{
-		Subject: EmailNotificationSubjectTigerSighting,
-		UserID:  userID,
-		Data:    data,
+func WithSubscriptionRepoForNotifier(repo repository.SubscriptionRepo) SightingEmailNotiferOption {
+	return func(n *sightingEmailNotifier) {
+		n.subscriptionRepo = repo
 	}
+}
 
-	notificationQueue <- notification
+func WithUserRepoForNotifier(repo repository.UserRepo) SightingEmailNotiferOption {
+	return func(n *sightingEmailNotifier) {
+		n.userRepo = repo
+	}
 }
 
+// TigerSightingEmail is the outbox payload of EmailNotificationSubjectTigerSighting. It is a
+// snapshot of everything the templates render, so sending never has to look the sighting up again.
 type TigerSightingEmail struct {
-	UserID  uint
-	TigerID uint
+	UserID             uuid.UUID `json:"user_id"`
+	TigerID            uint      `json:"tiger_id"`
+	TigerName          string    `json:"tiger_name"`
+	SightingID         uuid.UUID `json:"sighting_id"`
+	SightedAt          time.Time `json:"sighted_at"`
+	Lat                float64   `json:"lat"`
+	Lon                float64   `json:"lon"`
+	ReportedByUsername string    `json:"reported_by_username"`
+	ImageURL           string    `json:"image_url,omitempty"`
 }
 
-// ReportSightingToAllUsers simulates reporting a tiger sighting and sends a notification email
-func (e *sightingEmailNotifier) ReportSightingToAllUsers(ctx context.Context, tigerID uint) error {
-	reportedByUser := ctx.Value("userID")
+// GeofenceSightingEmail is the outbox payload of EmailNotificationSubjectGeofenceSighting
+type GeofenceSightingEmail struct {
+	TigerSightingEmail
+	GeofenceID   uuid.UUID `json:"geofence_id"`
+	GeofenceName string    `json:"geofence_name"`
+}
+
+// BuildSightingNotifications resolves everyone who should hear about a new sighting,
+// users who reported the tiger before and users subscribed to it, and returns the
+// outbox rows to be stored along with the sighting. Every recipient is notified
+// once, and the reporter is never notified of their own sighting.
+//
+// Geofences are matched while the sighting is stored, so the owners of matching
+// geofences are left to the returned GeofenceAlertBuilder.
+func (e *sightingEmailNotifier) BuildSightingNotifications(ctx context.Context, tiger *model.Tiger, sighting *model.Sighting) ([]model.OutboxNotification, repository.GeofenceAlertBuilder, error) {
+	reporter, err := e.userRepo.GetUser(ctx, repository.GetUserOpts{ID: sighting.ReportedByUserID})
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching reporter of the sighting", logger.Field("user_id", sighting.ReportedByUserID))
+		return nil, nil, errors.New("error while fetching reporter")
+	}
+
+	recipients, err := e.recipients(ctx, sighting)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload := func(userID uuid.UUID) TigerSightingEmail {
+		return TigerSightingEmail{
+			UserID:             userID,
+			TigerID:            tiger.ID,
+			TigerName:          tiger.Name,
+			SightingID:         sighting.ID,
+			SightedAt:          sighting.SightedAt,
+			Lat:                sighting.Lat,
+			Lon:                sighting.Lon,
+			ReportedByUsername: reporter.Username,
+			ImageURL:           sighting.ImageURL,
+		}
+	}
+
+	var notifications []model.OutboxNotification
+	for _, userID := range recipients {
+		notification, err := NewOutboxNotification(EmailNotificationSubjectTigerSighting, userID, payload(userID))
+		if err != nil {
+			logger.E(ctx, err, "Error while building sighting notification", logger.Field("tiger_id", sighting.TigerID))
+			return nil, nil, err
+		}
+
+		notifications = append(notifications, *notification)
+	}
 
-	tigerSightingEmail := TigerSightingEmail{
-		TigerID: tigerID,
+	geofenceAlert := func(geofence model.Geofence) (*model.OutboxNotification, error) {
+		return NewOutboxNotification(EmailNotificationSubjectGeofenceSighting, geofence.UserID, GeofenceSightingEmail{
+			TigerSightingEmail: payload(geofence.UserID),
+			GeofenceID:         geofence.ID,
+			GeofenceName:       geofence.Name,
+		})
 	}
 
+	return notifications, geofenceAlert, nil
+}
+
+// recipients returns the prior reporters of the tiger followed by its subscribers,
+// deduplicated and without the reporter of the sighting
+func (e *sightingEmailNotifier) recipients(ctx context.Context, sighting *model.Sighting) ([]uuid.UUID, error) {
 	sightings, err := e.sightingRepo.GetSightings(ctx, repository.GetSightingOpts{
-		TigerID:       tigerID,
-		ExcludeUserID: reportedByUser.(string),
+		TigerID:       sighting.TigerID,
+		ExcludeUserID: sighting.ReportedByUserID.String(),
 	})
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching existing sightings for the tiger", logger.Field("tiger_id", sighting.TigerID))
+		return nil, errors.New("error while fetching existing sightings")
+	}
 
+	subscriptions, err := e.subscriptionRepo.GetSubscriptions(ctx, repository.ListSubscriptionsOpts{TigerID: sighting.TigerID})
 	if err != nil {
-		logger.E(ctx, err, "Error while fetching existing sightings for the tiger", logger.Field("tiger_id", tigerID))
-		return errors.New("error while fetching existing sightings")
+		logger.E(ctx, err, "Error while fetching subscribers of the tiger", logger.Field("tiger_id", sighting.TigerID))
+		return nil, errors.New("error while fetching subscribers")
 	}
 
-	for _, sighting := range sightings {
-		wg.Add(1)
-		sighting := sighting
-		go func() {
-			defer wg.Done()
-			e.Process(sighting.ReportedByUserID, tigerSightingEmail)
-		}()
+	var recipients []uuid.UUID
+	seen := map[uuid.UUID]bool{sighting.ReportedByUserID: true}
+	add := func(userID uuid.UUID) {
+		if seen[userID] {
+			return
+		}
+		seen[userID] = true
+		recipients = append(recipients, userID)
+	}
+
+	for _, existing := range sightings {
+		add(existing.ReportedByUserID)
+	}
+
+	for _, subscription := range subscriptions {
+		add(subscription.UserID)
+	}
+
+	return recipients, nil
+}
+
+// NewOutboxNotification builds a pending outbox row for userID carrying data as its payload.
+func NewOutboxNotification(subject string, userID uuid.UUID, data interface{}) (*model.OutboxNotification, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &model.OutboxNotification{
+		ID:            uuid.New(),
+		Subject:       subject,
+		UserID:        userID,
+		Payload:       payload,
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}, nil
 }
@@ -0,0 +1,130 @@
+package notification_worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestSightingEmailNotifier_BuildSightingNotifications(t *testing.T) {
+	tiger := &model.Tiger{ID: 1, Name: "Machli"}
+	reporter := &model.User{ID: uuid.New(), Username: "ranger_ravi"}
+	priorReporter := uuid.New()
+	subscriber := uuid.New()
+
+	sighting := &model.Sighting{
+		ID:               uuid.New(),
+		TigerID:          tiger.ID,
+		ReportedByUserID: reporter.ID,
+		SightedAt:        time.Now(),
+	}
+	getSightingOpts := repository.GetSightingOpts{TigerID: tiger.ID, ExcludeUserID: reporter.ID.String()}
+	listSubscriptionsOpts := repository.ListSubscriptionsOpts{TigerID: tiger.ID}
+
+	t.Run("should return error when subscribers cannot be fetched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: reporter.ID}).Return(reporter, nil)
+
+		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
+		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(nil, nil)
+
+		mockSubscriptionRepo := mock_repository.NewMockSubscriptionRepo(ctrl)
+		mockSubscriptionRepo.EXPECT().GetSubscriptions(ctx, listSubscriptionsOpts).Return(nil, errors.New("some db error"))
+
+		notifier := NewSightingEmailNotifer(WithUserRepoForNotifier(mockUserRepo),
+			WithSightingRepoForNotifier(mockSightingRepo), WithSubscriptionRepoForNotifier(mockSubscriptionRepo))
+
+		notifications, geofenceAlert, err := notifier.BuildSightingNotifications(ctx, tiger, sighting)
+		assert.Nil(t, notifications)
+		assert.Nil(t, geofenceAlert)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should notify prior reporters and subscribers once, excluding the reporter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: reporter.ID}).Return(reporter, nil)
+
+		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
+		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return([]model.Sighting{
+			{ID: uuid.New(), TigerID: tiger.ID, ReportedByUserID: priorReporter},
+			{ID: uuid.New(), TigerID: tiger.ID, ReportedByUserID: priorReporter},
+		}, nil)
+
+		mockSubscriptionRepo := mock_repository.NewMockSubscriptionRepo(ctrl)
+		mockSubscriptionRepo.EXPECT().GetSubscriptions(ctx, listSubscriptionsOpts).Return([]model.TigerSubscription{
+			{UserID: priorReporter, TigerID: tiger.ID},
+			{UserID: reporter.ID, TigerID: tiger.ID},
+			{UserID: subscriber, TigerID: tiger.ID},
+		}, nil)
+
+		notifier := NewSightingEmailNotifer(WithUserRepoForNotifier(mockUserRepo),
+			WithSightingRepoForNotifier(mockSightingRepo), WithSubscriptionRepoForNotifier(mockSubscriptionRepo))
+
+		notifications, _, err := notifier.BuildSightingNotifications(ctx, tiger, sighting)
+		assert.Nil(t, err)
+		assert.Len(t, notifications, 2)
+		assert.Equal(t, priorReporter, notifications[0].UserID)
+		assert.Equal(t, subscriber, notifications[1].UserID)
+
+		var payload TigerSightingEmail
+		_ = json.Unmarshal(notifications[1].Payload, &payload)
+		assert.Equal(t, subscriber, payload.UserID)
+		assert.Equal(t, "Machli", payload.TigerName)
+		assert.Equal(t, "ranger_ravi", payload.ReportedByUsername)
+	})
+
+	t.Run("should build geofence alerts for the owner of the geofence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: reporter.ID}).Return(reporter, nil)
+
+		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
+		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(nil, nil)
+
+		mockSubscriptionRepo := mock_repository.NewMockSubscriptionRepo(ctrl)
+		mockSubscriptionRepo.EXPECT().GetSubscriptions(ctx, listSubscriptionsOpts).Return(nil, nil)
+
+		notifier := NewSightingEmailNotifer(WithUserRepoForNotifier(mockUserRepo),
+			WithSightingRepoForNotifier(mockSightingRepo), WithSubscriptionRepoForNotifier(mockSubscriptionRepo))
+
+		notifications, geofenceAlert, err := notifier.BuildSightingNotifications(ctx, tiger, sighting)
+		assert.Nil(t, err)
+		assert.Empty(t, notifications)
+
+		geofence := model.Geofence{ID: uuid.New(), UserID: subscriber, Name: "Rajbagh lake"}
+		alert, err := geofenceAlert(geofence)
+		assert.Nil(t, err)
+		assert.Equal(t, subscriber, alert.UserID)
+		assert.Equal(t, EmailNotificationSubjectGeofenceSighting, alert.Subject)
+
+		var payload GeofenceSightingEmail
+		_ = json.Unmarshal(alert.Payload, &payload)
+		assert.Equal(t, geofence.ID, payload.GeofenceID)
+		assert.Equal(t, "Rajbagh lake", payload.GeofenceName)
+		assert.Equal(t, "Machli", payload.TigerName)
+	})
+}
@@ -0,0 +1,155 @@
+package notification_worker
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTPServer speaks just enough SMTP to accept mail from net/smtp and records what it received
+type fakeSMTPServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	from     string
+	rcpt     []string
+	data     string
+	stall    bool
+}
+
+func newFakeSMTPServer(t *testing.T, stall bool) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	s := &fakeSMTPServer{listener: listener, stall: stall}
+	go s.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return s
+}
+
+func (s *fakeSMTPServer) hostPort() (string, string) {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host, port
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	if s.stall {
+		time.Sleep(time.Second)
+		return
+	}
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP fake")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 localhost")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = line
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, line)
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPNotifier_Process(t *testing.T) {
+	notification := Notification{
+		Subject: EmailNotificationSubjectTigerSighting,
+		UserID:  uuid.New(),
+		Email:   "ranger@example.com",
+		Content: Content{
+			Subject: "Machli was sighted again",
+			Text:    "Tiger 1 was sighted again",
+			HTML:    "<p>Tiger 1 was sighted again</p>",
+		},
+	}
+
+	t.Run("should deliver the notification to the SMTP server", func(t *testing.T) {
+		server := newFakeSMTPServer(t, false)
+		host, port := server.hostPort()
+
+		notifier := NewSMTPNotifier(SMTPConfig{Host: host, Port: port, From: "alerts@tigerhall.io"})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := notifier.Process(ctx, notification)
+		assert.Nil(t, err)
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		assert.Equal(t, "MAIL FROM:<alerts@tigerhall.io>", strings.SplitN(server.from, " BODY", 2)[0])
+		assert.Equal(t, []string{"RCPT TO:<ranger@example.com>"}, server.rcpt)
+		assert.Contains(t, server.data, "Subject: Machli was sighted again")
+		assert.Contains(t, server.data, "Content-Type: multipart/alternative")
+		assert.Contains(t, server.data, "Tiger 1 was sighted again")
+		assert.Contains(t, server.data, "<p>Tiger 1 was sighted again</p>")
+	})
+
+	t.Run("should fail when recipient has no email", func(t *testing.T) {
+		notifier := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: "25"})
+
+		err := notifier.Process(context.Background(), Notification{UserID: uuid.New()})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should give up when the server does not answer before the timeout", func(t *testing.T) {
+		server := newFakeSMTPServer(t, true)
+		host, port := server.hostPort()
+
+		notifier := NewSMTPNotifier(SMTPConfig{Host: host, Port: port, From: "alerts@tigerhall.io"})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		err := notifier.Process(ctx, notification)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,228 @@
+package notification_worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/config"
+	"tigerhall_kittens/internal/lifecycle"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+const (
+	WebhookSignatureHeader = "X-Tigerhall-Signature"
+	WebhookEventHeader     = "X-Tigerhall-Event"
+	WebhookDeliveryHeader  = "X-Tigerhall-Delivery"
+
+	// maxLoggedResponseBody bounds how much of a partner's response ends up in the delivery log
+	maxLoggedResponseBody = 1024
+)
+
+type WebhookDeliveryConfig struct {
+	WorkerConfig
+	Timeout time.Duration
+}
+
+type WebhookDeliveryWorker interface {
+	Run(ctx context.Context)
+	ProcessBatch(ctx context.Context) int
+}
+
+type webhookDeliveryWorker struct {
+	webhookRepo repository.WebhookRepo
+	client      *http.Client
+	config      WebhookDeliveryConfig
+}
+
+type WebhookDeliveryWorkerOption func(w *webhookDeliveryWorker)
+
+func NewWebhookDeliveryWorker(options ...WebhookDeliveryWorkerOption) WebhookDeliveryWorker {
+	w := &webhookDeliveryWorker{
+		webhookRepo: repository.NewWebhookRepo(),
+		client:      &http.Client{},
+		config: WebhookDeliveryConfig{
+			WorkerConfig: WorkerConfig{
+				PollInterval:   config.Env.NotificationPollInterval,
+				BatchSize:      config.Env.NotificationBatchSize,
+				MaxAttempts:    config.Env.WebhookMaxAttempts,
+				RetryBaseDelay: config.Env.NotificationRetryBaseDelay,
+				ClaimLease:     config.Env.NotificationClaimLease,
+			},
+			Timeout: config.Env.WebhookDeliveryTimeout,
+		},
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+func WithWebhookRepoForDelivery(repo repository.WebhookRepo) WebhookDeliveryWorkerOption {
+	return func(w *webhookDeliveryWorker) {
+		w.webhookRepo = repo
+	}
+}
+
+func WithHTTPClientForDelivery(client *http.Client) WebhookDeliveryWorkerOption {
+	return func(w *webhookDeliveryWorker) {
+		w.client = client
+	}
+}
+
+func WithWebhookDeliveryConfig(cfg WebhookDeliveryConfig) WebhookDeliveryWorkerOption {
+	return func(w *webhookDeliveryWorker) {
+		w.config = cfg
+	}
+}
+
+// SignWebhookPayload returns the X-Tigerhall-Signature header of a delivery, "t=<unix time>,v1=<hex
+// HMAC-SHA256 of "<unix time>.<body>" keyed with the subscription secret>". Signing the timestamp
+// lets receivers reject replayed deliveries.
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%s,v1=%s", unix, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Run polls for due deliveries until ctx is cancelled, like the notification worker
+func (w *webhookDeliveryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed := w.ProcessBatch(lifecycle.Detach(ctx))
+		if claimed > 0 && claimed == w.config.BatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *webhookDeliveryWorker) ProcessBatch(ctx context.Context) int {
+	deliveries, err := w.webhookRepo.ClaimDeliveries(ctx, repository.ClaimOutboxOpts{
+		Limit: w.config.BatchSize,
+		Lease: w.config.ClaimLease,
+	})
+	if err != nil {
+		return 0
+	}
+
+	var batch sync.WaitGroup
+	for i := range deliveries {
+		batch.Add(1)
+		delivery := &deliveries[i]
+		go func() {
+			defer batch.Done()
+			w.deliver(ctx, delivery)
+		}()
+	}
+	batch.Wait()
+
+	return len(deliveries)
+}
+
+// deliver POSTs the delivery to its subscription and logs the attempt. A 2xx response delivers it,
+// anything else is retried with backoff until the attempts run out.
+func (w *webhookDeliveryWorker) deliver(ctx context.Context, delivery *model.WebhookDelivery) {
+	attempt := &model.WebhookDeliveryAttempt{
+		ID:         uuid.New(),
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		CreatedAt:  time.Now(),
+	}
+
+	statusCode, body, err := w.post(ctx, delivery)
+	attempt.DurationMs = time.Since(attempt.CreatedAt).Milliseconds()
+	attempt.ResponseBody = body
+
+	delivery.ResponseCode = nil
+	if statusCode != 0 {
+		attempt.ResponseCode = &statusCode
+		delivery.ResponseCode = &statusCode
+	}
+
+	if err == nil && (statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices) {
+		err = fmt.Errorf("webhook responded with status %d", statusCode)
+	}
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = model.WebhookDeliveryStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		attempt.Error = err.Error()
+		delivery.LastError = err.Error()
+		delivery.Status = model.WebhookDeliveryStatusPending
+		delivery.NextAttemptAt = time.Now().Add(retryDelay(w.config.RetryBaseDelay, delivery.Attempts))
+
+		if delivery.Attempts >= w.config.MaxAttempts {
+			delivery.Status = model.WebhookDeliveryStatusDead
+		}
+
+		logger.W(ctx, "Failed to deliver webhook",
+			logger.Field("delivery_id", delivery.ID),
+			logger.Field("webhook_id", delivery.SubscriptionID),
+			logger.Field("attempts", delivery.Attempts),
+			logger.Field("status", delivery.Status),
+			logger.Field("error", err.Error()))
+	}
+
+	_ = w.webhookRepo.RecordAttempt(ctx, delivery, attempt)
+}
+
+func (w *webhookDeliveryWorker) post(ctx context.Context, delivery *model.WebhookDelivery) (int, string, error) {
+	if delivery.Subscription == nil {
+		return 0, "", fmt.Errorf("webhook subscription %s does not exist", delivery.SubscriptionID)
+	}
+
+	if w.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.config.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Subscription.Secret, time.Now(), delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedResponseBody))
+
+	return resp.StatusCode, string(body), nil
+}
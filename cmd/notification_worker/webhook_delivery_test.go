@@ -0,0 +1,153 @@
+package notification_worker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	t.Run("should sign the timestamp and body with the secret", func(t *testing.T) {
+		signature := SignWebhookPayload("0123456789abcdef", time.Unix(1700000000, 0), []byte(`{"event":"tiger.created"}`))
+		assert.Equal(t, "t=1700000000,v1=b03eec7e8a8ebbe5aeaf41774a0c67b2e5109de28ed7cc61214e971d3479294f", signature)
+	})
+}
+
+func TestWebhookDeliveryWorker_ProcessBatch(t *testing.T) {
+	deliveryConfig := WebhookDeliveryConfig{
+		WorkerConfig: WorkerConfig{
+			BatchSize:      10,
+			MaxAttempts:    3,
+			RetryBaseDelay: time.Minute,
+			ClaimLease:     time.Minute,
+		},
+		Timeout: time.Second,
+	}
+	claimOpts := repository.ClaimOutboxOpts{Limit: 10, Lease: time.Minute}
+
+	delivery := func(url string, attempts int) model.WebhookDelivery {
+		return model.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: uuid.New(),
+			Event:          model.WebhookEventSightingCreated,
+			Payload:        []byte(`{"event":"sighting.created"}`),
+			Attempts:       attempts,
+			Subscription:   &model.WebhookSubscription{URL: url, Secret: "0123456789abcdef"},
+		}
+	}
+
+	t.Run("should post a signed delivery and mark it delivered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			signature := r.Header.Get(WebhookSignatureHeader)
+			timestamp, _, _ := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+			unix, _ := strconv.ParseInt(timestamp, 10, 64)
+
+			assert.Equal(t, SignWebhookPayload("0123456789abcdef", time.Unix(unix, 0), body), signature)
+			assert.Equal(t, model.WebhookEventSightingCreated, r.Header.Get(WebhookEventHeader))
+			assert.JSONEq(t, `{"event":"sighting.created"}`, string(body))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		claimed := delivery(server.URL, 1)
+
+		mockWebhookRepo := mock_repository.NewMockWebhookRepo(ctrl)
+		mockWebhookRepo.EXPECT().ClaimDeliveries(ctx, claimOpts).Return([]model.WebhookDelivery{claimed}, nil)
+		mockWebhookRepo.EXPECT().RecordAttempt(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, d *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error {
+				assert.Equal(t, model.WebhookDeliveryStatusDelivered, d.Status)
+				assert.NotNil(t, d.DeliveredAt)
+				assert.Equal(t, http.StatusOK, *attempt.ResponseCode)
+				assert.Equal(t, "ok", attempt.ResponseBody)
+				assert.Equal(t, 1, attempt.Attempt)
+				return nil
+			})
+
+		w := NewWebhookDeliveryWorker(
+			WithWebhookRepoForDelivery(mockWebhookRepo),
+			WithHTTPClientForDelivery(server.Client()),
+			WithWebhookDeliveryConfig(deliveryConfig),
+		)
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+
+	t.Run("should retry a failed delivery with backoff", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		claimed := delivery(server.URL, 2)
+
+		mockWebhookRepo := mock_repository.NewMockWebhookRepo(ctrl)
+		mockWebhookRepo.EXPECT().ClaimDeliveries(ctx, claimOpts).Return([]model.WebhookDelivery{claimed}, nil)
+		mockWebhookRepo.EXPECT().RecordAttempt(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, d *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error {
+				assert.Equal(t, model.WebhookDeliveryStatusPending, d.Status)
+				assert.Equal(t, http.StatusBadGateway, *d.ResponseCode)
+				assert.WithinDuration(t, time.Now().Add(2*time.Minute), d.NextAttemptAt, 5*time.Second)
+				assert.Equal(t, "webhook responded with status 502", attempt.Error)
+				return nil
+			})
+
+		w := NewWebhookDeliveryWorker(
+			WithWebhookRepoForDelivery(mockWebhookRepo),
+			WithHTTPClientForDelivery(server.Client()),
+			WithWebhookDeliveryConfig(deliveryConfig),
+		)
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+
+	t.Run("should dead-letter a delivery out of attempts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		claimed := delivery("http://127.0.0.1:0", 3)
+
+		mockWebhookRepo := mock_repository.NewMockWebhookRepo(ctrl)
+		mockWebhookRepo.EXPECT().ClaimDeliveries(ctx, claimOpts).Return([]model.WebhookDelivery{claimed}, nil)
+		mockWebhookRepo.EXPECT().RecordAttempt(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, d *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error {
+				assert.Equal(t, model.WebhookDeliveryStatusDead, d.Status)
+				assert.Nil(t, attempt.ResponseCode)
+				assert.NotEmpty(t, attempt.Error)
+				return nil
+			})
+
+		w := NewWebhookDeliveryWorker(
+			WithWebhookRepoForDelivery(mockWebhookRepo),
+			WithWebhookDeliveryConfig(deliveryConfig),
+		)
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+}
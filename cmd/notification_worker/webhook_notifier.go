@@ -0,0 +1,66 @@
+package notification_worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+type webhookPayload struct {
+	Subject  string      `json:"subject"`
+	UserID   uuid.UUID   `json:"user_id"`
+	Template string      `json:"template"`
+	Locale   string      `json:"locale"`
+	Title    string      `json:"title"`
+	Text     string      `json:"text"`
+	HTML     string      `json:"html"`
+	Data     interface{} `json:"data"`
+}
+
+// NewWebhookNotifier returns a Notifier that POSTs every notification as JSON to url
+func NewWebhookNotifier(url string, client *http.Client) Notifier {
+	return &webhookNotifier{url: url, client: client}
+}
+
+func (n *webhookNotifier) Process(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(webhookPayload{
+		Subject:  notification.Subject,
+		UserID:   notification.UserID,
+		Template: notification.Template,
+		Locale:   notification.Locale,
+		Title:    notification.Content.Subject,
+		Text:     notification.Content.Text,
+		HTML:     notification.Content.HTML,
+		Data:     notification.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package notification_worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier_Process(t *testing.T) {
+	notification := Notification{
+		Subject:  EmailNotificationSubjectTigerSighting,
+		UserID:   uuid.New(),
+		Template: TemplateTigerSighting,
+		Content:  Content{Subject: "Machli was sighted again", Text: "Tiger 1 was sighted again"},
+		Data:     json.RawMessage(`{"tiger_id":1}`),
+	}
+
+	t.Run("should post the notification as json", func(t *testing.T) {
+		var received map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := NewWebhookNotifier(server.URL, server.Client()).Process(context.Background(), notification)
+		assert.Nil(t, err)
+		assert.Equal(t, notification.Subject, received["subject"])
+		assert.Equal(t, notification.UserID.String(), received["user_id"])
+		assert.Equal(t, notification.Content.Subject, received["title"])
+		assert.Equal(t, notification.Content.Text, received["text"])
+		assert.Equal(t, float64(1), received["data"].(map[string]interface{})["tiger_id"])
+	})
+
+	t.Run("should return error on non 2xx response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		err := NewWebhookNotifier(server.URL, server.Client()).Process(context.Background(), notification)
+		assert.EqualError(t, err, "webhook responded with status 502")
+	})
+}
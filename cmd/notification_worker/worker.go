@@ -1,33 +1,325 @@
 package notification_worker
 
 import (
-	"fmt"
+	"context"
 	"sync"
 	"time"
 
+	"tigerhall_kittens/internal/config"
+	"tigerhall_kittens/internal/lifecycle"
 	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
 )
 
-var (
-	notificationQueue = make(chan Notification)
-	wg                sync.WaitGroup
-)
+const maxRetryDelay = 6 * time.Hour
 
-// StartNotificationWorker starts a goroutine to process notification emails
-func StartNotificationWorker() {
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for notification := range notificationQueue {
-			logger.D(nil, fmt.Sprintf("Sending %s notification to user %s", notification.Subject, notification.UserID))
-			time.Sleep(1 * time.Second) // simulated notification sending time
+type WorkerConfig struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	ClaimLease     time.Duration
+}
+
+type Worker interface {
+	Run(ctx context.Context)
+	ProcessBatch(ctx context.Context) int
+}
+
+type worker struct {
+	outboxRepo      repository.OutboxRepo
+	userRepo        repository.UserRepo
+	preferencesRepo repository.PreferencesRepo
+	dispatcher      Dispatcher
+	config          WorkerConfig
+}
+
+type WorkerOption func(w *worker)
+
+func NewWorker(dispatcher Dispatcher, options ...WorkerOption) Worker {
+	w := &worker{
+		outboxRepo:      repository.NewOutboxRepo(),
+		userRepo:        repository.NewUserRepo(),
+		preferencesRepo: repository.NewPreferencesRepo(),
+		dispatcher:      dispatcher,
+		config: WorkerConfig{
+			PollInterval:   config.Env.NotificationPollInterval,
+			BatchSize:      config.Env.NotificationBatchSize,
+			MaxAttempts:    config.Env.NotificationMaxAttempts,
+			RetryBaseDelay: config.Env.NotificationRetryBaseDelay,
+			ClaimLease:     config.Env.NotificationClaimLease,
+		},
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+func WithOutboxRepo(repo repository.OutboxRepo) WorkerOption {
+	return func(w *worker) {
+		w.outboxRepo = repo
+	}
+}
+
+func WithUserRepo(repo repository.UserRepo) WorkerOption {
+	return func(w *worker) {
+		w.userRepo = repo
+	}
+}
+
+func WithPreferencesRepo(repo repository.PreferencesRepo) WorkerOption {
+	return func(w *worker) {
+		w.preferencesRepo = repo
+	}
+}
+
+func WithWorkerConfig(cfg WorkerConfig) WorkerOption {
+	return func(w *worker) {
+		w.config = cfg
+	}
+}
+
+// Run polls the outbox until ctx is cancelled. A full batch is followed
+// immediately by another claim so that a backlog drains without waiting
+// for the next tick. Cancelling ctx stops the claims, the batch in flight
+// is still sent.
+func (w *worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed := w.ProcessBatch(lifecycle.Detach(ctx))
+		if claimed > 0 && claimed == w.config.BatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch claims and dispatches one batch of due notifications and
+// returns how many rows were claimed. Notifications in a batch are sent
+// concurrently, each channel enforces its own concurrency limit.
+func (w *worker) ProcessBatch(ctx context.Context) int {
+	notifications, err := w.outboxRepo.ClaimNotifications(ctx, repository.ClaimOutboxOpts{
+		Limit: w.config.BatchSize,
+		Lease: w.config.ClaimLease,
+	})
+	if err != nil {
+		return 0
+	}
+
+	var batch sync.WaitGroup
+	for _, notification := range notifications {
+		batch.Add(1)
+		notification := notification
+		go func() {
+			defer batch.Done()
+			w.process(ctx, notification)
+		}()
+	}
+	batch.Wait()
+
+	return len(notifications)
+}
+
+func (w *worker) process(ctx context.Context, outboxNotification model.OutboxNotification) {
+	if err := w.deliver(ctx, outboxNotification); err != nil {
+		status, nextAttemptAt := w.nextAttempt(outboxNotification.Attempts)
+		logger.W(ctx, "Failed to send notification",
+			logger.Field("notification_id", outboxNotification.ID),
+			logger.Field("attempts", outboxNotification.Attempts),
+			logger.Field("status", status),
+			logger.Field("error", err.Error()))
+
+		_ = w.outboxRepo.MarkFailed(ctx, outboxNotification.ID, status, nextAttemptAt, err.Error())
+	}
+}
+
+// deliver applies the recipient's preferences to a claimed notification: notifications about a
+// muted tiger or for a user with every channel disabled are skipped, those for a digest subscriber
+// are held back for the digest scheduler and those arriving in quiet hours wait for them to end.
+// Anything left is sent. Transactional notifications skip the preferences and are emailed. An error means the notification has to be retried.
+func (w *worker) deliver(ctx context.Context, outboxNotification model.OutboxNotification) error {
+	if isTransactional(outboxNotification.Subject) {
+		if err := w.send(ctx, outboxNotification, []string{ChannelEmail}); err != nil {
+			return err
+		}
+
+		_ = w.outboxRepo.MarkSent(ctx, outboxNotification.ID)
+		return nil
+	}
+
+	preferences, err := w.preferencesRepo.GetPreferences(ctx, outboxNotification.UserID)
+	if err != nil {
+		return err
+	}
+
+	if tigerID := payloadTigerID(outboxNotification.Payload); tigerID != 0 {
+		muted, err := w.preferencesRepo.IsTigerMuted(ctx, outboxNotification.UserID, tigerID)
+		if err != nil {
+			return err
+		}
+
+		if muted {
+			_ = w.outboxRepo.MarkSkipped(ctx, outboxNotification.ID, "tiger is muted")
+			return nil
 		}
+	}
+
+	channels := preferredChannels(preferences)
+	if len(channels) == 0 {
+		_ = w.outboxRepo.MarkSkipped(ctx, outboxNotification.ID, "every channel is disabled")
+		return nil
+	}
+
+	if preferences.Delivery != model.DeliveryImmediate && outboxNotification.Subject != EmailNotificationSubjectDigest {
+		_ = w.outboxRepo.HoldForDigest(ctx, outboxNotification.ID)
+		return nil
+	}
+
+	if until, quiet := quietHoursEnd(preferences, time.Now()); quiet {
+		_ = w.outboxRepo.Defer(ctx, outboxNotification.ID, until)
+		return nil
+	}
+
+	if err := w.send(ctx, outboxNotification, channels); err != nil {
+		return err
+	}
+
+	_ = w.outboxRepo.MarkSent(ctx, outboxNotification.ID)
+	return nil
+}
+
+// nextAttempt decides what happens to a notification that failed on its given
+// attempt: it is retried with exponential backoff until MaxAttempts is used up,
+// after which it is dead-lettered.
+func (w *worker) nextAttempt(attempts int) (model.OutboxStatus, time.Time) {
+	if attempts >= w.config.MaxAttempts {
+		return model.OutboxStatusDead, time.Now()
+	}
+
+	return model.OutboxStatusPending, time.Now().Add(retryDelay(w.config.RetryBaseDelay, attempts))
+}
+
+// retryDelay doubles base for every attempt after the first, up to maxRetryDelay
+func retryDelay(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay
+}
+
+// send dispatches the notification on the channels an earlier attempt has not delivered it on yet. When
+// it fails, the channels that did deliver it are recorded before the failure is, so the retry skips them.
+func (w *worker) send(ctx context.Context, outboxNotification model.OutboxNotification, channels []string) error {
+	user, err := w.userRepo.GetUser(ctx, repository.GetUserOpts{ID: outboxNotification.UserID})
+	if err != nil {
+		return err
+	}
+
+	email := user.Email
+	if recipient := payloadRecipient(outboxNotification.Payload); recipient != "" {
+		email = recipient
+	}
+
+	delivered, err := w.dispatcher.Dispatch(ctx, Notification{
+		ID:        outboxNotification.ID,
+		Subject:   outboxNotification.Subject,
+		UserID:    outboxNotification.UserID,
+		Email:     email,
+		Locale:    user.Locale,
+		Data:      outboxNotification.Payload,
+		Channels:  channels,
+		Delivered: outboxNotification.DeliveredChannels,
+	})
+	if err != nil && len(delivered) > 0 {
+		delivered = append(append([]string{}, outboxNotification.DeliveredChannels...), delivered...)
+		_ = w.outboxRepo.MarkDelivered(ctx, outboxNotification.ID, delivered)
+	}
+
+	return err
+}
+
+// NotificationWorker drains the notification outbox, builds digests, delivers webhooks and builds
+// personal data exports as a lifecycle component
+type NotificationWorker struct {
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func NewNotificationWorker() *NotificationWorker {
+	return &NotificationWorker{}
+}
+
+func (n *NotificationWorker) Name() string {
+	return "notification worker"
+}
+
+func (n *NotificationWorker) Start(ctx context.Context) error {
+	dispatcher, err := NewDispatcherFromConfig()
+	if err != nil {
+		logger.E(ctx, err, "Invalid notification channel configuration")
+		return err
+	}
+
+	ctx, n.stop = context.WithCancel(ctx)
+
+	w := NewWorker(dispatcher)
+	scheduler := NewDigestScheduler()
+	webhooks := NewWebhookDeliveryWorker()
+	exports := NewDataExportWorker()
+
+	n.wg.Add(4)
+	go func() {
+		defer n.wg.Done()
+		w.Run(ctx)
 	}()
+	go func() {
+		defer n.wg.Done()
+		scheduler.Run(ctx)
+	}()
+	go func() {
+		defer n.wg.Done()
+		webhooks.Run(ctx)
+	}()
+	go func() {
+		defer n.wg.Done()
+		exports.Run(ctx)
+	}()
+
+	return nil
 }
 
-func SetupNotificationWorker() {
-	defer close(notificationQueue)
+// Stop stops claiming work and waits for the batches in flight to finish. Anything still
+// running at the deadline is abandoned, its claims run out and it is retried after a restart.
+func (n *NotificationWorker) Stop(ctx context.Context) error {
+	n.stop()
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
 
-	StartNotificationWorker()
-	wg.Wait()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,388 @@
+package notification_worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+type dispatcherFunc func(ctx context.Context, notification Notification) ([]string, error)
+
+func (f dispatcherFunc) Dispatch(ctx context.Context, notification Notification) ([]string, error) {
+	return f(ctx, notification)
+}
+
+func TestWorker_ProcessBatch(t *testing.T) {
+	workerConfig := WorkerConfig{
+		BatchSize:      10,
+		MaxAttempts:    3,
+		RetryBaseDelay: time.Minute,
+		ClaimLease:     time.Minute,
+	}
+	claimOpts := repository.ClaimOutboxOpts{Limit: 10, Lease: time.Minute}
+
+	user := &model.User{ID: uuid.New(), Email: "ranger@example.com"}
+	notification := model.OutboxNotification{
+		ID:       uuid.New(),
+		Subject:  EmailNotificationSubjectTigerSighting,
+		UserID:   user.ID,
+		Payload:  []byte(`{"tiger_id":1}`),
+		Attempts: 1,
+	}
+
+	t.Run("should mark delivered notifications as sent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{notification}, nil)
+		mockOutboxRepo.EXPECT().MarkSent(ctx, notification.ID).Return(nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockPreferencesRepo := defaultPreferencesRepo(ctrl, ctx, user.ID)
+
+		var dispatched Notification
+		w := NewWorker(dispatcherFunc(func(ctx context.Context, n Notification) ([]string, error) {
+			dispatched = n
+			return nil, nil
+		}), WithOutboxRepo(mockOutboxRepo), WithUserRepo(mockUserRepo),
+			WithPreferencesRepo(mockPreferencesRepo), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+		assert.Equal(t, user.Email, dispatched.Email)
+		assert.Equal(t, notification.Subject, dispatched.Subject)
+		assert.Equal(t, []string{ChannelEmail, ChannelInApp}, dispatched.Channels)
+	})
+
+	t.Run("should schedule a retry when sending fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{notification}, nil)
+		mockOutboxRepo.EXPECT().MarkFailed(ctx, notification.ID, model.OutboxStatusPending, gomock.Any(), "smtp down").Return(nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockPreferencesRepo := defaultPreferencesRepo(ctrl, ctx, user.ID)
+
+		w := NewWorker(dispatcherFunc(func(ctx context.Context, n Notification) ([]string, error) {
+			return nil, errors.New("smtp down")
+		}), WithOutboxRepo(mockOutboxRepo), WithUserRepo(mockUserRepo),
+			WithPreferencesRepo(mockPreferencesRepo), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+
+	t.Run("should record the channels that delivered before scheduling a retry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		retried := notification
+		retried.DeliveredChannels = []string{ChannelInApp}
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{retried}, nil)
+		gomock.InOrder(
+			mockOutboxRepo.EXPECT().MarkDelivered(ctx, notification.ID, []string{ChannelInApp, ChannelWebhook}).Return(nil),
+			mockOutboxRepo.EXPECT().MarkFailed(ctx, notification.ID, model.OutboxStatusPending, gomock.Any(), "smtp down").Return(nil),
+		)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockPreferencesRepo := defaultPreferencesRepo(ctrl, ctx, user.ID)
+
+		var dispatched Notification
+		w := NewWorker(dispatcherFunc(func(ctx context.Context, n Notification) ([]string, error) {
+			dispatched = n
+			return []string{ChannelWebhook}, errors.New("smtp down")
+		}), WithOutboxRepo(mockOutboxRepo), WithUserRepo(mockUserRepo),
+			WithPreferencesRepo(mockPreferencesRepo), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+		assert.Equal(t, []string{ChannelInApp}, dispatched.Delivered)
+	})
+
+	t.Run("should dead-letter a notification on its last attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		lastAttempt := notification
+		lastAttempt.Attempts = workerConfig.MaxAttempts
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{lastAttempt}, nil)
+		mockOutboxRepo.EXPECT().MarkFailed(ctx, notification.ID, model.OutboxStatusDead, gomock.Any(), "smtp down").Return(nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockPreferencesRepo := defaultPreferencesRepo(ctrl, ctx, user.ID)
+
+		w := NewWorker(dispatcherFunc(func(ctx context.Context, n Notification) ([]string, error) {
+			return nil, errors.New("smtp down")
+		}), WithOutboxRepo(mockOutboxRepo), WithUserRepo(mockUserRepo),
+			WithPreferencesRepo(mockPreferencesRepo), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+}
+
+func defaultPreferencesRepo(ctrl *gomock.Controller, ctx context.Context, userID uuid.UUID) *mock_repository.MockPreferencesRepo {
+	mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+	mockPreferencesRepo.EXPECT().GetPreferences(ctx, userID).Return(model.DefaultNotificationPreferences(userID), nil)
+	mockPreferencesRepo.EXPECT().IsTigerMuted(ctx, userID, uint(1)).Return(false, nil)
+
+	return mockPreferencesRepo
+}
+
+func TestWorker_ProcessBatch_Preferences(t *testing.T) {
+	workerConfig := WorkerConfig{BatchSize: 10, MaxAttempts: 3, RetryBaseDelay: time.Minute, ClaimLease: time.Minute}
+	claimOpts := repository.ClaimOutboxOpts{Limit: 10, Lease: time.Minute}
+
+	userID := uuid.New()
+	notification := model.OutboxNotification{
+		ID:       uuid.New(),
+		Subject:  EmailNotificationSubjectTigerSighting,
+		UserID:   userID,
+		Payload:  []byte(`{"tiger_id":1}`),
+		Attempts: 1,
+	}
+
+	noDispatch := dispatcherFunc(func(ctx context.Context, n Notification) ([]string, error) {
+		t.Fatal("notification should not be dispatched")
+		return nil, nil
+	})
+
+	t.Run("should skip notifications about a muted tiger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{notification}, nil)
+		mockOutboxRepo.EXPECT().MarkSkipped(ctx, notification.ID, "tiger is muted").Return(nil)
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, userID).Return(model.DefaultNotificationPreferences(userID), nil)
+		mockPreferencesRepo.EXPECT().IsTigerMuted(ctx, userID, uint(1)).Return(true, nil)
+
+		w := NewWorker(noDispatch, WithOutboxRepo(mockOutboxRepo), WithUserRepo(mock_repository.NewMockUserRepo(ctrl)),
+			WithPreferencesRepo(mockPreferencesRepo), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+
+	t.Run("should skip notifications when every channel is disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		preferences := &model.NotificationPreferences{UserID: userID, Delivery: model.DeliveryImmediate, TimeZone: "UTC"}
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{notification}, nil)
+		mockOutboxRepo.EXPECT().MarkSkipped(ctx, notification.ID, "every channel is disabled").Return(nil)
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, userID).Return(preferences, nil)
+		mockPreferencesRepo.EXPECT().IsTigerMuted(ctx, userID, uint(1)).Return(false, nil)
+
+		w := NewWorker(noDispatch, WithOutboxRepo(mockOutboxRepo), WithUserRepo(mock_repository.NewMockUserRepo(ctrl)),
+			WithPreferencesRepo(mockPreferencesRepo), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+
+	t.Run("should hold notifications for digest subscribers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		preferences := model.DefaultNotificationPreferences(userID)
+		preferences.Delivery = model.DeliveryHourlyDigest
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{notification}, nil)
+		mockOutboxRepo.EXPECT().HoldForDigest(ctx, notification.ID).Return(nil)
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, userID).Return(preferences, nil)
+		mockPreferencesRepo.EXPECT().IsTigerMuted(ctx, userID, uint(1)).Return(false, nil)
+
+		w := NewWorker(noDispatch, WithOutboxRepo(mockOutboxRepo), WithUserRepo(mock_repository.NewMockUserRepo(ctrl)),
+			WithPreferencesRepo(mockPreferencesRepo), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+
+	t.Run("should defer notifications until quiet hours end", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		now := time.Now().UTC()
+		start := now.Add(-time.Hour).Format(quietHoursLayout)
+		end := now.Add(time.Hour).Format(quietHoursLayout)
+		preferences := model.DefaultNotificationPreferences(userID)
+		preferences.QuietHoursStart, preferences.QuietHoursEnd = &start, &end
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{notification}, nil)
+		mockOutboxRepo.EXPECT().Defer(ctx, notification.ID, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, until time.Time) error {
+			assert.WithinDuration(t, now.Add(time.Hour), until, time.Minute)
+			return nil
+		})
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, userID).Return(preferences, nil)
+		mockPreferencesRepo.EXPECT().IsTigerMuted(ctx, userID, uint(1)).Return(false, nil)
+
+		w := NewWorker(noDispatch, WithOutboxRepo(mockOutboxRepo), WithUserRepo(mock_repository.NewMockUserRepo(ctrl)),
+			WithPreferencesRepo(mockPreferencesRepo), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+	})
+
+	t.Run("should email transactional notifications regardless of preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := &model.User{ID: userID, Email: "ranger@example.com"}
+		verification := model.OutboxNotification{
+			ID:       uuid.New(),
+			Subject:  EmailNotificationSubjectVerifyEmail,
+			UserID:   userID,
+			Payload:  []byte(`{"token":"abc"}`),
+			Attempts: 1,
+		}
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{verification}, nil)
+		mockOutboxRepo.EXPECT().MarkSent(ctx, verification.ID).Return(nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: userID}).Return(user, nil)
+
+		var dispatched Notification
+		w := NewWorker(dispatcherFunc(func(ctx context.Context, n Notification) ([]string, error) {
+			dispatched = n
+			return nil, nil
+		}), WithOutboxRepo(mockOutboxRepo), WithUserRepo(mockUserRepo),
+			WithPreferencesRepo(mock_repository.NewMockPreferencesRepo(ctrl)), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+		assert.Equal(t, []string{ChannelEmail}, dispatched.Channels)
+		assert.Equal(t, user.Email, dispatched.Email)
+	})
+
+	t.Run("should send email change confirmations to the new address", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := &model.User{ID: userID, Email: "ranger@example.com"}
+		confirmation := model.OutboxNotification{
+			ID:       uuid.New(),
+			Subject:  EmailNotificationSubjectConfirmEmailChange,
+			UserID:   userID,
+			Payload:  []byte(`{"recipient":"ravi@wwf.example.org","token":"abc"}`),
+			Attempts: 1,
+		}
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(ctx, claimOpts).Return([]model.OutboxNotification{confirmation}, nil)
+		mockOutboxRepo.EXPECT().MarkSent(ctx, confirmation.ID).Return(nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: userID}).Return(user, nil)
+
+		var dispatched Notification
+		w := NewWorker(dispatcherFunc(func(ctx context.Context, n Notification) ([]string, error) {
+			dispatched = n
+			return nil, nil
+		}), WithOutboxRepo(mockOutboxRepo), WithUserRepo(mockUserRepo),
+			WithPreferencesRepo(mock_repository.NewMockPreferencesRepo(ctrl)), WithWorkerConfig(workerConfig))
+
+		assert.Equal(t, 1, w.ProcessBatch(ctx))
+		assert.Equal(t, "ravi@wwf.example.org", dispatched.Email)
+	})
+}
+
+func TestWorker_Run(t *testing.T) {
+	t.Run("should finish the batch in flight once ctx is cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		user := &model.User{ID: uuid.New(), Email: "ranger@example.com"}
+		notification := model.OutboxNotification{ID: uuid.New(), Subject: EmailNotificationSubjectTigerSighting, UserID: user.ID, Attempts: 1}
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().ClaimNotifications(gomock.Any(), gomock.Any()).Return([]model.OutboxNotification{notification}, nil)
+		mockOutboxRepo.EXPECT().MarkSent(gomock.Any(), notification.ID).Return(nil)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(gomock.Any(), repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().GetPreferences(gomock.Any(), user.ID).Return(model.DefaultNotificationPreferences(user.ID), nil)
+
+		w := NewWorker(dispatcherFunc(func(dispatchCtx context.Context, n Notification) ([]string, error) {
+			cancel()
+			return nil, dispatchCtx.Err()
+		}), WithOutboxRepo(mockOutboxRepo), WithUserRepo(mockUserRepo), WithPreferencesRepo(mockPreferencesRepo),
+			WithWorkerConfig(WorkerConfig{PollInterval: time.Hour, BatchSize: 10, MaxAttempts: 3, ClaimLease: time.Minute}))
+
+		w.Run(ctx)
+	})
+}
+
+func TestWorker_nextAttempt(t *testing.T) {
+	w := &worker{config: WorkerConfig{
+		MaxAttempts:    4,
+		RetryBaseDelay: time.Minute,
+	}}
+
+	t.Run("should back off exponentially while attempts remain", func(t *testing.T) {
+		for attempts, expectedDelay := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 3: 4 * time.Minute} {
+			status, nextAttemptAt := w.nextAttempt(attempts)
+			assert.Equal(t, model.OutboxStatusPending, status)
+			assert.WithinDuration(t, time.Now().Add(expectedDelay), nextAttemptAt, time.Second)
+		}
+	})
+
+	t.Run("should dead-letter once attempts are exhausted", func(t *testing.T) {
+		status, _ := w.nextAttempt(4)
+		assert.Equal(t, model.OutboxStatusDead, status)
+	})
+
+	t.Run("should cap the retry delay", func(t *testing.T) {
+		w := &worker{config: WorkerConfig{MaxAttempts: 100, RetryBaseDelay: time.Hour}}
+
+		_, nextAttemptAt := w.nextAttempt(50)
+		assert.WithinDuration(t, time.Now().Add(maxRetryDelay), nextAttemptAt, time.Second)
+	})
+}
@@ -3,15 +3,22 @@ package config
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 
 	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/feed"
+	"tigerhall_kittens/internal/keyset"
 	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/oidc"
+	"tigerhall_kittens/internal/secretbox"
+	"tigerhall_kittens/internal/throttle"
 )
 
 const (
@@ -21,18 +28,168 @@ const (
 	EnvProduction  = "production"
 )
 
+const (
+	FeedBrokerMemory   = "memory"
+	FeedBrokerPostgres = "postgres"
+)
+
+const (
+	LoginThrottleStoreMemory   = "memory"
+	LoginThrottleStorePostgres = "postgres"
+)
+
 var Env Config
 
 type Config struct {
 	Environment            string `mapstructure:"ENV"`
 	Port                   string `mapstructure:"PORT"`
-	SecretKey              string `mapstructure:"SECRET_KEY"`
 	DatabaseHost           string `mapstructure:"DATABASE_HOST"`
 	DatabaseUser           string `mapstructure:"DATABASE_USER"`
 	DatabaseName           string `mapstructure:"DATABASE_NAME"`
 	DatabasePassword       string `mapstructure:"DATABASE_PASSWORD"`
 	DatabaseMinConnections string `mapstructure:"DB_MIN_CONNECTIONS"`
 	DatabaseMaxConnections string `mapstructure:"DB_MAX_CONNECTIONS"`
+
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+
+	// JWTKeysetFile is the manifest of the keys tokens are signed with, see keyset.Load. Without
+	// one a development server signs with a key generated on start.
+	JWTKeysetFile string `mapstructure:"JWT_KEYSET_FILE"`
+	// JWTKeyGracePeriod is how long a retired key keeps verifying, at least the longest token TTL
+	JWTKeyGracePeriod time.Duration `mapstructure:"JWT_KEY_GRACE_PERIOD"`
+
+	// LoginThrottleStore is "memory" for a single instance or "postgres" to count failed logins across instances
+	LoginThrottleStore     string        `mapstructure:"LOGIN_THROTTLE_STORE"`
+	LoginFreeFailures      int           `mapstructure:"LOGIN_FREE_FAILURES"`
+	LoginLockoutFailures   int           `mapstructure:"LOGIN_LOCKOUT_FAILURES"`
+	LoginIPFreeFailures    int           `mapstructure:"LOGIN_IP_FREE_FAILURES"`
+	LoginIPLockoutFailures int           `mapstructure:"LOGIN_IP_LOCKOUT_FAILURES"`
+	LoginBaseDelay         time.Duration `mapstructure:"LOGIN_BASE_DELAY"`
+	LoginMaxDelay          time.Duration `mapstructure:"LOGIN_MAX_DELAY"`
+	// LoginLockoutDuration is also how long failed logins are remembered
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+
+	// MFAIssuer is the name authenticator apps list the account under
+	MFAIssuer        string        `mapstructure:"MFA_ISSUER"`
+	MFAChallengeTTL  time.Duration `mapstructure:"MFA_CHALLENGE_TTL"`
+	MFARecoveryCodes int           `mapstructure:"MFA_RECOVERY_CODES"`
+
+	// OIDCProvidersFile lists the identity providers users can sign in with, see oidc.LoadProviders.
+	// Without it single sign-on is disabled.
+	OIDCProvidersFile string        `mapstructure:"OIDC_PROVIDERS_FILE"`
+	OIDCLoginTTL      time.Duration `mapstructure:"OIDC_LOGIN_TTL"`
+	OIDCHTTPTimeout   time.Duration `mapstructure:"OIDC_HTTP_TIMEOUT"`
+
+	EmailVerificationTTL time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`
+	// EmailVerificationURL is the page verification emails link to, the token is appended as ?token=
+	EmailVerificationURL          string        `mapstructure:"EMAIL_VERIFICATION_URL"`
+	EmailVerificationResendLimit  int           `mapstructure:"EMAIL_VERIFICATION_RESEND_LIMIT"`
+	EmailVerificationResendWindow time.Duration `mapstructure:"EMAIL_VERIFICATION_RESEND_WINDOW"`
+
+	PasswordResetTTL time.Duration `mapstructure:"PASSWORD_RESET_TTL"`
+	// PasswordResetURL is the page password reset emails link to, the token is appended as ?token=
+	PasswordResetURL    string        `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetLimit  int           `mapstructure:"PASSWORD_RESET_LIMIT"`
+	PasswordResetWindow time.Duration `mapstructure:"PASSWORD_RESET_WINDOW"`
+
+	// ShutdownTimeout bounds how long in-flight requests and notifications get to finish on SIGINT/SIGTERM
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+
+	NotificationPollInterval   time.Duration `mapstructure:"NOTIFICATION_POLL_INTERVAL"`
+	NotificationBatchSize      int           `mapstructure:"NOTIFICATION_BATCH_SIZE"`
+	NotificationMaxAttempts    int           `mapstructure:"NOTIFICATION_MAX_ATTEMPTS"`
+	NotificationRetryBaseDelay time.Duration `mapstructure:"NOTIFICATION_RETRY_BASE_DELAY"`
+	NotificationClaimLease     time.Duration `mapstructure:"NOTIFICATION_CLAIM_LEASE"`
+
+	// NotificationSecretKey is the base64 encoded AES-256 key one-time tokens in notification
+	// payloads and TOTP secrets are sealed with, see secretbox. Without one a development server
+	// generates a key on start, MFA enrollments do not survive a restart then.
+	NotificationSecretKey string `mapstructure:"NOTIFICATION_SECRET_KEY"`
+
+	NotificationDigestInterval  time.Duration `mapstructure:"NOTIFICATION_DIGEST_INTERVAL"`
+	NotificationDailyDigestHour int           `mapstructure:"NOTIFICATION_DAILY_DIGEST_HOUR"`
+
+	// NotificationRoutes maps notification templates to channels, e.g. "tiger_sighting:smtp"
+	NotificationRoutes         string        `mapstructure:"NOTIFICATION_ROUTES"`
+	NotificationDefaultChannel string        `mapstructure:"NOTIFICATION_DEFAULT_CHANNEL"`
+	NotificationLogConcurrency int           `mapstructure:"NOTIFICATION_LOG_CONCURRENCY"`
+	NotificationLogTimeout     time.Duration `mapstructure:"NOTIFICATION_LOG_TIMEOUT"`
+
+	NotificationInAppConcurrency int           `mapstructure:"NOTIFICATION_IN_APP_CONCURRENCY"`
+	NotificationInAppTimeout     time.Duration `mapstructure:"NOTIFICATION_IN_APP_TIMEOUT"`
+
+	SMTPHost        string        `mapstructure:"SMTP_HOST"`
+	SMTPPort        string        `mapstructure:"SMTP_PORT"`
+	SMTPUsername    string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword    string        `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom        string        `mapstructure:"SMTP_FROM"`
+	SMTPConcurrency int           `mapstructure:"SMTP_CONCURRENCY"`
+	SMTPTimeout     time.Duration `mapstructure:"SMTP_TIMEOUT"`
+
+	NotificationWebhookURL         string        `mapstructure:"NOTIFICATION_WEBHOOK_URL"`
+	NotificationWebhookConcurrency int           `mapstructure:"NOTIFICATION_WEBHOOK_CONCURRENCY"`
+	NotificationWebhookTimeout     time.Duration `mapstructure:"NOTIFICATION_WEBHOOK_TIMEOUT"`
+
+	WebhookMaxAttempts     int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookDeliveryTimeout time.Duration `mapstructure:"WEBHOOK_DELIVERY_TIMEOUT"`
+
+	// DataExportTTL is how long a personal data export can be downloaded before it is deleted
+	DataExportTTL time.Duration `mapstructure:"DATA_EXPORT_TTL"`
+
+	// FeedBroker is "memory" for a single instance or "postgres" to share the sighting feed over LISTEN/NOTIFY
+	FeedBroker       string `mapstructure:"FEED_BROKER"`
+	FeedReplayBuffer int    `mapstructure:"FEED_REPLAY_BUFFER"`
+}
+
+// setDefaults registers fallback values for the optional settings, so that
+// existing env files keep working without listing every knob.
+func setDefaults() {
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 30*time.Second)
+	viper.SetDefault("ACCESS_TOKEN_TTL", 15*time.Minute)
+	viper.SetDefault("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	viper.SetDefault("JWT_KEY_GRACE_PERIOD", time.Hour)
+	viper.SetDefault("LOGIN_THROTTLE_STORE", LoginThrottleStoreMemory)
+	viper.SetDefault("LOGIN_FREE_FAILURES", 3)
+	viper.SetDefault("LOGIN_LOCKOUT_FAILURES", 10)
+	viper.SetDefault("LOGIN_IP_FREE_FAILURES", 20)
+	viper.SetDefault("LOGIN_IP_LOCKOUT_FAILURES", 100)
+	viper.SetDefault("LOGIN_BASE_DELAY", time.Second)
+	viper.SetDefault("LOGIN_MAX_DELAY", time.Minute)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", throttle.DefaultForgetAfter)
+	viper.SetDefault("MFA_ISSUER", "Tigerhall Kittens")
+	viper.SetDefault("MFA_CHALLENGE_TTL", 5*time.Minute)
+	viper.SetDefault("MFA_RECOVERY_CODES", 10)
+	viper.SetDefault("OIDC_LOGIN_TTL", 10*time.Minute)
+	viper.SetDefault("OIDC_HTTP_TIMEOUT", 10*time.Second)
+	viper.SetDefault("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	viper.SetDefault("EMAIL_VERIFICATION_RESEND_LIMIT", 3)
+	viper.SetDefault("EMAIL_VERIFICATION_RESEND_WINDOW", time.Hour)
+	viper.SetDefault("PASSWORD_RESET_TTL", time.Hour)
+	viper.SetDefault("PASSWORD_RESET_LIMIT", 3)
+	viper.SetDefault("PASSWORD_RESET_WINDOW", time.Hour)
+	viper.SetDefault("NOTIFICATION_POLL_INTERVAL", 2*time.Second)
+	viper.SetDefault("NOTIFICATION_BATCH_SIZE", 20)
+	viper.SetDefault("NOTIFICATION_MAX_ATTEMPTS", 8)
+	viper.SetDefault("NOTIFICATION_RETRY_BASE_DELAY", 30*time.Second)
+	viper.SetDefault("NOTIFICATION_CLAIM_LEASE", 5*time.Minute)
+	viper.SetDefault("NOTIFICATION_DIGEST_INTERVAL", time.Minute)
+	viper.SetDefault("NOTIFICATION_DAILY_DIGEST_HOUR", 8)
+	viper.SetDefault("NOTIFICATION_DEFAULT_CHANNEL", "log")
+	viper.SetDefault("NOTIFICATION_LOG_CONCURRENCY", 16)
+	viper.SetDefault("NOTIFICATION_LOG_TIMEOUT", time.Second)
+	viper.SetDefault("NOTIFICATION_IN_APP_CONCURRENCY", 8)
+	viper.SetDefault("NOTIFICATION_IN_APP_TIMEOUT", 5*time.Second)
+	viper.SetDefault("SMTP_PORT", "25")
+	viper.SetDefault("SMTP_CONCURRENCY", 4)
+	viper.SetDefault("SMTP_TIMEOUT", 15*time.Second)
+	viper.SetDefault("NOTIFICATION_WEBHOOK_CONCURRENCY", 8)
+	viper.SetDefault("NOTIFICATION_WEBHOOK_TIMEOUT", 5*time.Second)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 10)
+	viper.SetDefault("WEBHOOK_DELIVERY_TIMEOUT", 10*time.Second)
+	viper.SetDefault("DATA_EXPORT_TTL", 7*24*time.Hour)
+	viper.SetDefault("FEED_BROKER", FeedBrokerMemory)
+	viper.SetDefault("FEED_REPLAY_BUFFER", feed.DefaultReplayBuffer)
 }
 
 func bindEnvs(iface interface{}, parts ...string) {
@@ -67,6 +224,7 @@ func LoadEnv() error {
 
 	viper.AutomaticEnv()
 	bindEnvs(Env)
+	setDefaults()
 
 	return viper.Unmarshal(&Env)
 }
@@ -85,8 +243,7 @@ func SetupDBConnection(ctx context.Context) {
 		panic(err)
 	}
 
-	dsn := fmt.Sprintf("host=%s user=%s dbname=%s sslmode=disable password=%s", Env.DatabaseHost, Env.DatabaseUser, Env.DatabaseName, Env.DatabasePassword)
-	err = db.Connect(dsn, minConnections, maxConnections)
+	err = db.Connect(databaseDSN(), minConnections, maxConnections)
 	if err != nil {
 		logger.E(ctx, err, "Failed connecting to database", logger.Field("error", err))
 		panic(err)
@@ -96,6 +253,121 @@ func SetupDBConnection(ctx context.Context) {
 	logger.I(ctx, "Established connection to database")
 }
 
+func databaseDSN() string {
+	return fmt.Sprintf("host=%s user=%s dbname=%s sslmode=disable password=%s", Env.DatabaseHost, Env.DatabaseUser, Env.DatabaseName, Env.DatabasePassword)
+}
+
+// SetupFeedBroker picks the broker the sighting feed is published to. It must run after
+// SetupDBConnection, the postgres broker listens until ctx is cancelled.
+func SetupFeedBroker(ctx context.Context) {
+	switch Env.FeedBroker {
+	case FeedBrokerMemory:
+		feed.Set(feed.NewMemoryBroker(Env.FeedReplayBuffer))
+	case FeedBrokerPostgres:
+		broker, err := feed.NewPostgresBroker(ctx, db.Get(), databaseDSN(), Env.FeedReplayBuffer)
+		if err != nil {
+			logger.E(ctx, err, "Failed listening for sighting feed events", logger.Field("error", err))
+			panic(err)
+		}
+		feed.Set(broker)
+	default:
+		err := fmt.Errorf("invalid FEED_BROKER %q", Env.FeedBroker)
+		logger.E(ctx, err, "Invalid sighting feed configuration")
+		panic(err)
+	}
+}
+
+// SetupLoginThrottle picks the store failed logins are counted in, it must run after SetupDBConnection
+func SetupLoginThrottle(ctx context.Context) {
+	switch Env.LoginThrottleStore {
+	case LoginThrottleStoreMemory:
+		throttle.Set(throttle.NewMemoryStore(Env.LoginLockoutDuration))
+	case LoginThrottleStorePostgres:
+		throttle.Set(throttle.NewPostgresStore(db.Get(), Env.LoginLockoutDuration))
+	default:
+		err := fmt.Errorf("invalid LOGIN_THROTTLE_STORE %q", Env.LoginThrottleStore)
+		logger.E(ctx, err, "Invalid login throttle configuration")
+		panic(err)
+	}
+}
+
+// SetupSigningKeys loads the keyset tokens are signed and verified with
+func SetupSigningKeys(ctx context.Context) {
+	if Env.JWTKeysetFile == "" {
+		if Env.Environment != EnvDevelopment && Env.Environment != "" {
+			err := fmt.Errorf("JWT_KEYSET_FILE is required in %s", Env.Environment)
+			logger.E(ctx, err, "Invalid signing key configuration")
+			panic(err)
+		}
+
+		keys, err := keyset.Generate("development")
+		if err != nil {
+			logger.E(ctx, err, "Failed generating a signing key")
+			panic(err)
+		}
+
+		logger.W(ctx, "JWT_KEYSET_FILE is not set, signing with a generated key that is lost on restart")
+		keyset.Set(keys)
+		return
+	}
+
+	keys, err := keyset.Load(Env.JWTKeysetFile, Env.JWTKeyGracePeriod)
+	if err != nil {
+		logger.E(ctx, err, "Failed loading signing keys", logger.Field("error", err))
+		panic(err)
+	}
+
+	keyset.Set(keys)
+}
+
+// SetupNotificationSecretKey loads the key one-time tokens in notification payloads are sealed with
+func SetupNotificationSecretKey(ctx context.Context) {
+	if Env.NotificationSecretKey == "" {
+		if Env.Environment != EnvDevelopment && Env.Environment != "" {
+			err := fmt.Errorf("NOTIFICATION_SECRET_KEY is required in %s", Env.Environment)
+			logger.E(ctx, err, "Invalid notification secret key configuration")
+			panic(err)
+		}
+
+		key, err := secretbox.GenerateKey()
+		if err == nil {
+			err = secretbox.Set(key)
+		}
+		if err != nil {
+			logger.E(ctx, err, "Failed generating a notification secret key")
+			panic(err)
+		}
+
+		logger.W(ctx, "NOTIFICATION_SECRET_KEY is not set, sealing with a generated key that is lost on restart")
+		return
+	}
+
+	key, err := secretbox.ParseKey(Env.NotificationSecretKey)
+	if err == nil {
+		err = secretbox.Set(key)
+	}
+	if err != nil {
+		logger.E(ctx, err, "Invalid NOTIFICATION_SECRET_KEY", logger.Field("error", err.Error()))
+		panic(err)
+	}
+}
+
+// SetupOIDCProviders loads the identity providers users can sign in with
+func SetupOIDCProviders(ctx context.Context) {
+	if Env.OIDCProvidersFile == "" {
+		oidc.Set(oidc.Providers{})
+		return
+	}
+
+	providers, err := oidc.LoadProviders(Env.OIDCProvidersFile, &http.Client{Timeout: Env.OIDCHTTPTimeout})
+	if err != nil {
+		logger.E(ctx, err, "Failed loading identity providers", logger.Field("error", err))
+		panic(err)
+	}
+
+	oidc.Set(providers)
+}
+
 func SetupLogger(env string) {
 	switch env {
 	case EnvDevelopment:
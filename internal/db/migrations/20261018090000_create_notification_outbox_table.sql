@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notification_outbox
+(
+    id              VARCHAR(36) PRIMARY KEY,
+    subject         VARCHAR(255)             NOT NULL,
+    user_id         VARCHAR(36)              NOT NULL,
+    payload         JSONB                    NOT NULL DEFAULT '{}',
+    status          VARCHAR(20)              NOT NULL DEFAULT 'pending',
+    attempts        INTEGER                  NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error      TEXT                              DEFAULT NULL,
+    sent_at         TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE          DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notification_outbox_pending ON notification_outbox (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_notification_outbox_status ON notification_outbox (status);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_outbox CASCADE;
+-- +goose StatementEnd
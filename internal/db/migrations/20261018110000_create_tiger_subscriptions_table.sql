@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE tiger_subscriptions
+(
+    user_id    VARCHAR(36)              NOT NULL,
+    tiger_id   INTEGER                  NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, tiger_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (tiger_id) REFERENCES tigers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_tiger_subscriptions_tiger_id ON tiger_subscriptions (tiger_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tiger_subscriptions CASCADE;
+-- +goose StatementEnd
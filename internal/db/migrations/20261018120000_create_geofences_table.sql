@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE geofences
+(
+    id               VARCHAR(36) PRIMARY KEY,
+    user_id          VARCHAR(36)              NOT NULL,
+    name             VARCHAR(100)             NOT NULL,
+    kind             VARCHAR(20)              NOT NULL,
+    lat              DECIMAL(9, 6)                     DEFAULT NULL,
+    lon              DECIMAL(9, 6)                     DEFAULT NULL,
+    radius_in_meters DOUBLE PRECISION                  DEFAULT NULL,
+    polygon          JSONB                             DEFAULT NULL,
+    -- area is what sightings are matched against, circles are stored buffered into a polygon
+    -- so that every fence can be matched with ST_Intersects on the spatial index
+    area             GEOGRAPHY(GEOMETRY, 4326),
+    created_at       TIMESTAMP WITH TIME ZONE          DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_geofences_user_id ON geofences (user_id);
+CREATE INDEX idx_geofences_area ON geofences USING GIST (area);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS geofences CASCADE;
+-- +goose StatementEnd
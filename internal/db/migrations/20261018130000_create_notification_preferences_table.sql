@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notification_preferences
+(
+    user_id           VARCHAR(36) PRIMARY KEY,
+    email_enabled     BOOLEAN                  NOT NULL DEFAULT TRUE,
+    webhook_enabled   BOOLEAN                  NOT NULL DEFAULT FALSE,
+    in_app_enabled    BOOLEAN                  NOT NULL DEFAULT TRUE,
+    delivery          VARCHAR(20)              NOT NULL DEFAULT 'immediate',
+    quiet_hours_start VARCHAR(5)                        DEFAULT NULL,
+    quiet_hours_end   VARCHAR(5)                        DEFAULT NULL,
+    time_zone         VARCHAR(64)              NOT NULL DEFAULT 'UTC',
+    last_digest_at    TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at        TIMESTAMP WITH TIME ZONE          DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE notification_tiger_mutes
+(
+    user_id    VARCHAR(36)              NOT NULL,
+    tiger_id   INTEGER                  NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, tiger_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (tiger_id) REFERENCES tigers (id) ON DELETE CASCADE
+);
+
+-- notifications held back for a digest are looked up per user
+CREATE INDEX idx_notification_outbox_digest ON notification_outbox (user_id) WHERE status = 'digest';
+
+-- channels a notification already went out on, a retry only resends the others
+ALTER TABLE notification_outbox ADD COLUMN delivered_channels TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS delivered_channels;
+DROP INDEX IF EXISTS idx_notification_outbox_digest;
+DROP TABLE IF EXISTS notification_tiger_mutes CASCADE;
+DROP TABLE IF EXISTS notification_preferences CASCADE;
+-- +goose StatementEnd
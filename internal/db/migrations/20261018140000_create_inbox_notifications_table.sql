@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE inbox_notifications
+(
+    id              VARCHAR(36) PRIMARY KEY,
+    user_id         VARCHAR(36)              NOT NULL,
+    notification_id VARCHAR(36)              NOT NULL,
+    subject         VARCHAR(255)             NOT NULL,
+    template        VARCHAR(100)             NOT NULL,
+    title           TEXT                     NOT NULL,
+    body            TEXT                     NOT NULL,
+    data            JSONB                    NOT NULL DEFAULT '{}',
+    read_at         TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- a retried outbox row must not show up twice in the inbox
+CREATE UNIQUE INDEX idx_inbox_notifications_notification_id ON inbox_notifications (notification_id);
+CREATE INDEX idx_inbox_notifications_user_id_created_at ON inbox_notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX idx_inbox_notifications_unread ON inbox_notifications (user_id) WHERE read_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS inbox_notifications CASCADE;
+-- +goose StatementEnd
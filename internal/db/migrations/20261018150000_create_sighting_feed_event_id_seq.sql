@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ids of sighting feed events, shared by every instance publishing to the feed
+CREATE SEQUENCE IF NOT EXISTS sighting_feed_event_id_seq;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP SEQUENCE IF EXISTS sighting_feed_event_id_seq;
+-- +goose StatementEnd
@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhook_subscriptions
+(
+    id                 VARCHAR(36) PRIMARY KEY,
+    url                TEXT                     NOT NULL,
+    secret             VARCHAR(255)             NOT NULL,
+    events             TEXT[]                   NOT NULL,
+    active             BOOLEAN                  NOT NULL DEFAULT TRUE,
+    created_by_user_id VARCHAR(36)                       DEFAULT NULL,
+    created_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    FOREIGN KEY (created_by_user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_webhook_subscriptions_events ON webhook_subscriptions USING GIN (events) WHERE active;
+
+CREATE TABLE webhook_deliveries
+(
+    id              VARCHAR(36) PRIMARY KEY,
+    subscription_id VARCHAR(36)              NOT NULL,
+    event_id        VARCHAR(36)              NOT NULL,
+    event           VARCHAR(100)             NOT NULL,
+    payload         JSONB                    NOT NULL,
+    status          VARCHAR(20)              NOT NULL DEFAULT 'pending',
+    attempts        INTEGER                  NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    response_code   INTEGER                           DEFAULT NULL,
+    last_error      TEXT                              DEFAULT NULL,
+    delivered_at    TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id, created_at DESC);
+
+CREATE TABLE webhook_delivery_attempts
+(
+    id            VARCHAR(36) PRIMARY KEY,
+    delivery_id   VARCHAR(36)              NOT NULL,
+    attempt       INTEGER                  NOT NULL,
+    response_code INTEGER                           DEFAULT NULL,
+    response_body TEXT                              DEFAULT NULL,
+    error         TEXT                              DEFAULT NULL,
+    duration_ms   BIGINT                   NOT NULL DEFAULT 0,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts (delivery_id, attempt);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_delivery_attempts CASCADE;
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+DROP TABLE IF EXISTS webhook_subscriptions CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- existing accounts could report sightings before roles existed, so they keep doing so
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'reporter';
+
+ALTER TABLE users ADD CONSTRAINT chk_users_role CHECK (role IN ('viewer', 'reporter', 'researcher', 'admin'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_role;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
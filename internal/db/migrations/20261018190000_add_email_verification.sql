@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- accounts created before signup existed were set up by an admin, they count as verified
+UPDATE users SET email_verified_at = created_at;
+
+CREATE TABLE user_tokens
+(
+    id          VARCHAR(36) PRIMARY KEY,
+    user_id     VARCHAR(36)              NOT NULL,
+    purpose     VARCHAR(50)              NOT NULL,
+    token_hash  VARCHAR(64)              NOT NULL,
+    expires_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at     TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- only the SHA-256 of a token is stored, it is looked up by that hash
+CREATE UNIQUE INDEX idx_user_tokens_token_hash ON user_tokens (token_hash);
+CREATE INDEX idx_user_tokens_user_id_purpose_created_at ON user_tokens (user_id, purpose, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_tokens CASCADE;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+-- +goose StatementEnd
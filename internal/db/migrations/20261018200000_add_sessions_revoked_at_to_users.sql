@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- access tokens issued before sessions_revoked_at are rejected, e.g. after a password reset
+ALTER TABLE users ADD COLUMN sessions_revoked_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS sessions_revoked_at;
+-- +goose StatementEnd
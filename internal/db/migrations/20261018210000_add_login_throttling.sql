@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- failed logins per key, e.g. "username:ravi" or "ip:10.0.0.1", shared by every instance
+CREATE TABLE login_throttles
+(
+    key          VARCHAR(320) PRIMARY KEY,
+    failures     INTEGER                  NOT NULL,
+    last_failure TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_login_throttles_last_failure ON login_throttles (last_failure);
+
+-- the audit trail outlives the users it mentions, so user_id is not a foreign key
+CREATE TABLE audit_events
+(
+    id         VARCHAR(36) PRIMARY KEY,
+    type       VARCHAR(50)              NOT NULL,
+    user_id    VARCHAR(36)                       DEFAULT NULL,
+    actor_id   VARCHAR(36)                       DEFAULT NULL,
+    username   VARCHAR(255)             NOT NULL DEFAULT '',
+    ip         VARCHAR(45)              NOT NULL DEFAULT '',
+    reason     VARCHAR(255)             NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_user_id_created_at ON audit_events (user_id, created_at);
+CREATE INDEX idx_audit_events_type_created_at ON audit_events (type, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS login_throttles;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- totp_secret is set on enrollment and only active once mfa_enabled_at is, totp_last_step is the
+-- last period a code was used for so that a code can not be replayed. The secret is sealed with
+-- NOTIFICATION_SECRET_KEY, see secretbox
+ALTER TABLE users ADD COLUMN totp_secret TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN mfa_enabled_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+CREATE TABLE mfa_recovery_codes
+(
+    id         VARCHAR(36) PRIMARY KEY,
+    user_id    VARCHAR(36)              NOT NULL,
+    code_hash  VARCHAR(64)              NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_mfa_recovery_codes_user_id_code_hash ON mfa_recovery_codes (user_id, code_hash);
+
+-- roles without a row do not require MFA
+CREATE TABLE role_mfa_policies
+(
+    role         VARCHAR(20) PRIMARY KEY,
+    mfa_required BOOLEAN                  NOT NULL DEFAULT FALSE,
+    updated_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_mfa_policies;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_enabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
+-- +goose StatementEnd
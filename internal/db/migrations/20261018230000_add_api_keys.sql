@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keys are looked up by the sha256 of the whole key, prefix is kept to tell them apart in lists
+CREATE TABLE api_keys
+(
+    id                 VARCHAR(36) PRIMARY KEY,
+    user_id            VARCHAR(36)              NOT NULL,
+    kind               VARCHAR(20)              NOT NULL,
+    name               VARCHAR(100)             NOT NULL,
+    prefix             VARCHAR(16)              NOT NULL,
+    key_hash           VARCHAR(64)              NOT NULL,
+    scopes             TEXT[]                   NOT NULL DEFAULT '{}',
+    expires_at         TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    last_used_at       TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    revoked_at         TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_by_user_id VARCHAR(36)                       DEFAULT NULL,
+    created_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by_user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
+
+-- machine accounts such as camera traps own service keys and can not log in
+ALTER TABLE users ADD COLUMN machine BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+ALTER TABLE users DROP COLUMN IF EXISTS machine;
+-- +goose StatementEnd
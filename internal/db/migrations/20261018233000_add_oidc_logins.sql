@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- links an account to the subject an identity provider knows it by
+CREATE TABLE user_identities
+(
+    id         VARCHAR(36) PRIMARY KEY,
+    user_id    VARCHAR(36)              NOT NULL,
+    provider   VARCHAR(50)              NOT NULL,
+    subject    VARCHAR(255)             NOT NULL,
+    email      VARCHAR(255)             NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_user_identities_provider_subject ON user_identities (provider, subject);
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
+
+-- a login sent to an identity provider and not back yet, it is deleted when the callback uses it
+CREATE TABLE oidc_logins
+(
+    id            VARCHAR(36) PRIMARY KEY,
+    provider      VARCHAR(50)              NOT NULL,
+    state_hash    VARCHAR(64)              NOT NULL,
+    nonce         VARCHAR(64)              NOT NULL,
+    code_verifier VARCHAR(128)             NOT NULL,
+    expires_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_oidc_logins_state_hash ON oidc_logins (state_hash);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oidc_logins;
+DROP TABLE IF EXISTS user_identities;
+-- +goose StatementEnd
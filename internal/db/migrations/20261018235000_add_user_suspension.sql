@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- suspended users keep their account but can not log in or use their tokens and API keys
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- admins page through users newest first
+CREATE INDEX idx_users_created_at_id ON users (created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_created_at_id;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_at;
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+-- erased users keep an anonymised row, everything else about them is deleted
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- the tombstone user reports the sightings of erased users, it can never log in
+INSERT INTO users (id, username, password, email, role, suspended_at, erased_at, deleted_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'erased-user', '', 'erased-user@tombstone.invalid', 'viewer',
+        CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+-- sightings are scientific records that outlive their reporter, deleting a user who still has
+-- sightings fails instead of taking them along
+ALTER TABLE sightings DROP CONSTRAINT IF EXISTS sightings_reported_by_user_id_fkey;
+ALTER TABLE sightings ADD CONSTRAINT sightings_reported_by_user_id_fkey
+    FOREIGN KEY (reported_by_user_id) REFERENCES users (id) ON DELETE RESTRICT;
+
+CREATE TABLE data_exports
+(
+    id              VARCHAR(36) PRIMARY KEY,
+    user_id         VARCHAR(36)              NOT NULL,
+    status          VARCHAR(20)              NOT NULL DEFAULT 'pending',
+    attempts        INTEGER                  NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    archive         BYTEA                             DEFAULT NULL,
+    last_error      TEXT                              DEFAULT NULL,
+    completed_at    TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    expires_at      TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_data_exports_pending ON data_exports (next_attempt_at) WHERE status = 'pending';
+-- a user has at most one export being built
+CREATE UNIQUE INDEX idx_data_exports_user_id_pending ON data_exports (user_id) WHERE status = 'pending';
+CREATE INDEX idx_data_exports_user_id ON data_exports (user_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS data_exports;
+
+ALTER TABLE sightings DROP CONSTRAINT IF EXISTS sightings_reported_by_user_id_fkey;
+ALTER TABLE sightings ADD CONSTRAINT sightings_reported_by_user_id_fkey
+    FOREIGN KEY (reported_by_user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+-- the tombstone user stays while it holds sightings of erased users
+DELETE FROM users
+WHERE id = '00000000-0000-0000-0000-000000000001'
+  AND NOT EXISTS (SELECT 1 FROM sightings WHERE reported_by_user_id = '00000000-0000-0000-0000-000000000001');
+
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
+-- +goose StatementEnd
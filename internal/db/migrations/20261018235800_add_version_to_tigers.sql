@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- updates are only applied to the version they were based on
+ALTER TABLE tigers ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_tigers_deleted_at ON tigers (deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tigers_deleted_at;
+ALTER TABLE tigers DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
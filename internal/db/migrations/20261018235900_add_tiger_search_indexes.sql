@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- serves both the case-insensitive prefix match and the fuzzy match on name
+CREATE INDEX idx_tigers_name_trgm ON tigers USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_tigers_date_of_birth ON tigers (date_of_birth);
+CREATE INDEX idx_tigers_last_seen_timestamp ON tigers (last_seen_timestamp);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tigers_last_seen_timestamp;
+DROP INDEX IF EXISTS idx_tigers_date_of_birth;
+DROP INDEX IF EXISTS idx_tigers_name_trgm;
+DROP EXTENSION IF EXISTS pg_trgm;
+-- +goose StatementEnd
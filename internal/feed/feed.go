@@ -0,0 +1,106 @@
+package feed
+
+import (
+	"context"
+	"sync"
+
+	"tigerhall_kittens/internal/model"
+)
+
+const (
+	DefaultReplayBuffer     = 256
+	defaultSubscriberBuffer = 64
+)
+
+// Event is a committed sighting as it is pushed to feed subscribers. IDs only ever grow,
+// so a client that reconnects can resume after the last ID it saw.
+type Event struct {
+	ID       uint64         `json:"id"`
+	Sighting model.Sighting `json:"sighting"`
+}
+
+// BoundingBox limits a feed to sightings within the given coordinates, edges included
+type BoundingBox struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+type Filter struct {
+	// TigerIDs limits the feed to sightings of these tigers, every tiger when empty
+	TigerIDs    []uint
+	BoundingBox *BoundingBox
+}
+
+func (f Filter) Match(event Event) bool {
+	if len(f.TigerIDs) > 0 {
+		found := false
+		for _, tigerID := range f.TigerIDs {
+			if tigerID == event.Sighting.TigerID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if box := f.BoundingBox; box != nil {
+		sighting := event.Sighting
+		if sighting.Lat < box.MinLat || sighting.Lat > box.MaxLat || sighting.Lon < box.MinLon || sighting.Lon > box.MaxLon {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Subscription is a subscriber's view of the feed: Replay holds the buffered events it missed,
+// after which Events delivers new ones. Events is closed once the subscriber falls too far
+// behind, the client is expected to reconnect and resume from the last event it received.
+type Subscription struct {
+	Replay []Event
+	Events <-chan Event
+
+	close func()
+}
+
+// Close unsubscribes, it is safe to call more than once
+func (s *Subscription) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
+
+type Broker interface {
+	Publish(ctx context.Context, sighting model.Sighting) error
+	// Subscribe starts a subscription replaying the buffered events after lastEventID, zero
+	// for a fresh subscription
+	Subscribe(ctx context.Context, lastEventID uint64, filter Filter) (*Subscription, error)
+	// Close ends every open subscription, so that streams finish and their clients reconnect
+	// to another instance while this one shuts down
+	Close()
+}
+
+var (
+	mu     sync.RWMutex
+	broker Broker = NewMemoryBroker(DefaultReplayBuffer)
+)
+
+// Get returns the broker sightings are published to, an in-process one unless Set replaced it
+func Get() Broker {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return broker
+}
+
+func Set(b Broker) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	broker = b
+}
@@ -0,0 +1,121 @@
+package feed
+
+import (
+	"context"
+	"sync"
+
+	"tigerhall_kittens/internal/model"
+)
+
+type subscriber struct {
+	filter Filter
+	events chan Event
+}
+
+type memoryBroker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	replay      []Event
+	replaySize  int
+	subscribers map[*subscriber]struct{}
+}
+
+// NewMemoryBroker returns a broker that fans sightings out to subscribers of this process
+// only, keeping the last replaySize events for clients that reconnect
+func NewMemoryBroker(replaySize int) Broker {
+	return newMemoryBroker(replaySize)
+}
+
+func newMemoryBroker(replaySize int) *memoryBroker {
+	return &memoryBroker{
+		replaySize:  replaySize,
+		subscribers: map[*subscriber]struct{}{},
+	}
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, sighting model.Sighting) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	b.deliverLocked(Event{ID: b.lastID, Sighting: sighting})
+
+	return nil
+}
+
+// deliver hands an event with an ID assigned elsewhere to the subscribers
+func (b *memoryBroker) deliver(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.ID > b.lastID {
+		b.lastID = event.ID
+	}
+	b.deliverLocked(event)
+}
+
+func (b *memoryBroker) deliverLocked(event Event) {
+	b.replay = append(b.replay, event)
+	if len(b.replay) > b.replaySize {
+		b.replay = b.replay[len(b.replay)-b.replaySize:]
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Match(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			// the subscriber is not keeping up, dropping it makes the client resume from
+			// the replay buffer instead of silently missing events
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (b *memoryBroker) Subscribe(ctx context.Context, lastEventID uint64, filter Filter) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &subscriber{
+		filter: filter,
+		events: make(chan Event, defaultSubscriberBuffer),
+	}
+	b.subscribers[sub] = struct{}{}
+
+	var replay []Event
+	if lastEventID != 0 {
+		for _, event := range b.replay {
+			if event.ID > lastEventID && filter.Match(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	return &Subscription{
+		Replay: replay,
+		Events: sub.events,
+		close: func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if _, ok := b.subscribers[sub]; ok {
+				delete(b.subscribers, sub)
+				close(sub.events)
+			}
+		},
+	}, nil
+}
+
+func (b *memoryBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
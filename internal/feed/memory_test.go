@@ -0,0 +1,111 @@
+package feed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+)
+
+func sighting(tigerID uint, lat, lon float64) model.Sighting {
+	return model.Sighting{ID: uuid.New(), TigerID: tigerID, Lat: lat, Lon: lon}
+}
+
+func TestMemoryBroker_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should deliver sightings matching the filter", func(t *testing.T) {
+		broker := NewMemoryBroker(DefaultReplayBuffer)
+
+		subscription, err := broker.Subscribe(ctx, 0, Filter{
+			TigerIDs:    []uint{1},
+			BoundingBox: &BoundingBox{MinLat: 20, MinLon: 70, MaxLat: 30, MaxLon: 80},
+		})
+		assert.Nil(t, err)
+		defer subscription.Close()
+
+		_ = broker.Publish(ctx, sighting(2, 25, 75))
+		_ = broker.Publish(ctx, sighting(1, 10, 75))
+		matching := sighting(1, 25, 75)
+		_ = broker.Publish(ctx, matching)
+
+		event := <-subscription.Events
+		assert.Equal(t, uint64(3), event.ID)
+		assert.Equal(t, matching.ID, event.Sighting.ID)
+		assert.Empty(t, subscription.Events)
+	})
+
+	t.Run("should replay buffered events after the last event id", func(t *testing.T) {
+		broker := NewMemoryBroker(2)
+
+		for i := 0; i < 4; i++ {
+			_ = broker.Publish(ctx, sighting(1, 25, 75))
+		}
+
+		subscription, err := broker.Subscribe(ctx, 2, Filter{})
+		assert.Nil(t, err)
+		defer subscription.Close()
+
+		assert.Len(t, subscription.Replay, 2)
+		assert.Equal(t, uint64(3), subscription.Replay[0].ID)
+		assert.Equal(t, uint64(4), subscription.Replay[1].ID)
+
+		subscription, err = broker.Subscribe(ctx, 0, Filter{})
+		assert.Nil(t, err)
+		defer subscription.Close()
+
+		assert.Empty(t, subscription.Replay)
+	})
+
+	t.Run("should close the events of a subscriber that falls behind", func(t *testing.T) {
+		broker := NewMemoryBroker(DefaultReplayBuffer)
+
+		subscription, err := broker.Subscribe(ctx, 0, Filter{})
+		assert.Nil(t, err)
+		defer subscription.Close()
+
+		for i := 0; i <= defaultSubscriberBuffer; i++ {
+			_ = broker.Publish(ctx, sighting(1, 25, 75))
+		}
+
+		received := 0
+		for range subscription.Events {
+			received++
+		}
+		assert.Equal(t, defaultSubscriberBuffer, received)
+	})
+
+	t.Run("should deliver events with ids assigned by another instance", func(t *testing.T) {
+		broker := newMemoryBroker(DefaultReplayBuffer)
+
+		subscription, err := broker.Subscribe(ctx, 0, Filter{})
+		assert.Nil(t, err)
+		defer subscription.Close()
+
+		broker.deliver(Event{ID: 41, Sighting: sighting(1, 25, 75)})
+
+		event := <-subscription.Events
+		assert.Equal(t, uint64(41), event.ID)
+	})
+}
+
+func TestMemoryBroker_Close(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should end every open subscription", func(t *testing.T) {
+		broker := NewMemoryBroker(DefaultReplayBuffer)
+
+		subscription, err := broker.Subscribe(ctx, 0, Filter{})
+		assert.Nil(t, err)
+
+		broker.Close()
+		subscription.Close()
+
+		_, ok := <-subscription.Events
+		assert.False(t, ok)
+		assert.Nil(t, broker.Publish(ctx, sighting(1, 25, 75)))
+	})
+}
@@ -0,0 +1,101 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+const (
+	postgresChannel = "sighting_feed"
+
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+type postgresBroker struct {
+	*memoryBroker
+	DB *gorm.DB
+}
+
+// NewPostgresBroker returns a broker for deployments running more than one instance. Sightings
+// are published with NOTIFY and every instance LISTENs for them, event IDs come from a database
+// sequence so they stay in order across instances. It listens until ctx is cancelled.
+func NewPostgresBroker(ctx context.Context, db *gorm.DB, dsn string, replaySize int) (Broker, error) {
+	listener := pq.NewListener(dsn, listenerMinReconnect, listenerMaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.W(ctx, "Sighting feed listener connection event", logger.Field("event", event), logger.Field("error", err.Error()))
+		}
+	})
+
+	if err := listener.Listen(postgresChannel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	b := &postgresBroker{
+		memoryBroker: newMemoryBroker(replaySize),
+		DB:           db,
+	}
+
+	go b.listen(ctx, listener)
+
+	return b, nil
+}
+
+func (b *postgresBroker) Publish(ctx context.Context, sighting model.Sighting) error {
+	var id uint64
+	if err := b.DB.WithContext(ctx).Raw("SELECT nextval('sighting_feed_event_id_seq')").Scan(&id).Error; err != nil {
+		logger.E(ctx, err, "Error while allocating sighting feed event id")
+		return err
+	}
+
+	payload, err := json.Marshal(Event{ID: id, Sighting: sighting})
+	if err != nil {
+		return err
+	}
+
+	if err := b.DB.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", postgresChannel, string(payload)).Error; err != nil {
+		logger.E(ctx, err, "Error while publishing sighting feed event", logger.Field("sighting_id", sighting.ID))
+		return err
+	}
+
+	return nil
+}
+
+func (b *postgresBroker) listen(ctx context.Context, listener *pq.Listener) {
+	defer listener.Close()
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = listener.Ping()
+		case notification := <-listener.Notify:
+			// a nil notification follows a reconnect, anything published meanwhile is lost
+			if notification == nil {
+				logger.W(ctx, "Sighting feed listener reconnected, events may have been missed")
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				logger.E(ctx, err, "Invalid sighting feed event")
+				continue
+			}
+
+			b.deliver(event)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+	"tigerhall_kittens/utils"
+)
+
+type APIKeyHandler interface {
+	CreatePersonalKey(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListPersonalKeys(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	RevokePersonalKey(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	CreateServiceKey(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListKeys(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	RevokeKey(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+}
+
+type apiKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler() APIKeyHandler {
+	return &apiKeyHandler{apiKeyService: service.NewAPIKeyService()}
+}
+
+func MakeAPIKeyHandler(apiKeyService service.APIKeyService) APIKeyHandler {
+	return &apiKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreatePersonalKey creates a key for the signed in user. The key is only returned here.
+func (h *apiKeyHandler) CreatePersonalKey(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.CreateAPIKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	resp, err := h.apiKeyService.CreatePersonalKey(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(resp)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+func (h *apiKeyHandler) ListPersonalKeys(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	opts, errRes := listAPIKeysOpts(r)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	keys, err := h.apiKeyService.ListPersonalKeys(r.Context(), opts)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return apiKeysResponse(keys, opts), nil
+}
+
+func (h *apiKeyHandler) RevokePersonalKey(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	keyID, err := uuid.Parse(r.GetPathParam("api_key_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid API key id")
+	}
+
+	if err := h.apiKeyService.RevokePersonalKey(r.Context(), keyID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// CreateServiceKey creates a key for the machine account in user_id. The key is only returned here.
+func (h *apiKeyHandler) CreateServiceKey(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.CreateAPIKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.UserID == uuid.Nil {
+		return nil, web.ErrBadRequest("user_id is required")
+	}
+
+	resp, err := h.apiKeyService.CreateServiceKey(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(resp)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+// ListKeys lists the keys of every user, optionally of one user or kind
+func (h *apiKeyHandler) ListKeys(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	opts, errRes := listAPIKeysOpts(r)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return nil, web.ErrBadRequest("Invalid user id")
+		}
+		opts.UserID = &userID
+	}
+
+	opts.Kind = model.APIKeyKind(r.URL.Query().Get("kind"))
+	switch opts.Kind {
+	case "", model.APIKeyKindPersonal, model.APIKeyKindService:
+	default:
+		return nil, web.ErrBadRequest("Invalid kind value")
+	}
+
+	keys, err := h.apiKeyService.ListKeys(r.Context(), opts)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return apiKeysResponse(keys, opts), nil
+}
+
+func (h *apiKeyHandler) RevokeKey(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	keyID, err := uuid.Parse(r.GetPathParam("api_key_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid API key id")
+	}
+
+	if err := h.apiKeyService.RevokeKey(r.Context(), keyID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func listAPIKeysOpts(r *web.Request) (repository.ListAPIKeysOpts, web.ErrorInterface) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		return repository.ListAPIKeysOpts{}, web.ErrBadRequest("Invalid page number")
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 {
+		return repository.ListAPIKeysOpts{}, web.ErrBadRequest("Invalid per_page value")
+	}
+
+	return repository.ListAPIKeysOpts{Limit: perPage, Offset: (page - 1) * perPage}, nil
+}
+
+func apiKeysResponse(keys []model.APIKey, opts repository.ListAPIKeysOpts) *web.JSONResponse {
+	res := map[string]interface{}{
+		"api_keys": keys,
+		"page":     opts.Offset/opts.Limit + 1,
+		"per_page": opts.Limit,
+	}
+
+	return (*web.JSONResponse)(&res)
+}
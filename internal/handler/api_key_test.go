@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestAPIKeyHandler_CreatePersonalKey(t *testing.T) {
+	routePath := "/api/v1/me/api-keys"
+
+	t.Run("should return the key only when it is created", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockAPIKeyService := mock_service.NewMockAPIKeyService(ctrl)
+		mockAPIKeyService.EXPECT().CreatePersonalKey(gomock.Any(), service.CreateAPIKeyReq{
+			Name:   "camera trap uploader",
+			Scopes: []model.Permission{model.PermissionWriteSightings},
+		}).Return(&service.CreateAPIKeyResponse{
+			Key: "thk_secret",
+			APIKey: &model.APIKey{
+				ID:      uuid.New(),
+				Name:    "camera trap uploader",
+				Prefix:  "thk_secr",
+				KeyHash: "hash",
+				Scopes:  []string{string(model.PermissionWriteSightings)},
+			},
+		}, nil)
+
+		apiKeyHandler := MakeAPIKeyHandler(mockAPIKeyService)
+
+		req, _ := http.NewRequest(http.MethodPost, routePath,
+			bytes.NewBufferString(`{"name":"camera trap uploader","scopes":["sightings:write"]}`))
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			apiKeyHandler.CreatePersonalKey))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		body, _ := ioutil.ReadAll(recorder.Body)
+		var res struct {
+			Data struct {
+				Key    string                 `json:"key"`
+				APIKey map[string]interface{} `json:"api_key"`
+			} `json:"data"`
+		}
+		assert.Nil(t, json.Unmarshal(body, &res))
+		assert.Equal(t, "thk_secret", res.Data.Key)
+		assert.Equal(t, "thk_secr", res.Data.APIKey["prefix"])
+		assert.NotContains(t, res.Data.APIKey, "key_hash")
+	})
+
+	t.Run("should return bad request for scopes beyond the role", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockAPIKeyService := mock_service.NewMockAPIKeyService(ctrl)
+		mockAPIKeyService.EXPECT().CreatePersonalKey(gomock.Any(), gomock.Any()).Return(nil, service.ErrInvalidAPIKeyDetails)
+
+		apiKeyHandler := MakeAPIKeyHandler(mockAPIKeyService)
+
+		req, _ := http.NewRequest(http.MethodPost, routePath, bytes.NewBufferString(`{"name":"etl","scopes":["users:manage"]}`))
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			apiKeyHandler.CreatePersonalKey))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestAPIKeyHandler_CreateServiceKey(t *testing.T) {
+	routePath := "/api/v1/admin/api-keys"
+
+	t.Run("should return bad request without a user id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		apiKeyHandler := MakeAPIKeyHandler(mock_service.NewMockAPIKeyService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodPost, routePath, bytes.NewBufferString(`{"name":"trap 7","scopes":["sightings:write"]}`))
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			apiKeyHandler.CreateServiceKey))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestAPIKeyHandler_RevokePersonalKey(t *testing.T) {
+	routePath := "/api/v1/me/api-keys/:api_key_id"
+
+	t.Run("should return not found for keys of other users", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		keyID := uuid.New()
+		mockAPIKeyService := mock_service.NewMockAPIKeyService(ctrl)
+		mockAPIKeyService.EXPECT().RevokePersonalKey(gomock.Any(), keyID).Return(service.ErrAPIKeyNotFound)
+
+		apiKeyHandler := MakeAPIKeyHandler(mockAPIKeyService)
+
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/me/api-keys/"+keyID.String(), nil)
+
+		router.Handle(http.MethodDelete, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			apiKeyHandler.RevokePersonalKey))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
@@ -2,6 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+
+	"github.com/google/uuid"
+
 	"tigerhall_kittens/internal/service"
 	"tigerhall_kittens/internal/web"
 	"tigerhall_kittens/utils"
@@ -9,6 +12,17 @@ import (
 
 type AuthHandler interface {
 	Login(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	Refresh(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	Logout(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	UnlockUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	EnrollMFA(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ConfirmMFA(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	VerifyMFA(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListRoleMFAPolicies(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	SetRoleMFAPolicy(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListOIDCProviders(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	StartOIDCLogin(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	FinishOIDCLogin(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
 }
 
 type authHandler struct {
@@ -29,6 +43,7 @@ func (h *authHandler) Login(r *web.Request) (*web.JSONResponse, web.ErrorInterfa
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return nil, web.ErrBadRequest("Failed to decode request body")
 	}
+	req.IP = r.ClientIP()
 
 	loginResp, err := h.authService.LoginUser(r.Context(), req)
 	if err != nil {
@@ -43,3 +58,64 @@ func (h *authHandler) Login(r *web.Request) (*web.JSONResponse, web.ErrorInterfa
 
 	return (*web.JSONResponse)(&jsonResponse), nil
 }
+
+// Refresh issues a new access token and rotates the refresh token
+func (h *authHandler) Refresh(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	req, decodeErr := decodeRefreshTokenReq(r)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	refreshResp, err := h.authService.RefreshToken(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(refreshResp)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+// Logout revokes the session of the refresh token
+func (h *authHandler) Logout(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	req, decodeErr := decodeRefreshTokenReq(r)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	if err := h.authService.Logout(r.Context(), req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// UnlockUser ends the lockout of a user after too many failed logins
+func (h *authHandler) UnlockUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	userID, err := uuid.Parse(r.GetPathParam("user_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid user id")
+	}
+
+	if err := h.authService.UnlockUser(r.Context(), userID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func decodeRefreshTokenReq(r *web.Request) (service.RefreshTokenReq, web.ErrorInterface) {
+	var req service.RefreshTokenReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.RefreshToken == "" {
+		return req, web.ErrBadRequest("Missing refresh token")
+	}
+
+	return req, nil
+}
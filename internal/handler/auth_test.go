@@ -11,12 +11,14 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 
 	"tigerhall_kittens/internal/handler/middleware"
 	"tigerhall_kittens/internal/service"
 	mock_service "tigerhall_kittens/internal/service/mocks"
+	"tigerhall_kittens/internal/web"
 )
 
 func TestAuthHandler_Login(t *testing.T) {
@@ -139,3 +141,298 @@ func TestAuthHandler_Login(t *testing.T) {
 		assert.Equal(t, resData["success"], true)
 	})
 }
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	path := "/api/v1/auth/refresh"
+
+	t.Run("should return bad request when the refresh token is missing", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		authHandler := MakeAuthHandler(mock_service.NewMockAuthService(ctrl))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path, bytes.NewBufferString(`{}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			authHandler.Refresh))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should return unauthorized for an invalid refresh token", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().RefreshToken(gomock.Any(), service.RefreshTokenReq{RefreshToken: "reused"}).
+			Return(nil, service.ErrInvalidRefreshToken)
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"refresh_token":"reused"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			authHandler.Refresh))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("should return the rotated tokens", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().RefreshToken(gomock.Any(), service.RefreshTokenReq{RefreshToken: "current"}).
+			Return(&service.LoginUserResponse{AccessToken: "access", RefreshToken: "next", ExpiresIn: 900}, nil)
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"refresh_token":"current"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			authHandler.Refresh))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "next", resData["data"].(map[string]interface{})["refresh_token"])
+	})
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	path := "/api/v1/auth/logout"
+
+	t.Run("should revoke the session of the refresh token", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().Logout(gomock.Any(), service.RefreshTokenReq{RefreshToken: "current"}).Return(nil)
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"refresh_token":"current"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			authHandler.Logout))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestAuthHandler_Login_Unverified(t *testing.T) {
+	path := "/api/v1/auth/login"
+
+	t.Run("should return a distinct error code for unverified accounts", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().LoginUser(gomock.Any(), gomock.Any()).Return(nil, service.ErrEmailNotVerified)
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"username":"ravi","password":"tiger_tracks"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			authHandler.Login))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, web.EmailNotVerified, resData["error"].(map[string]interface{})["code"])
+	})
+}
+
+func TestAuthHandler_Login_Throttled(t *testing.T) {
+	path := "/api/v1/auth/login"
+
+	t.Run("should return too many requests with the client IP passed on", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().LoginUser(gomock.Any(), service.LoginUserReq{Username: "ravi", Password: "tiger_tracks", IP: "10.0.0.1"}).
+			Return(nil, service.ErrAccountLocked)
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"username":"ravi","password":"tiger_tracks"}`))
+		req.RemoteAddr = "10.0.0.1:52144"
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			authHandler.Login))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	})
+}
+
+func TestAuthHandler_UnlockUser(t *testing.T) {
+	path := "/api/v1/admin/users/:user_id/unlock"
+
+	t.Run("should return bad request for an invalid user id", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		authHandler := MakeAuthHandler(mock_service.NewMockAuthService(ctrl))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/admin/users/abc/unlock", nil)
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.UnlockUser))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should return not found for unknown users", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userID := uuid.New()
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().UnlockUser(gomock.Any(), userID).Return(service.ErrUserNotFound)
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/admin/users/"+userID.String()+"/unlock", nil)
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.UnlockUser))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestAuthHandler_VerifyMFA(t *testing.T) {
+	path := "/api/v1/auth/mfa/verify"
+
+	t.Run("should return bad request unless exactly one kind of code is given", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		authHandler := MakeAuthHandler(mock_service.NewMockAuthService(ctrl))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"mfa_token":"token","code":"123456","recovery_code":"abcde-23456"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.VerifyMFA))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should return unauthorized for an invalid code", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().VerifyMFA(gomock.Any(), service.VerifyMFAReq{MFAToken: "token", Code: "123456"}).
+			Return(nil, service.ErrInvalidMFACode)
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"mfa_token":"token","code":"123456"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.VerifyMFA))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+}
+
+func TestAuthHandler_FinishOIDCLogin(t *testing.T) {
+	path := "/api/v1/auth/oidc/providers/:provider/callback"
+
+	t.Run("should return tokens for the provider in the path", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().FinishOIDCLogin(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req service.OIDCCallbackReq) (*service.LoginUserResponse, error) {
+				assert.Equal(t, "wwf", req.Provider)
+				assert.Equal(t, "code", req.Code)
+				assert.Equal(t, "state", req.State)
+				return &service.LoginUserResponse{AccessToken: "access", RefreshToken: "refresh"}, nil
+			})
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/auth/oidc/providers/wwf/callback",
+			bytes.NewBufferString(`{"code":"code","state":"state"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.FinishOIDCLogin))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"access_token":"access"`)
+	})
+
+	t.Run("should return bad request for an invalid state", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAuthService := mock_service.NewMockAuthService(ctrl)
+		mockAuthService.EXPECT().FinishOIDCLogin(gomock.Any(), gomock.Any()).Return(nil, service.ErrInvalidOIDCState)
+
+		authHandler := MakeAuthHandler(mockAuthService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/auth/oidc/providers/wwf/callback",
+			bytes.NewBufferString(`{"code":"code","state":"forged"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.FinishOIDCLogin))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
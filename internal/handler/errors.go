@@ -12,6 +12,18 @@ func errorResponse(err error) web.ErrorInterface {
 		return web.ErrBadRequest(fmt.Sprintf("error fetching tiger details : %s", err.Error()))
 	}
 
+	if errors.Is(err, service.ErrTigerNotFound) {
+		return web.ErrNotFound(err.Error())
+	}
+
+	if errors.Is(err, service.ErrTigerVersionMismatch) {
+		return web.ErrPreconditionFailed(fmt.Sprintf("update failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrInvalidTigerDetails) {
+		return web.ErrBadRequest(err.Error())
+	}
+
 	if errors.Is(err, service.ErrFetchingTigerDetails) {
 		return web.ErrInternalServerError(fmt.Sprintf("error while reporting sighting : %s", err.Error()))
 	}
@@ -32,6 +44,50 @@ func errorResponse(err error) web.ErrorInterface {
 		return web.ErrUnauthorizedRequest(fmt.Sprintf("login failed : %s", err.Error()))
 	}
 
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		return web.ErrEmailNotVerified(fmt.Sprintf("login failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrTooManyLoginAttempts) || errors.Is(err, service.ErrAccountLocked) {
+		return web.ErrTooManyRequests(fmt.Sprintf("login failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrInvalidMFAToken) || errors.Is(err, service.ErrInvalidMFACode) {
+		return web.ErrUnauthorizedRequest(fmt.Sprintf("login failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrMFAAlreadyEnabled) || errors.Is(err, service.ErrMFANotEnrolled) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidAPIKeyDetails) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		return web.ErrNotFound(err.Error())
+	}
+
+	if errors.Is(err, service.ErrOIDCProviderNotFound) {
+		return web.ErrNotFound(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidOIDCState) {
+		return web.ErrBadRequest(fmt.Sprintf("login failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrOIDCLoginFailed) || errors.Is(err, service.ErrOIDCEmailNotVerified) {
+		return web.ErrUnauthorizedRequest(fmt.Sprintf("login failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrUserNotFound) {
+		return web.ErrNotFound(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidRefreshToken) {
+		return web.ErrUnauthorizedRequest(err.Error())
+	}
+
 	if errors.Is(err, service.ErrTokenGenerationFailed) {
 		return web.ErrInternalServerError(fmt.Sprintf("login failed : %s", err.Error()))
 	}
@@ -40,9 +96,97 @@ func errorResponse(err error) web.ErrorInterface {
 		return web.ErrBadRequest(fmt.Sprintf("user creation failed : %s", err.Error()))
 	}
 
+	if errors.Is(err, service.ErrInvalidRole) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrAccountSuspended) {
+		return web.ErrForbidden(fmt.Sprintf("login failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrInvalidUserStatus) || errors.Is(err, service.ErrCannotManageOwnAccount) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrEmailTaken) {
+		return web.ErrBadRequest(fmt.Sprintf("profile update failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrIncorrectPassword) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrDataExportNotFound) {
+		return web.ErrNotFound(err.Error())
+	}
+
+	if errors.Is(err, service.ErrDataExportNotReady) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidUserDetails) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidVerificationToken) || errors.Is(err, service.ErrInvalidResetToken) {
+		return web.ErrBadRequest(err.Error())
+	}
+
 	if errors.Is(err, service.ErrCreatingUser) {
 		return web.ErrInternalServerError(err.Error())
 	}
 
+	if errors.Is(err, service.ErrNotificationNotReplayable) {
+		return web.ErrNotFound(fmt.Sprintf("replay failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrNotificationTokenDropped) {
+		return web.ErrBadRequest(fmt.Sprintf("replay failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrNotificationTemplateNotFound) {
+		return web.ErrNotFound(fmt.Sprintf("preview failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrSubscriptionNotFound) {
+		return web.ErrNotFound(fmt.Sprintf("unsubscribe failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrInvalidGeofence) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrGeofenceNotFound) {
+		return web.ErrNotFound(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidPreferences) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrTigerNotMuted) {
+		return web.ErrNotFound(fmt.Sprintf("unmute failed : %s", err.Error()))
+	}
+
+	if errors.Is(err, service.ErrInvalidCursor) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInboxNotificationNotFound) {
+		return web.ErrNotFound(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidFeedFilter) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrInvalidWebhook) {
+		return web.ErrBadRequest(err.Error())
+	}
+
+	if errors.Is(err, service.ErrWebhookNotFound) || errors.Is(err, service.ErrWebhookDeliveryNotFound) {
+		return web.ErrNotFound(err.Error())
+	}
+
 	return web.ErrInternalServerError(fmt.Sprintf("error while processing request : %s", err))
 }
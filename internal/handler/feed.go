@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tigerhall_kittens/internal/feed"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+)
+
+const (
+	feedHeartbeatInterval = 15 * time.Second
+	// feedRetry is how long EventSource clients wait before reconnecting
+	feedRetry = 3 * time.Second
+)
+
+type FeedHandler interface {
+	StreamSightings(w http.ResponseWriter, r *web.Request) web.ErrorInterface
+	SightingsWebSocket(w http.ResponseWriter, r *web.Request) web.ErrorInterface
+}
+
+type feedHandler struct {
+	feedService service.FeedService
+}
+
+func NewFeedHandler() FeedHandler {
+	return &feedHandler{feedService: service.NewFeedService()}
+}
+
+func MakeFeedHandler(feedService service.FeedService) FeedHandler {
+	return &feedHandler{feedService: feedService}
+}
+
+// StreamSightings pushes sightings as Server-Sent Events. Clients resume after a reconnect
+// with the Last-Event-ID header, which EventSource sends on its own.
+func (h *feedHandler) StreamSightings(w http.ResponseWriter, r *web.Request) web.ErrorInterface {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return web.ErrInternalServerError("Streaming is not supported")
+	}
+
+	req, errRes := parseSightingFeedReq(r)
+	if errRes != nil {
+		return errRes
+	}
+
+	subscription, err := h.feedService.SubscribeSightings(r.Context(), req)
+	if err != nil {
+		return errorResponse(err)
+	}
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", feedRetry.Milliseconds()); err != nil {
+		return nil
+	}
+
+	for _, event := range subscription.Replay {
+		if err := writeServerSentEvent(r.Context(), w, event); err != nil {
+			return nil
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(feedHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return nil
+			}
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return nil
+			}
+
+			if err := writeServerSentEvent(r.Context(), w, event); err != nil {
+				return nil
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeServerSentEvent(ctx context.Context, w http.ResponseWriter, event feed.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.E(ctx, err, "Error while encoding sighting feed event", logger.Field("event_id", event.ID))
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: sighting\ndata: %s\n\n", event.ID, data)
+	return err
+}
+
+// SightingsWebSocket pushes sightings as JSON text messages over a websocket. Browsers cannot set
+// headers on a websocket, so clients resume with the last_event_id query parameter instead.
+func (h *feedHandler) SightingsWebSocket(w http.ResponseWriter, r *web.Request) web.ErrorInterface {
+	if !web.IsWebSocketRequest(r.Request) {
+		return web.ErrBadRequest("Expected a websocket handshake")
+	}
+
+	req, errRes := parseSightingFeedReq(r)
+	if errRes != nil {
+		return errRes
+	}
+
+	subscription, err := h.feedService.SubscribeSightings(r.Context(), req)
+	if err != nil {
+		return errorResponse(err)
+	}
+	defer subscription.Close()
+
+	conn, err := web.UpgradeWebSocket(w, r.Request)
+	if err != nil {
+		return web.ErrBadRequest("Expected a websocket handshake")
+	}
+	defer conn.Close()
+
+	// the connection is hijacked, so the request context no longer notices the client leaving
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(event feed.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			logger.E(ctx, err, "Error while encoding sighting feed event", logger.Field("event_id", event.ID))
+			return nil
+		}
+
+		return conn.WriteMessage(web.WebSocketOpText, data)
+	}
+
+	for _, event := range subscription.Replay {
+		if err := send(event); err != nil {
+			return nil
+		}
+	}
+
+	heartbeat := time.NewTicker(feedHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if err := conn.WriteMessage(web.WebSocketOpPing, nil); err != nil {
+				return nil
+			}
+		case event, ok := <-subscription.Events:
+			if !ok {
+				_ = conn.WriteMessage(web.WebSocketOpClose, nil)
+				return nil
+			}
+
+			if err := send(event); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// parseSightingFeedReq reads the filters from the tiger_ids=1,2 and bbox=min_lon,min_lat,max_lon,max_lat
+// query parameters, and where to resume from the Last-Event-ID header or last_event_id query parameter
+func parseSightingFeedReq(r *web.Request) (service.SightingFeedReq, web.ErrorInterface) {
+	var req service.SightingFeedReq
+	query := r.URL.Query()
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("last_event_id")
+	}
+
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			return req, web.ErrBadRequest("Invalid last event id")
+		}
+		req.LastEventID = id
+	}
+
+	if tigerIDs := query.Get("tiger_ids"); tigerIDs != "" {
+		for _, tigerIDStr := range strings.Split(tigerIDs, ",") {
+			tigerID, err := strconv.ParseUint(strings.TrimSpace(tigerIDStr), 10, 0)
+			if err != nil || tigerID == 0 {
+				return req, web.ErrBadRequest("Invalid tiger id")
+			}
+			req.TigerIDs = append(req.TigerIDs, uint(tigerID))
+		}
+	}
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		parts := strings.Split(bbox, ",")
+		if len(parts) != 4 {
+			return req, web.ErrBadRequest("Invalid bbox value")
+		}
+
+		var coords [4]float64
+		for i, part := range parts {
+			coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return req, web.ErrBadRequest("Invalid bbox value")
+			}
+			coords[i] = coord
+		}
+
+		req.BoundingBox = &feed.BoundingBox{MinLon: coords[0], MinLat: coords[1], MaxLon: coords[2], MaxLat: coords[3]}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/feed"
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+	"tigerhall_kittens/internal/web"
+)
+
+func TestFeedHandler_StreamSightings(t *testing.T) {
+	routePath := "/api/v1/sightings/stream"
+
+	t.Run("should return bad request for invalid bbox", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		feedHandler := MakeFeedHandler(mock_service.NewMockFeedService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/sightings/stream?bbox=1,2,3", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Stream(middleware.EmptyMiddleware,
+			feedHandler.StreamSightings))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "Invalid bbox value", resData["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("should stream replayed and new sightings as events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		events := make(chan feed.Event, 1)
+		replayed := feed.Event{ID: 8, Sighting: model.Sighting{ID: uuid.New(), TigerID: 1}}
+		live := feed.Event{ID: 9, Sighting: model.Sighting{ID: uuid.New(), TigerID: 1}}
+
+		mockFeedService := mock_service.NewMockFeedService(ctrl)
+		mockFeedService.EXPECT().SubscribeSightings(gomock.Any(), service.SightingFeedReq{
+			LastEventID: 7,
+			TigerIDs:    []uint{1, 2},
+			BoundingBox: &feed.BoundingBox{MinLon: 70, MinLat: 20, MaxLon: 80, MaxLat: 30},
+		}).Return(&feed.Subscription{Replay: []feed.Event{replayed}, Events: events}, nil)
+
+		router := httprouter.New()
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Stream(middleware.EmptyMiddleware,
+			MakeFeedHandler(mockFeedService).StreamSightings))
+
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/sightings/stream?tiger_ids=1,2&bbox=70,20,80,30", nil)
+		req.Header.Set("Last-Event-ID", "7")
+
+		res, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(res.Body)
+		assert.Equal(t, "retry: 3000", readServerSentEvent(t, reader)[0])
+
+		lines := readServerSentEvent(t, reader)
+		assert.Equal(t, []string{"id: 8", "event: sighting"}, lines[:2])
+
+		events <- live
+		lines = readServerSentEvent(t, reader)
+		assert.Equal(t, "id: 9", lines[0])
+
+		var event feed.Event
+		_ = json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &event)
+		assert.Equal(t, live.Sighting.ID, event.Sighting.ID)
+
+		close(events)
+		_, err = reader.ReadString('\n')
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func readServerSentEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		assert.Nil(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestFeedHandler_SightingsWebSocket(t *testing.T) {
+	routePath := "/api/v1/sightings/ws"
+
+	t.Run("should return bad request for a plain http request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		feedHandler := MakeFeedHandler(mock_service.NewMockFeedService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/sightings/ws", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Stream(middleware.EmptyMiddleware,
+			feedHandler.SightingsWebSocket))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should push sightings as text messages", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		events := make(chan feed.Event, 1)
+		live := feed.Event{ID: 3, Sighting: model.Sighting{ID: uuid.New(), TigerID: 4}}
+
+		mockFeedService := mock_service.NewMockFeedService(ctrl)
+		mockFeedService.EXPECT().SubscribeSightings(gomock.Any(), service.SightingFeedReq{LastEventID: 2}).
+			Return(&feed.Subscription{Events: events}, nil)
+
+		router := httprouter.New()
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Stream(middleware.EmptyMiddleware,
+			MakeFeedHandler(mockFeedService).SightingsWebSocket))
+
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+		assert.Nil(t, err)
+		defer conn.Close()
+
+		key := "dGhlIHNhbXBsZSBub25jZQ=="
+		_, _ = fmt.Fprintf(conn, "GET /api/v1/sightings/ws?last_event_id=2 HTTP/1.1\r\nHost: feed\r\nUpgrade: websocket\r\n"+
+			"Connection: Upgrade\r\nSec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n", key)
+
+		reader := bufio.NewReader(conn)
+		res, err := http.ReadResponse(reader, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+		assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", res.Header.Get("Sec-WebSocket-Accept"))
+
+		events <- live
+
+		head := make([]byte, 2)
+		_, err = io.ReadFull(reader, head)
+		assert.Nil(t, err)
+		assert.Equal(t, byte(0x80|web.WebSocketOpText), head[0])
+
+		length := int(head[1] & 0x7F)
+		if length == 126 {
+			ext := make([]byte, 2)
+			_, _ = io.ReadFull(reader, ext)
+			length = int(binary.BigEndian.Uint16(ext))
+		}
+
+		payload := make([]byte, length)
+		_, err = io.ReadFull(reader, payload)
+		assert.Nil(t, err)
+
+		var event feed.Event
+		_ = json.Unmarshal(payload, &event)
+		assert.Equal(t, live.ID, event.ID)
+		assert.Equal(t, live.Sighting.ID, event.Sighting.ID)
+	})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+)
+
+type GeofenceHandler interface {
+	CreateGeofence(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	UpdateGeofence(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	DeleteGeofence(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	GetGeofence(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListGeofences(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+}
+
+type geofenceHandler struct {
+	geofenceService service.GeofenceService
+}
+
+func NewGeofenceHandler() GeofenceHandler {
+	return &geofenceHandler{geofenceService: service.NewGeofenceService()}
+}
+
+func MakeGeofenceHandler(geofenceService service.GeofenceService) GeofenceHandler {
+	return &geofenceHandler{geofenceService: geofenceService}
+}
+
+// CreateGeofence registers an area of interest for the logged-in user
+func (h *geofenceHandler) CreateGeofence(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.GeofenceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	geofence, err := h.geofenceService.CreateGeofence(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"geofence": geofence,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// UpdateGeofence replaces the name and shape of an area of interest
+func (h *geofenceHandler) UpdateGeofence(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	geofenceID, err := uuid.Parse(r.GetPathParam("geofence_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid geofence id")
+	}
+
+	var req service.GeofenceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	geofence, err := h.geofenceService.UpdateGeofence(r.Context(), geofenceID, req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"geofence": geofence,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+func (h *geofenceHandler) DeleteGeofence(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	geofenceID, err := uuid.Parse(r.GetPathParam("geofence_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid geofence id")
+	}
+
+	if err := h.geofenceService.DeleteGeofence(r.Context(), geofenceID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *geofenceHandler) GetGeofence(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	geofenceID, err := uuid.Parse(r.GetPathParam("geofence_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid geofence id")
+	}
+
+	geofence, err := h.geofenceService.GetGeofence(r.Context(), geofenceID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"geofence": geofence,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// ListGeofences lists the areas of interest of the logged-in user
+func (h *geofenceHandler) ListGeofences(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	pageStr := r.URL.Query().Get("page")
+	perPageStr := r.URL.Query().Get("per_page")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page <= 0 {
+		return nil, web.ErrBadRequest("Invalid page number")
+	}
+
+	perPage, err := strconv.Atoi(perPageStr)
+	if err != nil || perPage <= 0 {
+		return nil, web.ErrBadRequest("Invalid per_page value")
+	}
+
+	offset := (page - 1) * perPage
+
+	geofences, err := h.geofenceService.ListGeofences(r.Context(), repository.ListGeofencesOpts{
+		Limit:  perPage,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, web.ErrInternalServerError(fmt.Sprintf("Error while fetching geofences : %s", err.Error()))
+	}
+
+	res := map[string]interface{}{
+		"geofences": geofences,
+		"page":      page,
+		"per_page":  perPage,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestGeofenceHandler_CreateGeofence(t *testing.T) {
+	routePath := "/api/v1/geofences"
+
+	t.Run("should return bad request for invalid geofence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockGeofenceService := mock_service.NewMockGeofenceService(ctrl)
+		mockGeofenceService.EXPECT().CreateGeofence(gomock.Any(), service.GeofenceReq{Name: "Rajbagh lake"}).
+			Return(nil, fmt.Errorf("%w : exactly one of circle and polygon is required", service.ErrInvalidGeofence))
+
+		geofenceHandler := MakeGeofenceHandler(mockGeofenceService)
+
+		req, _ := http.NewRequest(http.MethodPost, routePath, bytes.NewBufferString(`{"name":"Rajbagh lake"}`))
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			geofenceHandler.CreateGeofence))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "invalid geofence : exactly one of circle and polygon is required", resData["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("should create geofence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		geofenceReq := service.GeofenceReq{
+			Name:   "Rajbagh lake",
+			Circle: &service.GeofenceCircle{Lat: 26.01, Lon: 76.5, RadiusInMeters: 500},
+		}
+		geofenceID := uuid.New()
+
+		mockGeofenceService := mock_service.NewMockGeofenceService(ctrl)
+		mockGeofenceService.EXPECT().CreateGeofence(gomock.Any(), geofenceReq).
+			Return(&model.Geofence{ID: geofenceID, Name: "Rajbagh lake", Kind: model.GeofenceKindCircle}, nil)
+
+		geofenceHandler := MakeGeofenceHandler(mockGeofenceService)
+
+		body, _ := json.Marshal(geofenceReq)
+		req, _ := http.NewRequest(http.MethodPost, routePath, bytes.NewBuffer(body))
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			geofenceHandler.CreateGeofence))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		geofence := resData["data"].(map[string]interface{})["geofence"].(map[string]interface{})
+		assert.Equal(t, geofenceID.String(), geofence["id"])
+		assert.Equal(t, "circle", geofence["kind"])
+	})
+}
+
+func TestGeofenceHandler_GetGeofence(t *testing.T) {
+	routePath := "/api/v1/geofences/:geofence_id"
+
+	t.Run("should return bad request for invalid geofence id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		geofenceHandler := MakeGeofenceHandler(mock_service.NewMockGeofenceService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/geofences/abc", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			geofenceHandler.GetGeofence))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should return not found for unknown geofence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		geofenceID := uuid.New()
+
+		mockGeofenceService := mock_service.NewMockGeofenceService(ctrl)
+		mockGeofenceService.EXPECT().GetGeofence(gomock.Any(), geofenceID).Return(nil, service.ErrGeofenceNotFound)
+
+		geofenceHandler := MakeGeofenceHandler(mockGeofenceService)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/geofences/"+geofenceID.String(), nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			geofenceHandler.GetGeofence))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
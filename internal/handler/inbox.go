@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+)
+
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+)
+
+type InboxHandler interface {
+	ListNotifications(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	UnreadCount(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	MarkRead(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	MarkAllRead(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+}
+
+type inboxHandler struct {
+	inboxService service.InboxService
+}
+
+func NewInboxHandler() InboxHandler {
+	return &inboxHandler{inboxService: service.NewInboxService()}
+}
+
+func MakeInboxHandler(inboxService service.InboxService) InboxHandler {
+	return &inboxHandler{inboxService: inboxService}
+}
+
+// ListNotifications lists the logged-in user's inbox, newest first. The next page is fetched by
+// passing the returned next_cursor as cursor.
+func (h *inboxHandler) ListNotifications(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	query := r.URL.Query()
+
+	limit := defaultInboxLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxInboxLimit {
+			return nil, web.ErrBadRequest("Invalid limit value")
+		}
+	}
+
+	unreadOnly := false
+	if unreadStr := query.Get("unread"); unreadStr != "" {
+		var err error
+		unreadOnly, err = strconv.ParseBool(unreadStr)
+		if err != nil {
+			return nil, web.ErrBadRequest("Invalid unread value")
+		}
+	}
+
+	page, err := h.inboxService.ListNotifications(r.Context(), service.ListInboxReq{
+		UnreadOnly: unreadOnly,
+		Cursor:     query.Get("cursor"),
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"notifications": page.Notifications,
+		"next_cursor":   page.NextCursor,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+func (h *inboxHandler) UnreadCount(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	count, err := h.inboxService.UnreadCount(r.Context())
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"unread": count,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+func (h *inboxHandler) MarkRead(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	id, err := uuid.Parse(r.GetPathParam("notification_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid notification id")
+	}
+
+	if err := h.inboxService.MarkRead(r.Context(), id); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *inboxHandler) MarkAllRead(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	count, err := h.inboxService.MarkAllRead(r.Context())
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"marked_read": count,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestInboxHandler_ListNotifications(t *testing.T) {
+	routePath := "/api/v1/me/notifications"
+
+	t.Run("should return bad request for invalid limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		inboxHandler := MakeInboxHandler(mock_service.NewMockInboxService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/me/notifications?limit=500", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			inboxHandler.ListNotifications))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "Invalid limit value", resData["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("should return bad request for invalid cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockInboxService := mock_service.NewMockInboxService(ctrl)
+		mockInboxService.EXPECT().ListNotifications(gomock.Any(), service.ListInboxReq{Cursor: "bad", Limit: 20}).
+			Return(nil, service.ErrInvalidCursor)
+
+		inboxHandler := MakeInboxHandler(mockInboxService)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/me/notifications?cursor=bad", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			inboxHandler.ListNotifications))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should list unread notifications", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		notificationID := uuid.New()
+
+		mockInboxService := mock_service.NewMockInboxService(ctrl)
+		mockInboxService.EXPECT().ListNotifications(gomock.Any(), service.ListInboxReq{UnreadOnly: true, Limit: 10}).
+			Return(&service.InboxPage{
+				Notifications: []model.InboxNotification{{ID: notificationID, Title: "Machli was sighted again"}},
+				NextCursor:    "next",
+			}, nil)
+
+		inboxHandler := MakeInboxHandler(mockInboxService)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/me/notifications?unread=true&limit=10", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			inboxHandler.ListNotifications))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		data := resData["data"].(map[string]interface{})
+		assert.Equal(t, "next", data["next_cursor"])
+		assert.Equal(t, notificationID.String(), data["notifications"].([]interface{})[0].(map[string]interface{})["id"])
+	})
+}
+
+func TestInboxHandler_MarkRead(t *testing.T) {
+	routePath := "/api/v1/me/notifications/:notification_id/read"
+
+	t.Run("should return bad request for invalid notification id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		inboxHandler := MakeInboxHandler(mock_service.NewMockInboxService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/me/notifications/abc/read", nil)
+
+		router.Handle(http.MethodPut, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			inboxHandler.MarkRead))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "Invalid notification id", resData["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("should return not found for unknown notification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		notificationID := uuid.New()
+
+		mockInboxService := mock_service.NewMockInboxService(ctrl)
+		mockInboxService.EXPECT().MarkRead(gomock.Any(), notificationID).Return(service.ErrInboxNotificationNotFound)
+
+		inboxHandler := MakeInboxHandler(mockInboxService)
+
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/me/notifications/"+notificationID.String()+"/read", nil)
+
+		router.Handle(http.MethodPut, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			inboxHandler.MarkRead))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestInboxHandler_UnreadCount(t *testing.T) {
+	t.Run("should return unread count", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockInboxService := mock_service.NewMockInboxService(ctrl)
+		mockInboxService.EXPECT().UnreadCount(gomock.Any()).Return(int64(3), nil)
+
+		inboxHandler := MakeInboxHandler(mockInboxService)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/me/notifications/unread-count", nil)
+
+		router.Handle(http.MethodGet, "/api/v1/me/notifications/unread-count", middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			inboxHandler.UnreadCount))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, float64(3), resData["data"].(map[string]interface{})["unread"])
+	})
+}
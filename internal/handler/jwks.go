@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/keyset"
+)
+
+// JWKSCacheMaxAge is how long partners may cache the keyset, a token with a kid missing from
+// their copy means it has to be fetched again
+const JWKSCacheMaxAge = 5 * time.Minute
+
+// JWKS publishes the public keys tokens are verified with. It is served as a plain JWK Set
+// rather than in the v1 envelope, so that JWT libraries of partner services can read it.
+func JWKS(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(JWKSCacheMaxAge.Seconds())))
+	_ = json.NewEncoder(w).Encode(keyset.Get().JWKS(time.Now()))
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/keyset"
+)
+
+func TestJWKS(t *testing.T) {
+	t.Run("should publish the public keys as a plain JWK Set", func(t *testing.T) {
+		previous := keyset.Get()
+		defer keyset.Set(previous)
+
+		keys, err := keyset.Generate("2026-10")
+		assert.Nil(t, err)
+		keyset.Set(keys)
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+		router.GET("/.well-known/jwks.json", JWKS)
+
+		req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "public, max-age=300", recorder.Header().Get("Cache-Control"))
+
+		var jwks keyset.JWKS
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &jwks))
+		assert.Len(t, jwks.Keys, 1)
+		assert.Equal(t, "2026-10", jwks.Keys[0].Kid)
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.NotContains(t, recorder.Body.String(), `"d"`)
+	})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+	"tigerhall_kittens/utils"
+)
+
+// EnrollMFA starts a TOTP enrollment and returns the secret to add to an authenticator app
+func (h *authHandler) EnrollMFA(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	enrollment, err := h.authService.EnrollMFA(r.Context())
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(enrollment)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+// ConfirmMFA enables MFA with a code from the authenticator app and returns the recovery codes
+func (h *authHandler) ConfirmMFA(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.ConfirmMFAReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.Code == "" {
+		return nil, web.ErrBadRequest("Missing code")
+	}
+	req.IP = r.ClientIP()
+
+	confirmation, err := h.authService.ConfirmMFA(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(confirmation)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+// VerifyMFA finishes a login that needs a second factor
+func (h *authHandler) VerifyMFA(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.VerifyMFAReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.MFAToken == "" {
+		return nil, web.ErrBadRequest("Missing MFA token")
+	}
+
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		return nil, web.ErrBadRequest("Either code or recovery_code is required")
+	}
+	req.IP = r.ClientIP()
+
+	loginResp, err := h.authService.VerifyMFA(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(loginResp)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+// ListRoleMFAPolicies lists the roles MFA has been configured for
+func (h *authHandler) ListRoleMFAPolicies(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	policies, err := h.authService.ListRoleMFAPolicies(r.Context())
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"policies": policies,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// SetRoleMFAPolicy requires, or stops requiring, MFA for a role
+func (h *authHandler) SetRoleMFAPolicy(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.SetRoleMFAPolicyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+	req.Role = model.Role(r.GetPathParam("role"))
+
+	if err := h.authService.SetRoleMFAPolicy(r.Context(), req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
@@ -4,44 +4,84 @@ import (
 	"context"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
-
+	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/service"
 	"tigerhall_kittens/internal/web"
 )
 
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware only lets requests through with a valid access token or a valid API key in the
+// X-API-Key header, either way the principal is put in the context. It does not check the scopes of
+// the key, routes that no permission covers must add RequireSession.
 func AuthMiddleware(next Controller) Controller {
+	withToken := authenticateToken(next)
+
 	return func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			return nil, web.ErrUnauthorizedRequest("Missing authorization header")
+		key := r.Header.Get(APIKeyHeader)
+		if key == "" {
+			return withToken(r)
 		}
 
-		tokenString := strings.Split(authHeader, " ")[1]
+		principal, err := service.NewAPIKeyService().Authenticate(r.Context(), key)
+		if err != nil {
+			return nil, web.ErrUnauthorizedRequest("Invalid API key")
+		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
+		setPrincipal(r, *principal, service.TokenPurposeAccess)
 
-			return service.JWTSecretKey, nil
-		})
+		return next(r)
+	}
+}
 
-		if err != nil {
-			return nil, web.ErrUnauthorizedRequest("Invalid token")
+// MFAEnrollmentMiddleware also accepts the token of a login that has to enroll in MFA first,
+// for the endpoints doing the enrollment. API keys are not accepted.
+func MFAEnrollmentMiddleware(next Controller) Controller {
+	return authenticateToken(next, service.TokenPurposeAccess, service.TokenPurposeMFAEnrollment)
+}
+
+// RequireSession refuses requests authenticated with an API key, for endpoints such as managing
+// API keys that a key must not be able to use. AuthMiddleware has to run first.
+func RequireSession(next Controller) Controller {
+	return func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+		principal, _ := r.Context().Value("principal").(model.Principal)
+		if principal.APIKeyID != nil {
+			return nil, web.ErrForbidden("API keys can not be used here, sign in instead")
 		}
 
-		if !token.Valid {
+		return next(r)
+	}
+}
+
+func authenticateToken(next Controller, purposes ...service.TokenPurpose) Controller {
+	return func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			return nil, web.ErrUnauthorizedRequest("Missing authorization header")
+		}
+
+		tokenString, ok := strings.CutPrefix(authHeader, "Bearer ")
+		if !ok {
 			return nil, web.ErrUnauthorizedRequest("Invalid token")
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			ctx := context.WithValue(r.Context(), "userID", claims["user_id"])
-			r.Request = r.Request.WithContext(ctx)
-		} else {
+		claims, err := service.NewAuthService().Authenticate(r.Context(), tokenString, purposes...)
+		if err != nil {
 			return nil, web.ErrUnauthorizedRequest("Invalid token")
 		}
 
+		setPrincipal(r, model.Principal{UserID: claims.UserID, Role: claims.Role}, claims.Purpose)
+
 		return next(r)
 	}
 }
+
+// setPrincipal puts the principal in the context, along with its user id and role on their own
+// for the services reading those
+func setPrincipal(r *web.Request, principal model.Principal, purpose service.TokenPurpose) {
+	ctx := context.WithValue(r.Context(), "principal", principal)
+	ctx = context.WithValue(ctx, "userID", principal.UserID.String())
+	ctx = context.WithValue(ctx, "role", string(principal.Role))
+	ctx = context.WithValue(ctx, "tokenPurpose", string(purpose))
+	r.Request = r.Request.WithContext(ctx)
+}
@@ -74,6 +74,9 @@ func serve(responseBuilder ResponseBuilder, handler Controller) httprouter.Handl
 		responseCode := responseCode(responseErr)
 
 		// setting response headers
+		for key, values := range webReq.ResponseHeaders() {
+			w.Header()[key] = values
+		}
 		w.Header().Set("Content-Type", "application/json")
 
 		w.WriteHeader(responseCode)
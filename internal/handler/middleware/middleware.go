@@ -5,3 +5,14 @@ type Middleware func(nextHandler Controller) Controller
 func EmptyMiddleware(nextHandler Controller) Controller {
 	return nextHandler
 }
+
+// Chain runs the middlewares in the given order, the first one sees the request first
+func Chain(middlewares ...Middleware) Middleware {
+	return func(nextHandler Controller) Controller {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			nextHandler = middlewares[i](nextHandler)
+		}
+
+		return nextHandler
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/web"
+)
+
+// RequirePermission only lets requests through whose principal has been granted permission, by
+// its role and for API keys by their scopes. The principal is put in the context by
+// AuthMiddleware, which has to run first.
+func RequirePermission(permission model.Permission) Middleware {
+	return func(next Controller) Controller {
+		return func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+			principal, _ := r.Context().Value("principal").(model.Principal)
+			if !principal.Can(permission) {
+				return nil, web.ErrForbidden(fmt.Sprintf("Missing permission %s", permission))
+			}
+
+			return next(r)
+		}
+	}
+}
+
+// Authorize authenticates the request and then requires permission
+func Authorize(permission model.Permission) Middleware {
+	return Chain(AuthMiddleware, RequirePermission(permission))
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/web"
+)
+
+func requestWithPrincipal(principal model.Principal) *web.Request {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tigers", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "principal", principal))
+
+	webReq := web.NewRequest(req)
+	return &webReq
+}
+
+func TestRequirePermission(t *testing.T) {
+	okController := func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+		return &web.JSONResponse{}, nil
+	}
+
+	t.Run("should let roles with the permission through", func(t *testing.T) {
+		controller := RequirePermission(model.PermissionWriteTigers)(okController)
+
+		for _, role := range []model.Role{model.RoleResearcher, model.RoleAdmin} {
+			_, err := controller(requestWithPrincipal(model.Principal{Role: role}))
+			assert.Nil(t, err)
+		}
+	})
+
+	t.Run("should forbid roles without the permission", func(t *testing.T) {
+		controller := RequirePermission(model.PermissionWriteTigers)(okController)
+
+		for _, role := range []model.Role{model.RoleViewer, model.RoleReporter, "ranger", ""} {
+			_, err := controller(requestWithPrincipal(model.Principal{Role: role}))
+			assert.NotNil(t, err)
+			assert.Equal(t, http.StatusForbidden, err.HTTPStatusCode())
+			assert.Equal(t, web.Forbidden, err.Code())
+		}
+	})
+
+	t.Run("should limit API keys to their scopes", func(t *testing.T) {
+		controller := RequirePermission(model.PermissionWriteTigers)(okController)
+		apiKeyID := uuid.New()
+
+		_, err := controller(requestWithPrincipal(model.Principal{
+			Role:     model.RoleAdmin,
+			APIKeyID: &apiKeyID,
+			Scopes:   []model.Permission{model.PermissionWriteTigers},
+		}))
+		assert.Nil(t, err)
+
+		_, err = controller(requestWithPrincipal(model.Principal{
+			Role:     model.RoleAdmin,
+			APIKeyID: &apiKeyID,
+			Scopes:   []model.Permission{model.PermissionReadTigers, model.PermissionWriteSightings},
+		}))
+		assert.Equal(t, http.StatusForbidden, err.HTTPStatusCode())
+	})
+
+	t.Run("should not let scopes grant more than the role", func(t *testing.T) {
+		controller := RequirePermission(model.PermissionWriteTigers)(okController)
+		apiKeyID := uuid.New()
+
+		_, err := controller(requestWithPrincipal(model.Principal{
+			Role:     model.RoleReporter,
+			APIKeyID: &apiKeyID,
+			Scopes:   []model.Permission{model.PermissionWriteTigers},
+		}))
+		assert.Equal(t, http.StatusForbidden, err.HTTPStatusCode())
+	})
+}
+
+func TestRequireSession(t *testing.T) {
+	okController := func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+		return &web.JSONResponse{}, nil
+	}
+
+	t.Run("should refuse API keys and let tokens through", func(t *testing.T) {
+		apiKeyID := uuid.New()
+
+		_, err := RequireSession(okController)(requestWithPrincipal(model.Principal{Role: model.RoleAdmin, APIKeyID: &apiKeyID}))
+		assert.Equal(t, http.StatusForbidden, err.HTTPStatusCode())
+
+		_, err = RequireSession(okController)(requestWithPrincipal(model.Principal{Role: model.RoleAdmin}))
+		assert.Nil(t, err)
+	})
+}
+
+func TestChain(t *testing.T) {
+	t.Run("should run the middlewares in order", func(t *testing.T) {
+		var calls []string
+		record := func(name string) Middleware {
+			return func(next Controller) Controller {
+				return func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+					calls = append(calls, name)
+					return next(r)
+				}
+			}
+		}
+
+		controller := Chain(record("auth"), record("permission"))(func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+			calls = append(calls, "handler")
+			return &web.JSONResponse{}, nil
+		})
+
+		_, _ = controller(requestWithPrincipal(model.Principal{}))
+		assert.Equal(t, []string{"auth", "permission", "handler"}, calls)
+	})
+}
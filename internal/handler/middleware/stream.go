@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/shared"
+	"tigerhall_kittens/internal/web"
+)
+
+// StreamController writes its own response, for endpoints that keep the connection open. It
+// may only return an error before it has written anything.
+type StreamController func(w http.ResponseWriter, request *web.Request) web.ErrorInterface
+
+// ServeV1Stream runs a streaming endpoint behind the same middleware as ServeV1Endpoint, errors
+// returned before the stream starts are written as regular v1 error responses
+func ServeV1Stream(middleware Middleware, handler StreamController) httprouter.Handle {
+	responseBuilder := buildResponseBuilder(APIVersionV1)
+
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		startTime := time.Now()
+		contextWithResult := context.WithValue(req.Context(), shared.CtxValueRequestId, RequestHeaderId(req))
+		contextWithResult = context.WithValue(contextWithResult, shared.CtxPathURL, getURL(req))
+		req = req.WithContext(contextWithResult)
+
+		webReq := web.NewRequest(req)
+		for i := range ps {
+			webReq.SetPathParam(ps[i].Key, ps[i].Value)
+		}
+
+		controller := middleware(func(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+			return nil, handler(w, r)
+		})
+
+		if _, streamErr := controller(&webReq); streamErr != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(streamErr.HTTPStatusCode())
+			writeResponse(req.Context(), w, responseBuilder(nil, streamErr))
+
+			logger.W(req.Context(), "Stream request error",
+				logger.Field("error", streamErr.Error()),
+				logger.Field("status", streamErr.HTTPStatusCode()),
+				logger.Field("path", getURL(req)),
+				logger.Field("request_params", req.URL.Query()),
+				logger.Field("method", req.Method),
+			)
+
+			return
+		}
+
+		logger.I(req.Context(), "Stream closed",
+			logger.Field("path", getURL(req)),
+			logger.Field("request_params", webReq.QueryParams()),
+			logger.Field("duration_ms", float64(time.Since(startTime).Milliseconds())),
+			logger.Field("method", req.Method),
+		)
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+)
+
+type NotificationHandler interface {
+	ListOutboxNotifications(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ReplayNotification(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListTemplates(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	PreviewTemplate(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+}
+
+type notificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler() NotificationHandler {
+	return &notificationHandler{notificationService: service.NewNotificationService()}
+}
+
+func MakeNotificationHandler(notificationService service.NotificationService) NotificationHandler {
+	return &notificationHandler{notificationService: notificationService}
+}
+
+// ListOutboxNotifications lists outbox rows by status, dead-lettered ones by default
+func (h *notificationHandler) ListOutboxNotifications(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	status := model.OutboxStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = model.OutboxStatusDead
+	case model.OutboxStatusPending, model.OutboxStatusSent, model.OutboxStatusDead,
+		model.OutboxStatusSkipped, model.OutboxStatusDigest, model.OutboxStatusDigested:
+	default:
+		return nil, web.ErrBadRequest("Invalid status value")
+	}
+
+	pageStr := r.URL.Query().Get("page")
+	perPageStr := r.URL.Query().Get("per_page")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page <= 0 {
+		return nil, web.ErrBadRequest("Invalid page number")
+	}
+
+	perPage, err := strconv.Atoi(perPageStr)
+	if err != nil || perPage <= 0 {
+		return nil, web.ErrBadRequest("Invalid per_page value")
+	}
+
+	offset := (page - 1) * perPage
+
+	notifications, err := h.notificationService.ListOutboxNotifications(r.Context(), repository.ListOutboxOpts{
+		Status: status,
+		Limit:  perPage,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, web.ErrInternalServerError(fmt.Sprintf("Error while fetching notifications : %s", err.Error()))
+	}
+
+	res := map[string]interface{}{
+		"notifications": notifications,
+		"page":          page,
+		"per_page":      perPage,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// ReplayNotification puts a dead-lettered notification back in the outbox
+func (h *notificationHandler) ReplayNotification(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	notificationID, err := uuid.Parse(r.GetPathParam("notification_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid notification id")
+	}
+
+	if err := h.notificationService.ReplayNotification(r.Context(), notificationID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *notificationHandler) ListTemplates(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	res := map[string]interface{}{
+		"templates": h.notificationService.ListTemplates(r.Context()),
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// PreviewTemplate renders a notification template with sample data in the requested locale
+func (h *notificationHandler) PreviewTemplate(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	name := r.GetPathParam("template")
+
+	locale := r.URL.Query().Get("locale")
+	if locale == "" {
+		locale = notification_worker.DefaultLocale
+	}
+
+	content, err := h.notificationService.PreviewTemplate(r.Context(), name, locale)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"template": name,
+		"locale":   locale,
+		"subject":  content.Subject,
+		"text":     content.Text,
+		"html":     content.HTML,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestNotificationHandler_ListOutboxNotifications(t *testing.T) {
+	routePath := "/api/v1/admin/notifications"
+
+	t.Run("should return bad request for unknown status", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		notificationHandler := MakeNotificationHandler(mock_service.NewMockNotificationService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodGet, routePath+"?status=unknown&page=1&per_page=10", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.ListOutboxNotifications))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "Invalid status value", resData["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("should return ISE if service returns error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+		mockNotificationService.EXPECT().ListOutboxNotifications(gomock.Any(), repository.ListOutboxOpts{
+			Status: model.OutboxStatusDead,
+			Limit:  10,
+			Offset: 10,
+		}).Return(nil, errors.New("some error"))
+
+		notificationHandler := MakeNotificationHandler(mockNotificationService)
+
+		req, _ := http.NewRequest(http.MethodGet, routePath+"?page=2&per_page=10", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.ListOutboxNotifications))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "Error while fetching notifications : some error", resData["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("should return notifications with requested status", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockNotifications := []model.OutboxNotification{
+			{ID: uuid.New(), Status: model.OutboxStatusPending},
+		}
+
+		mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+		mockNotificationService.EXPECT().ListOutboxNotifications(gomock.Any(), repository.ListOutboxOpts{
+			Status: model.OutboxStatusPending,
+			Limit:  10,
+			Offset: 0,
+		}).Return(mockNotifications, nil)
+
+		notificationHandler := MakeNotificationHandler(mockNotificationService)
+
+		req, _ := http.NewRequest(http.MethodGet, routePath+"?status=pending&page=1&per_page=10", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.ListOutboxNotifications))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, true, resData["success"])
+		assert.Len(t, resData["data"].(map[string]interface{})["notifications"], 1)
+	})
+}
+
+func TestNotificationHandler_ReplayNotification(t *testing.T) {
+	routePath := "/api/v1/admin/notifications/:notification_id/replay"
+	notificationID := uuid.New()
+
+	t.Run("should return bad request for invalid notification id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		notificationHandler := MakeNotificationHandler(mock_service.NewMockNotificationService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/notifications/not-a-uuid/replay", nil)
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.ReplayNotification))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should return not found when notification is not dead-lettered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+		mockNotificationService.EXPECT().ReplayNotification(gomock.Any(), notificationID).Return(service.ErrNotificationNotReplayable)
+
+		notificationHandler := MakeNotificationHandler(mockNotificationService)
+
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/admin/notifications/%s/replay", notificationID), nil)
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.ReplayNotification))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("should return bad request when the notification's one-time token is gone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+		mockNotificationService.EXPECT().ReplayNotification(gomock.Any(), notificationID).Return(service.ErrNotificationTokenDropped)
+
+		notificationHandler := MakeNotificationHandler(mockNotificationService)
+
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/admin/notifications/%s/replay", notificationID), nil)
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.ReplayNotification))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "request a new one")
+	})
+
+	t.Run("should replay notification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+		mockNotificationService.EXPECT().ReplayNotification(gomock.Any(), notificationID).Return(nil)
+
+		notificationHandler := MakeNotificationHandler(mockNotificationService)
+
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/admin/notifications/%s/replay", notificationID), nil)
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.ReplayNotification))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, true, resData["success"])
+	})
+}
+
+func TestNotificationHandler_PreviewTemplate(t *testing.T) {
+	routePath := "/api/v1/admin/notification-templates/:template/preview"
+
+	t.Run("should return not found for unknown template", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+		mockNotificationService.EXPECT().PreviewTemplate(gomock.Any(), "unknown", "en").Return(nil, service.ErrNotificationTemplateNotFound)
+
+		notificationHandler := MakeNotificationHandler(mockNotificationService)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/notification-templates/unknown/preview", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.PreviewTemplate))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("should render template in requested locale", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockNotificationService := mock_service.NewMockNotificationService(ctrl)
+		mockNotificationService.EXPECT().PreviewTemplate(gomock.Any(), "tiger_sighting", "hi").Return(&notification_worker.Content{
+			Subject: "subject",
+			Text:    "text",
+			HTML:    "<p>html</p>",
+		}, nil)
+
+		notificationHandler := MakeNotificationHandler(mockNotificationService)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/notification-templates/tiger_sighting/preview?locale=hi", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			notificationHandler.PreviewTemplate))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		data := resData["data"].(map[string]interface{})
+		assert.Equal(t, "tiger_sighting", data["template"])
+		assert.Equal(t, "hi", data["locale"])
+		assert.Equal(t, "subject", data["subject"])
+		assert.Equal(t, "text", data["text"])
+		assert.Equal(t, "<p>html</p>", data["html"])
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+	"tigerhall_kittens/utils"
+)
+
+// ListOIDCProviders lists the identity providers users can sign in with
+func (h *authHandler) ListOIDCProviders(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	res := map[string]interface{}{
+		"providers": h.authService.ListOIDCProviders(r.Context()),
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// StartOIDCLogin returns the URL to send the user to for signing in at an identity provider
+func (h *authHandler) StartOIDCLogin(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	authorization, err := h.authService.StartOIDCLogin(r.Context(), r.GetPathParam("provider"))
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(authorization)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+// FinishOIDCLogin logs in with the code and state the identity provider redirected back with
+func (h *authHandler) FinishOIDCLogin(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.OIDCCallbackReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.Code == "" || req.State == "" {
+		return nil, web.ErrBadRequest("Missing code or state")
+	}
+	req.Provider = r.GetPathParam("provider")
+	req.IP = r.ClientIP()
+
+	loginResp, err := h.authService.FinishOIDCLogin(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(loginResp)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+)
+
+type PreferencesHandler interface {
+	GetPreferences(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	UpdatePreferences(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	MuteTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	UnmuteTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+}
+
+type preferencesHandler struct {
+	preferencesService service.PreferencesService
+}
+
+func NewPreferencesHandler() PreferencesHandler {
+	return &preferencesHandler{preferencesService: service.NewPreferencesService()}
+}
+
+func MakePreferencesHandler(preferencesService service.PreferencesService) PreferencesHandler {
+	return &preferencesHandler{preferencesService: preferencesService}
+}
+
+func (h *preferencesHandler) GetPreferences(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	preferences, err := h.preferencesService.GetPreferences(r.Context())
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"preferences": preferences,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// UpdatePreferences replaces the notification preferences of the logged-in user
+func (h *preferencesHandler) UpdatePreferences(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.UpdatePreferencesReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	preferences, err := h.preferencesService.UpdatePreferences(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"preferences": preferences,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+func (h *preferencesHandler) MuteTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	tigerID, err := strconv.ParseUint(r.GetPathParam("tiger_id"), 10, 0)
+	if err != nil || tigerID == 0 {
+		return nil, web.ErrBadRequest("Invalid tiger id")
+	}
+
+	if err := h.preferencesService.MuteTiger(r.Context(), uint(tigerID)); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *preferencesHandler) UnmuteTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	tigerID, err := strconv.ParseUint(r.GetPathParam("tiger_id"), 10, 0)
+	if err != nil || tigerID == 0 {
+		return nil, web.ErrBadRequest("Invalid tiger id")
+	}
+
+	if err := h.preferencesService.UnmuteTiger(r.Context(), uint(tigerID)); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
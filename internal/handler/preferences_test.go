@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestPreferencesHandler_UpdatePreferences(t *testing.T) {
+	routePath := "/api/v1/me/notification-preferences"
+
+	t.Run("should return bad request for invalid preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockPreferencesService := mock_service.NewMockPreferencesService(ctrl)
+		mockPreferencesService.EXPECT().UpdatePreferences(gomock.Any(), service.UpdatePreferencesReq{Delivery: "weekly"}).
+			Return(nil, fmt.Errorf("%w : delivery must be one of immediate, hourly and daily", service.ErrInvalidPreferences))
+
+		preferencesHandler := MakePreferencesHandler(mockPreferencesService)
+
+		req, _ := http.NewRequest(http.MethodPut, routePath, bytes.NewBufferString(`{"delivery":"weekly"}`))
+
+		router.Handle(http.MethodPut, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			preferencesHandler.UpdatePreferences))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should return updated preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		preferences := model.DefaultNotificationPreferences(uuid.New())
+		preferences.Delivery = model.DeliveryDailyDigest
+
+		mockPreferencesService := mock_service.NewMockPreferencesService(ctrl)
+		mockPreferencesService.EXPECT().UpdatePreferences(gomock.Any(), service.UpdatePreferencesReq{EmailEnabled: true, Delivery: "daily"}).
+			Return(&service.PreferencesRes{NotificationPreferences: preferences, MutedTigerIDs: []uint{}}, nil)
+
+		preferencesHandler := MakePreferencesHandler(mockPreferencesService)
+
+		req, _ := http.NewRequest(http.MethodPut, routePath, bytes.NewBufferString(`{"email_enabled":true,"delivery":"daily"}`))
+
+		router.Handle(http.MethodPut, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			preferencesHandler.UpdatePreferences))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		res := resData["data"].(map[string]interface{})["preferences"].(map[string]interface{})
+		assert.Equal(t, "daily", res["delivery"])
+		assert.Equal(t, true, res["email_enabled"])
+		assert.Equal(t, []interface{}{}, res["muted_tiger_ids"])
+	})
+}
+
+func TestPreferencesHandler_UnmuteTiger(t *testing.T) {
+	routePath := "/api/v1/tigers/:tiger_id/mute"
+
+	t.Run("should return not found when tiger is not muted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockPreferencesService := mock_service.NewMockPreferencesService(ctrl)
+		mockPreferencesService.EXPECT().UnmuteTiger(gomock.Any(), uint(7)).Return(service.ErrTigerNotMuted)
+
+		preferencesHandler := MakePreferencesHandler(mockPreferencesService)
+
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tigers/7/mute", nil)
+
+		router.Handle(http.MethodDelete, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			preferencesHandler.UnmuteTiger))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+	"tigerhall_kittens/utils"
+)
+
+// RequestDataExport queues an export of the personal data of the logged-in user, its status is
+// polled with GetDataExport until it can be downloaded
+func (h *userHandler) RequestDataExport(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	export, err := h.userService.RequestDataExport(r.Context(), r.ClientIP())
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return dataExportResponse(export)
+}
+
+func (h *userHandler) GetDataExport(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	exportID, err := uuid.Parse(r.GetPathParam("export_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid export id")
+	}
+
+	export, err := h.userService.GetDataExport(r.Context(), exportID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return dataExportResponse(export)
+}
+
+// DownloadDataExport sends the ZIP archive of a ready export
+func (h *userHandler) DownloadDataExport(w http.ResponseWriter, r *web.Request) web.ErrorInterface {
+	exportID, err := uuid.Parse(r.GetPathParam("export_id"))
+	if err != nil {
+		return web.ErrBadRequest("Invalid export id")
+	}
+
+	export, err := h.userService.DownloadDataExport(r.Context(), exportID)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	filename := fmt.Sprintf("tigerhall-kittens-export-%s.zip", export.CompletedAt.UTC().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(export.Archive)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(export.Archive)
+
+	return nil
+}
+
+// EraseAccount erases the personal data of the logged-in user after checking their password,
+// their sightings are kept anonymously
+func (h *userHandler) EraseAccount(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.EraseAccountReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.Password == "" {
+		return nil, web.ErrBadRequest("Missing password")
+	}
+
+	if err := h.userService.EraseAccount(r.Context(), req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// EraseUser erases the personal data of any user, deleted ones included
+func (h *userHandler) EraseUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	userID, err := uuid.Parse(r.GetPathParam("user_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid user id")
+	}
+
+	if err := h.userService.EraseUser(r.Context(), userID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func dataExportResponse(export *model.DataExport) (*web.JSONResponse, web.ErrorInterface) {
+	jsonResponse, err := utils.StructToMap(export)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
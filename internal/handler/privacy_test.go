@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestUserHandler_DownloadDataExport(t *testing.T) {
+	path := "/api/v1/me/exports/:export_id/download"
+	exportID := uuid.New()
+
+	t.Run("should send the archive as a zip attachment", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		completedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().DownloadDataExport(gomock.Any(), exportID).Return(&model.DataExport{
+			ID:          exportID,
+			Status:      model.DataExportStatusReady,
+			Archive:     []byte("PK"),
+			CompletedAt: &completedAt,
+		}, nil)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet,
+			"/api/v1/me/exports/"+exportID.String()+"/download", nil)
+
+		router.Handle(http.MethodGet, path, middleware.ServeV1Stream(middleware.EmptyMiddleware, userHandler.DownloadDataExport))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="tigerhall-kittens-export-2026-10-18.zip"`, recorder.Header().Get("Content-Disposition"))
+		assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+		assert.Equal(t, "PK", recorder.Body.String())
+	})
+
+	t.Run("should return bad request when the export is not ready yet", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().DownloadDataExport(gomock.Any(), exportID).Return(nil, service.ErrDataExportNotReady)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet,
+			"/api/v1/me/exports/"+exportID.String()+"/download", nil)
+
+		router.Handle(http.MethodGet, path, middleware.ServeV1Stream(middleware.EmptyMiddleware, userHandler.DownloadDataExport))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestUserHandler_EraseAccount(t *testing.T) {
+	path := "/api/v1/me/erase"
+
+	t.Run("should return bad request without a password", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userHandler := MakeUserHandler(mock_service.NewMockUserService(ctrl))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path, strings.NewReader(`{}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.EraseAccount))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should erase the account once the password is confirmed", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().EraseAccount(gomock.Any(), service.EraseAccountReq{Password: "password"}).Return(nil)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path, strings.NewReader(`{"password":"password"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.EraseAccount))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+)
+
+type SubscriptionHandler interface {
+	Subscribe(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	Unsubscribe(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListSubscriptions(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+}
+
+type subscriptionHandler struct {
+	subscriptionService service.SubscriptionService
+}
+
+func NewSubscriptionHandler() SubscriptionHandler {
+	return &subscriptionHandler{subscriptionService: service.NewSubscriptionService()}
+}
+
+func MakeSubscriptionHandler(subscriptionService service.SubscriptionService) SubscriptionHandler {
+	return &subscriptionHandler{subscriptionService: subscriptionService}
+}
+
+// Subscribe subscribes the logged-in user to sightings of a tiger
+func (h *subscriptionHandler) Subscribe(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	tigerID, err := strconv.ParseUint(r.GetPathParam("tiger_id"), 10, 0)
+	if err != nil || tigerID == 0 {
+		return nil, web.ErrBadRequest("Invalid tiger id")
+	}
+
+	if err := h.subscriptionService.Subscribe(r.Context(), uint(tigerID)); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *subscriptionHandler) Unsubscribe(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	tigerID, err := strconv.ParseUint(r.GetPathParam("tiger_id"), 10, 0)
+	if err != nil || tigerID == 0 {
+		return nil, web.ErrBadRequest("Invalid tiger id")
+	}
+
+	if err := h.subscriptionService.Unsubscribe(r.Context(), uint(tigerID)); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// ListSubscriptions lists the tigers the logged-in user is subscribed to
+func (h *subscriptionHandler) ListSubscriptions(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	pageStr := r.URL.Query().Get("page")
+	perPageStr := r.URL.Query().Get("per_page")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page <= 0 {
+		return nil, web.ErrBadRequest("Invalid page number")
+	}
+
+	perPage, err := strconv.Atoi(perPageStr)
+	if err != nil || perPage <= 0 {
+		return nil, web.ErrBadRequest("Invalid per_page value")
+	}
+
+	offset := (page - 1) * perPage
+
+	subscriptions, err := h.subscriptionService.ListSubscriptions(r.Context(), repository.ListSubscriptionsOpts{
+		Limit:  perPage,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, web.ErrInternalServerError(fmt.Sprintf("Error while fetching subscriptions : %s", err.Error()))
+	}
+
+	res := map[string]interface{}{
+		"subscriptions": subscriptions,
+		"page":          page,
+		"per_page":      perPage,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestSubscriptionHandler_Subscribe(t *testing.T) {
+	routePath := "/api/v1/tigers/:tiger_id/subscriptions"
+
+	t.Run("should return bad request for invalid tiger id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		subscriptionHandler := MakeSubscriptionHandler(mock_service.NewMockSubscriptionService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/tigers/abc/subscriptions", nil)
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			subscriptionHandler.Subscribe))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "Invalid tiger id", resData["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("should subscribe to tiger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockSubscriptionService := mock_service.NewMockSubscriptionService(ctrl)
+		mockSubscriptionService.EXPECT().Subscribe(gomock.Any(), uint(7)).Return(nil)
+
+		subscriptionHandler := MakeSubscriptionHandler(mockSubscriptionService)
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/tigers/7/subscriptions", nil)
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			subscriptionHandler.Subscribe))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestSubscriptionHandler_Unsubscribe(t *testing.T) {
+	routePath := "/api/v1/tigers/:tiger_id/subscriptions"
+
+	t.Run("should return not found when user is not subscribed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockSubscriptionService := mock_service.NewMockSubscriptionService(ctrl)
+		mockSubscriptionService.EXPECT().Unsubscribe(gomock.Any(), uint(7)).Return(service.ErrSubscriptionNotFound)
+
+		subscriptionHandler := MakeSubscriptionHandler(mockSubscriptionService)
+
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tigers/7/subscriptions", nil)
+
+		router.Handle(http.MethodDelete, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			subscriptionHandler.Unsubscribe))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestSubscriptionHandler_ListSubscriptions(t *testing.T) {
+	routePath := "/api/v1/me/subscriptions"
+
+	t.Run("should return bad request for invalid page", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		subscriptionHandler := MakeSubscriptionHandler(mock_service.NewMockSubscriptionService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodGet, routePath+"?page=0&per_page=10", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			subscriptionHandler.ListSubscriptions))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should list subscriptions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockSubscriptionService := mock_service.NewMockSubscriptionService(ctrl)
+		mockSubscriptionService.EXPECT().ListSubscriptions(gomock.Any(), repository.ListSubscriptionsOpts{
+			Limit:  10,
+			Offset: 10,
+		}).Return([]model.TigerSubscription{{TigerID: 7}}, nil)
+
+		subscriptionHandler := MakeSubscriptionHandler(mockSubscriptionService)
+
+		req, _ := http.NewRequest(http.MethodGet, routePath+"?page=2&per_page=10", nil)
+
+		router.Handle(http.MethodGet, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			subscriptionHandler.ListSubscriptions))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Len(t, resData["data"].(map[string]interface{})["subscriptions"], 1)
+	})
+}
@@ -2,18 +2,28 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
 	"tigerhall_kittens/internal/service"
 	"tigerhall_kittens/internal/web"
+	"tigerhall_kittens/utils"
 )
 
 type TigerHandler interface {
 	CreateTiger(req *web.Request) (*web.JSONResponse, web.ErrorInterface)
 	ListTigers(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	GetTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	UpdateTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	DeleteTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	RestoreTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
 }
 
 type tigerHandler struct {
@@ -29,25 +39,31 @@ func MakeTigerHandler(tigerService service.TigerService) TigerHandler {
 }
 
 func (t *tigerHandler) CreateTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
-	var tiger model.Tiger
-	if err := json.NewDecoder(r.Body).Decode(&tiger); err != nil {
+	var req model.Tiger
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return nil, web.ErrBadRequest("Failed to decode request body")
 	}
 
+	// the id, version and timestamps are not the client's to set
+	tiger := model.Tiger{
+		Name:              req.Name,
+		DateOfBirth:       req.DateOfBirth,
+		LastSeenTimestamp: req.LastSeenTimestamp,
+		LastSeenLat:       req.LastSeenLat,
+		LastSeenLon:       req.LastSeenLon,
+	}
+
 	err := t.tigerService.CreateTiger(r.Context(), &tiger)
 	if err != nil {
 		return nil, web.ErrInternalServerError(fmt.Sprintf("error while saving tiger : %s", err))
 	}
 
-	//jsonResponse, err := utils.StructToMap(tiger)
-	//if err != nil {
-	//	return nil, web.ErrInternalServerError(err.Error())
-	//}
-
-	//return (*web.JSONResponse)(&jsonResponse), nil
-	return &web.JSONResponse{}, nil
+	return tigerResponse(r, &tiger)
 }
 
+// ListTigers lists a page of tigers. They can be filtered by the q, born_after, born_before,
+// seen_after, seen_before, bbox, near with radius and status query parameters, and ordered by
+// sort, see parseTigerFilter and parseTigerSort.
 func (t *tigerHandler) ListTigers(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
 	pageStr := r.URL.Query().Get("page")
 	perPageStr := r.URL.Query().Get("per_page")
@@ -64,7 +80,22 @@ func (t *tigerHandler) ListTigers(r *web.Request) (*web.JSONResponse, web.ErrorI
 
 	offset := (page - 1) * perPage
 
-	tigers, err := t.tigerService.ListTigers(r.Context(), repository.ListTigersOpts{Limit: perPage, Offset: offset})
+	filter, errResp := parseTigerFilter(r)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	sort, errResp := parseTigerSort(r.URL.Query().Get("sort"))
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	tigers, err := t.tigerService.ListTigers(r.Context(), repository.ListTigersOpts{
+		Limit:  perPage,
+		Offset: offset,
+		Filter: filter,
+		Sort:   sort,
+	})
 	if err != nil {
 		return nil, web.ErrInternalServerError(fmt.Sprintf("Error while fetching tigers : %s", err.Error()))
 	}
@@ -77,3 +108,243 @@ func (t *tigerHandler) ListTigers(r *web.Request) (*web.JSONResponse, web.ErrorI
 
 	return (*web.JSONResponse)(&res), nil
 }
+
+func (t *tigerHandler) GetTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	tigerID, err := strconv.ParseUint(r.GetPathParam("tiger_id"), 10, 0)
+	if err != nil || tigerID == 0 {
+		return nil, web.ErrBadRequest("Invalid tiger id")
+	}
+
+	tiger, err := t.tigerService.GetTiger(r.Context(), repository.GetTigerOpts{TigerID: uint(tigerID)})
+	if errors.Is(err, service.ErrTigerNotFound) {
+		return nil, errorResponse(err)
+	}
+
+	if err != nil {
+		return nil, web.ErrInternalServerError(fmt.Sprintf("Error while fetching tiger : %s", err.Error()))
+	}
+
+	return tigerResponse(r, tiger)
+}
+
+// UpdateTiger changes a tiger, the If-Match header carries the version the changes are based on
+// so a concurrent update is not silently overwritten
+func (t *tigerHandler) UpdateTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	tigerID, err := strconv.ParseUint(r.GetPathParam("tiger_id"), 10, 0)
+	if err != nil || tigerID == 0 {
+		return nil, web.ErrBadRequest("Invalid tiger id")
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil, web.ErrBadRequest("Missing If-Match header")
+	}
+
+	// the version is accepted as a bare number or as an ETag, weak or strong
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+	if err != nil || version <= 0 {
+		return nil, web.ErrBadRequest("Invalid If-Match header")
+	}
+
+	var req service.UpdateTigerReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.Name == nil && req.DateOfBirth == nil {
+		return nil, web.ErrBadRequest("Nothing to update")
+	}
+
+	tiger, err := t.tigerService.UpdateTiger(r.Context(), uint(tigerID), version, req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return tigerResponse(r, tiger)
+}
+
+func (t *tigerHandler) DeleteTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	tigerID, err := strconv.ParseUint(r.GetPathParam("tiger_id"), 10, 0)
+	if err != nil || tigerID == 0 {
+		return nil, web.ErrBadRequest("Invalid tiger id")
+	}
+
+	if err := t.tigerService.DeleteTiger(r.Context(), uint(tigerID)); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (t *tigerHandler) RestoreTiger(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	tigerID, err := strconv.ParseUint(r.GetPathParam("tiger_id"), 10, 0)
+	if err != nil || tigerID == 0 {
+		return nil, web.ErrBadRequest("Invalid tiger id")
+	}
+
+	tiger, err := t.tigerService.RestoreTiger(r.Context(), uint(tigerID))
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return tigerResponse(r, tiger)
+}
+
+// parseTigerFilter reads the filters of a tiger listing from the query parameters:
+//   - q matches the start of the name, ignoring case, or a name close to it
+//   - born_after and born_before bound the date of birth, as YYYY-MM-DD
+//   - seen_after and seen_before bound the last seen time, as RFC 3339
+//   - bbox is min_lon,min_lat,max_lon,max_lat around the last seen position
+//   - near is lat,lon and radius the distance in meters from it to the last seen position, up to
+//     maxTigerSearchRadius
+//   - status is active, deleted or all, only tiger managers see deleted tigers
+func parseTigerFilter(r *web.Request) (repository.TigerFilter, web.ErrorInterface) {
+	query := r.URL.Query()
+	filter := repository.TigerFilter{Name: strings.TrimSpace(query.Get("q"))}
+
+	if len(filter.Name) > 255 {
+		return filter, web.ErrBadRequest("Invalid q value")
+	}
+
+	var errResp web.ErrorInterface
+	if filter.BornAfter, errResp = parseTimeParam(query, "born_after", dateOfBirthLayout); errResp != nil {
+		return filter, errResp
+	}
+
+	if filter.BornBefore, errResp = parseTimeParam(query, "born_before", dateOfBirthLayout); errResp != nil {
+		return filter, errResp
+	}
+
+	if filter.SeenAfter, errResp = parseTimeParam(query, "seen_after", time.RFC3339); errResp != nil {
+		return filter, errResp
+	}
+
+	if filter.SeenBefore, errResp = parseTimeParam(query, "seen_before", time.RFC3339); errResp != nil {
+		return filter, errResp
+	}
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		values, ok := parseFloats(bbox, 4)
+		if !ok || !validLon(values[0]) || !validLat(values[1]) || !validLon(values[2]) || !validLat(values[3]) ||
+			values[0] > values[2] || values[1] > values[3] {
+			return filter, web.ErrBadRequest("Invalid bbox value, expected min_lon,min_lat,max_lon,max_lat")
+		}
+		filter.Within = &repository.BoundingBox{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+	}
+
+	near, radius := query.Get("near"), query.Get("radius")
+	if near != "" || radius != "" {
+		values, ok := parseFloats(near, 2)
+		if !ok || !validLat(values[0]) || !validLon(values[1]) {
+			return filter, web.ErrBadRequest("Invalid near value, expected lat,lon")
+		}
+
+		meters, err := strconv.ParseFloat(radius, 64)
+		if err != nil || !finite(meters) || meters <= 0 || meters > maxTigerSearchRadius {
+			return filter, web.ErrBadRequest(fmt.Sprintf("Invalid radius value, expected up to %d meters", maxTigerSearchRadius))
+		}
+		filter.Near = &repository.Radius{Lat: values[0], Lon: values[1], Meters: meters}
+	}
+
+	if status := query.Get("status"); status != "" {
+		filter.Status = model.TigerStatus(status)
+		if !filter.Status.Valid() {
+			return filter, web.ErrBadRequest("Invalid status value")
+		}
+
+		principal, _ := r.Context().Value("principal").(model.Principal)
+		if filter.Status != model.TigerStatusActive && !principal.Can(model.PermissionManageTigers) {
+			return filter, web.ErrForbidden("Only tiger managers can list deleted tigers")
+		}
+	}
+
+	if filter.BornAfter != nil && filter.BornBefore != nil && filter.BornAfter.After(*filter.BornBefore) {
+		return filter, web.ErrBadRequest("born_after must not be after born_before")
+	}
+
+	if filter.SeenAfter != nil && filter.SeenBefore != nil && filter.SeenAfter.After(*filter.SeenBefore) {
+		return filter, web.ErrBadRequest("seen_after must not be after seen_before")
+	}
+
+	return filter, nil
+}
+
+// parseTigerSort reads the order of a tiger listing from the sort query parameter, which is
+// name, age or last_seen, prefixed with - to reverse it
+func parseTigerSort(sort string) (repository.TigerSort, web.ErrorInterface) {
+	if sort == "" {
+		return repository.TigerSort{}, nil
+	}
+
+	tigerSort := repository.TigerSort{Field: repository.TigerSortField(strings.TrimPrefix(sort, "-"))}
+	tigerSort.Descending = strings.HasPrefix(sort, "-")
+
+	switch tigerSort.Field {
+	case repository.TigerSortName, repository.TigerSortAge, repository.TigerSortLastSeen:
+		return tigerSort, nil
+	}
+
+	return tigerSort, web.ErrBadRequest("Invalid sort value, expected name, age or last_seen")
+}
+
+const dateOfBirthLayout = "2006-01-02"
+
+// maxTigerSearchRadius bounds the radius of a near search, in meters
+const maxTigerSearchRadius = 500000
+
+func parseTimeParam(query url.Values, name string, layout string) (*time.Time, web.ErrorInterface) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(layout, value)
+	if err != nil {
+		return nil, web.ErrBadRequest(fmt.Sprintf("Invalid %s value", name))
+	}
+
+	return &parsed, nil
+}
+
+// parseFloats parses a comma separated list of exactly count finite numbers
+func parseFloats(value string, count int) ([]float64, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != count {
+		return nil, false
+	}
+
+	values := make([]float64, count)
+	for i, part := range parts {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || !finite(parsed) {
+			return nil, false
+		}
+		values[i] = parsed
+	}
+
+	return values, true
+}
+
+func finite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+func validLat(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func validLon(lon float64) bool {
+	return lon >= -180 && lon <= 180
+}
+
+// tigerResponse responds with the tiger and its version as the ETag, for the If-Match header of an update
+func tigerResponse(r *web.Request, tiger *model.Tiger) (*web.JSONResponse, web.ErrorInterface) {
+	jsonResponse, err := utils.StructToMap(tiger)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	r.SetResponseHeader("ETag", fmt.Sprintf(`"%d"`, tiger.Version))
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
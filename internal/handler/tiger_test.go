@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/julienschmidt/httprouter"
@@ -18,6 +19,7 @@ import (
 	"tigerhall_kittens/internal/handler/middleware"
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/service"
 	mock_service "tigerhall_kittens/internal/service/mocks"
 )
 
@@ -129,7 +131,11 @@ func TestTigerHandler_CreateTiger(t *testing.T) {
 		var tiger model.Tiger
 		err = json.Unmarshal([]byte(createTigerRequestBody), &tiger)
 		assert.Nil(t, err)
-		mockTigerService.EXPECT().CreateTiger(gomock.Any(), &tiger).Return(nil)
+		mockTigerService.EXPECT().CreateTiger(gomock.Any(), &tiger).DoAndReturn(func(_ context.Context, created *model.Tiger) error {
+			created.ID = 7
+			created.Version = 1
+			return nil
+		})
 
 		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
 			tigerHandler.CreateTiger))
@@ -140,9 +146,37 @@ func TestTigerHandler_CreateTiger(t *testing.T) {
 		respBody, _ := ioutil.ReadAll(recorder.Body)
 		var resData map[string]interface{}
 		_ = json.Unmarshal(respBody, &resData)
-		assert.Empty(t, resData["data"])
+		assert.Equal(t, float64(7), resData["data"].(map[string]interface{})["id"])
+		assert.Equal(t, float64(1), resData["data"].(map[string]interface{})["version"])
 		assert.Equal(t, resData["success"], true)
 	})
+
+	t.Run("should ignore the fields the server manages", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTigerService := mock_service.NewMockTigerService(ctrl)
+		tigerHandler := MakeTigerHandler(mockTigerService)
+
+		var tiger model.Tiger
+		err := json.Unmarshal([]byte(createTigerRequestBody), &tiger)
+		assert.Nil(t, err)
+		mockTigerService.EXPECT().CreateTiger(gomock.Any(), &tiger).Return(nil)
+
+		body := `{"id": 9, "name": "Royal Bengal Tiger", "date_of_birth": "2018-05-15T08:00:00Z",
+			"last_seen_timestamp": "2028-03-15T08:00:00Z", "last_seen_lat": 23.4567, "last_seen_lon": 45.6789,
+			"version": 5, "updated_at": "2028-03-15T08:00:00Z", "deleted_at": "2028-03-15T08:00:00Z"}`
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path, bytes.NewBufferString(body))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			tigerHandler.CreateTiger))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
 }
 
 func TestTigerHandler_ListTigers(t *testing.T) {
@@ -293,3 +327,245 @@ func TestTigerHandler_ListTigers(t *testing.T) {
 		assert.Equal(t, true, resData["success"])
 	})
 }
+
+func TestTigerHandler_GetTiger(t *testing.T) {
+	path := "/api/v1/tigers/:tiger_id"
+
+	t.Run("should return the tiger with its id and version", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTigerService := mock_service.NewMockTigerService(ctrl)
+		mockTigerService.EXPECT().GetTiger(gomock.Any(), repository.GetTigerOpts{TigerID: 7}).
+			Return(&model.Tiger{ID: 7, Name: "Machli", Version: 3}, nil)
+
+		tigerHandler := MakeTigerHandler(mockTigerService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/tigers/7", http.NoBody)
+
+		router.Handle(http.MethodGet, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.GetTiger))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+		assert.Contains(t, recorder.Body.String(), `"id":7`)
+		assert.Contains(t, recorder.Body.String(), `"version":3`)
+	})
+
+	t.Run("should return not found when the tiger does not exist", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTigerService := mock_service.NewMockTigerService(ctrl)
+		mockTigerService.EXPECT().GetTiger(gomock.Any(), repository.GetTigerOpts{TigerID: 7}).Return(nil, service.ErrTigerNotFound)
+
+		tigerHandler := MakeTigerHandler(mockTigerService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/tigers/7", http.NoBody)
+
+		router.Handle(http.MethodGet, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.GetTiger))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestTigerHandler_UpdateTiger(t *testing.T) {
+	path := "/api/v1/tigers/:tiger_id"
+	body := `{"name": "Machli"}`
+
+	t.Run("should return bad request without an If-Match header", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		tigerHandler := MakeTigerHandler(mock_service.NewMockTigerService(ctrl))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPatch, "/api/v1/tigers/7", bytes.NewBufferString(body))
+
+		router.Handle(http.MethodPatch, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.UpdateTiger))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "Missing If-Match header")
+	})
+
+	t.Run("should update the tiger at the version of the If-Match header", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		name := "Machli"
+		mockTigerService := mock_service.NewMockTigerService(ctrl)
+		mockTigerService.EXPECT().UpdateTiger(gomock.Any(), uint(7), 3, service.UpdateTigerReq{Name: &name}).
+			Return(&model.Tiger{ID: 7, Name: name, Version: 4}, nil)
+
+		tigerHandler := MakeTigerHandler(mockTigerService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPatch, "/api/v1/tigers/7", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", `"3"`)
+
+		router.Handle(http.MethodPatch, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.UpdateTiger))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `"4"`, recorder.Header().Get("ETag"))
+		assert.Contains(t, recorder.Body.String(), `"version":4`)
+	})
+
+	t.Run("should return precondition failed when the tiger was changed since", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTigerService := mock_service.NewMockTigerService(ctrl)
+		mockTigerService.EXPECT().UpdateTiger(gomock.Any(), uint(7), 3, gomock.Any()).Return(nil, service.ErrTigerVersionMismatch)
+
+		tigerHandler := MakeTigerHandler(mockTigerService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPatch, "/api/v1/tigers/7", bytes.NewBufferString(body))
+		req.Header.Set("If-Match", `W/"3"`)
+
+		router.Handle(http.MethodPatch, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.UpdateTiger))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	})
+}
+
+func TestTigerHandler_DeleteTiger(t *testing.T) {
+	t.Run("should return not found when the tiger does not exist", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTigerService := mock_service.NewMockTigerService(ctrl)
+		mockTigerService.EXPECT().DeleteTiger(gomock.Any(), uint(7)).Return(service.ErrTigerNotFound)
+
+		tigerHandler := MakeTigerHandler(mockTigerService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodDelete, "/api/v1/tigers/7", http.NoBody)
+
+		router.Handle(http.MethodDelete, "/api/v1/tigers/:tiger_id", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.DeleteTiger))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestTigerHandler_ListTigersFilters(t *testing.T) {
+	path := "/api/v1/tigers"
+
+	t.Run("should pass every filter and the sort to the service", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		bornAfter := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+		seenAfter := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+
+		mockTigerService := mock_service.NewMockTigerService(ctrl)
+		mockTigerService.EXPECT().ListTigers(gomock.Any(), repository.ListTigersOpts{
+			Limit:  10,
+			Offset: 10,
+			Filter: repository.TigerFilter{
+				Name:      "mach",
+				BornAfter: &bornAfter,
+				SeenAfter: &seenAfter,
+				Within:    &repository.BoundingBox{MinLon: 76.3, MinLat: 25.9, MaxLon: 76.7, MaxLat: 26.2},
+				Near:      &repository.Radius{Lat: 26.01, Lon: 76.5, Meters: 5000},
+			},
+			Sort: repository.TigerSort{Field: repository.TigerSortAge, Descending: true},
+		}).Return([]model.Tiger{{ID: 7, Name: "Machli"}}, nil)
+
+		tigerHandler := MakeTigerHandler(mockTigerService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, path+
+			"?page=2&per_page=10&q=mach&born_after=2015-01-01&seen_after=2026-10-01T06:00:00Z"+
+			"&bbox=76.3,25.9,76.7,26.2&near=26.01,76.5&radius=5000&sort=-age", http.NoBody)
+
+		router.Handle(http.MethodGet, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.ListTigers))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"id":7`)
+	})
+
+	t.Run("should return bad request for invalid filters", func(t *testing.T) {
+		for _, params := range []string{
+			"sort=weight",
+			"born_after=15-01-2015",
+			"seen_before=yesterday",
+			"bbox=76.7,25.9,76.3,26.2",
+			"bbox=1,2,3",
+			"near=26.01,76.5",
+			"near=95,76.5&radius=100",
+			"radius=100",
+			"bbox=NaN,25.9,76.7,26.2",
+			"near=NaN,76.5&radius=100",
+			"near=26.01,Inf&radius=100",
+			"near=26.01,76.5&radius=NaN",
+			"near=26.01,76.5&radius=+Inf",
+			"near=26.01,76.5&radius=500001",
+			"status=sleeping",
+			"born_after=2020-01-01&born_before=2015-01-01",
+		} {
+			recorder := httptest.NewRecorder()
+			router := httprouter.New()
+
+			ctrl := gomock.NewController(t)
+
+			tigerHandler := MakeTigerHandler(mock_service.NewMockTigerService(ctrl))
+
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, path+"?page=1&per_page=10&"+params, http.NoBody)
+
+			router.Handle(http.MethodGet, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.ListTigers))
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code, params)
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("should only list deleted tigers for tiger managers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTigerService := mock_service.NewMockTigerService(ctrl)
+		mockTigerService.EXPECT().ListTigers(gomock.Any(), repository.ListTigersOpts{
+			Limit:  10,
+			Filter: repository.TigerFilter{Status: model.TigerStatusDeleted},
+		}).Return([]model.Tiger{}, nil)
+
+		tigerHandler := MakeTigerHandler(mockTigerService)
+
+		for role, code := range map[model.Role]int{model.RoleResearcher: http.StatusForbidden, model.RoleAdmin: http.StatusOK} {
+			recorder := httptest.NewRecorder()
+			router := httprouter.New()
+
+			ctx := context.WithValue(context.Background(), "principal", model.Principal{Role: role})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, path+"?page=1&per_page=10&status=deleted", http.NoBody)
+
+			router.Handle(http.MethodGet, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, tigerHandler.ListTigers))
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, code, recorder.Code, role)
+		}
+	})
+}
@@ -2,12 +2,34 @@ package handler
 
 import (
 	"encoding/json"
+	"net/http"
+
 	"tigerhall_kittens/internal/service"
 	"tigerhall_kittens/internal/web"
+	"tigerhall_kittens/utils"
 )
 
 type UserHandler interface {
 	CreateUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	SignUp(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	VerifyEmail(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ResendVerification(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ForgotPassword(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ResetPassword(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	GetProfile(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	UpdateProfile(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ChangePassword(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListUsers(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	SuspendUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ReactivateUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ChangeRole(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	RevokeSessions(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	DeleteUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	RequestDataExport(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	GetDataExport(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	DownloadDataExport(w http.ResponseWriter, r *web.Request) web.ErrorInterface
+	EraseAccount(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	EraseUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
 }
 
 type userHandler struct {
@@ -36,3 +58,147 @@ func (h *userHandler) CreateUser(r *web.Request) (*web.JSONResponse, web.ErrorIn
 
 	return &web.JSONResponse{}, nil
 }
+
+// SignUp registers a pending account and mails it a verification token
+func (h *userHandler) SignUp(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.SignUpReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if err := h.userService.SignUp(r.Context(), &req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// VerifyEmail activates the account the verification token was sent to
+func (h *userHandler) VerifyEmail(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.VerifyEmailReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.Token == "" {
+		return nil, web.ErrBadRequest("Missing verification token")
+	}
+
+	if err := h.userService.VerifyEmail(r.Context(), req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// ResendVerification mails a new verification token, unknown and verified addresses get the same
+// response as a resend that went out
+func (h *userHandler) ResendVerification(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.ResendVerificationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.Email == "" {
+		return nil, web.ErrBadRequest("Missing email")
+	}
+
+	if err := h.userService.ResendVerification(r.Context(), req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// ForgotPassword mails a password reset token, unknown addresses get the same response as a reset
+// that went out
+func (h *userHandler) ForgotPassword(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.ForgotPasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.Email == "" {
+		return nil, web.ErrBadRequest("Missing email")
+	}
+
+	if err := h.userService.ForgotPassword(r.Context(), req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// ResetPassword sets a new password with the token mailed by ForgotPassword
+func (h *userHandler) ResetPassword(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.ResetPasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.Token == "" {
+		return nil, web.ErrBadRequest("Missing password reset token")
+	}
+
+	if err := h.userService.ResetPassword(r.Context(), req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// GetProfile returns the profile of the logged-in user
+func (h *userHandler) GetProfile(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	profile, err := h.userService.GetProfile(r.Context())
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(profile)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+// UpdateProfile changes the given fields of the profile of the logged-in user, a new email is
+// mailed a confirmation token and shows up as pending until it is confirmed
+func (h *userHandler) UpdateProfile(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.UpdateProfileReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+	req.IP = r.ClientIP()
+
+	profile, err := h.userService.UpdateProfile(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	jsonResponse, err := utils.StructToMap(profile)
+	if err != nil {
+		return nil, web.ErrInternalServerError(err.Error())
+	}
+
+	return (*web.JSONResponse)(&jsonResponse), nil
+}
+
+// ChangePassword sets a new password after checking the current one, the user has to log in again
+func (h *userHandler) ChangePassword(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.ChangePasswordReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return nil, web.ErrBadRequest("Missing current_password or new_password")
+	}
+	req.IP = r.ClientIP()
+
+	if err := h.userService.ChangePassword(r.Context(), req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
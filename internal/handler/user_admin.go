@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+)
+
+const (
+	defaultUserLimit = 20
+	maxUserLimit     = 100
+)
+
+// ListUsers lists users newest first, filtered by the q, role and status query parameters. The
+// next page is fetched by passing the returned next_cursor as cursor.
+func (h *userHandler) ListUsers(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	query := r.URL.Query()
+
+	limit := defaultUserLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxUserLimit {
+			return nil, web.ErrBadRequest("Invalid limit value")
+		}
+	}
+
+	page, err := h.userService.ListUsers(r.Context(), service.ListUsersReq{
+		Query:  query.Get("q"),
+		Role:   model.Role(query.Get("role")),
+		Status: model.UserStatus(query.Get("status")),
+		Cursor: query.Get("cursor"),
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"users":       page.Users,
+		"next_cursor": page.NextCursor,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// SuspendUser stops a user from logging in until they are reactivated, the reason is optional
+func (h *userHandler) SuspendUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	userID, err := uuid.Parse(r.GetPathParam("user_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid user id")
+	}
+
+	var req service.SuspendUserReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if err := h.userService.SuspendUser(r.Context(), userID, req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *userHandler) ReactivateUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	userID, err := uuid.Parse(r.GetPathParam("user_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid user id")
+	}
+
+	if err := h.userService.ReactivateUser(r.Context(), userID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *userHandler) ChangeRole(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	userID, err := uuid.Parse(r.GetPathParam("user_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid user id")
+	}
+
+	var req service.ChangeRoleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	if err := h.userService.ChangeRole(r.Context(), userID, req); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+// RevokeSessions signs a user out everywhere
+func (h *userHandler) RevokeSessions(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	userID, err := uuid.Parse(r.GetPathParam("user_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid user id")
+	}
+
+	if err := h.userService.RevokeSessions(r.Context(), userID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *userHandler) DeleteUser(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	userID, err := uuid.Parse(r.GetPathParam("user_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid user id")
+	}
+
+	if err := h.userService.DeleteUser(r.Context(), userID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
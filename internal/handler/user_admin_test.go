@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestUserHandler_ListUsers(t *testing.T) {
+	path := "/api/v1/admin/users"
+
+	t.Run("should pass the filters and return the next cursor", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().ListUsers(gomock.Any(), service.ListUsersReq{
+			Query:  "ravi",
+			Status: model.UserStatusSuspended,
+			Cursor: "abc",
+			Limit:  5,
+		}).Return(&service.UserPage{
+			Users: []service.ManagedUser{{
+				Profile: &service.Profile{Username: "ranger_ravi"},
+				Status:  model.UserStatusSuspended,
+			}},
+			NextCursor: "def",
+		}, nil)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet,
+			path+"?q=ravi&status=suspended&cursor=abc&limit=5", nil)
+
+		router.Handle(http.MethodGet, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.ListUsers))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"status":"suspended"`)
+		assert.Contains(t, recorder.Body.String(), `"next_cursor":"def"`)
+	})
+
+	t.Run("should return bad request for an invalid limit", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userHandler := MakeUserHandler(mock_service.NewMockUserService(ctrl))
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, path+"?limit=1000", nil)
+
+		router.Handle(http.MethodGet, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.ListUsers))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestUserHandler_SuspendUser(t *testing.T) {
+	path := "/api/v1/admin/users/:user_id/suspend"
+
+	t.Run("should accept a request without a body", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userID := uuid.New()
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().SuspendUser(gomock.Any(), userID, service.SuspendUserReq{}).Return(nil)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost,
+			"/api/v1/admin/users/"+userID.String()+"/suspend", http.NoBody)
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.SuspendUser))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should return bad request when suspending themselves", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().SuspendUser(gomock.Any(), gomock.Any(), gomock.Any()).Return(service.ErrCannotManageOwnAccount)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost,
+			"/api/v1/admin/users/"+uuid.NewString()+"/suspend", http.NoBody)
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.SuspendUser))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -139,3 +139,165 @@ func TestUserHandler_CreateUser(t *testing.T) {
 		assert.Equal(t, resData["success"], true)
 	})
 }
+
+func TestUserHandler_SignUp(t *testing.T) {
+	path := "/api/v1/auth/signup"
+
+	t.Run("should return bad request for invalid user details", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(service.ErrInvalidUserDetails)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"username":"ravi","email":"ravi@example.com","password":"short"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			userHandler.SignUp))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestUserHandler_ResendVerification(t *testing.T) {
+	path := "/api/v1/auth/resend-verification"
+
+	t.Run("should return success response", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().ResendVerification(gomock.Any(), service.ResendVerificationReq{Email: "ravi@example.com"}).Return(nil)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"email":"ravi@example.com"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			userHandler.ResendVerification))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestUserHandler_ForgotPassword(t *testing.T) {
+	path := "/api/v1/auth/forgot-password"
+
+	t.Run("should return success response", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().ForgotPassword(gomock.Any(), service.ForgotPasswordReq{Email: "ravi@example.com"}).Return(nil)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"email":"ravi@example.com"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			userHandler.ForgotPassword))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestUserHandler_ResetPassword(t *testing.T) {
+	path := "/api/v1/auth/reset-password"
+
+	t.Run("should return bad request for an invalid reset token", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().ResetPassword(gomock.Any(), service.ResetPasswordReq{Token: "token", Password: "new password"}).
+			Return(service.ErrInvalidResetToken)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"token":"token","password":"new password"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			userHandler.ResetPassword))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestUserHandler_UpdateProfile(t *testing.T) {
+	path := "/api/v1/me"
+
+	t.Run("should return the profile with the pending email", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		pendingEmail := "ravi@wwf.example.org"
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().UpdateProfile(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req service.UpdateProfileReq) (*service.Profile, error) {
+				assert.Equal(t, pendingEmail, *req.Email)
+				assert.Nil(t, req.DisplayName)
+				return &service.Profile{Username: "ranger_ravi", Email: "ravi@example.com", PendingEmail: &pendingEmail}, nil
+			})
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPatch, path,
+			bytes.NewBufferString(`{"email":"ravi@wwf.example.org"}`))
+
+		router.Handle(http.MethodPatch, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.UpdateProfile))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"pending_email":"ravi@wwf.example.org"`)
+	})
+}
+
+func TestUserHandler_ChangePassword(t *testing.T) {
+	path := "/api/v1/me/password"
+
+	t.Run("should return bad request for an incorrect current password", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserService := mock_service.NewMockUserService(ctrl)
+		mockUserService.EXPECT().ChangePassword(gomock.Any(), gomock.Any()).Return(service.ErrIncorrectPassword)
+
+		userHandler := MakeUserHandler(mockUserService)
+
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, path,
+			bytes.NewBufferString(`{"current_password":"incorrect","new_password":"new password"}`))
+
+		router.Handle(http.MethodPost, path, middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.ChangePassword))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/service"
+	"tigerhall_kittens/internal/web"
+)
+
+type WebhookHandler interface {
+	CreateWebhook(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	UpdateWebhook(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	DeleteWebhook(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	GetWebhook(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListWebhooks(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	ListDeliveries(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	GetDelivery(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+	Redeliver(r *web.Request) (*web.JSONResponse, web.ErrorInterface)
+}
+
+type webhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler() WebhookHandler {
+	return &webhookHandler{webhookService: service.NewWebhookService()}
+}
+
+func MakeWebhookHandler(webhookService service.WebhookService) WebhookHandler {
+	return &webhookHandler{webhookService: webhookService}
+}
+
+// CreateWebhook subscribes a partner URL to events. The signing secret is only returned here.
+func (h *webhookHandler) CreateWebhook(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	var req service.WebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(r.Context(), req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"webhook": webhook,
+		"secret":  webhook.Secret,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// UpdateWebhook replaces the URL and events of a webhook, and its secret or state when given
+func (h *webhookHandler) UpdateWebhook(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	webhookID, err := uuid.Parse(r.GetPathParam("webhook_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid webhook id")
+	}
+
+	var req service.WebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, web.ErrBadRequest("Failed to decode request body")
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(r.Context(), webhookID, req)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"webhook": webhook,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+func (h *webhookHandler) DeleteWebhook(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	webhookID, err := uuid.Parse(r.GetPathParam("webhook_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid webhook id")
+	}
+
+	if err := h.webhookService.DeleteWebhook(r.Context(), webhookID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
+
+func (h *webhookHandler) GetWebhook(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	webhookID, err := uuid.Parse(r.GetPathParam("webhook_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid webhook id")
+	}
+
+	webhook, err := h.webhookService.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"webhook": webhook,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+func (h *webhookHandler) ListWebhooks(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	pageStr := r.URL.Query().Get("page")
+	perPageStr := r.URL.Query().Get("per_page")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page <= 0 {
+		return nil, web.ErrBadRequest("Invalid page number")
+	}
+
+	perPage, err := strconv.Atoi(perPageStr)
+	if err != nil || perPage <= 0 {
+		return nil, web.ErrBadRequest("Invalid per_page value")
+	}
+
+	offset := (page - 1) * perPage
+
+	webhooks, err := h.webhookService.ListWebhooks(r.Context(), repository.ListWebhooksOpts{
+		Limit:  perPage,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, web.ErrInternalServerError(fmt.Sprintf("Error while fetching webhooks : %s", err.Error()))
+	}
+
+	res := map[string]interface{}{
+		"webhooks": webhooks,
+		"page":     page,
+		"per_page": perPage,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// ListDeliveries lists the delivery log of a webhook, optionally by status
+func (h *webhookHandler) ListDeliveries(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	webhookID, err := uuid.Parse(r.GetPathParam("webhook_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid webhook id")
+	}
+
+	status := model.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", model.WebhookDeliveryStatusPending, model.WebhookDeliveryStatusDelivered, model.WebhookDeliveryStatusDead:
+	default:
+		return nil, web.ErrBadRequest("Invalid status value")
+	}
+
+	pageStr := r.URL.Query().Get("page")
+	perPageStr := r.URL.Query().Get("per_page")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page <= 0 {
+		return nil, web.ErrBadRequest("Invalid page number")
+	}
+
+	perPage, err := strconv.Atoi(perPageStr)
+	if err != nil || perPage <= 0 {
+		return nil, web.ErrBadRequest("Invalid per_page value")
+	}
+
+	offset := (page - 1) * perPage
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), repository.ListWebhookDeliveriesOpts{
+		SubscriptionID: webhookID,
+		Status:         status,
+		Limit:          perPage,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"deliveries": deliveries,
+		"page":       page,
+		"per_page":   perPage,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+// GetDelivery returns a delivery with the response code and error of every attempt
+func (h *webhookHandler) GetDelivery(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	deliveryID, err := uuid.Parse(r.GetPathParam("delivery_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid delivery id")
+	}
+
+	delivery, err := h.webhookService.GetDelivery(r.Context(), deliveryID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	res := map[string]interface{}{
+		"delivery": delivery,
+	}
+
+	return (*web.JSONResponse)(&res), nil
+}
+
+func (h *webhookHandler) Redeliver(r *web.Request) (*web.JSONResponse, web.ErrorInterface) {
+	deliveryID, err := uuid.Parse(r.GetPathParam("delivery_id"))
+	if err != nil {
+		return nil, web.ErrBadRequest("Invalid delivery id")
+	}
+
+	if err := h.webhookService.Redeliver(r.Context(), deliveryID); err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return &web.JSONResponse{}, nil
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/service"
+	mock_service "tigerhall_kittens/internal/service/mocks"
+)
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	routePath := "/api/v1/admin/webhooks"
+
+	t.Run("should return bad request for an invalid webhook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockWebhookService := mock_service.NewMockWebhookService(ctrl)
+		mockWebhookService.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(nil, service.ErrInvalidWebhook)
+
+		webhookHandler := MakeWebhookHandler(mockWebhookService)
+
+		req, _ := http.NewRequest(http.MethodPost, routePath, bytes.NewBufferString(`{"url":"/hooks","events":["tiger.created"]}`))
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			webhookHandler.CreateWebhook))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("should return the secret of a created webhook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		mockWebhookService := mock_service.NewMockWebhookService(ctrl)
+		mockWebhookService.EXPECT().CreateWebhook(gomock.Any(), service.WebhookReq{
+			URL:    "https://ngo.example.org/hooks",
+			Events: []string{model.WebhookEventTigerCreated},
+		}).Return(&model.WebhookSubscription{
+			ID:     uuid.New(),
+			URL:    "https://ngo.example.org/hooks",
+			Secret: "0123456789abcdef",
+			Events: []string{model.WebhookEventTigerCreated},
+			Active: true,
+		}, nil)
+
+		webhookHandler := MakeWebhookHandler(mockWebhookService)
+
+		req, _ := http.NewRequest(http.MethodPost, routePath, bytes.NewBufferString(`{"url":"https://ngo.example.org/hooks","events":["tiger.created"]}`))
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			webhookHandler.CreateWebhook))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		data := resData["data"].(map[string]interface{})
+		assert.Equal(t, "0123456789abcdef", data["secret"])
+		assert.Nil(t, data["webhook"].(map[string]interface{})["secret"])
+	})
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	routePath := "/api/v1/admin/webhook-deliveries/:delivery_id/redeliver"
+
+	t.Run("should return bad request for invalid delivery id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		webhookHandler := MakeWebhookHandler(mock_service.NewMockWebhookService(ctrl))
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/webhook-deliveries/abc/redeliver", nil)
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			webhookHandler.Redeliver))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		respBody, _ := ioutil.ReadAll(recorder.Body)
+		var resData map[string]interface{}
+		_ = json.Unmarshal(respBody, &resData)
+		assert.Equal(t, "Invalid delivery id", resData["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("should return not found for unknown delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		recorder := httptest.NewRecorder()
+		router := httprouter.New()
+
+		deliveryID := uuid.New()
+
+		mockWebhookService := mock_service.NewMockWebhookService(ctrl)
+		mockWebhookService.EXPECT().Redeliver(gomock.Any(), deliveryID).Return(service.ErrWebhookDeliveryNotFound)
+
+		webhookHandler := MakeWebhookHandler(mockWebhookService)
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/webhook-deliveries/"+deliveryID.String()+"/redeliver", nil)
+
+		router.Handle(http.MethodPost, routePath, middleware.ServeV1Endpoint(middleware.EmptyMiddleware,
+			webhookHandler.Redeliver))
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
@@ -0,0 +1,138 @@
+package keyset
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var ErrNoActiveKey = errors.New("no active signing key")
+
+// Key is one key of the keyset, identified by the kid header of the tokens it signed
+type Key struct {
+	ID        string
+	Algorithm string
+	// Signer is the private key, only the active key needs one
+	Signer crypto.Signer
+	Public crypto.PublicKey
+	// RetiredAt is when the key stopped signing, it keeps verifying for the grace period after
+	RetiredAt *time.Time
+}
+
+// Keyset holds the key tokens are signed with and the retired keys still verifying the tokens
+// they signed. Rotating is done by making a new key active and retiring the old one, tokens of
+// the old key then keep working until they expire as long as the grace period covers their TTL.
+type Keyset struct {
+	active *Key
+	keys   []*Key
+	grace  time.Duration
+}
+
+func New(active *Key, retired []*Key, grace time.Duration) *Keyset {
+	keys := []*Key{}
+	if active != nil {
+		keys = append(keys, active)
+	}
+
+	return &Keyset{active: active, keys: append(keys, retired...), grace: grace}
+}
+
+// Active returns the key new tokens are signed with
+func (k *Keyset) Active() (*Key, error) {
+	if k.active == nil {
+		return nil, ErrNoActiveKey
+	}
+
+	return k.active, nil
+}
+
+// Verifier returns the key with id if it still verifies tokens at now
+func (k *Keyset) Verifier(id string, now time.Time) (*Key, bool) {
+	for _, key := range k.keys {
+		if key.ID == id && k.verifies(key, now) {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+// JWKS returns the public keys still verifying tokens at now, the active key first
+func (k *Keyset) JWKS(now time.Time) JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range k.keys {
+		if k.verifies(key, now) {
+			jwks.Keys = append(jwks.Keys, key.JWK())
+		}
+	}
+
+	return jwks
+}
+
+func (k *Keyset) verifies(key *Key, now time.Time) bool {
+	return key.RetiredAt == nil || now.Before(key.RetiredAt.Add(k.grace))
+}
+
+// JWKS is a JSON Web Key Set as defined by RFC 7517
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is the public part of a key, RSA keys use N and E and Ed25519 keys Crv and X
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+func (k *Key) JWK() JWK {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
+
+var (
+	mu      sync.RWMutex
+	current = New(nil, nil, 0)
+)
+
+// Get returns the keyset tokens are signed and verified with, an empty one unless Set replaced it
+func Get() *Keyset {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return current
+}
+
+func Set(k *Keyset) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current = k
+}
@@ -0,0 +1,128 @@
+package keyset
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+	assert.Nil(t, err)
+}
+
+func writeManifest(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "keyset.json")
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	writePEM(t, dir, "2026-10.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	der, err := x509.MarshalPKIXPublicKey(edPublic)
+	assert.Nil(t, err)
+	writePEM(t, dir, "2026-04.pub.pem", "PUBLIC KEY", der)
+
+	t.Run("should load the active key and the retired ones", func(t *testing.T) {
+		path := writeManifest(t, dir, `{"keys": [
+			{"kid": "2026-10", "file": "2026-10.pem"},
+			{"kid": "2026-04", "file": "2026-04.pub.pem", "retired_at": "2026-10-01T00:00:00Z"}
+		]}`)
+
+		keys, err := Load(path, 24*time.Hour)
+		assert.Nil(t, err)
+
+		active, err := keys.Active()
+		assert.Nil(t, err)
+		assert.Equal(t, "2026-10", active.ID)
+		assert.Equal(t, AlgorithmRS256, active.Algorithm)
+
+		retiredAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+		retired, ok := keys.Verifier("2026-04", retiredAt.Add(23*time.Hour))
+		assert.True(t, ok)
+		assert.Equal(t, AlgorithmEdDSA, retired.Algorithm)
+		assert.Nil(t, retired.Signer)
+
+		_, ok = keys.Verifier("2026-04", retiredAt.Add(25*time.Hour))
+		assert.False(t, ok)
+
+		_, ok = keys.Verifier("unknown", retiredAt)
+		assert.False(t, ok)
+	})
+
+	t.Run("should publish only the keys still verifying", func(t *testing.T) {
+		path := writeManifest(t, dir, `{"keys": [
+			{"kid": "2026-10", "file": "2026-10.pem"},
+			{"kid": "2026-04", "file": "2026-04.pub.pem", "retired_at": "2026-10-01T00:00:00Z"}
+		]}`)
+
+		keys, err := Load(path, 24*time.Hour)
+		assert.Nil(t, err)
+
+		jwks := keys.JWKS(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+		assert.Len(t, jwks.Keys, 2)
+		assert.Equal(t, JWK{Kty: "RSA", Kid: "2026-10", Use: "sig", Alg: AlgorithmRS256, N: jwks.Keys[0].N, E: "AQAB"}, jwks.Keys[0])
+		assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+		assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+
+		jwks = keys.JWKS(time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC))
+		assert.Len(t, jwks.Keys, 1)
+		assert.Equal(t, "2026-10", jwks.Keys[0].Kid)
+	})
+
+	t.Run("should return an error unless exactly one key with a private key is active", func(t *testing.T) {
+		for _, manifest := range []string{
+			`{"keys": [{"kid": "a", "file": "2026-10.pem"}, {"kid": "b", "file": "2026-10.pem"}]}`,
+			`{"keys": [{"kid": "a", "file": "2026-10.pem", "retired_at": "2026-10-01T00:00:00Z"}]}`,
+			`{"keys": [{"kid": "a", "file": "2026-04.pub.pem"}]}`,
+			`{"keys": [{"kid": "a", "file": "2026-10.pem"}, {"kid": "a", "file": "2026-04.pub.pem", "retired_at": "2026-10-01T00:00:00Z"}]}`,
+			`{"keys": [{"kid": "a", "file": "missing.pem"}]}`,
+		} {
+			_, err := Load(writeManifest(t, dir, manifest), time.Hour)
+			assert.NotNil(t, err, manifest)
+		}
+	})
+
+	t.Run("should return an error for short RSA keys", func(t *testing.T) {
+		shortKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		assert.Nil(t, err)
+		writePEM(t, dir, "short.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(shortKey))
+
+		_, err = Load(writeManifest(t, dir, `{"keys": [{"kid": "short", "file": "short.pem"}]}`), time.Hour)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("should have no active key until one is set", func(t *testing.T) {
+		_, err := Get().Active()
+		assert.Equal(t, ErrNoActiveKey, err)
+
+		keys, err := Generate("dev")
+		assert.Nil(t, err)
+		Set(keys)
+		defer Set(New(nil, nil, 0))
+
+		active, err := Get().Active()
+		assert.Nil(t, err)
+		assert.Equal(t, "dev", active.ID)
+		assert.Equal(t, AlgorithmEdDSA, active.Algorithm)
+	})
+}
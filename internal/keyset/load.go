@@ -0,0 +1,159 @@
+package keyset
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const minRSABits = 2048
+
+// manifest lists the key files of a keyset. Exactly one key has no retired_at and is the active
+// one, a retired key only needs its public key. Relative files are read from the manifest's directory.
+//
+//	{"keys": [
+//	  {"kid": "2026-10", "file": "2026-10.pem"},
+//	  {"kid": "2026-04", "file": "2026-04.pub.pem", "retired_at": "2026-10-01T00:00:00Z"}
+//	]}
+type manifest struct {
+	Keys []struct {
+		ID        string     `json:"kid"`
+		File      string     `json:"file"`
+		RetiredAt *time.Time `json:"retired_at"`
+	} `json:"keys"`
+}
+
+// Load reads the keyset described by the manifest at path. The keys are PEM encoded RSA or Ed25519
+// keys, PKCS #8 or PKCS #1 for private keys and PKIX for public ones.
+func Load(path string, grace time.Duration) (*Keyset, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("parsing keyset %s: %w", path, err)
+	}
+
+	var active *Key
+	var retired []*Key
+	seen := map[string]bool{}
+
+	for _, entry := range m.Keys {
+		if entry.ID == "" || seen[entry.ID] {
+			return nil, fmt.Errorf("keyset %s: every key needs a unique kid, got %q", path, entry.ID)
+		}
+		seen[entry.ID] = true
+
+		file := entry.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+
+		key, err := loadKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("keyset %s: key %s: %w", path, entry.ID, err)
+		}
+		key.ID = entry.ID
+		key.RetiredAt = entry.RetiredAt
+
+		if key.RetiredAt != nil {
+			retired = append(retired, key)
+			continue
+		}
+
+		if active != nil {
+			return nil, fmt.Errorf("keyset %s: keys %s and %s are both active, retire one of them", path, active.ID, key.ID)
+		}
+
+		if key.Signer == nil {
+			return nil, fmt.Errorf("keyset %s: active key %s needs a private key", path, key.ID)
+		}
+		active = key
+	}
+
+	if active == nil {
+		return nil, fmt.Errorf("keyset %s: %w", path, ErrNoActiveKey)
+	}
+
+	return New(active, retired, grace), nil
+}
+
+func loadKey(file string) (*Key, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", file)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newKey(parsed)
+}
+
+func newKey(parsed interface{}) (*Key, error) {
+	key := &Key{}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.Signer = signer
+		parsed = signer.Public()
+	}
+	key.Public = parsed
+
+	switch public := parsed.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys need at least %d bits", minRSABits)
+		}
+		key.Algorithm = AlgorithmRS256
+	case ed25519.PublicKey:
+		key.Algorithm = AlgorithmEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+
+	return key, nil
+}
+
+// Generate returns a keyset with a fresh Ed25519 key, for development and tests. Its tokens
+// stop verifying when the process exits.
+func Generate(id string) (*Keyset, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newKey(private)
+	if err != nil {
+		return nil, err
+	}
+	key.ID = id
+
+	return New(key, nil, 0), nil
+}
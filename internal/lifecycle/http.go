@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+type httpServer struct {
+	server   *http.Server
+	listener net.Listener
+	fail     func(error)
+}
+
+// NewHTTPServer manages server. It listens while starting, so a port that is taken fails the
+// start, and later serve errors are reported to fail. Stopping closes the listener and waits
+// for in-flight requests, connections still open at the deadline are closed.
+func NewHTTPServer(server *http.Server, fail func(error)) Component {
+	return &httpServer{server: server, fail: fail}
+}
+
+func (h *httpServer) Name() string {
+	return "http server"
+}
+
+func (h *httpServer) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", h.server.Addr)
+	if err != nil {
+		return err
+	}
+	h.listener = listener
+
+	go func() {
+		if err := h.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			h.fail(err)
+		}
+	}()
+
+	return nil
+}
+
+func (h *httpServer) Stop(ctx context.Context) error {
+	if err := h.server.Shutdown(ctx); err != nil {
+		_ = h.server.Close()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"tigerhall_kittens/internal/logger"
+)
+
+// Component is a part of the application with a managed lifetime. Start must not block,
+// anything long running belongs in a goroutine that Stop waits for. The context given to
+// Start is never cancelled, a component keeps running until it is stopped.
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Hook turns a pair of functions into a Component, either of them may be nil
+type Hook struct {
+	HookName string
+	OnStart  func(ctx context.Context) error
+	OnStop   func(ctx context.Context) error
+}
+
+func (h Hook) Name() string {
+	return h.HookName
+}
+
+func (h Hook) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+
+	return h.OnStart(ctx)
+}
+
+func (h Hook) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+
+	return h.OnStop(ctx)
+}
+
+type App struct {
+	components      []Component
+	shutdownTimeout time.Duration
+
+	failOnce sync.Once
+	failed   chan error
+}
+
+func New(shutdownTimeout time.Duration) *App {
+	return &App{
+		shutdownTimeout: shutdownTimeout,
+		failed:          make(chan error, 1),
+	}
+}
+
+// Append adds components in the order they are started, they are stopped in reverse
+func (a *App) Append(components ...Component) {
+	a.components = append(a.components, components...)
+}
+
+// Fail shuts the application down because a component can no longer do its job, only
+// the first failure is kept
+func (a *App) Fail(err error) {
+	a.failOnce.Do(func() {
+		a.failed <- err
+	})
+}
+
+// Run starts every component and blocks until ctx is cancelled or a component fails. The
+// components are then stopped in reverse order, sharing one shutdown deadline, so a component
+// is only stopped once everything that depends on it has been. If a component fails to start,
+// the ones started before it are stopped and its error is returned.
+func (a *App) Run(ctx context.Context) error {
+	for i, component := range a.components {
+		logger.I(ctx, "Starting component", logger.Field("component", component.Name()))
+
+		if err := component.Start(Detach(ctx)); err != nil {
+			logger.E(ctx, err, "Failed starting component", logger.Field("component", component.Name()))
+			stopErr := a.stop(ctx, a.components[:i])
+
+			return errors.Join(fmt.Errorf("starting %s: %w", component.Name(), err), stopErr)
+		}
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logger.I(ctx, "Shutting down")
+	case runErr = <-a.failed:
+		logger.E(ctx, runErr, "Shutting down after a component failed")
+	}
+
+	return errors.Join(runErr, a.stop(ctx, a.components))
+}
+
+func (a *App) stop(ctx context.Context, components []Component) error {
+	// ctx is usually cancelled by now, the shutdown gets a deadline of its own
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		component := components[i]
+		logger.I(ctx, "Stopping component", logger.Field("component", component.Name()))
+
+		if err := component.Stop(stopCtx); err != nil {
+			logger.E(ctx, err, "Failed stopping component", logger.Field("component", component.Name()))
+			errs = append(errs, fmt.Errorf("stopping %s: %w", component.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
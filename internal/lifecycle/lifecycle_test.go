@@ -0,0 +1,124 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingHook(name string, events *[]string, startErr error) Hook {
+	return Hook{
+		HookName: name,
+		OnStart: func(ctx context.Context) error {
+			*events = append(*events, "start "+name)
+			return startErr
+		},
+		OnStop: func(ctx context.Context) error {
+			*events = append(*events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestApp_Run(t *testing.T) {
+	t.Run("should stop components in reverse order once ctx is cancelled", func(t *testing.T) {
+		var events []string
+
+		app := New(time.Second)
+		app.Append(recordingHook("database", &events, nil), recordingHook("http", &events, nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := app.Run(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"start database", "start http", "stop http", "stop database"}, events)
+	})
+
+	t.Run("should stop the started components when one fails to start", func(t *testing.T) {
+		var events []string
+		startErr := errors.New("address already in use")
+
+		app := New(time.Second)
+		app.Append(
+			recordingHook("database", &events, nil),
+			recordingHook("http", &events, startErr),
+			recordingHook("worker", &events, nil),
+		)
+
+		err := app.Run(context.Background())
+		assert.True(t, errors.Is(err, startErr))
+		assert.Equal(t, []string{"start database", "start http", "stop database"}, events)
+	})
+
+	t.Run("should shut down when a component fails", func(t *testing.T) {
+		var events []string
+		failure := errors.New("listener closed")
+
+		app := New(time.Second)
+		app.Append(recordingHook("database", &events, nil))
+		app.Fail(failure)
+		app.Fail(errors.New("ignored"))
+
+		err := app.Run(context.Background())
+		assert.True(t, errors.Is(err, failure))
+		assert.Equal(t, []string{"start database", "stop database"}, events)
+	})
+
+	t.Run("should give the components a shutdown deadline", func(t *testing.T) {
+		app := New(10 * time.Millisecond)
+		app.Append(Hook{
+			HookName: "worker",
+			OnStart: func(ctx context.Context) error {
+				assert.Nil(t, ctx.Done())
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := app.Run(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func TestHTTPServer(t *testing.T) {
+	t.Run("should let in-flight requests finish while stopping", func(t *testing.T) {
+		started := make(chan struct{})
+		server := &http.Server{Addr: "127.0.0.1:0", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+		})}
+
+		component := NewHTTPServer(server, func(err error) { t.Error(err) }).(*httpServer)
+		assert.Nil(t, component.Start(context.Background()))
+
+		statuses := make(chan int, 1)
+		go func() {
+			res, err := http.Get("http://" + component.listener.Addr().String())
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			_ = res.Body.Close()
+			statuses <- res.StatusCode
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		assert.Nil(t, component.Stop(ctx))
+		assert.Equal(t, http.StatusNoContent, <-statuses)
+	})
+}
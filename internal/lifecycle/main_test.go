@@ -0,0 +1,13 @@
+package lifecycle
+
+import (
+	"os"
+	"testing"
+
+	"tigerhall_kittens/test_helpers"
+)
+
+func TestMain(m *testing.M) {
+	test_helpers.InitializeLogger()
+	os.Exit(m.Run())
+}
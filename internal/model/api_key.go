@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type APIKeyKind string
+
+const (
+	// APIKeyKindPersonal is created by a user for their own scripts, it is managed by them and
+	// stops working when their sessions are revoked
+	APIKeyKindPersonal APIKeyKind = "personal"
+	// APIKeyKindService is created by an admin for a machine account such as a camera trap, only
+	// admins manage it and it outlives password resets of its account
+	APIKeyKindService APIKeyKind = "service"
+)
+
+// APIKey lets a machine client act as the user owning it, limited to its scopes. Only the hash
+// of the key is stored, the key itself is shown once when it is created.
+type APIKey struct {
+	ID              uuid.UUID      `gorm:"primarykey" json:"id"`
+	UserID          uuid.UUID      `json:"user_id"`
+	Kind            APIKeyKind     `json:"kind"`
+	Name            string         `json:"name"`
+	Prefix          string         `json:"prefix"`
+	KeyHash         string         `json:"-"`
+	Scopes          pq.StringArray `gorm:"type:text[]" json:"scopes"`
+	ExpiresAt       *time.Time     `json:"expires_at"`
+	LastUsedAt      *time.Time     `json:"last_used_at"`
+	RevokedAt       *time.Time     `json:"revoked_at"`
+	CreatedByUserID *uuid.UUID     `json:"created_by_user_id"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
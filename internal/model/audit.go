@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditEventType string
+
+const (
+	AuditEventLoginSucceeded AuditEventType = "login.succeeded"
+	AuditEventLoginFailed    AuditEventType = "login.failed"
+	// AuditEventLoginThrottled is a login that was refused without checking the password
+	AuditEventLoginThrottled AuditEventType = "login.throttled"
+	// AuditEventLoginMFARequired is a login with a valid password that still needs a second factor
+	AuditEventLoginMFARequired AuditEventType = "login.mfa_required"
+	AuditEventUserUnlocked     AuditEventType = "user.unlocked"
+	AuditEventMFAEnabled       AuditEventType = "mfa.enabled"
+	// AuditEventMFAPolicyChanged is about a role rather than a user, its reason holds the new policy
+	AuditEventMFAPolicyChanged AuditEventType = "mfa.policy_changed"
+	// AuditEventUserProvisioned and AuditEventIdentityLinked hold the identity provider as their reason
+	AuditEventUserProvisioned AuditEventType = "user.provisioned"
+	AuditEventIdentityLinked  AuditEventType = "identity.linked"
+	// AuditEventAPIKeyCreated and AuditEventAPIKeyRevoked hold the key id as their reason
+	AuditEventAPIKeyCreated AuditEventType = "api_key.created"
+	AuditEventAPIKeyRevoked AuditEventType = "api_key.revoked"
+	// AuditEventProfileUpdated holds the changed fields as its reason
+	AuditEventProfileUpdated AuditEventType = "user.profile_updated"
+	// AuditEventEmailChangeRequested and AuditEventEmailChanged hold the new address as their reason
+	AuditEventEmailChangeRequested AuditEventType = "user.email_change_requested"
+	AuditEventEmailChanged         AuditEventType = "user.email_changed"
+	AuditEventPasswordChanged      AuditEventType = "user.password_changed"
+	// AuditEventUserSuspended holds the reason given by the admin, AuditEventRoleChanged the new role
+	AuditEventUserSuspended   AuditEventType = "user.suspended"
+	AuditEventUserReactivated AuditEventType = "user.reactivated"
+	AuditEventRoleChanged     AuditEventType = "user.role_changed"
+	AuditEventSessionsRevoked AuditEventType = "user.sessions_revoked"
+	AuditEventUserDeleted     AuditEventType = "user.deleted"
+	// AuditEventDataExportRequested holds the export id as its reason
+	AuditEventDataExportRequested AuditEventType = "user.data_export_requested"
+	AuditEventUserErased          AuditEventType = "user.erased"
+)
+
+// AuditEvent records a security relevant action on an account
+type AuditEvent struct {
+	ID   uuid.UUID      `gorm:"primarykey" json:"id"`
+	Type AuditEventType `json:"type"`
+	// UserID is the account the event is about, nil for a login with an unknown username
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+	// ActorID is who acted on the account when it is not the user, e.g. the admin unlocking it
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	Username  string     `json:"username,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
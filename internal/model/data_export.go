@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending DataExportStatus = "pending"
+	DataExportStatusReady   DataExportStatus = "ready"
+	DataExportStatusFailed  DataExportStatus = "failed"
+)
+
+// DataExport is a copy of the personal data of a user, built in the background as a ZIP archive
+// and kept until ExpiresAt
+type DataExport struct {
+	ID            uuid.UUID        `gorm:"primarykey" json:"id"`
+	UserID        uuid.UUID        `json:"-"`
+	Status        DataExportStatus `json:"status"`
+	Attempts      int              `json:"-"`
+	NextAttemptAt time.Time        `json:"-"`
+	// Archive is only loaded to download the export
+	Archive     []byte     `json:"-"`
+	LastError   string     `json:"-"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"-"`
+}
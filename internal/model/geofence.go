@@ -0,0 +1,31 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GeofenceKind string
+
+const (
+	GeofenceKindCircle  GeofenceKind = "circle"
+	GeofenceKindPolygon GeofenceKind = "polygon"
+)
+
+// Geofence is an area of interest of a user, who is notified of every sighting
+// inside it. A circle is described by Lat, Lon and RadiusInMeters, a polygon by
+// a GeoJSON Polygon geometry.
+type Geofence struct {
+	ID             uuid.UUID       `gorm:"primarykey" json:"id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	Name           string          `json:"name"`
+	Kind           GeofenceKind    `json:"kind"`
+	Lat            *float64        `json:"lat,omitempty"`
+	Lon            *float64        `json:"lon,omitempty"`
+	RadiusInMeters *float64        `json:"radius_in_meters,omitempty"`
+	Polygon        json.RawMessage `gorm:"type:jsonb" json:"polygon,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboxNotification is a notification as the recipient reads it in the app. NotificationID
+// is the outbox row it was dispatched from.
+type InboxNotification struct {
+	ID             uuid.UUID       `gorm:"primarykey" json:"id"`
+	UserID         uuid.UUID       `json:"-"`
+	NotificationID uuid.UUID       `json:"-"`
+	Subject        string          `json:"subject"`
+	Template       string          `json:"template"`
+	Title          string          `json:"title"`
+	Body           string          `json:"body"`
+	Data           json.RawMessage `gorm:"type:jsonb" json:"data"`
+	ReadAt         *time.Time      `json:"read_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
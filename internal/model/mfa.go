@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFARecoveryCode is a one-time code standing in for a TOTP code, e.g. after losing a phone
+type MFARecoveryCode struct {
+	ID        uuid.UUID `gorm:"primarykey"`
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// RoleMFAPolicy says whether users of a role have to log in with a second factor
+type RoleMFAPolicy struct {
+	Role        Role      `gorm:"primarykey" json:"role"`
+	MFARequired bool      `json:"mfa_required"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
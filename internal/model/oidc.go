@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to the subject an identity provider knows them by
+type UserIdentity struct {
+	ID        uuid.UUID `gorm:"primarykey" json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OIDCLogin is a login sent to an identity provider, found again by the state it comes back
+// with. Its nonce and PKCE code verifier never leave the server.
+type OIDCLogin struct {
+	ID           uuid.UUID `gorm:"primarykey"`
+	Provider     string
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusDead    OutboxStatus = "dead"
+	// OutboxStatusSkipped is a notification the recipient's preferences ruled out, e.g. a muted tiger
+	OutboxStatusSkipped OutboxStatus = "skipped"
+	// OutboxStatusDigest is a notification held back for the recipient's next digest,
+	// OutboxStatusDigested one that has been batched into a digest notification
+	OutboxStatusDigest   OutboxStatus = "digest"
+	OutboxStatusDigested OutboxStatus = "digested"
+)
+
+// OutboxNotification is a notification waiting in (or processed from) the
+// notification_outbox table. Rows are written in the same transaction as the
+// change that caused them and drained by the notification worker.
+type OutboxNotification struct {
+	ID      uuid.UUID `gorm:"primarykey" json:"id"`
+	Subject string    `json:"subject"`
+	UserID  uuid.UUID `json:"user_id"`
+	// Payload is left out of JSON, account notifications carry one-time tokens in it
+	Payload       json.RawMessage `gorm:"type:jsonb" json:"-"`
+	Status        OutboxStatus    `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	// DeliveredChannels are the channels an earlier attempt already delivered the notification on
+	DeliveredChannels pq.StringArray `gorm:"type:text[]" json:"delivered_channels"`
+	SentAt            *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+func (OutboxNotification) TableName() string {
+	return "notification_outbox"
+}
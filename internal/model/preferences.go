@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeliveryMode string
+
+const (
+	DeliveryImmediate    DeliveryMode = "immediate"
+	DeliveryHourlyDigest DeliveryMode = "hourly"
+	DeliveryDailyDigest  DeliveryMode = "daily"
+)
+
+// NotificationPreferences decide how, when and whether a user hears about
+// sightings. Users without a stored row get DefaultNotificationPreferences.
+type NotificationPreferences struct {
+	UserID         uuid.UUID    `gorm:"primarykey" json:"-"`
+	EmailEnabled   bool         `json:"email_enabled"`
+	WebhookEnabled bool         `json:"webhook_enabled"`
+	InAppEnabled   bool         `json:"in_app_enabled"`
+	Delivery       DeliveryMode `json:"delivery"`
+	// QuietHoursStart and QuietHoursEnd are "HH:MM" in TimeZone, a window may wrap past midnight
+	QuietHoursStart *string    `json:"quiet_hours_start"`
+	QuietHoursEnd   *string    `json:"quiet_hours_end"`
+	TimeZone        string     `json:"time_zone"`
+	LastDigestAt    *time.Time `json:"-"`
+	CreatedAt       time.Time  `json:"-"`
+	UpdatedAt       time.Time  `json:"-"`
+}
+
+func DefaultNotificationPreferences(userID uuid.UUID) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:       userID,
+		EmailEnabled: true,
+		InAppEnabled: true,
+		Delivery:     DeliveryImmediate,
+		TimeZone:     "UTC",
+	}
+}
+
+type NotificationTigerMute struct {
+	UserID    uuid.UUID `gorm:"primarykey"`
+	TigerID   uint      `gorm:"primarykey"`
+	CreatedAt time.Time
+}
+
+func (NotificationPreferences) TableName() string {
+	return "notification_preferences"
+}
+
+func (NotificationTigerMute) TableName() string {
+	return "notification_tiger_mutes"
+}
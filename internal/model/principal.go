@@ -0,0 +1,34 @@
+package model
+
+import "github.com/google/uuid"
+
+// Principal is who a request acts as: a user signed in with a token, or an API key acting
+// for the user owning it
+type Principal struct {
+	UserID uuid.UUID
+	Role   Role
+	// APIKeyID is only set when the request was authenticated with an API key
+	APIKeyID *uuid.UUID
+	// Scopes limit what an API key may do on top of the role of its owner
+	Scopes []Permission
+}
+
+// Can reports whether the principal has been granted permission, by the role and, for an API
+// key, by its scopes
+func (p Principal) Can(permission Permission) bool {
+	if !p.Role.Can(permission) {
+		return false
+	}
+
+	if p.APIKeyID == nil {
+		return true
+	}
+
+	for _, scope := range p.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+
+	return false
+}
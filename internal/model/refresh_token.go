@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is one link of a token family: every login starts a family and every refresh
+// rotates it to a new token. UsedAt is set once a token has been rotated, presenting it again
+// means it leaked and the whole family is revoked.
+type RefreshToken struct {
+	ID        uuid.UUID `gorm:"primarykey"`
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
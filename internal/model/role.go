@@ -0,0 +1,79 @@
+package model
+
+// Role decides what a user may do, each role can do everything the one before it can
+type Role string
+
+const (
+	RoleViewer     Role = "viewer"
+	RoleReporter   Role = "reporter"
+	RoleResearcher Role = "researcher"
+	RoleAdmin      Role = "admin"
+
+	DefaultRole = RoleReporter
+)
+
+type Permission string
+
+const (
+	PermissionReadTigers          Permission = "tigers:read"
+	PermissionWriteTigers         Permission = "tigers:write"
+	PermissionReadSightings       Permission = "sightings:read"
+	PermissionWriteSightings      Permission = "sightings:write"
+	PermissionManageUsers         Permission = "users:manage"
+	PermissionManageNotifications Permission = "notifications:manage"
+	PermissionManageWebhooks      Permission = "webhooks:manage"
+	PermissionManageTigers        Permission = "tigers:manage"
+)
+
+// rolePermissions is the permission matrix, a role is granted the permissions of its own
+// entry and of every role listed before it
+var rolePermissions = []struct {
+	role        Role
+	permissions []Permission
+}{
+	{RoleViewer, []Permission{PermissionReadTigers, PermissionReadSightings}},
+	{RoleReporter, []Permission{PermissionWriteSightings}},
+	{RoleResearcher, []Permission{PermissionWriteTigers}},
+	{RoleAdmin, []Permission{PermissionManageUsers, PermissionManageNotifications, PermissionManageWebhooks, PermissionManageTigers}},
+}
+
+// Permissions lists every permission of the permission matrix, which are also the API key scopes
+func Permissions() []Permission {
+	var permissions []Permission
+	for _, entry := range rolePermissions {
+		permissions = append(permissions, entry.permissions...)
+	}
+
+	return permissions
+}
+
+func (r Role) Valid() bool {
+	for _, entry := range rolePermissions {
+		if entry.role == r {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Can reports whether the role has been granted permission
+func (r Role) Can(permission Permission) bool {
+	if !r.Valid() {
+		return false
+	}
+
+	for _, entry := range rolePermissions {
+		for _, granted := range entry.permissions {
+			if granted == permission {
+				return true
+			}
+		}
+
+		if entry.role == r {
+			return false
+		}
+	}
+
+	return false
+}
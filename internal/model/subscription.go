@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TigerSubscription makes a user hear about every sighting of a tiger, whether
+// or not they have reported it themselves
+type TigerSubscription struct {
+	UserID    uuid.UUID `gorm:"primarykey" json:"user_id"`
+	TigerID   uint      `gorm:"primarykey" json:"tiger_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TigerSubscription) TableName() string {
+	return "tiger_subscriptions"
+}
@@ -2,13 +2,37 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Tiger struct {
-	ID                uint      `gorm:"primarykey" json:"-"`
+	ID                uint      `gorm:"primarykey" json:"id"`
 	Name              string    `json:"name"  validate:"required"`
 	DateOfBirth       time.Time `json:"date_of_birth"  validate:"required"`
 	LastSeenTimestamp time.Time `json:"last_seen_timestamp"  validate:"required"`
 	LastSeenLat       float64   `json:"last_seen_lat"  validate:"required"`
 	LastSeenLon       float64   `json:"last_seen_lon"  validate:"required"`
+	// Version goes up with every update, an update must name the version it was based on
+	Version   int            `json:"version"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+}
+
+// TigerStatus filters tiger listings by whether tigers are soft deleted
+type TigerStatus string
+
+const (
+	TigerStatusActive  TigerStatus = "active"
+	TigerStatusDeleted TigerStatus = "deleted"
+	TigerStatusAll     TigerStatus = "all"
+)
+
+func (s TigerStatus) Valid() bool {
+	switch s {
+	case TigerStatusActive, TigerStatusDeleted, TigerStatusAll:
+		return true
+	}
+
+	return false
 }
@@ -8,11 +8,71 @@ import (
 import "github.com/google/uuid"
 
 type User struct {
-	ID        uuid.UUID `gorm:"primarykey"`
-	Username  string    `gorm:"unique"`
-	Password  string
-	Email     string `gorm:"unique"`
+	ID       uuid.UUID `gorm:"primarykey"`
+	Username string    `gorm:"unique"`
+	Password string
+	Email    string `gorm:"unique"`
+	Locale   string
+	Role     Role
+	// Machine accounts, such as a camera trap, can not log in and only act through service API keys
+	Machine   bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	DisplayName  string
+	Organisation string
+	TimeZone     string `gorm:"default:UTC"`
+	// PendingEmail is a new address waiting to be confirmed, Email is kept until it is
+	PendingEmail *string
+
+	// EmailVerifiedAt is nil while a self-service signup is pending verification
+	EmailVerifiedAt *time.Time
+	// SessionsRevokedAt invalidates every access token issued before it
+	SessionsRevokedAt *time.Time
+	// SuspendedAt is set while an admin has suspended the account
+	SuspendedAt *time.Time
+	// ErasedAt is set once the personal data of the user is erased, the row is kept anonymised
+	ErasedAt *time.Time
+
+	// TOTPSecret is the pending secret of an enrollment until MFAEnabledAt is set, sealed with secretbox
+	TOTPSecret   string
+	TOTPLastStep int64
+	MFAEnabledAt *time.Time
+}
+
+// TombstoneUserID is the user the sightings of erased users are reassigned to
+var TombstoneUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+type UserStatus string
+
+const (
+	// UserStatusPending is a self-service signup whose email address is not verified yet
+	UserStatusPending   UserStatus = "pending"
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusDeleted   UserStatus = "deleted"
+)
+
+func (s UserStatus) Valid() bool {
+	switch s {
+	case UserStatusPending, UserStatusActive, UserStatusSuspended, UserStatusDeleted:
+		return true
+	}
+
+	return false
+}
+
+// Status is the state of the account, a deleted account has no other status
+func (u *User) Status() UserStatus {
+	switch {
+	case u.DeletedAt.Valid:
+		return UserStatusDeleted
+	case u.SuspendedAt != nil:
+		return UserStatusSuspended
+	case u.EmailVerifiedAt == nil:
+		return UserStatusPending
+	}
+
+	return UserStatusActive
 }
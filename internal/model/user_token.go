@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPurposeEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenPurposePasswordReset     UserTokenPurpose = "password_reset"
+	// UserTokenPurposeEmailChange is mailed to the PendingEmail of a user rather than to their Email
+	UserTokenPurposeEmailChange UserTokenPurpose = "email_change"
+)
+
+// UserToken is a single-use, expiring token mailed to a user to prove they own their email address
+type UserToken struct {
+	ID        uuid.UUID `gorm:"primarykey"`
+	UserID    uuid.UUID
+	Purpose   UserTokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
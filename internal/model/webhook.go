@@ -0,0 +1,81 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	WebhookEventSightingCreated = "sighting.created"
+	WebhookEventTigerCreated    = "tiger.created"
+	WebhookEventTigerUpdated    = "tiger.updated"
+)
+
+// WebhookEvents lists every event a webhook subscription can filter on
+var WebhookEvents = []string{WebhookEventSightingCreated, WebhookEventTigerCreated, WebhookEventTigerUpdated}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "dead"
+)
+
+// WebhookSubscription is a partner endpoint receiving the events it subscribed to. The secret
+// signs every delivery and is only ever shown when the subscription is created.
+type WebhookSubscription struct {
+	ID              uuid.UUID      `gorm:"primarykey" json:"id"`
+	URL             string         `json:"url"`
+	Secret          string         `json:"-"`
+	Events          pq.StringArray `gorm:"type:text[]" json:"events"`
+	Active          bool           `json:"active"`
+	CreatedByUserID *uuid.UUID     `json:"created_by_user_id"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// WebhookEnvelope is the body of every delivery. ID is shared by the deliveries of one event to
+// different subscriptions, so receivers can use it to drop duplicates.
+type WebhookEnvelope struct {
+	ID        uuid.UUID   `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery is one event on its way to one subscription, drained by the webhook delivery
+// worker the same way notifications are drained from the outbox
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"primarykey" json:"id"`
+	SubscriptionID uuid.UUID             `json:"subscription_id"`
+	EventID        uuid.UUID             `json:"event_id"`
+	Event          string                `json:"event"`
+	Payload        json.RawMessage       `gorm:"type:jsonb" json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	ResponseCode   *int                  `json:"response_code"`
+	LastError      string                `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+
+	// Subscription is loaded along with claimed deliveries
+	Subscription *WebhookSubscription `gorm:"-" json:"-"`
+}
+
+// WebhookDeliveryAttempt is the log entry of a single attempt at a delivery
+type WebhookDeliveryAttempt struct {
+	ID           uuid.UUID `gorm:"primarykey" json:"id"`
+	DeliveryID   uuid.UUID `json:"delivery_id"`
+	Attempt      int       `json:"attempt"`
+	ResponseCode *int      `json:"response_code"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
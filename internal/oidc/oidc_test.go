@@ -0,0 +1,110 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/oidc"
+	"tigerhall_kittens/internal/oidc/oidctest"
+)
+
+const redirectURL = "https://kittens.example.org/sso/callback"
+
+func newProvider(t *testing.T) (*oidc.Provider, *oidctest.Provider) {
+	mock, err := oidctest.NewProvider()
+	assert.Nil(t, err)
+	t.Cleanup(mock.Close)
+
+	return oidc.NewProvider(oidc.ProviderConfig{
+		Name:         "wwf",
+		Issuer:       mock.Issuer(),
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  redirectURL,
+	}, http.DefaultClient), mock
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	ctx := context.Background()
+	user := oidctest.User{Subject: "248289761001", Email: "ravi@wwf.example.org", EmailVerified: true, Name: "Ravi"}
+
+	t.Run("should return the identity of a code redeemed with its verifier", func(t *testing.T) {
+		provider, mock := newProvider(t)
+
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallenge("verifier"))
+		assert.Nil(t, err)
+
+		code, state, err := mock.Authorize(authURL, user)
+		assert.Nil(t, err)
+		assert.Equal(t, "state", state)
+
+		identity, err := provider.Exchange(ctx, code, "verifier", "nonce")
+		assert.Nil(t, err)
+		assert.Equal(t, &oidc.Identity{Subject: user.Subject, Email: user.Email, EmailVerified: true, Name: "Ravi"}, identity)
+
+		_, err = provider.Exchange(ctx, code, "verifier", "nonce")
+		assert.True(t, errors.Is(err, oidc.ErrCodeExchange))
+	})
+
+	t.Run("should refuse a code redeemed without its verifier", func(t *testing.T) {
+		provider, mock := newProvider(t)
+
+		authURL, _ := provider.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallenge("verifier"))
+		code, _, err := mock.Authorize(authURL, user)
+		assert.Nil(t, err)
+
+		_, err = provider.Exchange(ctx, code, "stolen", "nonce")
+		assert.True(t, errors.Is(err, oidc.ErrCodeExchange))
+	})
+
+	t.Run("should reject an ID token of another login", func(t *testing.T) {
+		provider, mock := newProvider(t)
+
+		authURL, _ := provider.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallenge("verifier"))
+		code, _, err := mock.Authorize(authURL, user)
+		assert.Nil(t, err)
+
+		_, err = provider.Exchange(ctx, code, "verifier", "other nonce")
+		assert.True(t, errors.Is(err, oidc.ErrInvalidIDToken))
+	})
+
+	t.Run("should return a discovery error for an issuer that is not up", func(t *testing.T) {
+		provider := oidc.NewProvider(oidc.ProviderConfig{Name: "down", Issuer: "http://127.0.0.1:1", ClientID: "id"}, http.DefaultClient)
+
+		_, err := provider.AuthCodeURL(ctx, "state", "nonce", "challenge")
+		assert.True(t, errors.Is(err, oidc.ErrDiscovery))
+	})
+}
+
+func TestLoadProviders(t *testing.T) {
+	t.Run("should return an error for incomplete or duplicate providers", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "providers.json")
+
+		for _, content := range []string{
+			`[{"name": "wwf", "issuer": "https://id.wwf.example.org"}]`,
+			`[{"name": "wwf", "issuer": "https://a", "client_id": "a", "redirect_url": "https://r"},
+			  {"name": "wwf", "issuer": "https://b", "client_id": "b", "redirect_url": "https://r"}]`,
+		} {
+			assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+
+			_, err := oidc.LoadProviders(path, http.DefaultClient)
+			assert.NotNil(t, err)
+		}
+	})
+
+	t.Run("should load the providers by name", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "providers.json")
+		assert.Nil(t, os.WriteFile(path, []byte(`[{"name": "wwf", "issuer": "https://id.wwf.example.org",
+			"client_id": "kittens", "client_secret": "secret", "redirect_url": "https://kittens.example.org/sso/callback"}]`), 0o600))
+
+		providers, err := oidc.LoadProviders(path, http.DefaultClient)
+		assert.Nil(t, err)
+		assert.Equal(t, "wwf", providers["wwf"].Name())
+	})
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+// lastUsedResolution is how stale last_used_at may get, so that a busy key is not written on
+// every request
+const lastUsedResolution = time.Minute
+
+type ListAPIKeysOpts struct {
+	UserID *uuid.UUID
+	Kind   model.APIKeyKind
+	Limit  int
+	Offset int
+}
+
+// RevokeAPIKeyOpts picks the key to revoke, UserID and Kind restrict it to the keys a user may
+// manage themselves
+type RevokeAPIKeyOpts struct {
+	ID     uuid.UUID
+	UserID *uuid.UUID
+	Kind   model.APIKeyKind
+}
+
+type APIKeyRepo interface {
+	CreateKey(ctx context.Context, key *model.APIKey) error
+	GetKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	ListKeys(ctx context.Context, opts ListAPIKeysOpts) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, opts RevokeAPIKeyOpts) (*model.APIKey, error)
+	TouchKey(ctx context.Context, id uuid.UUID, now time.Time) error
+}
+
+type apiKeyRepo struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyRepo() APIKeyRepo {
+	return &apiKeyRepo{DB: db.Get()}
+}
+
+func (t *apiKeyRepo) CreateKey(ctx context.Context, key *model.APIKey) error {
+	if err := t.DB.WithContext(ctx).Create(key).Error; err != nil {
+		logger.E(ctx, err, "Error while saving API key", logger.Field("user_id", key.UserID))
+		return err
+	}
+
+	return nil
+}
+
+func (t *apiKeyRepo) GetKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	var key model.APIKey
+
+	err := t.DB.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching API key")
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (t *apiKeyRepo) ListKeys(ctx context.Context, opts ListAPIKeysOpts) ([]model.APIKey, error) {
+	var keys []model.APIKey
+
+	query := t.DB.WithContext(ctx).Model(&model.APIKey{})
+	if opts.UserID != nil {
+		query = query.Where("user_id = ?", *opts.UserID)
+	}
+
+	if opts.Kind != "" {
+		query = query.Where("kind = ?", opts.Kind)
+	}
+
+	err := query.Order("created_at DESC").Limit(opts.Limit).Offset(opts.Offset).Find(&keys).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching API keys")
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokeKey revokes a key that has not been revoked yet and returns it, it returns
+// gorm.ErrRecordNotFound when there is no such key
+func (t *apiKeyRepo) RevokeKey(ctx context.Context, opts RevokeAPIKeyOpts) (*model.APIKey, error) {
+	var key model.APIKey
+
+	query := t.DB.WithContext(ctx).Model(&key).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND revoked_at IS NULL", opts.ID)
+	if opts.UserID != nil {
+		query = query.Where("user_id = ?", *opts.UserID)
+	}
+
+	if opts.Kind != "" {
+		query = query.Where("kind = ?", opts.Kind)
+	}
+
+	result := query.Update("revoked_at", time.Now())
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while revoking API key", logger.Field("api_key_id", opts.ID))
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &key, nil
+}
+
+// TouchKey records that the key was used at now, at most once per lastUsedResolution
+func (t *apiKeyRepo) TouchKey(ctx context.Context, id uuid.UUID, now time.Time) error {
+	err := t.DB.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-lastUsedResolution)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while updating API key last use", logger.Field("api_key_id", id))
+		return err
+	}
+
+	return nil
+}
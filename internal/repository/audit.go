@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type AuditRepo interface {
+	CreateEvent(ctx context.Context, event *model.AuditEvent) error
+	GetUserEvents(ctx context.Context, userID uuid.UUID) ([]model.AuditEvent, error)
+}
+
+type auditRepo struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepo() AuditRepo {
+	return &auditRepo{DB: db.Get()}
+}
+
+func (t *auditRepo) CreateEvent(ctx context.Context, event *model.AuditEvent) error {
+	if err := t.DB.WithContext(ctx).Create(event).Error; err != nil {
+		logger.E(ctx, err, "Error while saving audit event", logger.Field("type", event.Type))
+		return err
+	}
+
+	return nil
+}
+
+// GetUserEvents returns every event about the user, newest first
+func (t *auditRepo) GetUserEvents(ctx context.Context, userID uuid.UUID) ([]model.AuditEvent, error) {
+	var events []model.AuditEvent
+
+	err := t.DB.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&events).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching audit events of user", logger.Field("user_id", userID))
+		return nil, err
+	}
+
+	return events, nil
+}
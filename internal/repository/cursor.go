@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor points at the last row of a page ordered by creation time and id, the next page starts
+// right after it
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
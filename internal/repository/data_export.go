@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type GetDataExportOpts struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// Pending only matches an export that is still being built
+	Pending bool
+	// WithArchive loads the archive, which is left out otherwise
+	WithArchive bool
+}
+
+type DataExportRepo interface {
+	CreateExport(ctx context.Context, export *model.DataExport) error
+	GetExport(ctx context.Context, opts GetDataExportOpts) (*model.DataExport, error)
+	ClaimExports(ctx context.Context, opts ClaimOutboxOpts) ([]model.DataExport, error)
+	CompleteExport(ctx context.Context, export *model.DataExport, notification *model.OutboxNotification) error
+	FailExport(ctx context.Context, export *model.DataExport) error
+	DeleteExpiredExports(ctx context.Context, now time.Time) (int64, error)
+}
+
+type dataExportRepo struct {
+	DB *gorm.DB
+}
+
+func NewDataExportRepo() DataExportRepo {
+	return &dataExportRepo{DB: db.Get()}
+}
+
+func (t *dataExportRepo) CreateExport(ctx context.Context, export *model.DataExport) error {
+	if err := t.DB.WithContext(ctx).Create(export).Error; err != nil {
+		logger.E(ctx, err, "Error while creating data export", logger.Field("user_id", export.UserID))
+		return err
+	}
+
+	return nil
+}
+
+// GetExport returns the newest export matching opts
+func (t *dataExportRepo) GetExport(ctx context.Context, opts GetDataExportOpts) (*model.DataExport, error) {
+	var export model.DataExport
+
+	query := t.DB.WithContext(ctx).Where("user_id = ?", opts.UserID).Order("created_at desc")
+	if opts.ID != uuid.Nil {
+		query = query.Where("id = ?", opts.ID)
+	}
+
+	if opts.Pending {
+		query = query.Where("status = ?", model.DataExportStatusPending)
+	}
+
+	if !opts.WithArchive {
+		query = query.Omit("archive")
+	}
+
+	if err := query.First(&export).Error; err != nil {
+		logger.E(ctx, err, "Error while fetching data export", logger.Field("user_id", opts.UserID))
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// ClaimExports claims pending exports the way ClaimNotifications claims notifications
+func (t *dataExportRepo) ClaimExports(ctx context.Context, opts ClaimOutboxOpts) ([]model.DataExport, error) {
+	var exports []model.DataExport
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Omit("archive").
+			Where("status = ? AND next_attempt_at <= ?", model.DataExportStatusPending, now).
+			Order("next_attempt_at").
+			Limit(opts.Limit).
+			Find(&exports).Error
+		if err != nil {
+			return err
+		}
+
+		if len(exports) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(exports))
+		for i := range exports {
+			ids = append(ids, exports[i].ID)
+			exports[i].Attempts++
+			exports[i].NextAttemptAt = now.Add(opts.Lease)
+		}
+
+		return tx.Model(&model.DataExport{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": now.Add(opts.Lease),
+				"updated_at":      now,
+			}).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while claiming data exports")
+		return nil, err
+	}
+
+	return exports, nil
+}
+
+// CompleteExport saves the archive of a ready export together with the notification telling its
+// user, so the user is told exactly once
+func (t *dataExportRepo) CompleteExport(ctx context.Context, export *model.DataExport, notification *model.OutboxNotification) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.DataExport{}).
+			Where("id = ?", export.ID).
+			Updates(map[string]interface{}{
+				"status":       model.DataExportStatusReady,
+				"archive":      export.Archive,
+				"last_error":   "",
+				"completed_at": export.CompletedAt,
+				"expires_at":   export.ExpiresAt,
+				"updated_at":   time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(notification).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while completing data export", logger.Field("export_id", export.ID))
+		return err
+	}
+
+	return nil
+}
+
+// FailExport saves the outcome of a failed attempt, the export is either retried at its
+// NextAttemptAt or failed for good
+func (t *dataExportRepo) FailExport(ctx context.Context, export *model.DataExport) error {
+	err := t.DB.WithContext(ctx).Model(&model.DataExport{}).
+		Where("id = ?", export.ID).
+		Updates(map[string]interface{}{
+			"status":          export.Status,
+			"next_attempt_at": export.NextAttemptAt,
+			"last_error":      export.LastError,
+			"updated_at":      time.Now(),
+		}).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while failing data export", logger.Field("export_id", export.ID))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpiredExports deletes the exports that can no longer be downloaded and returns how many
+// there were
+func (t *dataExportRepo) DeleteExpiredExports(ctx context.Context, now time.Time) (int64, error) {
+	result := t.DB.WithContext(ctx).Where("expires_at < ?", now).Delete(&model.DataExport{})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while deleting expired data exports")
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
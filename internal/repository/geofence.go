@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type GetGeofenceOpts struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+type ListGeofencesOpts struct {
+	UserID uuid.UUID
+	Limit  int
+	Offset int
+}
+
+type GeofenceRepo interface {
+	CreateGeofence(ctx context.Context, geofence *model.Geofence) error
+	UpdateGeofence(ctx context.Context, geofence *model.Geofence) error
+	DeleteGeofence(ctx context.Context, opts GetGeofenceOpts) error
+	GetGeofence(ctx context.Context, opts GetGeofenceOpts) (*model.Geofence, error)
+	GetGeofences(ctx context.Context, opts ListGeofencesOpts) ([]model.Geofence, error)
+}
+
+type geofenceRepo struct {
+	DB *gorm.DB
+}
+
+func NewGeofenceRepo() GeofenceRepo {
+	return &geofenceRepo{DB: db.Get()}
+}
+
+// geofenceArea is the PostGIS expression for the area column of a geofence
+func geofenceArea(geofence *model.Geofence) clause.Expr {
+	if geofence.Kind == model.GeofenceKindCircle {
+		return gorm.Expr("ST_Buffer(ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)",
+			*geofence.Lon, *geofence.Lat, *geofence.RadiusInMeters)
+	}
+
+	return gorm.Expr("ST_SetSRID(ST_GeomFromGeoJSON(?), 4326)::geography", string(geofence.Polygon))
+}
+
+func (t *geofenceRepo) CreateGeofence(ctx context.Context, geofence *model.Geofence) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(geofence).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Geofence{}).Where("id = ?", geofence.ID).UpdateColumn("area", geofenceArea(geofence)).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while saving geofence")
+		return err
+	}
+
+	return nil
+}
+
+// UpdateGeofence replaces the name and shape of a geofence owned by geofence.UserID,
+// it returns gorm.ErrRecordNotFound when there is no such geofence
+func (t *geofenceRepo) UpdateGeofence(ctx context.Context, geofence *model.Geofence) error {
+	result := t.DB.WithContext(ctx).Model(&model.Geofence{}).
+		Where("id = ? AND user_id = ?", geofence.ID, geofence.UserID).
+		Updates(map[string]interface{}{
+			"name":             geofence.Name,
+			"kind":             geofence.Kind,
+			"lat":              geofence.Lat,
+			"lon":              geofence.Lon,
+			"radius_in_meters": geofence.RadiusInMeters,
+			"polygon":          geofence.Polygon,
+			"area":             geofenceArea(geofence),
+			"updated_at":       geofence.UpdatedAt,
+		})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while updating geofence", logger.Field("geofence_id", geofence.ID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *geofenceRepo) DeleteGeofence(ctx context.Context, opts GetGeofenceOpts) error {
+	result := t.DB.WithContext(ctx).Where("id = ? AND user_id = ?", opts.ID, opts.UserID).Delete(&model.Geofence{})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while deleting geofence", logger.Field("geofence_id", opts.ID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *geofenceRepo) GetGeofence(ctx context.Context, opts GetGeofenceOpts) (*model.Geofence, error) {
+	var geofence model.Geofence
+
+	err := t.DB.WithContext(ctx).Where("id = ? AND user_id = ?", opts.ID, opts.UserID).First(&geofence).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.E(ctx, err, "Error while fetching geofence", logger.Field("geofence_id", opts.ID))
+		}
+		return nil, err
+	}
+
+	return &geofence, nil
+}
+
+func (t *geofenceRepo) GetGeofences(ctx context.Context, opts ListGeofencesOpts) ([]model.Geofence, error) {
+	var geofences []model.Geofence
+
+	query := t.DB.WithContext(ctx).Where("user_id = ?", opts.UserID).Order("created_at desc")
+	if opts.Limit != 0 {
+		query = query.Limit(opts.Limit).Offset(opts.Offset)
+	}
+
+	err := query.Find(&geofences).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching geofences")
+		return nil, err
+	}
+
+	return geofences, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type ListInboxOpts struct {
+	UserID     uuid.UUID
+	UnreadOnly bool
+	After      *Cursor
+	Limit      int
+}
+
+type InboxRepo interface {
+	CreateNotification(ctx context.Context, notification *model.InboxNotification) error
+	GetNotifications(ctx context.Context, opts ListInboxOpts) ([]model.InboxNotification, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type inboxRepo struct {
+	DB *gorm.DB
+}
+
+func NewInboxRepo() InboxRepo {
+	return &inboxRepo{DB: db.Get()}
+}
+
+// CreateNotification is idempotent per outbox row, so a retried dispatch does not duplicate the entry
+func (t *inboxRepo) CreateNotification(ctx context.Context, notification *model.InboxNotification) error {
+	err := t.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "notification_id"}},
+		DoNothing: true,
+	}).Create(notification).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while saving inbox notification", logger.Field("notification_id", notification.NotificationID))
+		return err
+	}
+
+	return nil
+}
+
+// GetNotifications returns the newest notifications first, starting after opts.After when it is set
+func (t *inboxRepo) GetNotifications(ctx context.Context, opts ListInboxOpts) ([]model.InboxNotification, error) {
+	var notifications []model.InboxNotification
+
+	query := t.DB.WithContext(ctx).Where("user_id = ?", opts.UserID).Order("created_at desc, id desc").Limit(opts.Limit)
+	if opts.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if opts.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", opts.After.CreatedAt, opts.After.ID)
+	}
+
+	err := query.Find(&notifications).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching inbox notifications")
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// MarkRead keeps the original read time of a notification that was read before, it returns
+// gorm.ErrRecordNotFound when the user has no such notification
+func (t *inboxRepo) MarkRead(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	result := t.DB.WithContext(ctx).Model(&model.InboxNotification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while marking inbox notification as read", logger.Field("notification_id", id))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *inboxRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result := t.DB.WithContext(ctx).Model(&model.InboxNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while marking inbox notifications as read", logger.Field("user_id", userID))
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (t *inboxRepo) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+
+	err := t.DB.WithContext(ctx).Model(&model.InboxNotification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while counting unread inbox notifications", logger.Field("user_id", userID))
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type MFARepo interface {
+	// SetPendingSecret starts an enrollment with a sealed secret, it fails with gorm.ErrRecordNotFound
+	// once MFA is enabled
+	SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	EnableMFA(ctx context.Context, userID uuid.UUID, step int64, codes []*model.MFARecoveryCode) error
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+	IsMFARequired(ctx context.Context, role model.Role) (bool, error)
+	ListRolePolicies(ctx context.Context) ([]model.RoleMFAPolicy, error)
+	SetRolePolicy(ctx context.Context, policy *model.RoleMFAPolicy) error
+}
+
+type mfaRepo struct {
+	DB *gorm.DB
+}
+
+func NewMFARepo() MFARepo {
+	return &mfaRepo{DB: db.Get()}
+}
+
+func (t *mfaRepo) SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	result := t.DB.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND mfa_enabled_at IS NULL", userID).
+		Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while saving TOTP secret", logger.Field("user_id", userID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// EnableMFA activates the pending secret and replaces the recovery codes of the user. It fails
+// with gorm.ErrRecordNotFound when a concurrent confirmation enabled MFA first.
+func (t *mfaRepo) EnableMFA(ctx context.Context, userID uuid.UUID, step int64, codes []*model.MFARecoveryCode) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND mfa_enabled_at IS NULL AND totp_secret <> ''", userID).
+			Updates(map[string]interface{}{"mfa_enabled_at": time.Now(), "totp_last_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(codes).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while enabling MFA", logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// UseTOTPStep records that a code of step was used, it fails with gorm.ErrRecordNotFound when
+// that step or a later one was used before, which makes a replayed code fail
+func (t *mfaRepo) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	result := t.DB.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while using TOTP code", logger.Field("user_id", userID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used, it fails with gorm.ErrRecordNotFound
+// for unknown and used codes
+func (t *mfaRepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	result := t.DB.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while using recovery code", logger.Field("user_id", userID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *mfaRepo) IsMFARequired(ctx context.Context, role model.Role) (bool, error) {
+	var policies []model.RoleMFAPolicy
+
+	err := t.DB.WithContext(ctx).Where("role = ?", role).Limit(1).Find(&policies).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching MFA policy", logger.Field("role", role))
+		return false, err
+	}
+
+	return len(policies) > 0 && policies[0].MFARequired, nil
+}
+
+func (t *mfaRepo) ListRolePolicies(ctx context.Context) ([]model.RoleMFAPolicy, error) {
+	var policies []model.RoleMFAPolicy
+
+	if err := t.DB.WithContext(ctx).Order("role").Find(&policies).Error; err != nil {
+		logger.E(ctx, err, "Error while listing MFA policies")
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (t *mfaRepo) SetRolePolicy(ctx context.Context, policy *model.RoleMFAPolicy) error {
+	err := t.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "role"}},
+			DoUpdates: clause.AssignmentColumns([]string{"mfa_required", "updated_at"}),
+		}).
+		Create(policy).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while saving MFA policy", logger.Field("role", policy.Role))
+		return err
+	}
+
+	return nil
+}
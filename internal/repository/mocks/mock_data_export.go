@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/data_export.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+	repository "tigerhall_kittens/internal/repository"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDataExportRepo is a mock of DataExportRepo interface.
+type MockDataExportRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataExportRepoMockRecorder
+}
+
+// MockDataExportRepoMockRecorder is the mock recorder for MockDataExportRepo.
+type MockDataExportRepoMockRecorder struct {
+	mock *MockDataExportRepo
+}
+
+// NewMockDataExportRepo creates a new mock instance.
+func NewMockDataExportRepo(ctrl *gomock.Controller) *MockDataExportRepo {
+	mock := &MockDataExportRepo{ctrl: ctrl}
+	mock.recorder = &MockDataExportRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataExportRepo) EXPECT() *MockDataExportRepoMockRecorder {
+	return m.recorder
+}
+
+// ClaimExports mocks base method.
+func (m *MockDataExportRepo) ClaimExports(ctx context.Context, opts repository.ClaimOutboxOpts) ([]model.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExports", ctx, opts)
+	ret0, _ := ret[0].([]model.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExports indicates an expected call of ClaimExports.
+func (mr *MockDataExportRepoMockRecorder) ClaimExports(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExports", reflect.TypeOf((*MockDataExportRepo)(nil).ClaimExports), ctx, opts)
+}
+
+// CompleteExport mocks base method.
+func (m *MockDataExportRepo) CompleteExport(ctx context.Context, export *model.DataExport, notification *model.OutboxNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteExport", ctx, export, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteExport indicates an expected call of CompleteExport.
+func (mr *MockDataExportRepoMockRecorder) CompleteExport(ctx, export, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteExport", reflect.TypeOf((*MockDataExportRepo)(nil).CompleteExport), ctx, export, notification)
+}
+
+// CreateExport mocks base method.
+func (m *MockDataExportRepo) CreateExport(ctx context.Context, export *model.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExport", ctx, export)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateExport indicates an expected call of CreateExport.
+func (mr *MockDataExportRepoMockRecorder) CreateExport(ctx, export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExport", reflect.TypeOf((*MockDataExportRepo)(nil).CreateExport), ctx, export)
+}
+
+// DeleteExpiredExports mocks base method.
+func (m *MockDataExportRepo) DeleteExpiredExports(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredExports", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredExports indicates an expected call of DeleteExpiredExports.
+func (mr *MockDataExportRepoMockRecorder) DeleteExpiredExports(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredExports", reflect.TypeOf((*MockDataExportRepo)(nil).DeleteExpiredExports), ctx, now)
+}
+
+// FailExport mocks base method.
+func (m *MockDataExportRepo) FailExport(ctx context.Context, export *model.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailExport", ctx, export)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailExport indicates an expected call of FailExport.
+func (mr *MockDataExportRepoMockRecorder) FailExport(ctx, export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailExport", reflect.TypeOf((*MockDataExportRepo)(nil).FailExport), ctx, export)
+}
+
+// GetExport mocks base method.
+func (m *MockDataExportRepo) GetExport(ctx context.Context, opts repository.GetDataExportOpts) (*model.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", ctx, opts)
+	ret0, _ := ret[0].(*model.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockDataExportRepoMockRecorder) GetExport(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockDataExportRepo)(nil).GetExport), ctx, opts)
+}
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/geofence.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+	repository "tigerhall_kittens/internal/repository"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockGeofenceRepo is a mock of GeofenceRepo interface.
+type MockGeofenceRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockGeofenceRepoMockRecorder
+}
+
+// MockGeofenceRepoMockRecorder is the mock recorder for MockGeofenceRepo.
+type MockGeofenceRepoMockRecorder struct {
+	mock *MockGeofenceRepo
+}
+
+// NewMockGeofenceRepo creates a new mock instance.
+func NewMockGeofenceRepo(ctrl *gomock.Controller) *MockGeofenceRepo {
+	mock := &MockGeofenceRepo{ctrl: ctrl}
+	mock.recorder = &MockGeofenceRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGeofenceRepo) EXPECT() *MockGeofenceRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateGeofence mocks base method.
+func (m *MockGeofenceRepo) CreateGeofence(ctx context.Context, geofence *model.Geofence) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGeofence", ctx, geofence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateGeofence indicates an expected call of CreateGeofence.
+func (mr *MockGeofenceRepoMockRecorder) CreateGeofence(ctx, geofence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGeofence", reflect.TypeOf((*MockGeofenceRepo)(nil).CreateGeofence), ctx, geofence)
+}
+
+// DeleteGeofence mocks base method.
+func (m *MockGeofenceRepo) DeleteGeofence(ctx context.Context, opts repository.GetGeofenceOpts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGeofence", ctx, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGeofence indicates an expected call of DeleteGeofence.
+func (mr *MockGeofenceRepoMockRecorder) DeleteGeofence(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGeofence", reflect.TypeOf((*MockGeofenceRepo)(nil).DeleteGeofence), ctx, opts)
+}
+
+// GetGeofence mocks base method.
+func (m *MockGeofenceRepo) GetGeofence(ctx context.Context, opts repository.GetGeofenceOpts) (*model.Geofence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGeofence", ctx, opts)
+	ret0, _ := ret[0].(*model.Geofence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGeofence indicates an expected call of GetGeofence.
+func (mr *MockGeofenceRepoMockRecorder) GetGeofence(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGeofence", reflect.TypeOf((*MockGeofenceRepo)(nil).GetGeofence), ctx, opts)
+}
+
+// GetGeofences mocks base method.
+func (m *MockGeofenceRepo) GetGeofences(ctx context.Context, opts repository.ListGeofencesOpts) ([]model.Geofence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGeofences", ctx, opts)
+	ret0, _ := ret[0].([]model.Geofence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGeofences indicates an expected call of GetGeofences.
+func (mr *MockGeofenceRepoMockRecorder) GetGeofences(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGeofences", reflect.TypeOf((*MockGeofenceRepo)(nil).GetGeofences), ctx, opts)
+}
+
+// UpdateGeofence mocks base method.
+func (m *MockGeofenceRepo) UpdateGeofence(ctx context.Context, geofence *model.Geofence) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGeofence", ctx, geofence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGeofence indicates an expected call of UpdateGeofence.
+func (mr *MockGeofenceRepoMockRecorder) UpdateGeofence(ctx, geofence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGeofence", reflect.TypeOf((*MockGeofenceRepo)(nil).UpdateGeofence), ctx, geofence)
+}
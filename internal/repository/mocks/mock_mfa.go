@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/mfa.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockMFARepo is a mock of MFARepo interface.
+type MockMFARepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFARepoMockRecorder
+}
+
+// MockMFARepoMockRecorder is the mock recorder for MockMFARepo.
+type MockMFARepoMockRecorder struct {
+	mock *MockMFARepo
+}
+
+// NewMockMFARepo creates a new mock instance.
+func NewMockMFARepo(ctrl *gomock.Controller) *MockMFARepo {
+	mock := &MockMFARepo{ctrl: ctrl}
+	mock.recorder = &MockMFARepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFARepo) EXPECT() *MockMFARepoMockRecorder {
+	return m.recorder
+}
+
+// EnableMFA mocks base method.
+func (m *MockMFARepo) EnableMFA(ctx context.Context, userID uuid.UUID, step int64, codes []*model.MFARecoveryCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableMFA", ctx, userID, step, codes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableMFA indicates an expected call of EnableMFA.
+func (mr *MockMFARepoMockRecorder) EnableMFA(ctx, userID, step, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableMFA", reflect.TypeOf((*MockMFARepo)(nil).EnableMFA), ctx, userID, step, codes)
+}
+
+// IsMFARequired mocks base method.
+func (m *MockMFARepo) IsMFARequired(ctx context.Context, role model.Role) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMFARequired", ctx, role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMFARequired indicates an expected call of IsMFARequired.
+func (mr *MockMFARepoMockRecorder) IsMFARequired(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMFARequired", reflect.TypeOf((*MockMFARepo)(nil).IsMFARequired), ctx, role)
+}
+
+// ListRolePolicies mocks base method.
+func (m *MockMFARepo) ListRolePolicies(ctx context.Context) ([]model.RoleMFAPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRolePolicies", ctx)
+	ret0, _ := ret[0].([]model.RoleMFAPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRolePolicies indicates an expected call of ListRolePolicies.
+func (mr *MockMFARepoMockRecorder) ListRolePolicies(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRolePolicies", reflect.TypeOf((*MockMFARepo)(nil).ListRolePolicies), ctx)
+}
+
+// SetPendingSecret mocks base method.
+func (m *MockMFARepo) SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPendingSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPendingSecret indicates an expected call of SetPendingSecret.
+func (mr *MockMFARepoMockRecorder) SetPendingSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPendingSecret", reflect.TypeOf((*MockMFARepo)(nil).SetPendingSecret), ctx, userID, secret)
+}
+
+// SetRolePolicy mocks base method.
+func (m *MockMFARepo) SetRolePolicy(ctx context.Context, policy *model.RoleMFAPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRolePolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRolePolicy indicates an expected call of SetRolePolicy.
+func (mr *MockMFARepoMockRecorder) SetRolePolicy(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRolePolicy", reflect.TypeOf((*MockMFARepo)(nil).SetRolePolicy), ctx, policy)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockMFARepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockMFARepoMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockMFARepo)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockMFARepo) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockMFARepoMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockMFARepo)(nil).UseTOTPStep), ctx, userID, step)
+}
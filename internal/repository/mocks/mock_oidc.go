@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/oidc.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOIDCRepo is a mock of OIDCRepo interface.
+type MockOIDCRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCRepoMockRecorder
+}
+
+// MockOIDCRepoMockRecorder is the mock recorder for MockOIDCRepo.
+type MockOIDCRepoMockRecorder struct {
+	mock *MockOIDCRepo
+}
+
+// NewMockOIDCRepo creates a new mock instance.
+func NewMockOIDCRepo(ctrl *gomock.Controller) *MockOIDCRepo {
+	mock := &MockOIDCRepo{ctrl: ctrl}
+	mock.recorder = &MockOIDCRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCRepo) EXPECT() *MockOIDCRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateLogin mocks base method.
+func (m *MockOIDCRepo) CreateLogin(ctx context.Context, login *model.OIDCLogin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLogin", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLogin indicates an expected call of CreateLogin.
+func (mr *MockOIDCRepoMockRecorder) CreateLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLogin", reflect.TypeOf((*MockOIDCRepo)(nil).CreateLogin), ctx, login)
+}
+
+// GetIdentity mocks base method.
+func (m *MockOIDCRepo) GetIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdentity", ctx, provider, subject)
+	ret0, _ := ret[0].(*model.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentity indicates an expected call of GetIdentity.
+func (mr *MockOIDCRepoMockRecorder) GetIdentity(ctx, provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentity", reflect.TypeOf((*MockOIDCRepo)(nil).GetIdentity), ctx, provider, subject)
+}
+
+// LinkIdentity mocks base method.
+func (m *MockOIDCRepo) LinkIdentity(ctx context.Context, identity *model.UserIdentity, claimPasswordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkIdentity", ctx, identity, claimPasswordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkIdentity indicates an expected call of LinkIdentity.
+func (mr *MockOIDCRepoMockRecorder) LinkIdentity(ctx, identity, claimPasswordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkIdentity", reflect.TypeOf((*MockOIDCRepo)(nil).LinkIdentity), ctx, identity, claimPasswordHash)
+}
+
+// ProvisionUser mocks base method.
+func (m *MockOIDCRepo) ProvisionUser(ctx context.Context, user *model.User, identity *model.UserIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvisionUser", ctx, user, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProvisionUser indicates an expected call of ProvisionUser.
+func (mr *MockOIDCRepoMockRecorder) ProvisionUser(ctx, user, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvisionUser", reflect.TypeOf((*MockOIDCRepo)(nil).ProvisionUser), ctx, user, identity)
+}
+
+// UseLogin mocks base method.
+func (m *MockOIDCRepo) UseLogin(ctx context.Context, provider, stateHash string) (*model.OIDCLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLogin", ctx, provider, stateHash)
+	ret0, _ := ret[0].(*model.OIDCLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseLogin indicates an expected call of UseLogin.
+func (mr *MockOIDCRepoMockRecorder) UseLogin(ctx, provider, stateHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLogin", reflect.TypeOf((*MockOIDCRepo)(nil).UseLogin), ctx, provider, stateHash)
+}
@@ -0,0 +1,212 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/outbox.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+	repository "tigerhall_kittens/internal/repository"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockOutboxRepo is a mock of OutboxRepo interface.
+type MockOutboxRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepoMockRecorder
+}
+
+// MockOutboxRepoMockRecorder is the mock recorder for MockOutboxRepo.
+type MockOutboxRepoMockRecorder struct {
+	mock *MockOutboxRepo
+}
+
+// NewMockOutboxRepo creates a new mock instance.
+func NewMockOutboxRepo(ctrl *gomock.Controller) *MockOutboxRepo {
+	mock := &MockOutboxRepo{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepo) EXPECT() *MockOutboxRepoMockRecorder {
+	return m.recorder
+}
+
+// ClaimNotifications mocks base method.
+func (m *MockOutboxRepo) ClaimNotifications(ctx context.Context, opts repository.ClaimOutboxOpts) ([]model.OutboxNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimNotifications", ctx, opts)
+	ret0, _ := ret[0].([]model.OutboxNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimNotifications indicates an expected call of ClaimNotifications.
+func (mr *MockOutboxRepoMockRecorder) ClaimNotifications(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimNotifications", reflect.TypeOf((*MockOutboxRepo)(nil).ClaimNotifications), ctx, opts)
+}
+
+// CreateDigest mocks base method.
+func (m *MockOutboxRepo) CreateDigest(ctx context.Context, userID uuid.UUID, build repository.DigestBuilder) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDigest", ctx, userID, build)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDigest indicates an expected call of CreateDigest.
+func (mr *MockOutboxRepoMockRecorder) CreateDigest(ctx, userID, build interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDigest", reflect.TypeOf((*MockOutboxRepo)(nil).CreateDigest), ctx, userID, build)
+}
+
+// Defer mocks base method.
+func (m *MockOutboxRepo) Defer(ctx context.Context, id uuid.UUID, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Defer", ctx, id, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Defer indicates an expected call of Defer.
+func (mr *MockOutboxRepoMockRecorder) Defer(ctx, id, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Defer", reflect.TypeOf((*MockOutboxRepo)(nil).Defer), ctx, id, until)
+}
+
+// GetDigestUserIDs mocks base method.
+func (m *MockOutboxRepo) GetDigestUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDigestUserIDs", ctx)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDigestUserIDs indicates an expected call of GetDigestUserIDs.
+func (mr *MockOutboxRepoMockRecorder) GetDigestUserIDs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDigestUserIDs", reflect.TypeOf((*MockOutboxRepo)(nil).GetDigestUserIDs), ctx)
+}
+
+// GetNotification mocks base method.
+func (m *MockOutboxRepo) GetNotification(ctx context.Context, id uuid.UUID) (*model.OutboxNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotification", ctx, id)
+	ret0, _ := ret[0].(*model.OutboxNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotification indicates an expected call of GetNotification.
+func (mr *MockOutboxRepoMockRecorder) GetNotification(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotification", reflect.TypeOf((*MockOutboxRepo)(nil).GetNotification), ctx, id)
+}
+
+// GetNotifications mocks base method.
+func (m *MockOutboxRepo) GetNotifications(ctx context.Context, opts repository.ListOutboxOpts) ([]model.OutboxNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, opts)
+	ret0, _ := ret[0].([]model.OutboxNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockOutboxRepoMockRecorder) GetNotifications(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockOutboxRepo)(nil).GetNotifications), ctx, opts)
+}
+
+// HoldForDigest mocks base method.
+func (m *MockOutboxRepo) HoldForDigest(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HoldForDigest", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HoldForDigest indicates an expected call of HoldForDigest.
+func (mr *MockOutboxRepoMockRecorder) HoldForDigest(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HoldForDigest", reflect.TypeOf((*MockOutboxRepo)(nil).HoldForDigest), ctx, id)
+}
+
+// MarkDelivered mocks base method.
+func (m *MockOutboxRepo) MarkDelivered(ctx context.Context, id uuid.UUID, channels []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDelivered", ctx, id, channels)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDelivered indicates an expected call of MarkDelivered.
+func (mr *MockOutboxRepoMockRecorder) MarkDelivered(ctx, id, channels interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDelivered", reflect.TypeOf((*MockOutboxRepo)(nil).MarkDelivered), ctx, id, channels)
+}
+
+// MarkFailed mocks base method.
+func (m *MockOutboxRepo) MarkFailed(ctx context.Context, id uuid.UUID, status model.OutboxStatus, nextAttemptAt time.Time, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, id, status, nextAttemptAt, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockOutboxRepoMockRecorder) MarkFailed(ctx, id, status, nextAttemptAt, lastErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutboxRepo)(nil).MarkFailed), ctx, id, status, nextAttemptAt, lastErr)
+}
+
+// MarkSent mocks base method.
+func (m *MockOutboxRepo) MarkSent(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockOutboxRepoMockRecorder) MarkSent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockOutboxRepo)(nil).MarkSent), ctx, id)
+}
+
+// MarkSkipped mocks base method.
+func (m *MockOutboxRepo) MarkSkipped(ctx context.Context, id uuid.UUID, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSkipped", ctx, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSkipped indicates an expected call of MarkSkipped.
+func (mr *MockOutboxRepoMockRecorder) MarkSkipped(ctx, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSkipped", reflect.TypeOf((*MockOutboxRepo)(nil).MarkSkipped), ctx, id, reason)
+}
+
+// ReplayNotification mocks base method.
+func (m *MockOutboxRepo) ReplayNotification(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayNotification", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayNotification indicates an expected call of ReplayNotification.
+func (mr *MockOutboxRepoMockRecorder) ReplayNotification(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayNotification", reflect.TypeOf((*MockOutboxRepo)(nil).ReplayNotification), ctx, id)
+}
@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/preferences.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockPreferencesRepo is a mock of PreferencesRepo interface.
+type MockPreferencesRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreferencesRepoMockRecorder
+}
+
+// MockPreferencesRepoMockRecorder is the mock recorder for MockPreferencesRepo.
+type MockPreferencesRepoMockRecorder struct {
+	mock *MockPreferencesRepo
+}
+
+// NewMockPreferencesRepo creates a new mock instance.
+func NewMockPreferencesRepo(ctrl *gomock.Controller) *MockPreferencesRepo {
+	mock := &MockPreferencesRepo{ctrl: ctrl}
+	mock.recorder = &MockPreferencesRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPreferencesRepo) EXPECT() *MockPreferencesRepoMockRecorder {
+	return m.recorder
+}
+
+// GetMutedTigers mocks base method.
+func (m *MockPreferencesRepo) GetMutedTigers(ctx context.Context, userID uuid.UUID) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMutedTigers", ctx, userID)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMutedTigers indicates an expected call of GetMutedTigers.
+func (mr *MockPreferencesRepoMockRecorder) GetMutedTigers(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMutedTigers", reflect.TypeOf((*MockPreferencesRepo)(nil).GetMutedTigers), ctx, userID)
+}
+
+// GetPreferences mocks base method.
+func (m *MockPreferencesRepo) GetPreferences(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userID)
+	ret0, _ := ret[0].(*model.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockPreferencesRepoMockRecorder) GetPreferences(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockPreferencesRepo)(nil).GetPreferences), ctx, userID)
+}
+
+// IsTigerMuted mocks base method.
+func (m *MockPreferencesRepo) IsTigerMuted(ctx context.Context, userID uuid.UUID, tigerID uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTigerMuted", ctx, userID, tigerID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTigerMuted indicates an expected call of IsTigerMuted.
+func (mr *MockPreferencesRepoMockRecorder) IsTigerMuted(ctx, userID, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTigerMuted", reflect.TypeOf((*MockPreferencesRepo)(nil).IsTigerMuted), ctx, userID, tigerID)
+}
+
+// MarkDigestSent mocks base method.
+func (m *MockPreferencesRepo) MarkDigestSent(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDigestSent", ctx, userID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDigestSent indicates an expected call of MarkDigestSent.
+func (mr *MockPreferencesRepoMockRecorder) MarkDigestSent(ctx, userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDigestSent", reflect.TypeOf((*MockPreferencesRepo)(nil).MarkDigestSent), ctx, userID, at)
+}
+
+// MuteTiger mocks base method.
+func (m *MockPreferencesRepo) MuteTiger(ctx context.Context, userID uuid.UUID, tigerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteTiger", ctx, userID, tigerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteTiger indicates an expected call of MuteTiger.
+func (mr *MockPreferencesRepoMockRecorder) MuteTiger(ctx, userID, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteTiger", reflect.TypeOf((*MockPreferencesRepo)(nil).MuteTiger), ctx, userID, tigerID)
+}
+
+// SavePreferences mocks base method.
+func (m *MockPreferencesRepo) SavePreferences(ctx context.Context, preferences *model.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePreferences", ctx, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePreferences indicates an expected call of SavePreferences.
+func (mr *MockPreferencesRepoMockRecorder) SavePreferences(ctx, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferences", reflect.TypeOf((*MockPreferencesRepo)(nil).SavePreferences), ctx, preferences)
+}
+
+// UnmuteTiger mocks base method.
+func (m *MockPreferencesRepo) UnmuteTiger(ctx context.Context, userID uuid.UUID, tigerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteTiger", ctx, userID, tigerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteTiger indicates an expected call of UnmuteTiger.
+func (mr *MockPreferencesRepoMockRecorder) UnmuteTiger(ctx, userID, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteTiger", reflect.TypeOf((*MockPreferencesRepo)(nil).UnmuteTiger), ctx, userID, tigerID)
+}
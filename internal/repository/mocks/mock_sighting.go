@@ -11,6 +11,7 @@ import (
 	repository "tigerhall_kittens/internal/repository"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 )
 
 // MockSightingRepo is a mock of SightingRepo interface.
@@ -51,16 +52,31 @@ func (mr *MockSightingRepoMockRecorder) GetSightings(ctx, opts interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSightings", reflect.TypeOf((*MockSightingRepo)(nil).GetSightings), ctx, opts)
 }
 
+// GetUserSightings mocks base method.
+func (m *MockSightingRepo) GetUserSightings(ctx context.Context, userID uuid.UUID) ([]model.Sighting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSightings", ctx, userID)
+	ret0, _ := ret[0].([]model.Sighting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSightings indicates an expected call of GetUserSightings.
+func (mr *MockSightingRepoMockRecorder) GetUserSightings(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSightings", reflect.TypeOf((*MockSightingRepo)(nil).GetUserSightings), ctx, userID)
+}
+
 // ReportSighting mocks base method.
-func (m *MockSightingRepo) ReportSighting(ctx context.Context, sighting *model.Sighting) error {
+func (m *MockSightingRepo) ReportSighting(ctx context.Context, sighting *model.Sighting, notifications []model.OutboxNotification, geofenceAlert repository.GeofenceAlertBuilder) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReportSighting", ctx, sighting)
+	ret := m.ctrl.Call(m, "ReportSighting", ctx, sighting, notifications, geofenceAlert)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ReportSighting indicates an expected call of ReportSighting.
-func (mr *MockSightingRepoMockRecorder) ReportSighting(ctx, sighting interface{}) *gomock.Call {
+func (mr *MockSightingRepoMockRecorder) ReportSighting(ctx, sighting, notifications, geofenceAlert interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSighting", reflect.TypeOf((*MockSightingRepo)(nil).ReportSighting), ctx, sighting)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSighting", reflect.TypeOf((*MockSightingRepo)(nil).ReportSighting), ctx, sighting, notifications, geofenceAlert)
 }
@@ -36,6 +36,20 @@ func (m *MockTigerRepo) EXPECT() *MockTigerRepoMockRecorder {
 	return m.recorder
 }
 
+// DeleteTiger mocks base method.
+func (m *MockTigerRepo) DeleteTiger(ctx context.Context, tigerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTiger", ctx, tigerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTiger indicates an expected call of DeleteTiger.
+func (mr *MockTigerRepoMockRecorder) DeleteTiger(ctx, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTiger", reflect.TypeOf((*MockTigerRepo)(nil).DeleteTiger), ctx, tigerID)
+}
+
 // GetTiger mocks base method.
 func (m *MockTigerRepo) GetTiger(ctx context.Context, opts repository.GetTigerOpts) (*model.Tiger, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +80,36 @@ func (mr *MockTigerRepoMockRecorder) GetTigers(ctx, opts interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTigers", reflect.TypeOf((*MockTigerRepo)(nil).GetTigers), ctx, opts)
 }
 
+// RecomputeLastSeen mocks base method.
+func (m *MockTigerRepo) RecomputeLastSeen(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecomputeLastSeen", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecomputeLastSeen indicates an expected call of RecomputeLastSeen.
+func (mr *MockTigerRepoMockRecorder) RecomputeLastSeen(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecomputeLastSeen", reflect.TypeOf((*MockTigerRepo)(nil).RecomputeLastSeen), ctx)
+}
+
+// RestoreTiger mocks base method.
+func (m *MockTigerRepo) RestoreTiger(ctx context.Context, tigerID uint) (*model.Tiger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTiger", ctx, tigerID)
+	ret0, _ := ret[0].(*model.Tiger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreTiger indicates an expected call of RestoreTiger.
+func (mr *MockTigerRepoMockRecorder) RestoreTiger(ctx, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTiger", reflect.TypeOf((*MockTigerRepo)(nil).RestoreTiger), ctx, tigerID)
+}
+
 // SaveTiger mocks base method.
 func (m *MockTigerRepo) SaveTiger(ctx context.Context, tiger *model.Tiger) error {
 	m.ctrl.T.Helper()
@@ -79,3 +123,18 @@ func (mr *MockTigerRepoMockRecorder) SaveTiger(ctx, tiger interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTiger", reflect.TypeOf((*MockTigerRepo)(nil).SaveTiger), ctx, tiger)
 }
+
+// UpdateTiger mocks base method.
+func (m *MockTigerRepo) UpdateTiger(ctx context.Context, tigerID uint, version int, opts repository.UpdateTigerOpts) (*model.Tiger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTiger", ctx, tigerID, version, opts)
+	ret0, _ := ret[0].(*model.Tiger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTiger indicates an expected call of UpdateTiger.
+func (mr *MockTigerRepoMockRecorder) UpdateTiger(ctx, tigerID, version, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTiger", reflect.TypeOf((*MockTigerRepo)(nil).UpdateTiger), ctx, tigerID, version, opts)
+}
@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/user_token.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockUserTokenRepo is a mock of UserTokenRepo interface.
+type MockUserTokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserTokenRepoMockRecorder
+}
+
+// MockUserTokenRepoMockRecorder is the mock recorder for MockUserTokenRepo.
+type MockUserTokenRepoMockRecorder struct {
+	mock *MockUserTokenRepo
+}
+
+// NewMockUserTokenRepo creates a new mock instance.
+func NewMockUserTokenRepo(ctrl *gomock.Controller) *MockUserTokenRepo {
+	mock := &MockUserTokenRepo{ctrl: ctrl}
+	mock.recorder = &MockUserTokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserTokenRepo) EXPECT() *MockUserTokenRepoMockRecorder {
+	return m.recorder
+}
+
+// ConfirmEmailChange mocks base method.
+func (m *MockUserTokenRepo) ConfirmEmailChange(ctx context.Context, tokenHash string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", ctx, tokenHash)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockUserTokenRepoMockRecorder) ConfirmEmailChange(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockUserTokenRepo)(nil).ConfirmEmailChange), ctx, tokenHash)
+}
+
+// CountTokens mocks base method.
+func (m *MockUserTokenRepo) CountTokens(ctx context.Context, userID uuid.UUID, purpose model.UserTokenPurpose, since time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTokens", ctx, userID, purpose, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTokens indicates an expected call of CountTokens.
+func (mr *MockUserTokenRepoMockRecorder) CountTokens(ctx, userID, purpose, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTokens", reflect.TypeOf((*MockUserTokenRepo)(nil).CountTokens), ctx, userID, purpose, since)
+}
+
+// CreateToken mocks base method.
+func (m *MockUserTokenRepo) CreateToken(ctx context.Context, token *model.UserToken, notification *model.OutboxNotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateToken", ctx, token, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateToken indicates an expected call of CreateToken.
+func (mr *MockUserTokenRepoMockRecorder) CreateToken(ctx, token, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockUserTokenRepo)(nil).CreateToken), ctx, token, notification)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserTokenRepo) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, tokenHash, passwordHash)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserTokenRepoMockRecorder) ResetPassword(ctx, tokenHash, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserTokenRepo)(nil).ResetPassword), ctx, tokenHash, passwordHash)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserTokenRepo) VerifyEmail(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, tokenHash)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserTokenRepoMockRecorder) VerifyEmail(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserTokenRepo)(nil).VerifyEmail), ctx, tokenHash)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type OIDCRepo interface {
+	CreateLogin(ctx context.Context, login *model.OIDCLogin) error
+	UseLogin(ctx context.Context, provider, stateHash string) (*model.OIDCLogin, error)
+	GetIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error)
+	LinkIdentity(ctx context.Context, identity *model.UserIdentity, claimPasswordHash string) error
+	ProvisionUser(ctx context.Context, user *model.User, identity *model.UserIdentity) error
+}
+
+type oidcRepo struct {
+	DB *gorm.DB
+}
+
+func NewOIDCRepo() OIDCRepo {
+	return &oidcRepo{DB: db.Get()}
+}
+
+func (t *oidcRepo) CreateLogin(ctx context.Context, login *model.OIDCLogin) error {
+	if err := t.DB.WithContext(ctx).Create(login).Error; err != nil {
+		logger.E(ctx, err, "Error while saving OIDC login", logger.Field("provider", login.Provider))
+		return err
+	}
+
+	return nil
+}
+
+// UseLogin deletes and returns the unexpired login of provider with the state, so that a state is
+// only ever used once. It returns gorm.ErrRecordNotFound when there is no such login.
+func (t *oidcRepo) UseLogin(ctx context.Context, provider, stateHash string) (*model.OIDCLogin, error) {
+	var login model.OIDCLogin
+
+	result := t.DB.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("provider = ? AND state_hash = ? AND expires_at > ?", provider, stateHash, time.Now()).
+		Delete(&login)
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while using OIDC login", logger.Field("provider", provider))
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &login, nil
+}
+
+func (t *oidcRepo) GetIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+
+	err := t.DB.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching user identity", logger.Field("provider", provider))
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// LinkIdentity links an existing user to an identity. An account whose email was never verified
+// is claimed by the identity proving it: claimPasswordHash, when given, replaces the password
+// whoever signed up with the address chose, the email is marked verified and every session of
+// the account is revoked.
+func (t *oidcRepo) LinkIdentity(ctx context.Context, identity *model.UserIdentity, claimPasswordHash string) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(identity).Error; err != nil {
+			return err
+		}
+
+		if claimPasswordHash == "" {
+			return nil
+		}
+
+		now := time.Now()
+		err := tx.Model(&model.User{}).
+			Where("id = ?", identity.UserID).
+			Updates(map[string]interface{}{
+				"password":            claimPasswordHash,
+				"sessions_revoked_at": now,
+				"email_verified_at":   now,
+				"updated_at":          now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", identity.UserID).
+			Update("revoked_at", now).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while linking user identity", logger.Field("user_id", identity.UserID))
+		return err
+	}
+
+	return nil
+}
+
+// ProvisionUser creates a user along with the identity they signed in with
+func (t *oidcRepo) ProvisionUser(ctx context.Context, user *model.User, identity *model.UserIdentity) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(identity).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while provisioning user", logger.Field("provider", identity.Provider))
+		return err
+	}
+
+	return nil
+}
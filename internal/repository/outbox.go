@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type ClaimOutboxOpts struct {
+	Limit int
+	// Lease is how long a claimed row stays invisible to other workers. If the
+	// claiming worker dies before reporting back, the row is picked up again
+	// once the lease runs out.
+	Lease time.Duration
+}
+
+type ListOutboxOpts struct {
+	Status model.OutboxStatus
+	Limit  int
+	Offset int
+}
+
+// DigestBuilder builds the digest notification for the notifications held back for a user
+type DigestBuilder func(held []model.OutboxNotification) (*model.OutboxNotification, error)
+
+type OutboxRepo interface {
+	ClaimNotifications(ctx context.Context, opts ClaimOutboxOpts) ([]model.OutboxNotification, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, status model.OutboxStatus, nextAttemptAt time.Time, lastErr string) error
+	MarkDelivered(ctx context.Context, id uuid.UUID, channels []string) error
+	GetNotifications(ctx context.Context, opts ListOutboxOpts) ([]model.OutboxNotification, error)
+	GetNotification(ctx context.Context, id uuid.UUID) (*model.OutboxNotification, error)
+	ReplayNotification(ctx context.Context, id uuid.UUID) error
+	MarkSkipped(ctx context.Context, id uuid.UUID, reason string) error
+	HoldForDigest(ctx context.Context, id uuid.UUID) error
+	Defer(ctx context.Context, id uuid.UUID, until time.Time) error
+	GetDigestUserIDs(ctx context.Context) ([]uuid.UUID, error)
+	CreateDigest(ctx context.Context, userID uuid.UUID, build DigestBuilder) (bool, error)
+}
+
+type outboxRepo struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepo() OutboxRepo {
+	return &outboxRepo{DB: db.Get()}
+}
+
+// ClaimNotifications locks a batch of due notifications with FOR UPDATE SKIP LOCKED,
+// bumps their attempt count and pushes next_attempt_at past the lease, so that
+// concurrent workers never pick the same rows.
+func (t *outboxRepo) ClaimNotifications(ctx context.Context, opts ClaimOutboxOpts) ([]model.OutboxNotification, error) {
+	var notifications []model.OutboxNotification
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, now).
+			Order("next_attempt_at").
+			Limit(opts.Limit).
+			Find(&notifications).Error
+		if err != nil {
+			return err
+		}
+
+		if len(notifications) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(notifications))
+		for i := range notifications {
+			ids = append(ids, notifications[i].ID)
+			notifications[i].Attempts++
+			notifications[i].NextAttemptAt = now.Add(opts.Lease)
+		}
+
+		return tx.Model(&model.OutboxNotification{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": now.Add(opts.Lease),
+				"updated_at":      now,
+			}).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while claiming outbox notifications")
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// sealedPayloadKeys are the payload fields holding a sealed one-time token, see
+// notification_worker.SealedToken. They are dropped once a notification is sent or dead, so the
+// token can not be recovered from the outbox even with the key.
+var sealedPayloadKeys = pq.StringArray{"token", "verify_url", "reset_url", "confirm_url"}
+
+// MarkSent marks the notification as sent and drops its one-time token
+func (t *outboxRepo) MarkSent(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+
+	err := t.DB.WithContext(ctx).Model(&model.OutboxNotification{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     model.OutboxStatusSent,
+			"sent_at":    now,
+			"last_error": nil,
+			"payload":    gorm.Expr("payload - ?::text[]", sealedPayloadKeys),
+			"updated_at": now,
+		}).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while marking outbox notification as sent", logger.Field("notification_id", id))
+		return err
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt, a notification given up on as dead also drops its
+// one-time token
+func (t *outboxRepo) MarkFailed(ctx context.Context, id uuid.UUID, status model.OutboxStatus, nextAttemptAt time.Time, lastErr string) error {
+	updates := map[string]interface{}{
+		"status":          status,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastErr,
+		"updated_at":      time.Now(),
+	}
+
+	if status != model.OutboxStatusPending {
+		updates["payload"] = gorm.Expr("payload - ?::text[]", sealedPayloadKeys)
+	}
+
+	err := t.DB.WithContext(ctx).Model(&model.OutboxNotification{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while marking outbox notification as failed", logger.Field("notification_id", id))
+		return err
+	}
+
+	return nil
+}
+
+// MarkDelivered records the channels the notification has been delivered on so far, a retry
+// skips them
+func (t *outboxRepo) MarkDelivered(ctx context.Context, id uuid.UUID, channels []string) error {
+	err := t.DB.WithContext(ctx).Model(&model.OutboxNotification{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"delivered_channels": pq.StringArray(channels),
+			"updated_at":         time.Now(),
+		}).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while recording delivered channels of outbox notification", logger.Field("notification_id", id))
+		return err
+	}
+
+	return nil
+}
+
+func (t *outboxRepo) GetNotifications(ctx context.Context, opts ListOutboxOpts) ([]model.OutboxNotification, error) {
+	var notifications []model.OutboxNotification
+
+	query := t.DB.WithContext(ctx).Order("updated_at desc")
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+
+	if opts.Limit != 0 {
+		query = query.Limit(opts.Limit).Offset(opts.Offset)
+	}
+
+	err := query.Find(&notifications).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching outbox notifications")
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (t *outboxRepo) GetNotification(ctx context.Context, id uuid.UUID) (*model.OutboxNotification, error) {
+	var notification model.OutboxNotification
+
+	err := t.DB.WithContext(ctx).Where("id = ?", id).First(&notification).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.E(ctx, err, "Error while fetching outbox notification", logger.Field("notification_id", id))
+		}
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// ReplayNotification moves a dead-lettered notification back to the pending
+// state with a fresh attempt budget.
+func (t *outboxRepo) ReplayNotification(ctx context.Context, id uuid.UUID) error {
+	result := t.DB.WithContext(ctx).Model(&model.OutboxNotification{}).
+		Where("id = ? AND status = ?", id, model.OutboxStatusDead).
+		Updates(map[string]interface{}{
+			"status":          model.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while replaying outbox notification", logger.Field("notification_id", id))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *outboxRepo) MarkSkipped(ctx context.Context, id uuid.UUID, reason string) error {
+	err := t.DB.WithContext(ctx).Model(&model.OutboxNotification{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     model.OutboxStatusSkipped,
+			"last_error": reason,
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while marking outbox notification as skipped", logger.Field("notification_id", id))
+		return err
+	}
+
+	return nil
+}
+
+// HoldForDigest parks a notification until the recipient's next digest is built
+func (t *outboxRepo) HoldForDigest(ctx context.Context, id uuid.UUID) error {
+	err := t.DB.WithContext(ctx).Model(&model.OutboxNotification{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     model.OutboxStatusDigest,
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while holding outbox notification for digest", logger.Field("notification_id", id))
+		return err
+	}
+
+	return nil
+}
+
+// Defer postpones a claimed notification without using up the attempt the claim counted
+func (t *outboxRepo) Defer(ctx context.Context, id uuid.UUID, until time.Time) error {
+	err := t.DB.WithContext(ctx).Model(&model.OutboxNotification{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("GREATEST(attempts - 1, 0)"),
+			"next_attempt_at": until,
+			"updated_at":      time.Now(),
+		}).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while deferring outbox notification", logger.Field("notification_id", id))
+		return err
+	}
+
+	return nil
+}
+
+// GetDigestUserIDs lists the users who have notifications held back for a digest
+func (t *outboxRepo) GetDigestUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+
+	err := t.DB.WithContext(ctx).Model(&model.OutboxNotification{}).
+		Where("status = ?", model.OutboxStatusDigest).
+		Distinct().
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching digest recipients")
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// CreateDigest locks the notifications held back for the user, queues the digest built from
+// them and marks them as digested, all in one transaction so no notification is digested twice.
+// It reports whether a digest was queued, there is none when nothing is held back for the user.
+func (t *outboxRepo) CreateDigest(ctx context.Context, userID uuid.UUID, build DigestBuilder) (bool, error) {
+	var created bool
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var held []model.OutboxNotification
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("user_id = ? AND status = ?", userID, model.OutboxStatusDigest).
+			Order("created_at").
+			Find(&held).Error
+		if err != nil {
+			return err
+		}
+
+		if len(held) == 0 {
+			return nil
+		}
+
+		digest, err := build(held)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(digest).Error; err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, 0, len(held))
+		for _, notification := range held {
+			ids = append(ids, notification.ID)
+		}
+
+		created = true
+		return tx.Model(&model.OutboxNotification{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":     model.OutboxStatusDigested,
+				"updated_at": time.Now(),
+			}).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while creating notification digest", logger.Field("user_id", userID))
+		return false, err
+	}
+
+	return created, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type PreferencesRepo interface {
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, preferences *model.NotificationPreferences) error
+	MarkDigestSent(ctx context.Context, userID uuid.UUID, at time.Time) error
+	MuteTiger(ctx context.Context, userID uuid.UUID, tigerID uint) error
+	UnmuteTiger(ctx context.Context, userID uuid.UUID, tigerID uint) error
+	GetMutedTigers(ctx context.Context, userID uuid.UUID) ([]uint, error)
+	IsTigerMuted(ctx context.Context, userID uuid.UUID, tigerID uint) (bool, error)
+}
+
+type preferencesRepo struct {
+	DB *gorm.DB
+}
+
+func NewPreferencesRepo() PreferencesRepo {
+	return &preferencesRepo{DB: db.Get()}
+}
+
+// GetPreferences returns the stored preferences of the user, or the defaults if they never changed them
+func (t *preferencesRepo) GetPreferences(ctx context.Context, userID uuid.UUID) (*model.NotificationPreferences, error) {
+	var preferences []model.NotificationPreferences
+
+	err := t.DB.WithContext(ctx).Where("user_id = ?", userID).Limit(1).Find(&preferences).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching notification preferences", logger.Field("user_id", userID))
+		return nil, err
+	}
+
+	if len(preferences) == 0 {
+		return model.DefaultNotificationPreferences(userID), nil
+	}
+
+	return &preferences[0], nil
+}
+
+// SavePreferences upserts the preferences of preferences.UserID, keeping when the last digest was sent
+func (t *preferencesRepo) SavePreferences(ctx context.Context, preferences *model.NotificationPreferences) error {
+	err := t.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"email_enabled", "webhook_enabled", "in_app_enabled", "delivery",
+			"quiet_hours_start", "quiet_hours_end", "time_zone", "updated_at",
+		}),
+	}).Create(preferences).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while saving notification preferences", logger.Field("user_id", preferences.UserID))
+		return err
+	}
+
+	return nil
+}
+
+func (t *preferencesRepo) MarkDigestSent(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	preferences := model.DefaultNotificationPreferences(userID)
+	preferences.LastDigestAt = &at
+	preferences.CreatedAt = at
+	preferences.UpdatedAt = at
+
+	err := t.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_digest_at"}),
+	}).Create(preferences).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while recording digest", logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+func (t *preferencesRepo) MuteTiger(ctx context.Context, userID uuid.UUID, tigerID uint) error {
+	err := t.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.NotificationTigerMute{
+		UserID:    userID,
+		TigerID:   tigerID,
+		CreatedAt: time.Now(),
+	}).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while muting tiger", logger.Field("tiger_id", tigerID))
+		return err
+	}
+
+	return nil
+}
+
+// UnmuteTiger returns gorm.ErrRecordNotFound when the tiger was not muted
+func (t *preferencesRepo) UnmuteTiger(ctx context.Context, userID uuid.UUID, tigerID uint) error {
+	result := t.DB.WithContext(ctx).Where("user_id = ? AND tiger_id = ?", userID, tigerID).Delete(&model.NotificationTigerMute{})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while unmuting tiger", logger.Field("tiger_id", tigerID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *preferencesRepo) GetMutedTigers(ctx context.Context, userID uuid.UUID) ([]uint, error) {
+	tigerIDs := []uint{}
+
+	err := t.DB.WithContext(ctx).Model(&model.NotificationTigerMute{}).Where("user_id = ?", userID).Order("tiger_id").Pluck("tiger_id", &tigerIDs).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching muted tigers", logger.Field("user_id", userID))
+		return nil, err
+	}
+
+	return tigerIDs, nil
+}
+
+func (t *preferencesRepo) IsTigerMuted(ctx context.Context, userID uuid.UUID, tigerID uint) (bool, error) {
+	var count int64
+
+	err := t.DB.WithContext(ctx).Model(&model.NotificationTigerMute{}).Where("user_id = ? AND tiger_id = ?", userID, tigerID).Count(&count).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while checking muted tiger", logger.Field("tiger_id", tigerID))
+		return false, err
+	}
+
+	return count > 0, nil
+}
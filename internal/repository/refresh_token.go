@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type RefreshTokenRepo interface {
+	CreateToken(ctx context.Context, token *model.RefreshToken) error
+	GetToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	RotateToken(ctx context.Context, id uuid.UUID, next *model.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID) error
+}
+
+type refreshTokenRepo struct {
+	DB *gorm.DB
+}
+
+func NewRefreshTokenRepo() RefreshTokenRepo {
+	return &refreshTokenRepo{DB: db.Get()}
+}
+
+func (t *refreshTokenRepo) CreateToken(ctx context.Context, token *model.RefreshToken) error {
+	if err := t.DB.WithContext(ctx).Create(token).Error; err != nil {
+		logger.E(ctx, err, "Error while saving refresh token", logger.Field("user_id", token.UserID))
+		return err
+	}
+
+	return nil
+}
+
+func (t *refreshTokenRepo) GetToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+
+	err := t.DB.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching refresh token")
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RotateToken marks the token as used and stores its successor in one transaction. Only one of
+// two concurrent rotations of the same token wins, the other gets gorm.ErrRecordNotFound.
+func (t *refreshTokenRepo) RotateToken(ctx context.Context, id uuid.UUID, next *model.RefreshToken) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Create(next).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while rotating refresh token", logger.Field("refresh_token_id", id))
+		return err
+	}
+
+	return nil
+}
+
+func (t *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	err := t.DB.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while revoking refresh token family", logger.Field("family_id", familyID))
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserTokens revokes every refresh token of the user, signing them out everywhere
+func (t *refreshTokenRepo) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	err := t.DB.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while revoking refresh tokens", logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"tigerhall_kittens/internal/db"
@@ -19,9 +21,13 @@ type GetSightingOpts struct {
 	ExcludeUserID string
 }
 
+// GeofenceAlertBuilder builds the notification for the owner of a geofence a new sighting falls in
+type GeofenceAlertBuilder func(geofence model.Geofence) (*model.OutboxNotification, error)
+
 type SightingRepo interface {
 	GetSightings(ctx context.Context, opts GetSightingOpts) ([]model.Sighting, error)
-	ReportSighting(ctx context.Context, sighting *model.Sighting) error
+	ReportSighting(ctx context.Context, sighting *model.Sighting, notifications []model.OutboxNotification, geofenceAlert GeofenceAlertBuilder) error
+	GetUserSightings(ctx context.Context, userID uuid.UUID) ([]model.Sighting, error)
 }
 
 type sightingRepo struct {
@@ -34,7 +40,7 @@ func NewSightingRepo() SightingRepo {
 
 func (t *sightingRepo) GetSightings(ctx context.Context, opts GetSightingOpts) ([]model.Sighting, error) {
 	var sightings []model.Sighting
-	query := t.DB.Order("sighted_at desc")
+	query := t.DB.WithContext(ctx).Order("sighted_at desc")
 
 	if opts.Limit != 0 {
 		query = query.Limit(opts.Limit).Offset(opts.Offset)
@@ -59,8 +65,57 @@ func (t *sightingRepo) GetSightings(ctx context.Context, opts GetSightingOpts) (
 	return sightings, nil
 }
 
-func (t *sightingRepo) ReportSighting(ctx context.Context, sighting *model.Sighting) error {
-	err := t.DB.Create(sighting).Error
+// GetUserSightings returns every sighting the user reported, newest first
+func (t *sightingRepo) GetUserSightings(ctx context.Context, userID uuid.UUID) ([]model.Sighting, error) {
+	var sightings []model.Sighting
+
+	err := t.DB.WithContext(ctx).Where("reported_by_user_id = ?", userID).Order("sighted_at desc").Find(&sightings).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching sightings of user", logger.Field("user_id", userID))
+		return nil, err
+	}
+
+	return sightings, nil
+}
+
+// ReportSighting stores the sighting together with the last seen fields of its tiger and the
+// notifications and webhook deliveries it triggers in a single transaction, so a notification
+// is never queued for a sighting that was rolled back, nor lost for one that was committed. Owners
+// of geofences the sighting falls in are alerted through geofenceAlert, unless they
+// are already among the recipients of notifications.
+func (t *sightingRepo) ReportSighting(ctx context.Context, sighting *model.Sighting, notifications []model.OutboxNotification, geofenceAlert GeofenceAlertBuilder) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(sighting).Error; err != nil {
+			return err
+		}
+
+		if err := updateTigerLastSeen(tx, sighting); err != nil {
+			return err
+		}
+
+		err := enqueueWebhookEvent(tx, model.WebhookEventSightingCreated, map[string]interface{}{
+			"sighting_id": sighting.ID,
+			"sighting":    sighting,
+		})
+		if err != nil {
+			return err
+		}
+
+		if geofenceAlert != nil {
+			alerts, err := t.geofenceAlerts(tx, sighting, notifications, geofenceAlert)
+			if err != nil {
+				return err
+			}
+			notifications = append(notifications, alerts...)
+		}
+
+		if len(notifications) == 0 {
+			return nil
+		}
+
+		return tx.Create(&notifications).Error
+	})
+
 	if err != nil {
 		logger.E(ctx, err, "Error while saving sighting")
 		return err
@@ -68,3 +123,38 @@ func (t *sightingRepo) ReportSighting(ctx context.Context, sighting *model.Sight
 
 	return nil
 }
+
+// geofenceAlerts matches the sighting against every geofence using the spatial index on
+// geofences.area, and builds one alert per matching user
+func (t *sightingRepo) geofenceAlerts(tx *gorm.DB, sighting *model.Sighting, notifications []model.OutboxNotification, geofenceAlert GeofenceAlertBuilder) ([]model.OutboxNotification, error) {
+	var geofences []model.Geofence
+
+	err := tx.Where("user_id != ? AND ST_Intersects(area, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography)",
+		sighting.ReportedByUserID, sighting.Lon, sighting.Lat).
+		Order("user_id, created_at").
+		Find(&geofences).Error
+	if err != nil {
+		return nil, err
+	}
+
+	notified := make(map[uuid.UUID]bool, len(notifications))
+	for _, notification := range notifications {
+		notified[notification.UserID] = true
+	}
+
+	var alerts []model.OutboxNotification
+	for _, geofence := range geofences {
+		if notified[geofence.UserID] {
+			continue
+		}
+		notified[geofence.UserID] = true
+
+		alert, err := geofenceAlert(geofence)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+
+	return alerts, nil
+}
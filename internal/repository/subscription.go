@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type ListSubscriptionsOpts struct {
+	UserID  uuid.UUID
+	TigerID uint
+	Limit   int
+	Offset  int
+}
+
+type SubscriptionRepo interface {
+	Subscribe(ctx context.Context, subscription *model.TigerSubscription) error
+	Unsubscribe(ctx context.Context, userID uuid.UUID, tigerID uint) error
+	GetSubscriptions(ctx context.Context, opts ListSubscriptionsOpts) ([]model.TigerSubscription, error)
+}
+
+type subscriptionRepo struct {
+	DB *gorm.DB
+}
+
+func NewSubscriptionRepo() SubscriptionRepo {
+	return &subscriptionRepo{DB: db.Get()}
+}
+
+// Subscribe is idempotent, subscribing to a tiger twice keeps the original subscription
+func (t *subscriptionRepo) Subscribe(ctx context.Context, subscription *model.TigerSubscription) error {
+	err := t.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(subscription).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while saving subscription", logger.Field("tiger_id", subscription.TigerID))
+		return err
+	}
+
+	return nil
+}
+
+// Unsubscribe returns gorm.ErrRecordNotFound when the user was not subscribed to the tiger
+func (t *subscriptionRepo) Unsubscribe(ctx context.Context, userID uuid.UUID, tigerID uint) error {
+	result := t.DB.WithContext(ctx).Where("user_id = ? AND tiger_id = ?", userID, tigerID).Delete(&model.TigerSubscription{})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while deleting subscription", logger.Field("tiger_id", tigerID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *subscriptionRepo) GetSubscriptions(ctx context.Context, opts ListSubscriptionsOpts) ([]model.TigerSubscription, error) {
+	var subscriptions []model.TigerSubscription
+
+	query := t.DB.WithContext(ctx).Order("created_at desc")
+	if opts.UserID != uuid.Nil {
+		query = query.Where("user_id = ?", opts.UserID)
+	}
+
+	if opts.TigerID != 0 {
+		query = query.Where("tiger_id = ?", opts.TigerID)
+	}
+
+	if opts.Limit != 0 {
+		query = query.Limit(opts.Limit).Offset(opts.Offset)
+	}
+
+	err := query.Find(&subscriptions).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching subscriptions")
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"tigerhall_kittens/internal/db"
 	"tigerhall_kittens/internal/logger"
@@ -12,16 +15,72 @@ import (
 type ListTigersOpts struct {
 	Limit  int
 	Offset int
+	Filter TigerFilter
+	Sort   TigerSort
+}
+
+// TigerFilter narrows down a tiger listing, every field that is set must match and zero fields
+// match everything. Deleted tigers are only listed when Status asks for them.
+type TigerFilter struct {
+	// Name matches names starting with it, ignoring case, or close to it by trigram similarity
+	Name       string
+	BornAfter  *time.Time
+	BornBefore *time.Time
+	SeenAfter  *time.Time
+	SeenBefore *time.Time
+	Within     *BoundingBox
+	Near       *Radius
+	Status     model.TigerStatus
+}
+
+// BoundingBox matches tigers last seen inside it, edges included
+type BoundingBox struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// Radius matches tigers last seen at most Meters away from a point
+type Radius struct {
+	Lat    float64
+	Lon    float64
+	Meters float64
+}
+
+type TigerSortField string
+
+const (
+	TigerSortName     TigerSortField = "name"
+	TigerSortAge      TigerSortField = "age"
+	TigerSortLastSeen TigerSortField = "last_seen"
+)
+
+// TigerSort orders a tiger listing. Without a field tigers are listed by how well they match the
+// name filter when there is one, most recently seen first otherwise.
+type TigerSort struct {
+	Field      TigerSortField
+	Descending bool
 }
 
 type GetTigerOpts struct {
 	TigerID uint
 }
 
+// UpdateTigerOpts holds the fields to change, nil fields are left as they are
+type UpdateTigerOpts struct {
+	Name        *string
+	DateOfBirth *time.Time
+}
+
 type TigerRepo interface {
 	SaveTiger(ctx context.Context, tiger *model.Tiger) error
 	GetTiger(ctx context.Context, opts GetTigerOpts) (*model.Tiger, error)
 	GetTigers(ctx context.Context, opts ListTigersOpts) ([]model.Tiger, error)
+	UpdateTiger(ctx context.Context, tigerID uint, version int, opts UpdateTigerOpts) (*model.Tiger, error)
+	DeleteTiger(ctx context.Context, tigerID uint) error
+	RestoreTiger(ctx context.Context, tigerID uint) (*model.Tiger, error)
+	RecomputeLastSeen(ctx context.Context) (int64, error)
 }
 
 type tigerRepo struct {
@@ -32,23 +91,38 @@ func NewTigerRepo() TigerRepo {
 	return &tigerRepo{DB: db.Get()}
 }
 
+// SaveTiger creates the tiger and queues its tiger.created webhook deliveries in one transaction
 func (t *tigerRepo) SaveTiger(ctx context.Context, tiger *model.Tiger) error {
-	err := t.DB.Create(tiger).Error
+	tiger.Version = 1
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tiger).Error; err != nil {
+			return err
+		}
+
+		return enqueueWebhookEvent(tx, model.WebhookEventTigerCreated, map[string]interface{}{
+			"tiger_id": tiger.ID,
+			"tiger":    tiger,
+		})
+	})
 	if err != nil {
-		logger.E(ctx, err, "Error while saving user")
+		logger.E(ctx, err, "Error while saving tiger")
 		return err
 	}
 
 	return nil
 }
 
+// GetTiger returns gorm.ErrRecordNotFound for a missing or deleted tiger
 func (t *tigerRepo) GetTiger(ctx context.Context, opts GetTigerOpts) (*model.Tiger, error) {
 	var tiger model.Tiger
 
-	queryErr := t.DB.Where(model.Tiger{ID: opts.TigerID}).Find(&tiger).Error
-	if queryErr != nil {
-		logger.E(ctx, queryErr, "Error while fetching tigers")
-		return nil, queryErr
+	err := t.DB.WithContext(ctx).Where(model.Tiger{ID: opts.TigerID}).First(&tiger).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.E(ctx, err, "Error while fetching tiger", logger.Field("tiger_id", opts.TigerID))
+		}
+		return nil, err
 	}
 
 	return &tiger, nil
@@ -57,7 +131,10 @@ func (t *tigerRepo) GetTiger(ctx context.Context, opts GetTigerOpts) (*model.Tig
 func (t *tigerRepo) GetTigers(ctx context.Context, opts ListTigersOpts) ([]model.Tiger, error) {
 	var tigers []model.Tiger
 
-	queryErr := t.DB.Limit(opts.Limit).Offset(opts.Offset).Order("last_seen_timestamp desc").Find(&tigers).Error
+	query := opts.Filter.apply(t.DB.WithContext(ctx))
+	query = opts.Sort.apply(query, opts.Filter)
+
+	queryErr := query.Limit(opts.Limit).Offset(opts.Offset).Find(&tigers).Error
 	if queryErr != nil {
 		logger.E(ctx, queryErr, "Error while fetching tigers")
 		return nil, queryErr
@@ -65,3 +142,197 @@ func (t *tigerRepo) GetTigers(ctx context.Context, opts ListTigersOpts) ([]model
 
 	return tigers, nil
 }
+
+func (f TigerFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Name != "" {
+		query = query.Where("(name ILIKE ? OR name % ?)", likeEscaper.Replace(f.Name)+"%", f.Name)
+	}
+
+	if f.BornAfter != nil {
+		query = query.Where("date_of_birth >= ?", *f.BornAfter)
+	}
+
+	if f.BornBefore != nil {
+		query = query.Where("date_of_birth <= ?", *f.BornBefore)
+	}
+
+	if f.SeenAfter != nil {
+		query = query.Where("last_seen_timestamp >= ?", *f.SeenAfter)
+	}
+
+	if f.SeenBefore != nil {
+		query = query.Where("last_seen_timestamp <= ?", *f.SeenBefore)
+	}
+
+	if f.Within != nil {
+		query = query.Where("last_seen_lat BETWEEN ? AND ? AND last_seen_lon BETWEEN ? AND ?",
+			f.Within.MinLat, f.Within.MaxLat, f.Within.MinLon, f.Within.MaxLon)
+	}
+
+	if f.Near != nil {
+		query = query.Where("ST_DWithin(ST_MakePoint(last_seen_lon, last_seen_lat)::geography, ST_MakePoint(?, ?)::geography, ?)",
+			f.Near.Lon, f.Near.Lat, f.Near.Meters)
+	}
+
+	switch f.Status {
+	case model.TigerStatusDeleted:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	case model.TigerStatusAll:
+		query = query.Unscoped()
+	}
+
+	return query
+}
+
+func (s TigerSort) apply(query *gorm.DB, filter TigerFilter) *gorm.DB {
+	direction := "asc"
+	if s.Descending {
+		direction = "desc"
+	}
+
+	switch s.Field {
+	case TigerSortName:
+		return query.Order("name " + direction + ", id " + direction)
+	case TigerSortAge:
+		// the oldest tiger is born first, so ascending age is descending date of birth
+		ageDirection := "desc"
+		if s.Descending {
+			ageDirection = "asc"
+		}
+		return query.Order("date_of_birth " + ageDirection + ", id " + direction)
+	case TigerSortLastSeen:
+		return query.Order("last_seen_timestamp " + direction + " NULLS LAST, id " + direction)
+	}
+
+	if filter.Name != "" {
+		return query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "name ILIKE ? DESC, similarity(name, ?) DESC, id",
+			Vars:               []interface{}{likeEscaper.Replace(filter.Name) + "%", filter.Name},
+			WithoutParentheses: true,
+		}})
+	}
+
+	return query.Order("last_seen_timestamp desc")
+}
+
+// UpdateTiger applies the changes only if the tiger is still at version, bumps its version and
+// queues its tiger.updated webhook deliveries in one transaction. A tiger that is deleted or at
+// another version is not found.
+func (t *tigerRepo) UpdateTiger(ctx context.Context, tigerID uint, version int, opts UpdateTigerOpts) (*model.Tiger, error) {
+	var tiger model.Tiger
+
+	updates := map[string]interface{}{
+		"version": gorm.Expr("version + 1"),
+	}
+
+	if opts.Name != nil {
+		updates["name"] = *opts.Name
+	}
+
+	if opts.DateOfBirth != nil {
+		updates["date_of_birth"] = *opts.DateOfBirth
+	}
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&tiger).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND version = ?", tigerID, version).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return enqueueWebhookEvent(tx, model.WebhookEventTigerUpdated, map[string]interface{}{
+			"tiger_id": tiger.ID,
+			"tiger":    tiger,
+		})
+	})
+	if err == gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while updating tiger", logger.Field("tiger_id", tigerID))
+		return nil, err
+	}
+
+	return &tiger, nil
+}
+
+// DeleteTiger soft deletes the tiger, its sightings are kept
+func (t *tigerRepo) DeleteTiger(ctx context.Context, tigerID uint) error {
+	result := t.DB.WithContext(ctx).Delete(&model.Tiger{}, tigerID)
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while deleting tiger", logger.Field("tiger_id", tigerID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RestoreTiger undoes the soft delete of a tiger, a tiger that is not deleted is not found
+func (t *tigerRepo) RestoreTiger(ctx context.Context, tigerID uint) (*model.Tiger, error) {
+	var tiger model.Tiger
+
+	result := t.DB.WithContext(ctx).Unscoped().Model(&tiger).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND deleted_at IS NOT NULL", tigerID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while restoring tiger", logger.Field("tiger_id", tigerID))
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &tiger, nil
+}
+
+// RecomputeLastSeen sets the last seen fields of every tiger, deleted ones included, to its
+// latest sighting and returns how many tigers changed. Tigers that were never sighted keep the
+// last seen fields they were created with.
+func (t *tigerRepo) RecomputeLastSeen(ctx context.Context) (int64, error) {
+	result := t.DB.WithContext(ctx).Exec(`
+		UPDATE tigers
+		SET last_seen_timestamp = latest.sighted_at,
+			last_seen_lat       = latest.lat,
+			last_seen_lon       = latest.lon,
+			updated_at          = ?
+		FROM (
+			SELECT DISTINCT ON (tiger_id) tiger_id, sighted_at, lat, lon
+			FROM sightings
+			ORDER BY tiger_id, sighted_at DESC, created_at DESC
+		) AS latest
+		WHERE tigers.id = latest.tiger_id
+			AND (tigers.last_seen_timestamp IS DISTINCT FROM latest.sighted_at
+				OR tigers.last_seen_lat IS DISTINCT FROM latest.lat
+				OR tigers.last_seen_lon IS DISTINCT FROM latest.lon)`, time.Now())
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while recomputing last seen of tigers")
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// updateTigerLastSeen moves the last seen fields of the tiger to the sighting, unless the tiger
+// was already seen at or after it, so a sighting reported late does not overwrite a newer one
+func updateTigerLastSeen(tx *gorm.DB, sighting *model.Sighting) error {
+	return tx.Model(&model.Tiger{}).
+		Where("id = ? AND (last_seen_timestamp IS NULL OR last_seen_timestamp < ?)", sighting.TigerID, sighting.SightedAt).
+		Updates(map[string]interface{}{
+			"last_seen_timestamp": sighting.SightedAt,
+			"last_seen_lat":       sighting.Lat,
+			"last_seen_lon":       sighting.Lon,
+		}).Error
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"tigerhall_kittens/internal/db"
 	"tigerhall_kittens/internal/logger"
@@ -12,13 +15,43 @@ import (
 )
 
 type GetUserOpts struct {
+	ID       uuid.UUID
 	Username string
 	Email    string
+	// WithDeleted also finds soft deleted users
+	WithDeleted bool
 }
 
 type UserRepo interface {
 	CreateUser(ctx context.Context, user *model.User) error
+	SignUpUser(ctx context.Context, user *model.User, token *model.UserToken, notification *model.OutboxNotification) error
 	GetUser(ctx context.Context, opts GetUserOpts) (*model.User, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, opts UpdateProfileOpts) (*model.User, error)
+	RequestEmailChange(ctx context.Context, userID uuid.UUID, email string, token *model.UserToken, notification *model.OutboxNotification) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	ListUsers(ctx context.Context, opts ListUsersOpts) ([]model.User, error)
+	SuspendUser(ctx context.Context, userID uuid.UUID) error
+	ReactivateUser(ctx context.Context, userID uuid.UUID) error
+	SetRole(ctx context.Context, userID uuid.UUID, role model.Role) error
+	RevokeSessions(ctx context.Context, userID uuid.UUID) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	EraseUser(ctx context.Context, userID uuid.UUID) (*model.User, error)
+}
+
+type ListUsersOpts struct {
+	// Query matches part of the username, email or display name
+	Query  string
+	Role   model.Role
+	Status model.UserStatus
+	After  *Cursor
+	Limit  int
+}
+
+// UpdateProfileOpts holds the profile fields to change, nil fields are left alone
+type UpdateProfileOpts struct {
+	DisplayName  *string
+	Organisation *string
+	TimeZone     *string
 }
 
 type userRepo struct {
@@ -30,7 +63,7 @@ func NewUserRepo() UserRepo {
 }
 
 func (t *userRepo) CreateUser(ctx context.Context, user *model.User) error {
-	err := t.DB.Create(user).Error
+	err := t.DB.WithContext(ctx).Create(user).Error
 	if err != nil {
 		logger.E(ctx, err, "Error while creating user")
 		return err
@@ -39,19 +72,48 @@ func (t *userRepo) CreateUser(ctx context.Context, user *model.User) error {
 	return nil
 }
 
+// SignUpUser creates a pending user along with its email verification token and the notification
+// mailing it, so that no account is left without a way to verify it
+func (t *userRepo) SignUpUser(ctx context.Context, user *model.User, token *model.UserToken, notification *model.OutboxNotification) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		return createUserToken(tx, token, notification)
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while signing up user")
+		return err
+	}
+
+	return nil
+}
+
 func (t *userRepo) GetUser(ctx context.Context, opts GetUserOpts) (*model.User, error) {
 	var user model.User
 
-	var condition string
-	if opts.Username != "" {
-		condition = fmt.Sprintf("username = '%s'", opts.Username)
+	query := t.DB.WithContext(ctx)
+	if opts.WithDeleted {
+		query = query.Unscoped()
 	}
 
-	if opts.Email != "" {
-		condition = condition + fmt.Sprintf(" or email = '%s'", opts.Email)
+	if opts.ID != uuid.Nil {
+		query = query.Where("id = ?", opts.ID)
 	}
 
-	err := t.DB.Where(condition).First(&user).Error
+	// a user matching either the username or the email is a hit
+	switch {
+	case opts.Username != "" && opts.Email != "":
+		query = query.Where("username = ? OR email = ?", opts.Username, opts.Email)
+	case opts.Username != "":
+		query = query.Where("username = ?", opts.Username)
+	case opts.Email != "":
+		query = query.Where("email = ?", opts.Email)
+	}
+
+	err := query.First(&user).Error
 	if err != nil {
 		logger.E(ctx, err, "Error while fetching user")
 		return nil, err
@@ -59,3 +121,338 @@ func (t *userRepo) GetUser(ctx context.Context, opts GetUserOpts) (*model.User,
 
 	return &user, nil
 }
+
+// UpdateProfile changes the given profile fields and returns the updated user
+func (t *userRepo) UpdateProfile(ctx context.Context, userID uuid.UUID, opts UpdateProfileOpts) (*model.User, error) {
+	var user model.User
+
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if opts.DisplayName != nil {
+		updates["display_name"] = *opts.DisplayName
+	}
+
+	if opts.Organisation != nil {
+		updates["organisation"] = *opts.Organisation
+	}
+
+	if opts.TimeZone != nil {
+		updates["time_zone"] = *opts.TimeZone
+	}
+
+	result := t.DB.WithContext(ctx).Model(&user).
+		Clauses(clause.Returning{}).
+		Where("id = ?", userID).
+		Updates(updates)
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while updating profile", logger.Field("user_id", userID))
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &user, nil
+}
+
+// RequestEmailChange sets the pending email of the user along with the token confirming it and
+// the notification mailing it there. A previous pending email is replaced and its token retired.
+func (t *userRepo) RequestEmailChange(ctx context.Context, userID uuid.UUID, email string, token *model.UserToken, notification *model.OutboxNotification) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"pending_email": email,
+				"updated_at":    time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return createUserToken(tx, token, notification)
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while requesting email change", logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// ChangePassword sets the password of the user and signs them out everywhere, like ResetPassword
+func (t *userRepo) ChangePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateAndSignOut(tx.Where("id = ?", userID), userID, map[string]interface{}{"password": passwordHash})
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while changing password", logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// ListUsers returns the newest users first, starting after opts.After when it is set. Deleted users
+// are only listed when asked for by their status.
+func (t *userRepo) ListUsers(ctx context.Context, opts ListUsersOpts) ([]model.User, error) {
+	var users []model.User
+
+	query := t.DB.WithContext(ctx).
+		Where("id <> ?", model.TombstoneUserID).
+		Order("created_at desc, id desc").
+		Limit(opts.Limit)
+	if opts.Query != "" {
+		pattern := "%" + likeEscaper.Replace(opts.Query) + "%"
+		query = query.Where("username ILIKE ? OR email ILIKE ? OR display_name ILIKE ?", pattern, pattern, pattern)
+	}
+
+	if opts.Role != "" {
+		query = query.Where("role = ?", opts.Role)
+	}
+
+	switch opts.Status {
+	case model.UserStatusPending:
+		query = query.Where("email_verified_at IS NULL AND suspended_at IS NULL")
+	case model.UserStatusActive:
+		query = query.Where("email_verified_at IS NOT NULL AND suspended_at IS NULL")
+	case model.UserStatusSuspended:
+		query = query.Where("suspended_at IS NOT NULL")
+	case model.UserStatusDeleted:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if opts.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", opts.After.CreatedAt, opts.After.ID)
+	}
+
+	if err := query.Find(&users).Error; err != nil {
+		logger.E(ctx, err, "Error while listing users")
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// SuspendUser suspends a user who is not suspended yet and signs them out everywhere
+func (t *userRepo) SuspendUser(ctx context.Context, userID uuid.UUID) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateAndSignOut(tx.Where("id = ? AND suspended_at IS NULL", userID), userID,
+			map[string]interface{}{"suspended_at": time.Now()})
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while suspending user", logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// ReactivateUser lifts the suspension of a user, the sessions revoked by it stay revoked
+func (t *userRepo) ReactivateUser(ctx context.Context, userID uuid.UUID) error {
+	result := t.DB.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND suspended_at IS NOT NULL", userID).
+		Updates(map[string]interface{}{
+			"suspended_at": nil,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while reactivating user", logger.Field("user_id", userID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *userRepo) SetRole(ctx context.Context, userID uuid.UUID, role model.Role) error {
+	result := t.DB.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"role":       role,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while changing role", logger.Field("user_id", userID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RevokeSessions signs the user out everywhere
+func (t *userRepo) RevokeSessions(ctx context.Context, userID uuid.UUID) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateAndSignOut(tx.Where("id = ?", userID), userID, nil)
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while revoking sessions", logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUser soft deletes the user and signs them out everywhere. A deleted user is not found by
+// any other method of the repo except ListUsers.
+func (t *userRepo) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updateAndSignOut(tx.Where("id = ?", userID), userID, nil); err != nil {
+			return err
+		}
+
+		return tx.Delete(&model.User{}, "id = ?", userID).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while deleting user", logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// personalTables hold rows that only mean something to their user, erasing the user deletes them
+var personalTables = []string{
+	"refresh_tokens",
+	"user_tokens",
+	"api_keys",
+	"mfa_recovery_codes",
+	"user_identities",
+	"tiger_subscriptions",
+	"geofences",
+	"notification_preferences",
+	"notification_tiger_mutes",
+	"inbox_notifications",
+	"notification_outbox",
+	"data_exports",
+}
+
+// personalAuditEvents are the audit events whose reason holds personal data of the user
+var personalAuditEvents = []model.AuditEventType{
+	model.AuditEventEmailChangeRequested,
+	model.AuditEventEmailChanged,
+}
+
+// EraseUser anonymises the user and returns the anonymised row. Their sightings are reassigned to
+// the tombstone user, their audit events lose the username, IP and personal reasons, and every
+// other row about them is deleted. The users row is kept and soft deleted, so nothing depends on
+// ON DELETE CASCADE. Erasing a user twice is gorm.ErrRecordNotFound.
+func (t *userRepo) EraseUser(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	var user model.User
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		anonymous := "erased-" + userID.String()
+
+		result := tx.Unscoped().Model(&user).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND erased_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"username":            anonymous,
+				"email":               anonymous + "@erased.invalid",
+				"password":            "",
+				"display_name":        "",
+				"organisation":        "",
+				"time_zone":           "UTC",
+				"pending_email":       nil,
+				"totp_secret":         "",
+				"totp_last_step":      0,
+				"mfa_enabled_at":      nil,
+				"sessions_revoked_at": now,
+				"erased_at":           now,
+				"deleted_at":          gorm.Expr("COALESCE(deleted_at, ?)", now),
+				"updated_at":          now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Model(&model.Sighting{}).
+			Where("reported_by_user_id = ?", userID).
+			Updates(map[string]interface{}{
+				"reported_by_user_id": model.TombstoneUserID,
+				"updated_at":          now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.AuditEvent{}).
+			Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"username": anonymous, "ip": ""}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.AuditEvent{}).
+			Where("user_id = ? AND type IN ?", userID, personalAuditEvents).
+			Update("reason", "").Error
+		if err != nil {
+			return err
+		}
+
+		for _, table := range personalTables {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE user_id = ?", userID).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while erasing user", logger.Field("user_id", userID))
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// updateAndSignOut applies updates to the user matched by query and revokes the access tokens
+// issued so far and the refresh tokens of the user. No user matching is gorm.ErrRecordNotFound.
+func updateAndSignOut(query *gorm.DB, userID uuid.UUID, updates map[string]interface{}) error {
+	now := time.Now()
+	columns := map[string]interface{}{
+		"sessions_revoked_at": now,
+		"updated_at":          now,
+	}
+	for column, value := range updates {
+		columns[column] = value
+	}
+
+	result := query.Model(&model.User{}).Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	// the conditions of query are for the user only
+	return query.Session(&gorm.Session{NewDB: true}).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type UserTokenRepo interface {
+	CreateToken(ctx context.Context, token *model.UserToken, notification *model.OutboxNotification) error
+	CountTokens(ctx context.Context, userID uuid.UUID, purpose model.UserTokenPurpose, since time.Time) (int64, error)
+	VerifyEmail(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (uuid.UUID, error)
+	ConfirmEmailChange(ctx context.Context, tokenHash string) (*model.User, error)
+}
+
+type userTokenRepo struct {
+	DB *gorm.DB
+}
+
+func NewUserTokenRepo() UserTokenRepo {
+	return &userTokenRepo{DB: db.Get()}
+}
+
+// CreateToken stores the token together with the notification mailing it, and retires the
+// tokens for the same purpose the user was sent before so that only the latest one works
+func (t *userTokenRepo) CreateToken(ctx context.Context, token *model.UserToken, notification *model.OutboxNotification) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createUserToken(tx, token, notification)
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while saving user token", logger.Field("user_id", token.UserID))
+		return err
+	}
+
+	return nil
+}
+
+func createUserToken(tx *gorm.DB, token *model.UserToken, notification *model.OutboxNotification) error {
+	err := tx.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Create(token).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(notification).Error
+}
+
+// CountTokens counts the tokens for purpose the user was sent since the given time
+func (t *userTokenRepo) CountTokens(ctx context.Context, userID uuid.UUID, purpose model.UserTokenPurpose, since time.Time) (int64, error) {
+	var count int64
+
+	err := t.DB.WithContext(ctx).Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at >= ?", userID, purpose, since).
+		Count(&count).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while counting user tokens", logger.Field("user_id", userID))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// VerifyEmail uses up an unexpired email verification token and marks the email address of its
+// user as verified, returning the user. An unknown, used or expired token is gorm.ErrRecordNotFound.
+func (t *userTokenRepo) VerifyEmail(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := useToken(tx, model.UserTokenPurposeEmailVerification, tokenHash)
+		if err != nil {
+			return err
+		}
+		userID = token.UserID
+
+		return tx.Model(&model.User{}).
+			Where("id = ? AND email_verified_at IS NULL", token.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while verifying email")
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+// ResetPassword uses up an unexpired password reset token, sets the new password of its user and
+// signs them out everywhere by revoking their refresh tokens and the access tokens issued so far.
+// Receiving the token proves the user owns the email address, so it is marked verified as well.
+func (t *userTokenRepo) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := useToken(tx, model.UserTokenPurposePasswordReset, tokenHash)
+		if err != nil {
+			return err
+		}
+		userID = token.UserID
+
+		now := time.Now()
+		err = tx.Model(&model.User{}).
+			Where("id = ?", token.UserID).
+			Updates(map[string]interface{}{
+				"password":            passwordHash,
+				"sessions_revoked_at": now,
+				"email_verified_at":   gorm.Expr("COALESCE(email_verified_at, ?)", now),
+				"updated_at":          now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", token.UserID).
+			Update("revoked_at", now).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while resetting password")
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+// ConfirmEmailChange uses up an unexpired email change token and makes the pending email of its
+// user their email, returning the updated user. An unknown, used or expired token, or one whose
+// change was cancelled, is gorm.ErrRecordNotFound.
+func (t *userTokenRepo) ConfirmEmailChange(ctx context.Context, tokenHash string) (*model.User, error) {
+	var user model.User
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := useToken(tx, model.UserTokenPurposeEmailChange, tokenHash)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&user).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND pending_email IS NOT NULL", token.UserID).
+			Updates(map[string]interface{}{
+				"email":             gorm.Expr("pending_email"),
+				"pending_email":     nil,
+				"email_verified_at": now,
+				"updated_at":        now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while confirming email change")
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// useToken marks a usable token as used, concurrent uses of the same token are serialised by the
+// row lock so only one of them succeeds
+func useToken(tx *gorm.DB, purpose model.UserTokenPurpose, tokenHash string) (*model.UserToken, error) {
+	var token model.UserToken
+
+	now := time.Now()
+	result := tx.Model(&token).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &token, nil
+}
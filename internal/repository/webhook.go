@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"tigerhall_kittens/internal/db"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+)
+
+type ListWebhooksOpts struct {
+	Limit  int
+	Offset int
+}
+
+type ListWebhookDeliveriesOpts struct {
+	SubscriptionID uuid.UUID
+	Status         model.WebhookDeliveryStatus
+	Limit          int
+	Offset         int
+}
+
+type WebhookRepo interface {
+	CreateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	UpdateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id uuid.UUID) (*model.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context, opts ListWebhooksOpts) ([]model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+
+	GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, opts ListWebhookDeliveriesOpts) ([]model.WebhookDelivery, error)
+	GetDeliveryAttempts(ctx context.Context, deliveryID uuid.UUID) ([]model.WebhookDeliveryAttempt, error)
+	Redeliver(ctx context.Context, id uuid.UUID) error
+
+	ClaimDeliveries(ctx context.Context, opts ClaimOutboxOpts) ([]model.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error
+}
+
+type webhookRepo struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepo() WebhookRepo {
+	return &webhookRepo{DB: db.Get()}
+}
+
+func (t *webhookRepo) CreateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	err := t.DB.WithContext(ctx).Create(subscription).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while saving webhook subscription")
+		return err
+	}
+
+	return nil
+}
+
+// UpdateSubscription saves the URL, secret, events and state of the subscription, it returns
+// gorm.ErrRecordNotFound when the subscription does not exist
+func (t *webhookRepo) UpdateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	subscription.UpdatedAt = time.Now()
+
+	result := t.DB.WithContext(ctx).Model(&model.WebhookSubscription{}).
+		Where("id = ?", subscription.ID).
+		Updates(map[string]interface{}{
+			"url":        subscription.URL,
+			"secret":     subscription.Secret,
+			"events":     subscription.Events,
+			"active":     subscription.Active,
+			"updated_at": subscription.UpdatedAt,
+		})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while updating webhook subscription", logger.Field("webhook_id", subscription.ID))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *webhookRepo) GetSubscription(ctx context.Context, id uuid.UUID) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+
+	err := t.DB.WithContext(ctx).Where("id = ?", id).First(&subscription).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.E(ctx, err, "Error while fetching webhook subscription", logger.Field("webhook_id", id))
+		}
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (t *webhookRepo) GetSubscriptions(ctx context.Context, opts ListWebhooksOpts) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+
+	query := t.DB.WithContext(ctx).Order("created_at desc")
+	if opts.Limit != 0 {
+		query = query.Limit(opts.Limit).Offset(opts.Offset)
+	}
+
+	err := query.Find(&subscriptions).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching webhook subscriptions")
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteSubscription deletes the subscription along with its delivery log
+func (t *webhookRepo) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	result := t.DB.WithContext(ctx).Where("id = ?", id).Delete(&model.WebhookSubscription{})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while deleting webhook subscription", logger.Field("webhook_id", id))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *webhookRepo) GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+
+	err := t.DB.WithContext(ctx).Where("id = ?", id).First(&delivery).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.E(ctx, err, "Error while fetching webhook delivery", logger.Field("delivery_id", id))
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (t *webhookRepo) GetDeliveries(ctx context.Context, opts ListWebhookDeliveriesOpts) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+
+	query := t.DB.WithContext(ctx).Where("subscription_id = ?", opts.SubscriptionID).Order("created_at desc")
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+
+	if opts.Limit != 0 {
+		query = query.Limit(opts.Limit).Offset(opts.Offset)
+	}
+
+	err := query.Find(&deliveries).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching webhook deliveries", logger.Field("webhook_id", opts.SubscriptionID))
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (t *webhookRepo) GetDeliveryAttempts(ctx context.Context, deliveryID uuid.UUID) ([]model.WebhookDeliveryAttempt, error) {
+	var attempts []model.WebhookDeliveryAttempt
+
+	err := t.DB.WithContext(ctx).Where("delivery_id = ?", deliveryID).Order("attempt").Find(&attempts).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching webhook delivery attempts", logger.Field("delivery_id", deliveryID))
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// Redeliver queues a delivery again with a fresh attempt budget, whatever became of it before
+func (t *webhookRepo) Redeliver(ctx context.Context, id uuid.UUID) error {
+	result := t.DB.WithContext(ctx).Model(&model.WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          model.WebhookDeliveryStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		logger.E(ctx, result.Error, "Error while redelivering webhook", logger.Field("delivery_id", id))
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ClaimDeliveries claims due deliveries to active subscriptions the way ClaimNotifications claims
+// notifications, and loads the subscription of each
+func (t *webhookRepo) ClaimDeliveries(ctx context.Context, opts ClaimOutboxOpts) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryStatusPending, now).
+			Where("subscription_id IN (?)", tx.Model(&model.WebhookSubscription{}).Select("id").Where("active")).
+			Order("next_attempt_at").
+			Limit(opts.Limit).
+			Find(&deliveries).Error
+		if err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		subscriptionIDs := make([]uuid.UUID, 0, len(deliveries))
+		for i := range deliveries {
+			ids = append(ids, deliveries[i].ID)
+			subscriptionIDs = append(subscriptionIDs, deliveries[i].SubscriptionID)
+			deliveries[i].Attempts++
+			deliveries[i].NextAttemptAt = now.Add(opts.Lease)
+		}
+
+		var subscriptions []model.WebhookSubscription
+		if err := tx.Where("id IN ?", subscriptionIDs).Find(&subscriptions).Error; err != nil {
+			return err
+		}
+
+		byID := make(map[uuid.UUID]*model.WebhookSubscription, len(subscriptions))
+		for i := range subscriptions {
+			byID[subscriptions[i].ID] = &subscriptions[i]
+		}
+
+		for i := range deliveries {
+			deliveries[i].Subscription = byID[deliveries[i].SubscriptionID]
+		}
+
+		return tx.Model(&model.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": now.Add(opts.Lease),
+				"updated_at":      now,
+			}).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while claiming webhook deliveries")
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt logs an attempt at a delivery and saves the outcome on the delivery
+func (t *webhookRepo) RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error {
+	err := t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.WebhookDelivery{}).
+			Where("id = ?", delivery.ID).
+			Updates(map[string]interface{}{
+				"status":          delivery.Status,
+				"next_attempt_at": delivery.NextAttemptAt,
+				"response_code":   delivery.ResponseCode,
+				"last_error":      delivery.LastError,
+				"delivered_at":    delivery.DeliveredAt,
+				"updated_at":      time.Now(),
+			}).Error
+	})
+
+	if err != nil {
+		logger.E(ctx, err, "Error while recording webhook delivery attempt", logger.Field("delivery_id", delivery.ID))
+		return err
+	}
+
+	return nil
+}
+
+// enqueueWebhookEvent queues a delivery of the event to every active subscription that wants it.
+// It runs in the transaction of the change the event is about, like the notification outbox.
+func enqueueWebhookEvent(tx *gorm.DB, event string, data interface{}) error {
+	envelope := model.WebhookEnvelope{
+		ID:        uuid.New(),
+		Event:     event,
+		CreatedAt: time.Now(),
+		Data:      data,
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(`INSERT INTO webhook_deliveries (id, subscription_id, event_id, event, payload, status, attempts, next_attempt_at, created_at, updated_at)
+		SELECT gen_random_uuid()::text, id, ?, ?, ?::jsonb, ?, 0, ?, ?, ?
+		FROM webhook_subscriptions
+		WHERE active AND ? = ANY(events)`,
+		envelope.ID, event, string(payload), model.WebhookDeliveryStatusPending,
+		envelope.CreatedAt, envelope.CreatedAt, envelope.CreatedAt, event).Error
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/handler"
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+)
+
+// RegisterAPIKeyRoutes registers the endpoints managing API keys, they can only be used signed in
+// so that a leaked key can not mint more keys
+func RegisterAPIKeyRoutes(router *httprouter.Router) {
+	apiKeyHandler := handler.NewAPIKeyHandler()
+	signedIn := middleware.Chain(middleware.AuthMiddleware, middleware.RequireSession)
+	admin := middleware.Chain(middleware.Authorize(model.PermissionManageUsers), middleware.RequireSession)
+
+	router.POST("/api/v1/me/api-keys", middleware.ServeV1Endpoint(signedIn, apiKeyHandler.CreatePersonalKey))
+	router.GET("/api/v1/me/api-keys", middleware.ServeV1Endpoint(signedIn, apiKeyHandler.ListPersonalKeys))
+	router.DELETE("/api/v1/me/api-keys/:api_key_id", middleware.ServeV1Endpoint(signedIn, apiKeyHandler.RevokePersonalKey))
+	router.POST("/api/v1/admin/api-keys", middleware.ServeV1Endpoint(admin, apiKeyHandler.CreateServiceKey))
+	router.GET("/api/v1/admin/api-keys", middleware.ServeV1Endpoint(admin, apiKeyHandler.ListKeys))
+	router.DELETE("/api/v1/admin/api-keys/:api_key_id", middleware.ServeV1Endpoint(admin, apiKeyHandler.RevokeKey))
+}
@@ -5,9 +5,22 @@ import (
 
 	"tigerhall_kittens/internal/handler"
 	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
 )
 
 func RegisterAuthRoutes(router *httprouter.Router) {
 	authHandler := handler.NewAuthHandler()
 	router.POST("/api/v1/auth/login", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.Login))
+	router.POST("/api/v1/auth/refresh", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.Refresh))
+	router.POST("/api/v1/auth/logout", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.Logout))
+	router.POST("/api/v1/auth/mfa/enroll", middleware.ServeV1Endpoint(middleware.MFAEnrollmentMiddleware, authHandler.EnrollMFA))
+	router.POST("/api/v1/auth/mfa/confirm", middleware.ServeV1Endpoint(middleware.MFAEnrollmentMiddleware, authHandler.ConfirmMFA))
+	router.POST("/api/v1/auth/mfa/verify", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.VerifyMFA))
+	router.GET("/api/v1/auth/oidc/providers", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.ListOIDCProviders))
+	router.POST("/api/v1/auth/oidc/providers/:provider/authorize", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.StartOIDCLogin))
+	router.POST("/api/v1/auth/oidc/providers/:provider/callback", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, authHandler.FinishOIDCLogin))
+	router.GET("/api/v1/admin/mfa-policies", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageUsers), authHandler.ListRoleMFAPolicies))
+	router.PUT("/api/v1/admin/mfa-policies/:role", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageUsers), authHandler.SetRoleMFAPolicy))
+	router.GET("/.well-known/jwks.json", handler.JWKS)
+	router.POST("/api/v1/admin/users/:user_id/unlock", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageUsers), authHandler.UnlockUser))
 }
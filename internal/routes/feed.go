@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/handler"
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+)
+
+func RegisterFeedRoutes(router *httprouter.Router) {
+	feedHandler := handler.NewFeedHandler()
+	router.GET("/api/v1/sightings/stream", middleware.ServeV1Stream(middleware.Authorize(model.PermissionReadSightings), feedHandler.StreamSightings))
+	router.GET("/api/v1/sightings/ws", middleware.ServeV1Stream(middleware.Authorize(model.PermissionReadSightings), feedHandler.SightingsWebSocket))
+}
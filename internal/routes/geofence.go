@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/handler"
+	"tigerhall_kittens/internal/handler/middleware"
+)
+
+// RegisterGeofenceRoutes registers the endpoints for the logged-in user's geofences, they can only be used signed in
+// since no API key scope covers them
+func RegisterGeofenceRoutes(router *httprouter.Router) {
+	geofenceHandler := handler.NewGeofenceHandler()
+	signedIn := middleware.Chain(middleware.AuthMiddleware, middleware.RequireSession)
+	router.POST("/api/v1/geofences", middleware.ServeV1Endpoint(signedIn, geofenceHandler.CreateGeofence))
+	router.GET("/api/v1/geofences", middleware.ServeV1Endpoint(signedIn, geofenceHandler.ListGeofences))
+	router.GET("/api/v1/geofences/:geofence_id", middleware.ServeV1Endpoint(signedIn, geofenceHandler.GetGeofence))
+	router.PUT("/api/v1/geofences/:geofence_id", middleware.ServeV1Endpoint(signedIn, geofenceHandler.UpdateGeofence))
+	router.DELETE("/api/v1/geofences/:geofence_id", middleware.ServeV1Endpoint(signedIn, geofenceHandler.DeleteGeofence))
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/handler"
+	"tigerhall_kittens/internal/handler/middleware"
+)
+
+// RegisterInboxRoutes registers the endpoints for the logged-in user's inbox, they can only be used signed in
+// since no API key scope covers them
+func RegisterInboxRoutes(router *httprouter.Router) {
+	inboxHandler := handler.NewInboxHandler()
+	signedIn := middleware.Chain(middleware.AuthMiddleware, middleware.RequireSession)
+	router.GET("/api/v1/me/notifications", middleware.ServeV1Endpoint(signedIn, inboxHandler.ListNotifications))
+	router.GET("/api/v1/me/notifications/unread-count", middleware.ServeV1Endpoint(signedIn, inboxHandler.UnreadCount))
+	router.PUT("/api/v1/me/notifications/:notification_id/read", middleware.ServeV1Endpoint(signedIn, inboxHandler.MarkRead))
+	router.POST("/api/v1/me/notifications/read-all", middleware.ServeV1Endpoint(signedIn, inboxHandler.MarkAllRead))
+}
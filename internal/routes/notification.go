@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/handler"
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+)
+
+func RegisterNotificationRoutes(router *httprouter.Router) {
+	notificationHandler := handler.NewNotificationHandler()
+	router.GET("/api/v1/admin/notifications", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageNotifications), notificationHandler.ListOutboxNotifications))
+	router.POST("/api/v1/admin/notifications/:notification_id/replay", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageNotifications), notificationHandler.ReplayNotification))
+	router.GET("/api/v1/admin/notification-templates", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageNotifications), notificationHandler.ListTemplates))
+	router.GET("/api/v1/admin/notification-templates/:template/preview", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageNotifications), notificationHandler.PreviewTemplate))
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/handler"
+	"tigerhall_kittens/internal/handler/middleware"
+)
+
+// RegisterPreferencesRoutes registers the endpoints for the logged-in user's notification preferences and tiger mutes, they can only be used signed in
+// since no API key scope covers them
+func RegisterPreferencesRoutes(router *httprouter.Router) {
+	preferencesHandler := handler.NewPreferencesHandler()
+	signedIn := middleware.Chain(middleware.AuthMiddleware, middleware.RequireSession)
+	router.GET("/api/v1/me/notification-preferences", middleware.ServeV1Endpoint(signedIn, preferencesHandler.GetPreferences))
+	router.PUT("/api/v1/me/notification-preferences", middleware.ServeV1Endpoint(signedIn, preferencesHandler.UpdatePreferences))
+	router.POST("/api/v1/tigers/:tiger_id/mute", middleware.ServeV1Endpoint(signedIn, preferencesHandler.MuteTiger))
+	router.DELETE("/api/v1/tigers/:tiger_id/mute", middleware.ServeV1Endpoint(signedIn, preferencesHandler.UnmuteTiger))
+}
@@ -17,4 +17,12 @@ func Init(router *httprouter.Router) {
 	RegisterUserRoutes(router)
 	RegisterTigerRoutes(router)
 	RegisterSightingRoutes(router)
+	RegisterNotificationRoutes(router)
+	RegisterSubscriptionRoutes(router)
+	RegisterGeofenceRoutes(router)
+	RegisterPreferencesRoutes(router)
+	RegisterInboxRoutes(router)
+	RegisterFeedRoutes(router)
+	RegisterWebhookRoutes(router)
+	RegisterAPIKeyRoutes(router)
 }
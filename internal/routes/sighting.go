@@ -5,10 +5,11 @@ import (
 
 	"tigerhall_kittens/internal/handler"
 	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
 )
 
 func RegisterSightingRoutes(router *httprouter.Router) {
 	sightingHandler := handler.NewSightingHandler()
-	router.POST("/api/v1/sightings", middleware.ServeV1Endpoint(middleware.AuthMiddleware, sightingHandler.ReportSighting))
-	router.GET("/api/v1/tigers/:tiger_id/sightings", middleware.ServeV1Endpoint(middleware.AuthMiddleware, sightingHandler.GetSightings))
+	router.POST("/api/v1/sightings", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionWriteSightings), sightingHandler.ReportSighting))
+	router.GET("/api/v1/tigers/:tiger_id/sightings", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionReadSightings), sightingHandler.GetSightings))
 }
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/handler"
+	"tigerhall_kittens/internal/handler/middleware"
+)
+
+// RegisterSubscriptionRoutes registers the endpoints for the logged-in user's tiger subscriptions, they can only be used signed in
+// since no API key scope covers them
+func RegisterSubscriptionRoutes(router *httprouter.Router) {
+	subscriptionHandler := handler.NewSubscriptionHandler()
+	signedIn := middleware.Chain(middleware.AuthMiddleware, middleware.RequireSession)
+	router.POST("/api/v1/tigers/:tiger_id/subscriptions", middleware.ServeV1Endpoint(signedIn, subscriptionHandler.Subscribe))
+	router.DELETE("/api/v1/tigers/:tiger_id/subscriptions", middleware.ServeV1Endpoint(signedIn, subscriptionHandler.Unsubscribe))
+	router.GET("/api/v1/me/subscriptions", middleware.ServeV1Endpoint(signedIn, subscriptionHandler.ListSubscriptions))
+}
@@ -5,10 +5,15 @@ import (
 
 	"tigerhall_kittens/internal/handler"
 	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
 )
 
 func RegisterTigerRoutes(router *httprouter.Router) {
 	tigerHandler := handler.NewTigerHandler()
-	router.POST("/api/v1/tigers", middleware.ServeV1Endpoint(middleware.AuthMiddleware, tigerHandler.CreateTiger))
-	router.GET("/api/v1/tigers", middleware.ServeV1Endpoint(middleware.AuthMiddleware, tigerHandler.ListTigers))
+	router.POST("/api/v1/tigers", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionWriteTigers), tigerHandler.CreateTiger))
+	router.GET("/api/v1/tigers", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionReadTigers), tigerHandler.ListTigers))
+	router.GET("/api/v1/tigers/:tiger_id", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionReadTigers), tigerHandler.GetTiger))
+	router.PATCH("/api/v1/tigers/:tiger_id", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionWriteTigers), tigerHandler.UpdateTiger))
+	router.DELETE("/api/v1/tigers/:tiger_id", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionWriteTigers), tigerHandler.DeleteTiger))
+	router.POST("/api/v1/admin/tigers/:tiger_id/restore", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageTigers), tigerHandler.RestoreTiger))
 }
@@ -5,9 +5,34 @@ import (
 
 	"tigerhall_kittens/internal/handler"
 	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
 )
 
 func RegisterUserRoutes(router *httprouter.Router) {
 	userHandler := handler.NewUserHandler()
-	router.POST("/api/v1/users", middleware.ServeV1Endpoint(middleware.AuthMiddleware, userHandler.CreateUser))
+	router.POST("/api/v1/users", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageUsers), userHandler.CreateUser))
+	router.POST("/api/v1/auth/signup", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.SignUp))
+	router.POST("/api/v1/auth/verify-email", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.VerifyEmail))
+	router.POST("/api/v1/auth/resend-verification", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.ResendVerification))
+	router.POST("/api/v1/auth/forgot-password", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.ForgotPassword))
+	router.POST("/api/v1/auth/reset-password", middleware.ServeV1Endpoint(middleware.EmptyMiddleware, userHandler.ResetPassword))
+
+	// the account needs a session, API keys are limited to what their scopes allow
+	signedIn := middleware.Chain(middleware.AuthMiddleware, middleware.RequireSession)
+	router.GET("/api/v1/me", middleware.ServeV1Endpoint(signedIn, userHandler.GetProfile))
+	router.PATCH("/api/v1/me", middleware.ServeV1Endpoint(signedIn, userHandler.UpdateProfile))
+	router.POST("/api/v1/me/password", middleware.ServeV1Endpoint(signedIn, userHandler.ChangePassword))
+	router.POST("/api/v1/me/erase", middleware.ServeV1Endpoint(signedIn, userHandler.EraseAccount))
+	router.POST("/api/v1/me/exports", middleware.ServeV1Endpoint(signedIn, userHandler.RequestDataExport))
+	router.GET("/api/v1/me/exports/:export_id", middleware.ServeV1Endpoint(signedIn, userHandler.GetDataExport))
+	router.GET("/api/v1/me/exports/:export_id/download", middleware.ServeV1Stream(signedIn, userHandler.DownloadDataExport))
+
+	manageUsers := middleware.Authorize(model.PermissionManageUsers)
+	router.GET("/api/v1/admin/users", middleware.ServeV1Endpoint(manageUsers, userHandler.ListUsers))
+	router.POST("/api/v1/admin/users/:user_id/suspend", middleware.ServeV1Endpoint(manageUsers, userHandler.SuspendUser))
+	router.POST("/api/v1/admin/users/:user_id/reactivate", middleware.ServeV1Endpoint(manageUsers, userHandler.ReactivateUser))
+	router.PUT("/api/v1/admin/users/:user_id/role", middleware.ServeV1Endpoint(manageUsers, userHandler.ChangeRole))
+	router.POST("/api/v1/admin/users/:user_id/logout", middleware.ServeV1Endpoint(manageUsers, userHandler.RevokeSessions))
+	router.DELETE("/api/v1/admin/users/:user_id", middleware.ServeV1Endpoint(manageUsers, userHandler.DeleteUser))
+	router.POST("/api/v1/admin/users/:user_id/erase", middleware.ServeV1Endpoint(manageUsers, userHandler.EraseUser))
 }
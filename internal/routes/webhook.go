@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+
+	"tigerhall_kittens/internal/handler"
+	"tigerhall_kittens/internal/handler/middleware"
+	"tigerhall_kittens/internal/model"
+)
+
+func RegisterWebhookRoutes(router *httprouter.Router) {
+	webhookHandler := handler.NewWebhookHandler()
+	router.POST("/api/v1/admin/webhooks", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageWebhooks), webhookHandler.CreateWebhook))
+	router.GET("/api/v1/admin/webhooks", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageWebhooks), webhookHandler.ListWebhooks))
+	router.GET("/api/v1/admin/webhooks/:webhook_id", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageWebhooks), webhookHandler.GetWebhook))
+	router.PUT("/api/v1/admin/webhooks/:webhook_id", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageWebhooks), webhookHandler.UpdateWebhook))
+	router.DELETE("/api/v1/admin/webhooks/:webhook_id", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageWebhooks), webhookHandler.DeleteWebhook))
+	router.GET("/api/v1/admin/webhooks/:webhook_id/deliveries", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageWebhooks), webhookHandler.ListDeliveries))
+	router.GET("/api/v1/admin/webhook-deliveries/:delivery_id", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageWebhooks), webhookHandler.GetDelivery))
+	router.POST("/api/v1/admin/webhook-deliveries/:delivery_id/redeliver", middleware.ServeV1Endpoint(middleware.Authorize(model.PermissionManageWebhooks), webhookHandler.Redeliver))
+}
@@ -0,0 +1,118 @@
+// Package secretbox seals short secrets, like the one-time tokens queued in notification
+// payloads, with AES-256-GCM so reading the database does not reveal them
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// KeySize is the length of a key in bytes
+const KeySize = 32
+
+var (
+	ErrNoKey         = errors.New("no secret key")
+	ErrInvalidSealed = errors.New("invalid sealed secret")
+)
+
+// GenerateKey returns a new random key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ParseKey decodes a base64 encoded key
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("secret key is not base64 : %w", err)
+	}
+
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	return key, nil
+}
+
+var (
+	mu      sync.RWMutex
+	current cipher.AEAD
+)
+
+// Set replaces the key secrets are sealed and opened with
+func Set(key []byte) error {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	current = aead
+
+	return nil
+}
+
+func get() (cipher.AEAD, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if current == nil {
+		return nil, ErrNoKey
+	}
+
+	return current, nil
+}
+
+// Seal encrypts plaintext with a random nonce and returns it base64 encoded
+func Seal(plaintext string) (string, error) {
+	aead, err := get()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret returned by Seal
+func Open(sealed string) (string, error) {
+	aead, err := get()
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < aead.NonceSize() {
+		return "", ErrInvalidSealed
+	}
+
+	plaintext, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrInvalidSealed
+	}
+
+	return string(plaintext), nil
+}
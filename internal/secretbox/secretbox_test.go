@@ -0,0 +1,55 @@
+package secretbox
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealOpen(t *testing.T) {
+	key, err := GenerateKey()
+	assert.Nil(t, err)
+	assert.Nil(t, Set(key))
+
+	t.Run("should open what it sealed", func(t *testing.T) {
+		sealed, err := Seal("q8Xf3n1cV0d2kL7sT9wY4bR6uE5hJ2mA")
+		assert.Nil(t, err)
+		assert.NotContains(t, sealed, "q8Xf3n1cV0d2kL7sT9wY4bR6uE5hJ2mA")
+
+		opened, err := Open(sealed)
+		assert.Nil(t, err)
+		assert.Equal(t, "q8Xf3n1cV0d2kL7sT9wY4bR6uE5hJ2mA", opened)
+	})
+
+	t.Run("should not open a secret sealed with another key", func(t *testing.T) {
+		sealed, err := Seal("token")
+		assert.Nil(t, err)
+
+		otherKey, _ := GenerateKey()
+		assert.Nil(t, Set(otherKey))
+		defer func() { _ = Set(key) }()
+
+		_, err = Open(sealed)
+		assert.Equal(t, ErrInvalidSealed, err)
+	})
+
+	t.Run("should not open a tampered secret", func(t *testing.T) {
+		_, err := Open("not-sealed")
+		assert.Equal(t, ErrInvalidSealed, err)
+	})
+}
+
+func TestParseKey(t *testing.T) {
+	t.Run("should accept a base64 key of the right size only", func(t *testing.T) {
+		key, err := ParseKey(base64.StdEncoding.EncodeToString(make([]byte, KeySize)))
+		assert.Nil(t, err)
+		assert.Len(t, key, KeySize)
+
+		_, err = ParseKey(base64.StdEncoding.EncodeToString(make([]byte, 16)))
+		assert.NotNil(t, err)
+
+		_, err = ParseKey("%%%")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+const (
+	// API_KEY_PREFIX starts every key so that leaked keys are easy to spot
+	API_KEY_PREFIX = "thk_"
+	// API_KEY_SHOWN_LENGTH is how much of a key is kept in clear to tell keys apart
+	API_KEY_SHOWN_LENGTH = 12
+	MAX_API_KEY_NAME     = 100
+)
+
+// CreateAPIKeyReq describes a new key. UserID is the machine account a service key is for, a
+// personal key always belongs to the user creating it.
+type CreateAPIKeyReq struct {
+	UserID    uuid.UUID          `json:"user_id"`
+	Name      string             `json:"name"`
+	Scopes    []model.Permission `json:"scopes"`
+	ExpiresAt *time.Time         `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse is the only time the key is shown
+type CreateAPIKeyResponse struct {
+	Key    string        `json:"key"`
+	APIKey *model.APIKey `json:"api_key"`
+}
+
+type APIKeyService interface {
+	CreatePersonalKey(ctx context.Context, req CreateAPIKeyReq) (*CreateAPIKeyResponse, error)
+	CreateServiceKey(ctx context.Context, req CreateAPIKeyReq) (*CreateAPIKeyResponse, error)
+	ListPersonalKeys(ctx context.Context, opts repository.ListAPIKeysOpts) ([]model.APIKey, error)
+	ListKeys(ctx context.Context, opts repository.ListAPIKeysOpts) ([]model.APIKey, error)
+	RevokePersonalKey(ctx context.Context, keyID uuid.UUID) error
+	RevokeKey(ctx context.Context, keyID uuid.UUID) error
+	Authenticate(ctx context.Context, key string) (*model.Principal, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repository.APIKeyRepo
+	userRepo   repository.UserRepo
+	auditRepo  repository.AuditRepo
+}
+
+type APIKeyServiceOption func(service *apiKeyService)
+
+func NewAPIKeyService(options ...APIKeyServiceOption) APIKeyService {
+	service := &apiKeyService{
+		apiKeyRepo: repository.NewAPIKeyRepo(),
+		userRepo:   repository.NewUserRepo(),
+		auditRepo:  repository.NewAuditRepo(),
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+func WithAPIKeyRepo(repo repository.APIKeyRepo) APIKeyServiceOption {
+	return func(s *apiKeyService) {
+		s.apiKeyRepo = repo
+	}
+}
+
+func WithUserRepoForAPIKeyService(repo repository.UserRepo) APIKeyServiceOption {
+	return func(s *apiKeyService) {
+		s.userRepo = repo
+	}
+}
+
+func WithAuditRepoForAPIKeyService(repo repository.AuditRepo) APIKeyServiceOption {
+	return func(s *apiKeyService) {
+		s.auditRepo = repo
+	}
+}
+
+// CreatePersonalKey creates a key acting as the signed in user
+func (t *apiKeyService) CreatePersonalKey(ctx context.Context, req CreateAPIKeyReq) (*CreateAPIKeyResponse, error) {
+	req.UserID = uuid.MustParse(ctx.Value("userID").(string))
+
+	return t.createKey(ctx, model.APIKeyKindPersonal, req)
+}
+
+// CreateServiceKey creates a key acting as the machine account req.UserID, for admins. Keys acting as
+// people are personal keys, which their owners create and which stop with their sessions.
+func (t *apiKeyService) CreateServiceKey(ctx context.Context, req CreateAPIKeyReq) (*CreateAPIKeyResponse, error) {
+	return t.createKey(ctx, model.APIKeyKindService, req)
+}
+
+// createKey can only grant scopes the role of the owner has, a key never does more than its owner
+func (t *apiKeyService) createKey(ctx context.Context, kind model.APIKeyKind, req CreateAPIKeyReq) (*CreateAPIKeyResponse, error) {
+	owner, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: req.UserID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching API key owner", logger.Field("user_id", req.UserID))
+		return nil, err
+	}
+
+	if kind == model.APIKeyKindService && !owner.Machine {
+		logger.W(ctx, "Service API key for a person", logger.Field("user_id", owner.ID))
+		return nil, fmt.Errorf("%w : service keys can only act as machine accounts", ErrInvalidAPIKeyDetails)
+	}
+
+	if err := validateAPIKeyReq(owner, req); err != nil {
+		logger.W(ctx, "Invalid API key", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	key, err := newOpaqueToken()
+	if err != nil {
+		logger.E(ctx, err, "Error while generating API key")
+		return nil, err
+	}
+	key = API_KEY_PREFIX + key
+
+	creatorID := uuid.MustParse(ctx.Value("userID").(string))
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	apiKey := &model.APIKey{
+		ID:              uuid.New(),
+		UserID:          owner.ID,
+		Kind:            kind,
+		Name:            strings.TrimSpace(req.Name),
+		Prefix:          key[:API_KEY_SHOWN_LENGTH],
+		KeyHash:         hashToken(key),
+		Scopes:          scopes,
+		ExpiresAt:       req.ExpiresAt,
+		CreatedByUserID: &creatorID,
+		CreatedAt:       time.Now(),
+	}
+
+	if err := t.apiKeyRepo.CreateKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	t.audit(ctx, model.AuditEventAPIKeyCreated, apiKey)
+
+	return &CreateAPIKeyResponse{Key: key, APIKey: apiKey}, nil
+}
+
+func validateAPIKeyReq(owner *model.User, req CreateAPIKeyReq) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > MAX_API_KEY_NAME {
+		return fmt.Errorf("%w : name is required and can have at most %d characters", ErrInvalidAPIKeyDetails, MAX_API_KEY_NAME)
+	}
+
+	if len(req.Scopes) == 0 {
+		return fmt.Errorf("%w : at least one scope is required", ErrInvalidAPIKeyDetails)
+	}
+
+	for _, scope := range req.Scopes {
+		if !owner.Role.Can(scope) {
+			return fmt.Errorf("%w : scope %s is not granted to role %s", ErrInvalidAPIKeyDetails, scope, owner.Role)
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w : expires_at has to be in the future", ErrInvalidAPIKeyDetails)
+	}
+
+	return nil
+}
+
+// ListPersonalKeys lists the personal keys of the signed in user, newest first
+func (t *apiKeyService) ListPersonalKeys(ctx context.Context, opts repository.ListAPIKeysOpts) ([]model.APIKey, error) {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+	opts.UserID = &userID
+	opts.Kind = model.APIKeyKindPersonal
+
+	return t.ListKeys(ctx, opts)
+}
+
+// ListKeys lists keys of every kind and user, for admins
+func (t *apiKeyService) ListKeys(ctx context.Context, opts repository.ListAPIKeysOpts) ([]model.APIKey, error) {
+	keys, err := t.apiKeyRepo.ListKeys(ctx, opts)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching API keys", logger.Field("opts", opts))
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokePersonalKey revokes one of the personal keys of the signed in user
+func (t *apiKeyService) RevokePersonalKey(ctx context.Context, keyID uuid.UUID) error {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	return t.revokeKey(ctx, repository.RevokeAPIKeyOpts{ID: keyID, UserID: &userID, Kind: model.APIKeyKindPersonal})
+}
+
+// RevokeKey revokes any key, for admins
+func (t *apiKeyService) RevokeKey(ctx context.Context, keyID uuid.UUID) error {
+	return t.revokeKey(ctx, repository.RevokeAPIKeyOpts{ID: keyID})
+}
+
+func (t *apiKeyService) revokeKey(ctx context.Context, opts repository.RevokeAPIKeyOpts) error {
+	apiKey, err := t.apiKeyRepo.RevokeKey(ctx, opts)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "API key does not exist", logger.Field("api_key_id", opts.ID))
+		return ErrAPIKeyNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	t.audit(ctx, model.AuditEventAPIKeyRevoked, apiKey)
+
+	return nil
+}
+
+// Authenticate returns the principal a key acts as. Its owner is loaded on every use, so that a
+// change of role applies to their keys right away. Personal keys created before the sessions of
+// their owner were revoked, e.g. by a password reset, no longer work.
+func (t *apiKeyService) Authenticate(ctx context.Context, key string) (*model.Principal, error) {
+	if !strings.HasPrefix(key, API_KEY_PREFIX) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := t.apiKeyRepo.GetKeyByHash(ctx, hashToken(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	owner, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: apiKey.UserID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if owner.SuspendedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.Kind == model.APIKeyKindPersonal && owner.SessionsRevokedAt != nil && apiKey.CreatedAt.Before(*owner.SessionsRevokedAt) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	_ = t.apiKeyRepo.TouchKey(ctx, apiKey.ID, now)
+
+	scopes := make([]model.Permission, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, model.Permission(scope))
+	}
+
+	return &model.Principal{UserID: owner.ID, Role: owner.Role, APIKeyID: &apiKey.ID, Scopes: scopes}, nil
+}
+
+func (t *apiKeyService) audit(ctx context.Context, eventType model.AuditEventType, apiKey *model.APIKey) {
+	actorID := uuid.MustParse(ctx.Value("userID").(string))
+	event := &model.AuditEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		UserID:    &apiKey.UserID,
+		Reason:    apiKey.ID.String(),
+		CreatedAt: time.Now(),
+	}
+
+	if actorID != apiKey.UserID {
+		event.ActorID = &actorID
+	}
+
+	_ = t.auditRepo.CreateEvent(ctx, event)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestAPIKeyService_CreatePersonalKey(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Username: "ranger_ravi", Role: model.RoleReporter}
+	ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+	t.Run("should store only the hash of the key and return the key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		var saved *model.APIKey
+		mockAPIKeyRepo := mock_repository.NewMockAPIKeyRepo(ctrl)
+		mockAPIKeyRepo.EXPECT().CreateKey(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, key *model.APIKey) error {
+			saved = key
+			return nil
+		})
+
+		apiKeyService := NewAPIKeyService(WithAPIKeyRepo(mockAPIKeyRepo), WithUserRepoForAPIKeyService(mockUserRepo),
+			WithAuditRepoForAPIKeyService(anyAuditRepo(ctrl)))
+
+		resp, err := apiKeyService.CreatePersonalKey(ctx, CreateAPIKeyReq{
+			Name:   " camera trap uploader ",
+			Scopes: []model.Permission{model.PermissionWriteSightings, model.PermissionReadTigers},
+		})
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(resp.Key, API_KEY_PREFIX))
+		assert.Equal(t, hashToken(resp.Key), saved.KeyHash)
+		assert.Equal(t, resp.Key[:API_KEY_SHOWN_LENGTH], saved.Prefix)
+		assert.Equal(t, "camera trap uploader", saved.Name)
+		assert.Equal(t, model.APIKeyKindPersonal, saved.Kind)
+		assert.Equal(t, user.ID, saved.UserID)
+	})
+
+	t.Run("should return invalid details error for scopes the role does not have", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil).Times(3)
+
+		apiKeyService := NewAPIKeyService(WithAPIKeyRepo(mock_repository.NewMockAPIKeyRepo(ctrl)), WithUserRepoForAPIKeyService(mockUserRepo))
+
+		past := time.Now().Add(-time.Hour)
+		for _, req := range []CreateAPIKeyReq{
+			{Name: "etl", Scopes: []model.Permission{model.PermissionWriteTigers}},
+			{Name: "etl", Scopes: []model.Permission{"sightings:delete"}},
+			{Name: "etl", Scopes: []model.Permission{model.PermissionReadTigers}, ExpiresAt: &past},
+		} {
+			_, err := apiKeyService.CreatePersonalKey(ctx, req)
+			assert.True(t, errors.Is(err, ErrInvalidAPIKeyDetails))
+		}
+	})
+}
+
+func TestAPIKeyService_CreateServiceKey(t *testing.T) {
+	admin := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", admin.String())
+	req := CreateAPIKeyReq{Name: "camera trap 7", Scopes: []model.Permission{model.PermissionWriteSightings}}
+
+	t.Run("should refuse to create a service key acting as a person", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		person := &model.User{ID: uuid.New(), Username: "admin_asha", Role: model.RoleAdmin}
+		req := req
+		req.UserID = person.ID
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: person.ID}).Return(person, nil)
+
+		apiKeyService := NewAPIKeyService(WithAPIKeyRepo(mock_repository.NewMockAPIKeyRepo(ctrl)), WithUserRepoForAPIKeyService(mockUserRepo))
+
+		_, err := apiKeyService.CreateServiceKey(ctx, req)
+		assert.True(t, errors.Is(err, ErrInvalidAPIKeyDetails))
+	})
+
+	t.Run("should create a service key acting as a machine account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		machine := &model.User{ID: uuid.New(), Username: "camera_trap_7", Role: model.RoleReporter, Machine: true}
+		req := req
+		req.UserID = machine.ID
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: machine.ID}).Return(machine, nil)
+
+		var saved *model.APIKey
+		mockAPIKeyRepo := mock_repository.NewMockAPIKeyRepo(ctrl)
+		mockAPIKeyRepo.EXPECT().CreateKey(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, key *model.APIKey) error {
+			saved = key
+			return nil
+		})
+
+		apiKeyService := NewAPIKeyService(WithAPIKeyRepo(mockAPIKeyRepo), WithUserRepoForAPIKeyService(mockUserRepo),
+			WithAuditRepoForAPIKeyService(anyAuditRepo(ctrl)))
+
+		_, err := apiKeyService.CreateServiceKey(ctx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, model.APIKeyKindService, saved.Kind)
+		assert.Equal(t, machine.ID, saved.UserID)
+	})
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	key := API_KEY_PREFIX + "0123456789abcdef"
+	owner := &model.User{ID: uuid.New(), Role: model.RoleResearcher}
+
+	newKey := func(kind model.APIKeyKind) *model.APIKey {
+		return &model.APIKey{
+			ID:        uuid.New(),
+			UserID:    owner.ID,
+			Kind:      kind,
+			KeyHash:   hashToken(key),
+			Scopes:    []string{string(model.PermissionWriteSightings)},
+			CreatedAt: time.Now().Add(-time.Hour),
+		}
+	}
+
+	setup := func(ctrl *gomock.Controller, apiKey *model.APIKey, owner *model.User) APIKeyService {
+		mockAPIKeyRepo := mock_repository.NewMockAPIKeyRepo(ctrl)
+		mockAPIKeyRepo.EXPECT().GetKeyByHash(gomock.Any(), hashToken(key)).Return(apiKey, nil).AnyTimes()
+		mockAPIKeyRepo.EXPECT().TouchKey(gomock.Any(), apiKey.ID, gomock.Any()).Return(nil).AnyTimes()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(gomock.Any(), repository.GetUserOpts{ID: owner.ID}).Return(owner, nil).AnyTimes()
+
+		return NewAPIKeyService(WithAPIKeyRepo(mockAPIKeyRepo), WithUserRepoForAPIKeyService(mockUserRepo))
+	}
+
+	t.Run("should return the owner limited to the scopes of the key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		apiKey := newKey(model.APIKeyKindService)
+		principal, err := setup(ctrl, apiKey, owner).Authenticate(context.Background(), key)
+		assert.Nil(t, err)
+		assert.Equal(t, owner.ID, principal.UserID)
+		assert.Equal(t, &apiKey.ID, principal.APIKeyID)
+		assert.True(t, principal.Can(model.PermissionWriteSightings))
+		assert.False(t, principal.Can(model.PermissionWriteTigers))
+	})
+
+	t.Run("should reject revoked and expired keys", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		past := time.Now().Add(-time.Minute)
+
+		revoked := newKey(model.APIKeyKindService)
+		revoked.RevokedAt = &past
+		_, err := setup(ctrl, revoked, owner).Authenticate(context.Background(), key)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+
+		expired := newKey(model.APIKeyKindService)
+		expired.ExpiresAt = &past
+		_, err = setup(ctrl, expired, owner).Authenticate(context.Background(), key)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+
+	t.Run("should reject personal keys created before the sessions were revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		revokedAt := time.Now().Add(-time.Minute)
+		resetOwner := *owner
+		resetOwner.SessionsRevokedAt = &revokedAt
+
+		_, err := setup(ctrl, newKey(model.APIKeyKindPersonal), &resetOwner).Authenticate(context.Background(), key)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+
+		_, err = setup(ctrl, newKey(model.APIKeyKindService), &resetOwner).Authenticate(context.Background(), key)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should reject keys of suspended owners", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		suspendedAt := time.Now()
+		suspendedOwner := *owner
+		suspendedOwner.SuspendedAt = &suspendedAt
+
+		_, err := setup(ctrl, newKey(model.APIKeyKindService), &suspendedOwner).Authenticate(context.Background(), key)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+
+	t.Run("should reject unknown keys", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAPIKeyRepo := mock_repository.NewMockAPIKeyRepo(ctrl)
+		mockAPIKeyRepo.EXPECT().GetKeyByHash(gomock.Any(), gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+
+		apiKeyService := NewAPIKeyService(WithAPIKeyRepo(mockAPIKeyRepo))
+
+		_, err := apiKeyService.Authenticate(context.Background(), API_KEY_PREFIX+"unknown")
+		assert.Equal(t, ErrInvalidAPIKey, err)
+
+		_, err = apiKeyService.Authenticate(context.Background(), "not-a-key")
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	})
+}
+
+func TestAPIKeyService_RevokePersonalKey(t *testing.T) {
+	t.Run("should only revoke personal keys of the signed in user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userID := uuid.New()
+		keyID := uuid.New()
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockAPIKeyRepo := mock_repository.NewMockAPIKeyRepo(ctrl)
+		mockAPIKeyRepo.EXPECT().RevokeKey(ctx, repository.RevokeAPIKeyOpts{ID: keyID, UserID: &userID, Kind: model.APIKeyKindPersonal}).
+			Return(nil, gorm.ErrRecordNotFound)
+
+		apiKeyService := NewAPIKeyService(WithAPIKeyRepo(mockAPIKeyRepo))
+
+		err := apiKeyService.RevokePersonalKey(ctx, keyID)
+		assert.Equal(t, ErrAPIKeyNotFound, err)
+	})
+}
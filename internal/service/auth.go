@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,40 +16,137 @@ import (
 	"gorm.io/gorm"
 
 	"tigerhall_kittens/internal/config"
+	"tigerhall_kittens/internal/keyset"
 	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/oidc"
 	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/throttle"
 )
 
+const OPAQUE_TOKEN_BYTES = 32
+
 type LoginUserReq struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// IP is the client the login came from, failed logins are throttled per username and per IP
+	IP string `json:"-"`
+}
+
+// LoginThrottleConfig holds the policies for failed logins per username and per client IP. Many
+// users can share an IP, so its policy is usually the more lenient one.
+type LoginThrottleConfig struct {
+	Username throttle.Policy
+	IP       throttle.Policy
 }
 
 type LoginUserResponse struct {
-	AccessToken string `json:"access_token,omitempty"`
-	Error       error  `json:"error,omitempty"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+	// MFAToken replaces the tokens above when the login needs a second factor. It is exchanged
+	// at VerifyMFA, or when MFAEnrollmentRequired is set, it authenticates the enrollment.
+	MFAToken              string `json:"mfa_token,omitempty"`
+	MFARequired           bool   `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	Error                 error  `json:"error,omitempty"`
 }
 
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenPurpose tells access tokens apart from the short-lived tokens of an unfinished MFA login
+type TokenPurpose string
+
+const (
+	TokenPurposeAccess        TokenPurpose = ""
+	TokenPurposeMFA           TokenPurpose = "mfa"
+	TokenPurposeMFAEnrollment TokenPurpose = "mfa_enrollment"
+)
+
 // Claims represents the JWT claims
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	jwt.Claims
+	UserID  uuid.UUID    `json:"user_id"`
+	Role    model.Role   `json:"role"`
+	Purpose TokenPurpose `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
 }
 
-var JWTSecretKey = []byte(config.Env.SecretKey)
+// signingMethods are the JWT signing methods of the algorithms a keyset can hold
+var signingMethods = map[string]jwt.SigningMethod{
+	keyset.AlgorithmRS256: jwt.SigningMethodRS256,
+	keyset.AlgorithmEdDSA: jwt.SigningMethodEdDSA,
+}
 
 type AuthService interface {
 	LoginUser(ctx context.Context, req LoginUserReq) (*LoginUserResponse, error)
+	RefreshToken(ctx context.Context, req RefreshTokenReq) (*LoginUserResponse, error)
+	Logout(ctx context.Context, req RefreshTokenReq) error
+	Authenticate(ctx context.Context, token string, purposes ...TokenPurpose) (*Claims, error)
+	UnlockUser(ctx context.Context, userID uuid.UUID) error
+	EnrollMFA(ctx context.Context) (*MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, req ConfirmMFAReq) (*ConfirmMFAResponse, error)
+	VerifyMFA(ctx context.Context, req VerifyMFAReq) (*LoginUserResponse, error)
+	ListRoleMFAPolicies(ctx context.Context) ([]model.RoleMFAPolicy, error)
+	SetRoleMFAPolicy(ctx context.Context, req SetRoleMFAPolicyReq) error
+	ListOIDCProviders(ctx context.Context) []string
+	StartOIDCLogin(ctx context.Context, provider string) (*OIDCAuthorization, error)
+	FinishOIDCLogin(ctx context.Context, req OIDCCallbackReq) (*LoginUserResponse, error)
 }
 
 type authService struct {
-	userRepo repository.UserRepo
+	userRepo         repository.UserRepo
+	refreshTokenRepo repository.RefreshTokenRepo
+	auditRepo        repository.AuditRepo
+	mfaRepo          repository.MFARepo
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+	loginThrottle    throttle.Store
+	loginPolicies    LoginThrottleConfig
+	mfa              MFAConfig
+	oidcRepo         repository.OIDCRepo
+	oidcProviders    oidc.Providers
+	oidcLoginTTL     time.Duration
 }
 
 type AuthServiceOption func(service *authService)
 
 func NewAuthService(options ...AuthServiceOption) AuthService {
-	service := &authService{userRepo: repository.NewUserRepo()}
+	service := &authService{
+		userRepo:         repository.NewUserRepo(),
+		refreshTokenRepo: repository.NewRefreshTokenRepo(),
+		auditRepo:        repository.NewAuditRepo(),
+		mfaRepo:          repository.NewMFARepo(),
+		accessTokenTTL:   config.Env.AccessTokenTTL,
+		refreshTokenTTL:  config.Env.RefreshTokenTTL,
+		loginThrottle:    throttle.Get(),
+		loginPolicies: LoginThrottleConfig{
+			Username: throttle.Policy{
+				FreeFailures:    config.Env.LoginFreeFailures,
+				BaseDelay:       config.Env.LoginBaseDelay,
+				MaxDelay:        config.Env.LoginMaxDelay,
+				LockoutFailures: config.Env.LoginLockoutFailures,
+				LockoutDuration: config.Env.LoginLockoutDuration,
+			},
+			IP: throttle.Policy{
+				FreeFailures:    config.Env.LoginIPFreeFailures,
+				BaseDelay:       config.Env.LoginBaseDelay,
+				MaxDelay:        config.Env.LoginMaxDelay,
+				LockoutFailures: config.Env.LoginIPLockoutFailures,
+				LockoutDuration: config.Env.LoginLockoutDuration,
+			},
+		},
+		mfa: MFAConfig{
+			Issuer:        config.Env.MFAIssuer,
+			ChallengeTTL:  config.Env.MFAChallengeTTL,
+			RecoveryCodes: config.Env.MFARecoveryCodes,
+		},
+		oidcRepo:      repository.NewOIDCRepo(),
+		oidcProviders: oidc.Get(),
+		oidcLoginTTL:  config.Env.OIDCLoginTTL,
+	}
 
 	for _, option := range options {
 		option(service)
@@ -59,49 +161,465 @@ func WithUserRepoForAuthService(repo repository.UserRepo) AuthServiceOption {
 	}
 }
 
+func WithRefreshTokenRepo(repo repository.RefreshTokenRepo) AuthServiceOption {
+	return func(s *authService) {
+		s.refreshTokenRepo = repo
+	}
+}
+
+func WithTokenTTLs(accessTokenTTL, refreshTokenTTL time.Duration) AuthServiceOption {
+	return func(s *authService) {
+		s.accessTokenTTL = accessTokenTTL
+		s.refreshTokenTTL = refreshTokenTTL
+	}
+}
+
+func WithAuditRepo(repo repository.AuditRepo) AuthServiceOption {
+	return func(s *authService) {
+		s.auditRepo = repo
+	}
+}
+
+func WithLoginThrottle(store throttle.Store, policies LoginThrottleConfig) AuthServiceOption {
+	return func(s *authService) {
+		s.loginThrottle = store
+		s.loginPolicies = policies
+	}
+}
+
+func WithMFARepo(repo repository.MFARepo) AuthServiceOption {
+	return func(s *authService) {
+		s.mfaRepo = repo
+	}
+}
+
+func WithMFAConfig(cfg MFAConfig) AuthServiceOption {
+	return func(s *authService) {
+		s.mfa = cfg
+	}
+}
+
+func WithOIDCRepo(repo repository.OIDCRepo) AuthServiceOption {
+	return func(s *authService) {
+		s.oidcRepo = repo
+	}
+}
+
+func WithOIDCProviders(providers oidc.Providers, loginTTL time.Duration) AuthServiceOption {
+	return func(s *authService) {
+		s.oidcProviders = providers
+		s.oidcLoginTTL = loginTTL
+	}
+}
+
+// dummyPasswordHash is compared against for unknown usernames, so that refusing them takes as long
+// as refusing a wrong password and does not tell which accounts exist
+var dummyPasswordHash = []byte("$2a$10$YrVIB3XOniCJAaJV3sT4tOw6x2s8AduX3rnxHO1f31V.8n8FrZEC6")
+
+// LoginUser checks the credentials and starts a session, or for users with MFA, returns a
+// challenge to finish the login with at VerifyMFA. Every login is counted per username and per
+// client IP, unknown usernames included, before the password is checked, and once there are too
+// many failures further logins are refused without checking the password until a delay, or a
+// lockout, has passed. Every attempt is recorded as an audit event.
 func (t *authService) LoginUser(ctx context.Context, req LoginUserReq) (*LoginUserResponse, error) {
+	if err := t.countLoginAttempt(ctx, req); err != nil {
+		return nil, err
+	}
+
 	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{Username: req.Username})
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		logger.W(ctx, "User does not exist", logger.Field("username", req.Username))
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		t.audit(ctx, model.AuditEventLoginFailed, nil, nil, req, "unknown username")
 		return nil, err
 	}
 
 	if err != nil {
 		logger.W(ctx, "Error while getting user details", logger.Field("username", req.Username))
+		t.forgiveLoginAttempt(ctx, t.loginThrottleKeys(req))
 		return nil, err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
 		logger.W(ctx, "Invalid username or password", logger.Field("username", req.Username))
+		t.audit(ctx, model.AuditEventLoginFailed, &user.ID, nil, req, "invalid password")
+		return nil, ErrInvalidUsernamePassword
+	}
+
+	t.loginSucceeded(ctx, req)
+
+	if user.Machine {
+		logger.W(ctx, "Login of a machine account", logger.Field("username", req.Username))
+		t.audit(ctx, model.AuditEventLoginFailed, &user.ID, nil, req, "machine account")
 		return nil, ErrInvalidUsernamePassword
 	}
 
-	token, err := generateJWTToken(user.ID)
+	if user.SuspendedAt != nil {
+		logger.W(ctx, "Login of a suspended user", logger.Field("username", req.Username))
+		t.audit(ctx, model.AuditEventLoginFailed, &user.ID, nil, req, "account suspended")
+		return nil, ErrAccountSuspended
+	}
+
+	if user.EmailVerifiedAt == nil {
+		logger.W(ctx, "Login before email verification", logger.Field("username", req.Username))
+		t.audit(ctx, model.AuditEventLoginFailed, &user.ID, nil, req, "email not verified")
+		return nil, ErrEmailNotVerified
+	}
+
+	challenge, err := t.mfaChallenge(ctx, user, req)
+	if challenge != nil || err != nil {
+		return challenge, err
+	}
+
+	return t.startSession(ctx, user, req, "")
+}
+
+// startSession issues the tokens of a new session to a user who has logged in
+func (t *authService) startSession(ctx context.Context, user *model.User, req LoginUserReq, reason string) (*LoginUserResponse, error) {
+	refreshToken, next, err := t.newRefreshToken(user.ID, uuid.New())
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate refresh token", logger.Field("user_id", user.ID))
+		return nil, ErrTokenGenerationFailed
+	}
+
+	if err := t.refreshTokenRepo.CreateToken(ctx, next); err != nil {
+		return nil, ErrTokenGenerationFailed
+	}
+
+	t.audit(ctx, model.AuditEventLoginSucceeded, &user.ID, nil, req, reason)
+
+	return t.tokenResponse(ctx, user, refreshToken)
+}
+
+// UnlockUser forgets the failed logins of a user, ending a lockout. The admin doing so is taken
+// from ctx and recorded.
+func (t *authService) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: userID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := t.loginThrottle.Reset(ctx, usernameThrottleKey(user.Username)); err != nil {
+		return err
+	}
+
+	actorID := uuid.MustParse(ctx.Value("userID").(string))
+	t.audit(ctx, model.AuditEventUserUnlocked, &user.ID, &actorID, LoginUserReq{Username: user.Username}, "")
+
+	return nil
+}
+
+type loginThrottleKey struct {
+	key    string
+	policy throttle.Policy
+	locked error
+}
+
+func usernameThrottleKey(username string) string {
+	return "username:" + username
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (t *authService) loginThrottleKeys(req LoginUserReq) []loginThrottleKey {
+	keys := []loginThrottleKey{{key: usernameThrottleKey(req.Username), policy: t.loginPolicies.Username, locked: ErrAccountLocked}}
+	if req.IP != "" {
+		keys = append(keys, loginThrottleKey{key: ipThrottleKey(req.IP), policy: t.loginPolicies.IP, locked: ErrTooManyLoginAttempts})
+	}
+
+	return keys
+}
+
+// countLoginAttempt counts the login against the username and the IP before the credentials are
+// checked, so that concurrent guesses can not all pass on the same count, and refuses it while
+// either has to wait after its failures. A refused login is not counted.
+func (t *authService) countLoginAttempt(ctx context.Context, req LoginUserReq) error {
+	now := time.Now()
+	keys := t.loginThrottleKeys(req)
+
+	for i, key := range keys {
+		attempts, err := t.loginThrottle.Attempt(ctx, key.key, key.policy, now)
+		if err != nil {
+			t.forgiveLoginAttempt(ctx, keys[:i])
+			return err
+		}
+
+		wait, locked := key.policy.Wait(attempts, now)
+		if wait <= 0 {
+			continue
+		}
+
+		t.forgiveLoginAttempt(ctx, keys[:i])
+
+		throttleErr := ErrTooManyLoginAttempts
+		if locked {
+			throttleErr = key.locked
+		}
+
+		logger.W(ctx, "Login throttled", logger.Field("key", key.key), logger.Field("failures", attempts.Failures))
+		t.audit(ctx, model.AuditEventLoginThrottled, nil, nil, req, throttleErr.Error())
+
+		return fmt.Errorf("%w : try again in %s", throttleErr, wait.Round(time.Second))
+	}
+
+	return nil
+}
+
+// forgiveLoginAttempt takes back the login counted against keys
+func (t *authService) forgiveLoginAttempt(ctx context.Context, keys []loginThrottleKey) {
+	for _, key := range keys {
+		_ = t.loginThrottle.Forgive(ctx, key.key)
+	}
+}
+
+// loginSucceeded forgets the failures of the username. Only the login itself is taken back from
+// the IP, its failures are left to expire, a valid login of one account says nothing about the
+// others tried from the same address.
+func (t *authService) loginSucceeded(ctx context.Context, req LoginUserReq) {
+	_ = t.loginThrottle.Reset(ctx, usernameThrottleKey(req.Username))
+	if req.IP != "" {
+		_ = t.loginThrottle.Forgive(ctx, ipThrottleKey(req.IP))
+	}
+}
+
+// audit records an event, failing to do so does not fail the action it is about
+func (t *authService) audit(ctx context.Context, eventType model.AuditEventType, userID, actorID *uuid.UUID, req LoginUserReq, reason string) {
+	_ = t.auditRepo.CreateEvent(ctx, &model.AuditEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		UserID:    userID,
+		ActorID:   actorID,
+		Username:  req.Username,
+		IP:        req.IP,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+}
+
+// RefreshToken trades a refresh token for a new access token and rotates it, the presented token
+// can not be used again. A token that was already rotated is being replayed, by an attacker or by
+// the user it was stolen from, so its whole family is revoked and both have to log in again.
+func (t *authService) RefreshToken(ctx context.Context, req RefreshTokenReq) (*LoginUserResponse, error) {
+	current, err := t.refreshTokenRepo.GetToken(ctx, hashToken(req.RefreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.UsedAt != nil {
+		return nil, t.revokeReusedFamily(ctx, current)
+	}
+
+	// the user is looked up again so that a changed role is in the new access token
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: current.UserID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err != nil {
+		logger.W(ctx, "Error while getting user details", logger.Field("user_id", current.UserID))
+		return nil, err
+	}
+
+	if user.SuspendedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	refreshToken, next, err := t.newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate refresh token", logger.Field("user_id", current.UserID))
+		return nil, ErrTokenGenerationFailed
+	}
+
+	err = t.refreshTokenRepo.RotateToken(ctx, current.ID, next)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// a concurrent request rotated the token first
+		return nil, t.revokeReusedFamily(ctx, current)
+	}
+
+	if err != nil {
+		return nil, ErrTokenGenerationFailed
+	}
+
+	return t.tokenResponse(ctx, user, refreshToken)
+}
+
+// Logout revokes the family of the refresh token, signing out the session it belongs to. Unknown
+// tokens are ignored so that logging out twice is not an error.
+func (t *authService) Logout(ctx context.Context, req RefreshTokenReq) error {
+	current, err := t.refreshTokenRepo.GetToken(ctx, hashToken(req.RefreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return t.refreshTokenRepo.RevokeFamily(ctx, current.FamilyID)
+}
+
+// Authenticate validates a token of one of purposes, an access token when none are given, and
+// returns its claims. Tokens of deleted and suspended users, and tokens issued before the sessions
+// of their user were revoked, e.g. by a password reset, are rejected, which means the user is
+// looked up on every request.
+func (t *authService) Authenticate(ctx context.Context, tokenString string, purposes ...TokenPurpose) (*Claims, error) {
+	if len(purposes) == 0 {
+		purposes = []TokenPurpose{TokenPurposeAccess}
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey,
+		jwt.WithIssuedAt(), jwt.WithValidMethods([]string{keyset.AlgorithmRS256, keyset.AlgorithmEdDSA}))
+	if err != nil || !token.Valid || !hasPurpose(claims.Purpose, purposes) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: claims.UserID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAccessToken
+	}
+
 	if err != nil {
-		logger.E(ctx, err, "Failed to generate token", logger.Field("username", req.Username))
+		return nil, err
+	}
+
+	if user.SuspendedAt != nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	// iat only has second precision, a token issued in the second the sessions were revoked in can
+	// not be told apart from one issued before, so it is refused as well
+	if user.SessionsRevokedAt != nil &&
+		(claims.IssuedAt == nil || !claims.IssuedAt.After(*user.SessionsRevokedAt)) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	// a role changed by an admin applies right away rather than once the token expires
+	claims.Role = user.Role
+
+	return claims, nil
+}
+
+func (t *authService) revokeReusedFamily(ctx context.Context, reused *model.RefreshToken) error {
+	logger.W(ctx, "Refresh token reused, revoking its family",
+		logger.Field("user_id", reused.UserID),
+		logger.Field("family_id", reused.FamilyID))
+
+	if err := t.refreshTokenRepo.RevokeFamily(ctx, reused.FamilyID); err != nil {
+		return err
+	}
+
+	return ErrInvalidRefreshToken
+}
+
+func (t *authService) tokenResponse(ctx context.Context, user *model.User, refreshToken string) (*LoginUserResponse, error) {
+	token, err := generateJWTToken(user, t.accessTokenTTL)
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate token", logger.Field("user_id", user.ID))
 		return nil, ErrTokenGenerationFailed
 	}
 
 	return &LoginUserResponse{
-		AccessToken: token,
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(t.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// newRefreshToken returns an opaque refresh token for the user and the row storing its hash
+func (t *authService) newRefreshToken(userID, familyID uuid.UUID) (string, *model.RefreshToken, error) {
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return refreshToken, &model.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(t.refreshTokenTTL),
 	}, nil
 }
 
-func generateJWTToken(userID uuid.UUID) (string, error) {
+// newOpaqueToken returns a random URL-safe token, only its hashToken is ever stored
+func newOpaqueToken() (string, error) {
+	raw := make([]byte, OPAQUE_TOKEN_BYTES)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func hasPurpose(purpose TokenPurpose, purposes []TokenPurpose) bool {
+	for _, p := range purposes {
+		if p == purpose {
+			return true
+		}
+	}
+
+	return false
+}
+
+func generateJWTToken(user *model.User, ttl time.Duration) (string, error) {
+	return generatePurposeToken(user, ttl, TokenPurposeAccess)
+}
+
+func generatePurposeToken(user *model.User, ttl time.Duration, purpose TokenPurpose) (string, error) {
 	claims := &Claims{
-		Claims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-		UserID: userID,
+		UserID:  user.ID,
+		Role:    user.Role,
+		Purpose: purpose,
+	}
+
+	key, err := keyset.Get().Active()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(JWTSecretKey)
+	token := jwt.NewWithClaims(signingMethods[key.Algorithm], claims)
+	token.Header["kid"] = key.ID
+	signedToken, err := token.SignedString(key.Signer)
 	if err != nil {
 		return "", err
 	}
 
 	return signedToken, nil
 }
+
+// verificationKey picks the key of the keyset named by the kid header of a token, retired keys
+// only verify during their grace period
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keyset.Get().Verifier(kid, time.Now())
+	if !ok || token.Method.Alg() != key.Algorithm {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	return key.Public, nil
+}
@@ -3,18 +3,44 @@ package service
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"tigerhall_kittens/internal/keyset"
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
 	mock_repository "tigerhall_kittens/internal/repository/mocks"
+	"tigerhall_kittens/internal/throttle"
 )
 
+var testLoginPolicies = LoginThrottleConfig{
+	Username: throttle.Policy{FreeFailures: 3, BaseDelay: time.Second, MaxDelay: time.Minute, LockoutFailures: 10, LockoutDuration: time.Hour},
+	IP:       throttle.Policy{FreeFailures: 20, BaseDelay: time.Second, MaxDelay: time.Minute, LockoutFailures: 100, LockoutDuration: time.Hour},
+}
+
+// noMFARepo is for logins of users without MFA in roles not requiring it
+func noMFARepo(ctrl *gomock.Controller) repository.MFARepo {
+	mockMFARepo := mock_repository.NewMockMFARepo(ctrl)
+	mockMFARepo.EXPECT().IsMFARequired(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+
+	return mockMFARepo
+}
+
+func anyAuditRepo(ctrl *gomock.Controller) repository.AuditRepo {
+	mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+	mockAuditRepo.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return mockAuditRepo
+}
+
 func TestAuthService_LoginUser(t *testing.T) {
 	loginReq := LoginUserReq{
 		Username: "username",
@@ -32,6 +58,8 @@ func TestAuthService_LoginUser(t *testing.T) {
 
 		authService := NewAuthService(
 			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
 		)
 
 		resp, actualErr := authService.LoginUser(ctx, loginReq)
@@ -50,6 +78,8 @@ func TestAuthService_LoginUser(t *testing.T) {
 
 		authService := NewAuthService(
 			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
 		)
 
 		resp, actualErr := authService.LoginUser(ctx, loginReq)
@@ -73,6 +103,8 @@ func TestAuthService_LoginUser(t *testing.T) {
 
 		authService := NewAuthService(
 			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
 		)
 
 		resp, actualErr := authService.LoginUser(ctx, loginReq)
@@ -80,6 +112,97 @@ func TestAuthService_LoginUser(t *testing.T) {
 		assert.Nil(t, resp)
 	})
 
+	t.Run("should refuse to log in machine accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(loginReq.Password), bcrypt.MinCost)
+		mockUser := &model.User{
+			ID:       uuid.New(),
+			Username: loginReq.Username,
+			Password: string(hashedPassword),
+			Machine:  true,
+		}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: loginReq.Username}).Return(mockUser, nil)
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
+		)
+
+		resp, actualErr := authService.LoginUser(ctx, loginReq)
+		assert.Equal(t, ErrInvalidUsernamePassword, actualErr)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("should return email not verified error for pending users", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(loginReq.Password), bcrypt.DefaultCost)
+		mockUser := &model.User{
+			Username: loginReq.Username,
+			Password: string(hashedPassword),
+		}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: loginReq.Username}).Return(mockUser, nil)
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
+		)
+
+		resp, actualErr := authService.LoginUser(ctx, loginReq)
+		assert.Equal(t, ErrEmailNotVerified, actualErr)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("should reject suspended users after checking their password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(loginReq.Password), bcrypt.MinCost)
+		now := time.Now()
+		mockUser := &model.User{
+			ID:              uuid.New(),
+			Username:        loginReq.Username,
+			Password:        string(hashedPassword),
+			EmailVerifiedAt: &now,
+			SuspendedAt:     &now,
+		}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: loginReq.Username}).Return(mockUser, nil)
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventLoginFailed, event.Type)
+			assert.Equal(t, "account suspended", event.Reason)
+			return nil
+		})
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(mockAuditRepo),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
+		)
+
+		resp, actualErr := authService.LoginUser(ctx, loginReq)
+		assert.Equal(t, ErrAccountSuspended, actualErr)
+		assert.Nil(t, resp)
+	})
+
 	t.Run("should return token", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -96,14 +219,548 @@ func TestAuthService_LoginUser(t *testing.T) {
 
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(loginReq.Password), bcrypt.DefaultCost)
 		mockUser.Password = string(hashedPassword)
+		verifiedAt := time.Now()
+		mockUser.EmailVerifiedAt = &verifiedAt
+
+		var stored *model.RefreshToken
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().CreateToken(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, token *model.RefreshToken) error {
+				stored = token
+				return nil
+			})
 
 		authService := NewAuthService(
 			WithUserRepoForAuthService(mockUserRepo),
+			WithRefreshTokenRepo(mockRefreshTokenRepo),
+			WithTokenTTLs(15*time.Minute, time.Hour),
+			WithMFARepo(noMFARepo(ctrl)),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
 		)
 
 		resp, actualErr := authService.LoginUser(ctx, loginReq)
 		assert.Nil(t, actualErr)
 		assert.Nil(t, resp.Error)
 		assert.NotNil(t, resp.AccessToken)
+		assert.Equal(t, int64(900), resp.ExpiresIn)
+		assert.Equal(t, hashToken(resp.RefreshToken), stored.TokenHash)
+		assert.NotEqual(t, resp.RefreshToken, stored.TokenHash)
+	})
+
+	t.Run("should delay logins after repeated failures without checking the password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		req := LoginUserReq{Username: loginReq.Username, Password: "wrong password", IP: "10.0.0.1"}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: loginReq.Username}).
+			Return(&model.User{ID: uuid.New(), Username: loginReq.Username, Password: "random_hash"}, nil).Times(2)
+
+		var events []model.AuditEventType
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, event *model.AuditEvent) error {
+				events = append(events, event.Type)
+				return nil
+			}).AnyTimes()
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(mockAuditRepo),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), LoginThrottleConfig{
+				Username: throttle.Policy{FreeFailures: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, LockoutFailures: 10, LockoutDuration: time.Hour},
+				IP:       testLoginPolicies.IP,
+			}),
+		)
+
+		_, err := authService.LoginUser(ctx, req)
+		assert.Equal(t, ErrInvalidUsernamePassword, err)
+		_, err = authService.LoginUser(ctx, req)
+		assert.Equal(t, ErrInvalidUsernamePassword, err)
+
+		_, err = authService.LoginUser(ctx, req)
+		assert.True(t, errors.Is(err, ErrTooManyLoginAttempts))
+		assert.Equal(t, []model.AuditEventType{model.AuditEventLoginFailed, model.AuditEventLoginFailed, model.AuditEventLoginThrottled}, events)
+	})
+
+	t.Run("should lock out a username and count unknown usernames alike", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		req := LoginUserReq{Username: "nobody", Password: "password", IP: "10.0.0.1"}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: req.Username}).Return(nil, gorm.ErrRecordNotFound).Times(2)
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), LoginThrottleConfig{
+				Username: throttle.Policy{FreeFailures: 5, LockoutFailures: 2, LockoutDuration: time.Hour},
+				IP:       testLoginPolicies.IP,
+			}),
+		)
+
+		for i := 0; i < 2; i++ {
+			_, err := authService.LoginUser(ctx, req)
+			assert.Equal(t, gorm.ErrRecordNotFound, err)
+		}
+
+		_, err := authService.LoginUser(ctx, req)
+		assert.True(t, errors.Is(err, ErrAccountLocked))
+	})
+
+	t.Run("should throttle an IP trying many usernames", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, gomock.Any()).Return(nil, gorm.ErrRecordNotFound).Times(2)
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), LoginThrottleConfig{
+				Username: testLoginPolicies.Username,
+				IP:       throttle.Policy{FreeFailures: 5, LockoutFailures: 2, LockoutDuration: time.Hour},
+			}),
+		)
+
+		_, _ = authService.LoginUser(ctx, LoginUserReq{Username: "first", Password: "password", IP: "10.0.0.1"})
+		_, _ = authService.LoginUser(ctx, LoginUserReq{Username: "second", Password: "password", IP: "10.0.0.1"})
+
+		_, err := authService.LoginUser(ctx, LoginUserReq{Username: "third", Password: "password", IP: "10.0.0.1"})
+		assert.True(t, errors.Is(err, ErrTooManyLoginAttempts))
+	})
+
+	t.Run("should let only as many concurrent guesses check the password as the lockout allows", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		req := LoginUserReq{Username: loginReq.Username, Password: "wrong password", IP: "10.0.0.1"}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: req.Username}).
+			Return(&model.User{ID: uuid.New(), Username: req.Username, Password: "random_hash"}, nil).Times(2)
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), LoginThrottleConfig{
+				Username: throttle.Policy{FreeFailures: 5, LockoutFailures: 2, LockoutDuration: time.Hour},
+				IP:       testLoginPolicies.IP,
+			}),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = authService.LoginUser(ctx, req)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("should not count valid logins against the IP", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		req := LoginUserReq{Username: loginReq.Username, Password: loginReq.Password, IP: "10.0.0.1"}
+
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(loginReq.Password), bcrypt.MinCost)
+		now := time.Now()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: req.Username}).
+			Return(&model.User{ID: uuid.New(), Username: req.Username, Password: string(hashedPassword), SuspendedAt: &now}, nil).Times(3)
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), LoginThrottleConfig{
+				Username: testLoginPolicies.Username,
+				IP:       throttle.Policy{FreeFailures: 5, LockoutFailures: 2, LockoutDuration: time.Hour},
+			}),
+		)
+
+		for i := 0; i < 3; i++ {
+			_, err := authService.LoginUser(ctx, req)
+			assert.Equal(t, ErrAccountSuspended, err)
+		}
+	})
+}
+
+func TestAuthService_RefreshToken(t *testing.T) {
+	refreshReq := RefreshTokenReq{RefreshToken: "refresh_token"}
+	tokenHash := hashToken(refreshReq.RefreshToken)
+
+	storedToken := func() *model.RefreshToken {
+		return &model.RefreshToken{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			FamilyID:  uuid.New(),
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+
+	t.Run("should rotate the refresh token within its family", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		current := storedToken()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: current.UserID}).Return(&model.User{ID: current.UserID, Role: model.RoleResearcher}, nil)
+
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().GetToken(ctx, tokenHash).Return(current, nil)
+		mockRefreshTokenRepo.EXPECT().RotateToken(ctx, current.ID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uuid.UUID, next *model.RefreshToken) error {
+				assert.Equal(t, current.FamilyID, next.FamilyID)
+				assert.Equal(t, current.UserID, next.UserID)
+				return nil
+			})
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo), WithRefreshTokenRepo(mockRefreshTokenRepo),
+			WithTokenTTLs(15*time.Minute, time.Hour))
+
+		resp, err := authService.RefreshToken(ctx, refreshReq)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+
+		claims := &Claims{}
+		_, err = jwt.ParseWithClaims(resp.AccessToken, claims, verificationKey)
+		assert.Nil(t, err)
+		assert.Equal(t, model.RoleResearcher, claims.Role)
+		assert.NotEqual(t, refreshReq.RefreshToken, resp.RefreshToken)
+	})
+
+	t.Run("should revoke the family when a rotated token is reused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		current := storedToken()
+		usedAt := time.Now().Add(-time.Minute)
+		current.UsedAt = &usedAt
+
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().GetToken(ctx, tokenHash).Return(current, nil)
+		mockRefreshTokenRepo.EXPECT().RevokeFamily(ctx, current.FamilyID).Return(nil)
+
+		authService := NewAuthService(WithRefreshTokenRepo(mockRefreshTokenRepo))
+
+		resp, err := authService.RefreshToken(ctx, refreshReq)
+		assert.Equal(t, ErrInvalidRefreshToken, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("should revoke the family when a concurrent refresh rotated the token first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		current := storedToken()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: current.UserID}).Return(&model.User{ID: current.UserID}, nil)
+
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().GetToken(ctx, tokenHash).Return(current, nil)
+		mockRefreshTokenRepo.EXPECT().RotateToken(ctx, current.ID, gomock.Any()).Return(gorm.ErrRecordNotFound)
+		mockRefreshTokenRepo.EXPECT().RevokeFamily(ctx, current.FamilyID).Return(nil)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo), WithRefreshTokenRepo(mockRefreshTokenRepo))
+
+		_, err := authService.RefreshToken(ctx, refreshReq)
+		assert.Equal(t, ErrInvalidRefreshToken, err)
+	})
+
+	t.Run("should reject expired, revoked and unknown tokens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		expired := storedToken()
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+		revoked := storedToken()
+		revokedAt := time.Now()
+		revoked.RevokedAt = &revokedAt
+
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		gomock.InOrder(
+			mockRefreshTokenRepo.EXPECT().GetToken(ctx, tokenHash).Return(expired, nil),
+			mockRefreshTokenRepo.EXPECT().GetToken(ctx, tokenHash).Return(revoked, nil),
+			mockRefreshTokenRepo.EXPECT().GetToken(ctx, tokenHash).Return(nil, gorm.ErrRecordNotFound),
+		)
+
+		authService := NewAuthService(WithRefreshTokenRepo(mockRefreshTokenRepo))
+
+		for i := 0; i < 3; i++ {
+			_, err := authService.RefreshToken(ctx, refreshReq)
+			assert.Equal(t, ErrInvalidRefreshToken, err)
+		}
+	})
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	refreshReq := RefreshTokenReq{RefreshToken: "refresh_token"}
+
+	t.Run("should revoke the family of the refresh token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		familyID := uuid.New()
+
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().GetToken(ctx, hashToken(refreshReq.RefreshToken)).Return(&model.RefreshToken{FamilyID: familyID}, nil)
+		mockRefreshTokenRepo.EXPECT().RevokeFamily(ctx, familyID).Return(nil)
+
+		authService := NewAuthService(WithRefreshTokenRepo(mockRefreshTokenRepo))
+
+		assert.Nil(t, authService.Logout(ctx, refreshReq))
+	})
+
+	t.Run("should ignore unknown refresh tokens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().GetToken(ctx, gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+
+		authService := NewAuthService(WithRefreshTokenRepo(mockRefreshTokenRepo))
+
+		assert.Nil(t, authService.Logout(ctx, refreshReq))
+	})
+}
+
+func TestAuthService_Authenticate(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Role: model.RoleReporter}
+
+	t.Run("should return the claims of a valid token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		token, err := generateJWTToken(user, time.Minute)
+		assert.Nil(t, err)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo))
+
+		claims, err := authService.Authenticate(ctx, token)
+		assert.Nil(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.Equal(t, model.RoleReporter, claims.Role)
+	})
+
+	t.Run("should reject expired tokens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token, err := generateJWTToken(user, -time.Minute)
+		assert.Nil(t, err)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mock_repository.NewMockUserRepo(ctrl)))
+
+		_, err = authService.Authenticate(context.Background(), token)
+		assert.Equal(t, ErrInvalidAccessToken, err)
+	})
+
+	t.Run("should reject tokens issued before the sessions of the user were revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		token, err := generateJWTToken(user, time.Hour)
+		assert.Nil(t, err)
+
+		revokedAt := time.Now().Add(time.Second)
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).
+			Return(&model.User{ID: user.ID, SessionsRevokedAt: &revokedAt}, nil)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo))
+
+		_, err = authService.Authenticate(ctx, token)
+		assert.Equal(t, ErrInvalidAccessToken, err)
+	})
+
+	t.Run("should reject tokens issued in the second the sessions of the user were revoked in", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		token, err := generateJWTToken(user, time.Hour)
+		assert.Nil(t, err)
+
+		revokedAt := time.Now()
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).
+			Return(&model.User{ID: user.ID, SessionsRevokedAt: &revokedAt}, nil)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo))
+
+		_, err = authService.Authenticate(ctx, token)
+		assert.Equal(t, ErrInvalidAccessToken, err)
+	})
+
+	t.Run("should reject tokens of suspended and deleted users", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		token, err := generateJWTToken(user, time.Hour)
+		assert.Nil(t, err)
+
+		suspendedAt := time.Now()
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		gomock.InOrder(
+			mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).
+				Return(&model.User{ID: user.ID, Role: model.RoleReporter, SuspendedAt: &suspendedAt}, nil),
+			mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).
+				Return(nil, gorm.ErrRecordNotFound),
+		)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo))
+
+		_, err = authService.Authenticate(ctx, token)
+		assert.Equal(t, ErrInvalidAccessToken, err)
+
+		_, err = authService.Authenticate(ctx, token)
+		assert.Equal(t, ErrInvalidAccessToken, err)
+	})
+
+	t.Run("should apply the current role of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		token, err := generateJWTToken(user, time.Hour)
+		assert.Nil(t, err)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).
+			Return(&model.User{ID: user.ID, Role: model.RoleResearcher}, nil)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo))
+
+		claims, err := authService.Authenticate(ctx, token)
+		assert.Nil(t, err)
+		assert.Equal(t, model.RoleResearcher, claims.Role)
+	})
+
+	t.Run("should verify tokens of a retired key during the grace period only", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		previous := keyset.Get()
+		defer keyset.Set(previous)
+
+		token, err := generateJWTToken(user, time.Hour)
+		assert.Nil(t, err)
+
+		rotated, err := keyset.Generate("next")
+		assert.Nil(t, err)
+		next, _ := rotated.Active()
+		old, _ := previous.Active()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(gomock.Any(), repository.GetUserOpts{ID: user.ID}).Return(user, nil).AnyTimes()
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo))
+
+		retiredAt := time.Now()
+		keyset.Set(keyset.New(next, []*keyset.Key{{ID: old.ID, Algorithm: old.Algorithm, Public: old.Public, RetiredAt: &retiredAt}}, time.Hour))
+		_, err = authService.Authenticate(context.Background(), token)
+		assert.Nil(t, err)
+
+		retiredAt = time.Now().Add(-2 * time.Hour)
+		_, err = authService.Authenticate(context.Background(), token)
+		assert.Equal(t, ErrInvalidAccessToken, err)
+	})
+
+	t.Run("should reject tokens signed with a shared secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		active, _ := keyset.Get().Active()
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: user.ID, Role: model.RoleAdmin})
+		token.Header["kid"] = active.ID
+		signed, err := token.SignedString([]byte("secret"))
+		assert.Nil(t, err)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mock_repository.NewMockUserRepo(ctrl)))
+
+		_, err = authService.Authenticate(context.Background(), signed)
+		assert.Equal(t, ErrInvalidAccessToken, err)
+	})
+}
+
+func TestAuthService_UnlockUser(t *testing.T) {
+	adminID := uuid.New()
+	user := &model.User{ID: uuid.New(), Username: "ranger_ravi"}
+
+	t.Run("should forget the failed logins of the user and record who unlocked it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", adminID.String())
+
+		store := throttle.NewMemoryStore(time.Hour)
+		_, _ = store.Attempt(ctx, "username:"+user.Username, testLoginPolicies.Username, time.Now())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, event *model.AuditEvent) error {
+				assert.Equal(t, model.AuditEventUserUnlocked, event.Type)
+				assert.Equal(t, user.ID, *event.UserID)
+				assert.Equal(t, adminID, *event.ActorID)
+				return nil
+			})
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo), WithAuditRepo(mockAuditRepo),
+			WithLoginThrottle(store, testLoginPolicies))
+
+		assert.Nil(t, authService.UnlockUser(ctx, user.ID))
+
+		attempts, _ := store.Get(ctx, "username:"+user.Username)
+		assert.Equal(t, 0, attempts.Failures)
+	})
+
+	t.Run("should return user not found error for unknown users", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", adminID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(nil, gorm.ErrRecordNotFound)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo), WithAuditRepo(anyAuditRepo(ctrl)))
+
+		assert.Equal(t, ErrUserNotFound, authService.UnlockUser(ctx, user.ID))
 	})
 }
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tigerhall_kittens/internal/repository"
+)
+
+// encodeCursor makes an opaque cursor out of the position of the last row on a page
+func encodeCursor(cursor repository.Cursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(encoded string) (*repository.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w : not base64", ErrInvalidCursor)
+	}
+
+	createdAtStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("%w : malformed", ErrInvalidCursor)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w : invalid time", ErrInvalidCursor)
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w : invalid id", ErrInvalidCursor)
+	}
+
+	return &repository.Cursor{CreatedAt: createdAt, ID: id}, nil
+}
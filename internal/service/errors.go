@@ -5,6 +5,9 @@ import "errors"
 var (
 	ErrTigerDoesNotExist    = errors.New("tiger does not exist")
 	ErrFetchingTigerDetails = errors.New("unable to fetch tiger details")
+	ErrTigerNotFound        = errors.New("tiger not found")
+	ErrTigerVersionMismatch = errors.New("tiger was changed since the given version")
+	ErrInvalidTigerDetails  = errors.New("invalid tiger details")
 
 	ErrFetchingExistingSightings = errors.New("unable to check existing sightings")
 	ErrSightingAlreadyReported   = errors.New("already reported in range")
@@ -13,7 +16,61 @@ var (
 
 	ErrInvalidUsernamePassword = errors.New("invalid username or password")
 	ErrTokenGenerationFailed   = errors.New("failed to generate token")
+	ErrInvalidRefreshToken     = errors.New("invalid refresh token")
+	ErrInvalidAccessToken      = errors.New("invalid access token")
+	ErrTooManyLoginAttempts    = errors.New("too many failed logins")
+	ErrAccountLocked           = errors.New("account is temporarily locked after too many failed logins")
+
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token")
+	ErrInvalidMFACode    = errors.New("invalid MFA code")
+	ErrMFAAlreadyEnabled = errors.New("MFA is already enabled")
+	ErrMFANotEnrolled    = errors.New("MFA enrollment has not been started")
+
+	ErrInvalidAPIKey        = errors.New("invalid, expired or revoked API key")
+	ErrInvalidAPIKeyDetails = errors.New("invalid API key details")
+	ErrAPIKeyNotFound       = errors.New("API key does not exist")
+
+	ErrOIDCProviderNotFound = errors.New("identity provider does not exist")
+	ErrInvalidOIDCState     = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed      = errors.New("identity provider login failed")
+	ErrOIDCEmailNotVerified = errors.New("identity provider did not return a verified email address")
 
 	ErrUserAlreadyExistsWithSameEmailUsername = errors.New("user already exists with same email/username")
 	ErrCreatingUser                           = errors.New("error while creating user")
+	ErrInvalidRole                            = errors.New("invalid role")
+	ErrInvalidUserDetails                     = errors.New("invalid user details")
+	ErrUserNotFound                           = errors.New("user does not exist")
+	ErrIncorrectPassword                      = errors.New("current password is incorrect")
+	ErrEmailTaken                             = errors.New("email address is already in use")
+	ErrInvalidUserStatus                      = errors.New("invalid user status")
+	ErrCannotManageOwnAccount                 = errors.New("admins can not suspend, delete, erase or change the role of their own account")
+	ErrAccountSuspended                       = errors.New("account is suspended")
+
+	ErrDataExportNotFound = errors.New("data export does not exist")
+	ErrDataExportNotReady = errors.New("data export is not ready yet")
+
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrInvalidResetToken        = errors.New("invalid or expired password reset token")
+
+	ErrNotificationNotReplayable    = errors.New("notification does not exist or is not dead-lettered")
+	ErrNotificationTokenDropped     = errors.New("notification carried a one-time token that is gone, the user has to request a new one")
+	ErrNotificationTemplateNotFound = errors.New("notification template does not exist")
+
+	ErrSubscriptionNotFound = errors.New("not subscribed to tiger")
+
+	ErrInvalidGeofence  = errors.New("invalid geofence")
+	ErrGeofenceNotFound = errors.New("geofence does not exist")
+
+	ErrInvalidPreferences = errors.New("invalid notification preferences")
+	ErrTigerNotMuted      = errors.New("tiger is not muted")
+
+	ErrInvalidCursor             = errors.New("invalid cursor")
+	ErrInboxNotificationNotFound = errors.New("notification does not exist")
+
+	ErrInvalidFeedFilter = errors.New("invalid feed filter")
+
+	ErrInvalidWebhook          = errors.New("invalid webhook")
+	ErrWebhookNotFound         = errors.New("webhook does not exist")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery does not exist")
 )
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"tigerhall_kittens/internal/feed"
+	"tigerhall_kittens/internal/logger"
+)
+
+type SightingFeedReq struct {
+	// LastEventID is the last event the client received before reconnecting, zero on the first connect
+	LastEventID uint64
+	TigerIDs    []uint
+	BoundingBox *feed.BoundingBox
+}
+
+type FeedService interface {
+	SubscribeSightings(ctx context.Context, req SightingFeedReq) (*feed.Subscription, error)
+}
+
+type feedService struct {
+	feedBroker feed.Broker
+}
+
+type FeedServiceOption func(service *feedService)
+
+func NewFeedService(options ...FeedServiceOption) FeedService {
+	service := &feedService{
+		feedBroker: feed.Get(),
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+func WithFeedBrokerForFeed(broker feed.Broker) FeedServiceOption {
+	return func(s *feedService) {
+		s.feedBroker = broker
+	}
+}
+
+// SubscribeSightings subscribes to sightings as they are reported, the caller must close the subscription
+func (t *feedService) SubscribeSightings(ctx context.Context, req SightingFeedReq) (*feed.Subscription, error) {
+	if box := req.BoundingBox; box != nil {
+		if box.MinLat < -90 || box.MaxLat > 90 || box.MinLon < -180 || box.MaxLon > 180 {
+			return nil, fmt.Errorf("%w : bbox lat must be within [-90, 90] and lon within [-180, 180]", ErrInvalidFeedFilter)
+		}
+
+		if box.MinLat > box.MaxLat || box.MinLon > box.MaxLon {
+			return nil, fmt.Errorf("%w : bbox must be min_lon,min_lat,max_lon,max_lat", ErrInvalidFeedFilter)
+		}
+	}
+
+	subscription, err := t.feedBroker.Subscribe(ctx, req.LastEventID, feed.Filter{
+		TigerIDs:    req.TigerIDs,
+		BoundingBox: req.BoundingBox,
+	})
+	if err != nil {
+		logger.E(ctx, err, "Error while subscribing to the sighting feed")
+		return nil, err
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"tigerhall_kittens/internal/feed"
+	mock_feed "tigerhall_kittens/internal/feed/mocks"
+)
+
+func TestFeedService_SubscribeSightings(t *testing.T) {
+	t.Run("should return invalid feed filter error for an inverted bbox", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		feedService := NewFeedService(WithFeedBrokerForFeed(mock_feed.NewMockBroker(ctrl)))
+
+		_, err := feedService.SubscribeSightings(context.Background(), SightingFeedReq{
+			BoundingBox: &feed.BoundingBox{MinLat: 30, MinLon: 70, MaxLat: 20, MaxLon: 80},
+		})
+		assert.True(t, errors.Is(err, ErrInvalidFeedFilter))
+	})
+
+	t.Run("should subscribe with the requested filter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		box := &feed.BoundingBox{MinLat: 20, MinLon: 70, MaxLat: 30, MaxLon: 80}
+		subscription := &feed.Subscription{}
+
+		mockBroker := mock_feed.NewMockBroker(ctrl)
+		mockBroker.EXPECT().Subscribe(ctx, uint64(5), feed.Filter{TigerIDs: []uint{1}, BoundingBox: box}).Return(subscription, nil)
+
+		feedService := NewFeedService(WithFeedBrokerForFeed(mockBroker))
+
+		actual, err := feedService.SubscribeSightings(ctx, SightingFeedReq{LastEventID: 5, TigerIDs: []uint{1}, BoundingBox: box})
+		assert.Nil(t, err)
+		assert.Equal(t, subscription, actual)
+	})
+}
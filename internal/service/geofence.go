@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+const MAX_GEOFENCE_RADIUS_IN_METERS = 100000
+
+type GeofenceCircle struct {
+	Lat            float64 `json:"lat"`
+	Lon            float64 `json:"lon"`
+	RadiusInMeters float64 `json:"radius_in_meters"`
+}
+
+// GeofenceReq describes an area of interest, either Circle or Polygon, a GeoJSON Polygon geometry, must be set
+type GeofenceReq struct {
+	Name    string          `json:"name"`
+	Circle  *GeofenceCircle `json:"circle,omitempty"`
+	Polygon json.RawMessage `json:"polygon,omitempty"`
+}
+
+type GeofenceService interface {
+	CreateGeofence(ctx context.Context, req GeofenceReq) (*model.Geofence, error)
+	UpdateGeofence(ctx context.Context, geofenceID uuid.UUID, req GeofenceReq) (*model.Geofence, error)
+	DeleteGeofence(ctx context.Context, geofenceID uuid.UUID) error
+	GetGeofence(ctx context.Context, geofenceID uuid.UUID) (*model.Geofence, error)
+	ListGeofences(ctx context.Context, opts repository.ListGeofencesOpts) ([]model.Geofence, error)
+}
+
+type geofenceService struct {
+	geofenceRepo repository.GeofenceRepo
+}
+
+type GeofenceServiceOption func(service *geofenceService)
+
+func NewGeofenceService(options ...GeofenceServiceOption) GeofenceService {
+	service := &geofenceService{geofenceRepo: repository.NewGeofenceRepo()}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+func WithGeofenceRepo(repo repository.GeofenceRepo) GeofenceServiceOption {
+	return func(s *geofenceService) {
+		s.geofenceRepo = repo
+	}
+}
+
+func (t *geofenceService) CreateGeofence(ctx context.Context, req GeofenceReq) (*model.Geofence, error) {
+	now := time.Now()
+	geofence := &model.Geofence{
+		ID:        uuid.New(),
+		UserID:    uuid.MustParse(ctx.Value("userID").(string)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := applyGeofenceReq(geofence, req); err != nil {
+		logger.W(ctx, "Invalid geofence", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	if err := t.geofenceRepo.CreateGeofence(ctx, geofence); err != nil {
+		logger.E(ctx, err, "Error while creating geofence")
+		return nil, err
+	}
+
+	return geofence, nil
+}
+
+func (t *geofenceService) UpdateGeofence(ctx context.Context, geofenceID uuid.UUID, req GeofenceReq) (*model.Geofence, error) {
+	geofence, err := t.GetGeofence(ctx, geofenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyGeofenceReq(geofence, req); err != nil {
+		logger.W(ctx, "Invalid geofence", logger.Field("error", err.Error()))
+		return nil, err
+	}
+	geofence.UpdatedAt = time.Now()
+
+	err = t.geofenceRepo.UpdateGeofence(ctx, geofence)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrGeofenceNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while updating geofence", logger.Field("geofence_id", geofenceID))
+		return nil, err
+	}
+
+	return geofence, nil
+}
+
+func (t *geofenceService) DeleteGeofence(ctx context.Context, geofenceID uuid.UUID) error {
+	err := t.geofenceRepo.DeleteGeofence(ctx, repository.GetGeofenceOpts{
+		ID:     geofenceID,
+		UserID: uuid.MustParse(ctx.Value("userID").(string)),
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Geofence does not exist", logger.Field("geofence_id", geofenceID))
+		return ErrGeofenceNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while deleting geofence", logger.Field("geofence_id", geofenceID))
+		return err
+	}
+
+	return nil
+}
+
+// GetGeofence returns a geofence of the logged-in user, geofences of other users are reported as not found
+func (t *geofenceService) GetGeofence(ctx context.Context, geofenceID uuid.UUID) (*model.Geofence, error) {
+	geofence, err := t.geofenceRepo.GetGeofence(ctx, repository.GetGeofenceOpts{
+		ID:     geofenceID,
+		UserID: uuid.MustParse(ctx.Value("userID").(string)),
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Geofence does not exist", logger.Field("geofence_id", geofenceID))
+		return nil, ErrGeofenceNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching geofence", logger.Field("geofence_id", geofenceID))
+		return nil, err
+	}
+
+	return geofence, nil
+}
+
+func (t *geofenceService) ListGeofences(ctx context.Context, opts repository.ListGeofencesOpts) ([]model.Geofence, error) {
+	opts.UserID = uuid.MustParse(ctx.Value("userID").(string))
+
+	geofences, err := t.geofenceRepo.GetGeofences(ctx, opts)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching geofences", logger.Field("opts", opts))
+		return nil, err
+	}
+
+	return geofences, nil
+}
+
+// applyGeofenceReq validates req and copies its name and shape onto geofence
+func applyGeofenceReq(geofence *model.Geofence, req GeofenceReq) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return fmt.Errorf("%w : name must be between 1 and 100 characters", ErrInvalidGeofence)
+	}
+
+	if (req.Circle == nil) == (len(req.Polygon) == 0) {
+		return fmt.Errorf("%w : exactly one of circle and polygon is required", ErrInvalidGeofence)
+	}
+
+	geofence.Name = name
+	geofence.Lat, geofence.Lon, geofence.RadiusInMeters, geofence.Polygon = nil, nil, nil, nil
+
+	if req.Circle != nil {
+		circle := *req.Circle
+		if err := validatePosition(circle.Lon, circle.Lat); err != nil {
+			return err
+		}
+
+		if circle.RadiusInMeters <= 0 || circle.RadiusInMeters > MAX_GEOFENCE_RADIUS_IN_METERS {
+			return fmt.Errorf("%w : radius_in_meters must be greater than 0 and at most %d", ErrInvalidGeofence, MAX_GEOFENCE_RADIUS_IN_METERS)
+		}
+
+		geofence.Kind = model.GeofenceKindCircle
+		geofence.Lat, geofence.Lon, geofence.RadiusInMeters = &circle.Lat, &circle.Lon, &circle.RadiusInMeters
+		return nil
+	}
+
+	if err := validatePolygon(req.Polygon); err != nil {
+		return err
+	}
+
+	geofence.Kind = model.GeofenceKindPolygon
+	geofence.Polygon = req.Polygon
+	return nil
+}
+
+// validatePolygon checks that raw is a GeoJSON Polygon geometry made of closed linear rings
+func validatePolygon(raw json.RawMessage) error {
+	var polygon struct {
+		Type        string         `json:"type"`
+		Coordinates [][][2]float64 `json:"coordinates"`
+	}
+
+	if err := json.Unmarshal(raw, &polygon); err != nil || polygon.Type != "Polygon" {
+		return fmt.Errorf("%w : polygon must be a GeoJSON Polygon geometry", ErrInvalidGeofence)
+	}
+
+	if len(polygon.Coordinates) == 0 {
+		return fmt.Errorf("%w : polygon must have at least one ring", ErrInvalidGeofence)
+	}
+
+	for _, ring := range polygon.Coordinates {
+		if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+			return fmt.Errorf("%w : polygon rings must be closed and have at least 4 positions", ErrInvalidGeofence)
+		}
+
+		for _, position := range ring {
+			if err := validatePosition(position[0], position[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validatePosition(lon, lat float64) error {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return fmt.Errorf("%w : lat must be within [-90, 90] and lon within [-180, 180]", ErrInvalidGeofence)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestGeofenceService_CreateGeofence(t *testing.T) {
+	userID := uuid.New()
+
+	invalidReqs := map[string]GeofenceReq{
+		"missing name":          {Circle: &GeofenceCircle{Lat: 26.01, Lon: 76.50, RadiusInMeters: 500}},
+		"missing shape":         {Name: "Rajbagh lake"},
+		"both shapes":           {Name: "Rajbagh lake", Circle: &GeofenceCircle{Lat: 26.01, Lon: 76.50, RadiusInMeters: 500}, Polygon: json.RawMessage(`{}`)},
+		"radius too large":      {Name: "Rajbagh lake", Circle: &GeofenceCircle{Lat: 26.01, Lon: 76.50, RadiusInMeters: 200000}},
+		"latitude out of range": {Name: "Rajbagh lake", Circle: &GeofenceCircle{Lat: 126.01, Lon: 76.50, RadiusInMeters: 500}},
+		"not a polygon":         {Name: "Rajbagh lake", Polygon: json.RawMessage(`{"type":"Point","coordinates":[76.5,26.0]}`)},
+		"open ring":             {Name: "Rajbagh lake", Polygon: json.RawMessage(`{"type":"Polygon","coordinates":[[[76.4,26.0],[76.6,26.0],[76.6,26.1],[76.4,26.1]]]}`)},
+	}
+
+	for name, req := range invalidReqs {
+		req := req
+		t.Run("should return invalid geofence error for "+name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+			geofenceService := NewGeofenceService(WithGeofenceRepo(mock_repository.NewMockGeofenceRepo(ctrl)))
+
+			geofence, actualErr := geofenceService.CreateGeofence(ctx, req)
+			assert.Nil(t, geofence)
+			assert.True(t, errors.Is(actualErr, ErrInvalidGeofence))
+		})
+	}
+
+	t.Run("should create circular geofence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockGeofenceRepo := mock_repository.NewMockGeofenceRepo(ctrl)
+		mockGeofenceRepo.EXPECT().CreateGeofence(ctx, gomock.Any()).Return(nil)
+
+		geofenceService := NewGeofenceService(WithGeofenceRepo(mockGeofenceRepo))
+
+		geofence, actualErr := geofenceService.CreateGeofence(ctx, GeofenceReq{
+			Name:   " Rajbagh lake ",
+			Circle: &GeofenceCircle{Lat: 26.01, Lon: 76.50, RadiusInMeters: 500},
+		})
+		assert.Nil(t, actualErr)
+		assert.Equal(t, userID, geofence.UserID)
+		assert.Equal(t, "Rajbagh lake", geofence.Name)
+		assert.Equal(t, model.GeofenceKindCircle, geofence.Kind)
+		assert.Equal(t, 500.0, *geofence.RadiusInMeters)
+		assert.Nil(t, geofence.Polygon)
+	})
+
+	t.Run("should create polygon geofence", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+		polygon := json.RawMessage(`{"type":"Polygon","coordinates":[[[76.4,26.0],[76.6,26.0],[76.6,26.1],[76.4,26.1],[76.4,26.0]]]}`)
+
+		mockGeofenceRepo := mock_repository.NewMockGeofenceRepo(ctrl)
+		mockGeofenceRepo.EXPECT().CreateGeofence(ctx, gomock.Any()).Return(nil)
+
+		geofenceService := NewGeofenceService(WithGeofenceRepo(mockGeofenceRepo))
+
+		geofence, actualErr := geofenceService.CreateGeofence(ctx, GeofenceReq{Name: "Padam talao", Polygon: polygon})
+		assert.Nil(t, actualErr)
+		assert.Equal(t, model.GeofenceKindPolygon, geofence.Kind)
+		assert.Equal(t, polygon, geofence.Polygon)
+		assert.Nil(t, geofence.Lat)
+	})
+}
+
+func TestGeofenceService_UpdateGeofence(t *testing.T) {
+	userID := uuid.New()
+	geofenceID := uuid.New()
+	getGeofenceOpts := repository.GetGeofenceOpts{ID: geofenceID, UserID: userID}
+
+	t.Run("should return geofence not found error for geofence of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockGeofenceRepo := mock_repository.NewMockGeofenceRepo(ctrl)
+		mockGeofenceRepo.EXPECT().GetGeofence(ctx, getGeofenceOpts).Return(nil, gorm.ErrRecordNotFound)
+
+		geofenceService := NewGeofenceService(WithGeofenceRepo(mockGeofenceRepo))
+
+		geofence, actualErr := geofenceService.UpdateGeofence(ctx, geofenceID, GeofenceReq{Name: "Rajbagh lake"})
+		assert.Nil(t, geofence)
+		assert.Equal(t, ErrGeofenceNotFound, actualErr)
+	})
+
+	t.Run("should replace circle with polygon", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+		lat, lon, radius := 26.01, 76.50, 500.0
+		polygon := json.RawMessage(`{"type":"Polygon","coordinates":[[[76.4,26.0],[76.6,26.0],[76.6,26.1],[76.4,26.0]]]}`)
+
+		mockGeofenceRepo := mock_repository.NewMockGeofenceRepo(ctrl)
+		mockGeofenceRepo.EXPECT().GetGeofence(ctx, getGeofenceOpts).Return(&model.Geofence{
+			ID: geofenceID, UserID: userID, Name: "Rajbagh lake", Kind: model.GeofenceKindCircle,
+			Lat: &lat, Lon: &lon, RadiusInMeters: &radius,
+		}, nil)
+		mockGeofenceRepo.EXPECT().UpdateGeofence(ctx, gomock.Any()).Return(nil)
+
+		geofenceService := NewGeofenceService(WithGeofenceRepo(mockGeofenceRepo))
+
+		geofence, actualErr := geofenceService.UpdateGeofence(ctx, geofenceID, GeofenceReq{Name: "Rajbagh lake", Polygon: polygon})
+		assert.Nil(t, actualErr)
+		assert.Equal(t, model.GeofenceKindPolygon, geofence.Kind)
+		assert.Nil(t, geofence.Lat)
+		assert.Nil(t, geofence.RadiusInMeters)
+		assert.Equal(t, polygon, geofence.Polygon)
+	})
+}
+
+func TestGeofenceService_DeleteGeofence(t *testing.T) {
+	userID := uuid.New()
+	geofenceID := uuid.New()
+
+	t.Run("should return geofence not found error when nothing was deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockGeofenceRepo := mock_repository.NewMockGeofenceRepo(ctrl)
+		mockGeofenceRepo.EXPECT().DeleteGeofence(ctx, repository.GetGeofenceOpts{ID: geofenceID, UserID: userID}).Return(gorm.ErrRecordNotFound)
+
+		geofenceService := NewGeofenceService(WithGeofenceRepo(mockGeofenceRepo))
+
+		actualErr := geofenceService.DeleteGeofence(ctx, geofenceID)
+		assert.Equal(t, ErrGeofenceNotFound, actualErr)
+	})
+}
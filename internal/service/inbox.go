@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+type ListInboxReq struct {
+	UnreadOnly bool
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+	Limit  int
+}
+
+type InboxPage struct {
+	Notifications []model.InboxNotification `json:"notifications"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+type InboxService interface {
+	ListNotifications(ctx context.Context, req ListInboxReq) (*InboxPage, error)
+	MarkRead(ctx context.Context, id uuid.UUID) error
+	MarkAllRead(ctx context.Context) (int64, error)
+	UnreadCount(ctx context.Context) (int64, error)
+}
+
+type inboxService struct {
+	inboxRepo repository.InboxRepo
+}
+
+type InboxServiceOption func(service *inboxService)
+
+func NewInboxService(options ...InboxServiceOption) InboxService {
+	service := &inboxService{
+		inboxRepo: repository.NewInboxRepo(),
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+func WithInboxRepo(repo repository.InboxRepo) InboxServiceOption {
+	return func(s *inboxService) {
+		s.inboxRepo = repo
+	}
+}
+
+// ListNotifications returns a page of the logged-in user's inbox, newest first. One extra row is
+// fetched to tell whether another page follows.
+func (t *inboxService) ListNotifications(ctx context.Context, req ListInboxReq) (*InboxPage, error) {
+	opts := repository.ListInboxOpts{
+		UserID:     uuid.MustParse(ctx.Value("userID").(string)),
+		UnreadOnly: req.UnreadOnly,
+		Limit:      req.Limit + 1,
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		opts.After = cursor
+	}
+
+	notifications, err := t.inboxRepo.GetNotifications(ctx, opts)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching inbox notifications", logger.Field("user_id", opts.UserID))
+		return nil, err
+	}
+
+	page := &InboxPage{Notifications: notifications}
+	if len(notifications) > req.Limit {
+		page.Notifications = notifications[:req.Limit]
+		last := page.Notifications[req.Limit-1]
+		page.NextCursor = encodeCursor(repository.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	if page.Notifications == nil {
+		page.Notifications = []model.InboxNotification{}
+	}
+
+	return page, nil
+}
+
+func (t *inboxService) MarkRead(ctx context.Context, id uuid.UUID) error {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	err := t.inboxRepo.MarkRead(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Inbox notification does not exist", logger.Field("notification_id", id), logger.Field("user_id", userID))
+		return ErrInboxNotificationNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while marking inbox notification as read", logger.Field("notification_id", id), logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the logged-in user as read and returns how many there were
+func (t *inboxService) MarkAllRead(ctx context.Context) (int64, error) {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	count, err := t.inboxRepo.MarkAllRead(ctx, userID)
+	if err != nil {
+		logger.E(ctx, err, "Error while marking inbox notifications as read", logger.Field("user_id", userID))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (t *inboxService) UnreadCount(ctx context.Context) (int64, error) {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	count, err := t.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		logger.E(ctx, err, "Error while counting unread inbox notifications", logger.Field("user_id", userID))
+		return 0, err
+	}
+
+	return count, nil
+}
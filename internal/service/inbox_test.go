@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestInboxService_ListNotifications(t *testing.T) {
+	userID := uuid.New()
+	now := time.Now().UTC()
+
+	notifications := []model.InboxNotification{
+		{ID: uuid.New(), UserID: userID, Title: "third", CreatedAt: now},
+		{ID: uuid.New(), UserID: userID, Title: "second", CreatedAt: now.Add(-time.Minute)},
+		{ID: uuid.New(), UserID: userID, Title: "first", CreatedAt: now.Add(-2 * time.Minute)},
+	}
+
+	t.Run("should return a cursor when there are more notifications", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockInboxRepo := mock_repository.NewMockInboxRepo(ctrl)
+		mockInboxRepo.EXPECT().GetNotifications(ctx, repository.ListInboxOpts{UserID: userID, Limit: 3}).Return(notifications, nil)
+
+		inboxService := NewInboxService(WithInboxRepo(mockInboxRepo))
+
+		page, err := inboxService.ListNotifications(ctx, ListInboxReq{Limit: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, notifications[:2], page.Notifications)
+		assert.NotEmpty(t, page.NextCursor)
+
+		cursor, err := decodeCursor(page.NextCursor)
+		assert.Nil(t, err)
+		assert.Equal(t, notifications[1].ID, cursor.ID)
+		assert.True(t, notifications[1].CreatedAt.Equal(cursor.CreatedAt))
+	})
+
+	t.Run("should continue after the cursor and stop on the last page", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+		after := repository.Cursor{CreatedAt: notifications[1].CreatedAt, ID: notifications[1].ID}
+
+		mockInboxRepo := mock_repository.NewMockInboxRepo(ctrl)
+		mockInboxRepo.EXPECT().GetNotifications(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts repository.ListInboxOpts) ([]model.InboxNotification, error) {
+				assert.True(t, opts.UnreadOnly)
+				assert.Equal(t, after.ID, opts.After.ID)
+				assert.True(t, after.CreatedAt.Equal(opts.After.CreatedAt))
+				return notifications[2:], nil
+			})
+
+		inboxService := NewInboxService(WithInboxRepo(mockInboxRepo))
+
+		page, err := inboxService.ListNotifications(ctx, ListInboxReq{UnreadOnly: true, Cursor: encodeCursor(after), Limit: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, notifications[2:], page.Notifications)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("should return invalid cursor error for a malformed cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		inboxService := NewInboxService(WithInboxRepo(mock_repository.NewMockInboxRepo(ctrl)))
+
+		_, err := inboxService.ListNotifications(ctx, ListInboxReq{Cursor: "not-a-cursor", Limit: 2})
+		assert.True(t, errors.Is(err, ErrInvalidCursor))
+	})
+}
+
+func TestInboxService_MarkRead(t *testing.T) {
+	userID := uuid.New()
+	notificationID := uuid.New()
+
+	t.Run("should return not found error for a notification of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockInboxRepo := mock_repository.NewMockInboxRepo(ctrl)
+		mockInboxRepo.EXPECT().MarkRead(ctx, userID, notificationID).Return(gorm.ErrRecordNotFound)
+
+		inboxService := NewInboxService(WithInboxRepo(mockInboxRepo))
+
+		err := inboxService.MarkRead(ctx, notificationID)
+		assert.Equal(t, ErrInboxNotificationNotFound, err)
+	})
+
+	t.Run("should mark notification as read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockInboxRepo := mock_repository.NewMockInboxRepo(ctrl)
+		mockInboxRepo.EXPECT().MarkRead(ctx, userID, notificationID).Return(nil)
+
+		inboxService := NewInboxService(WithInboxRepo(mockInboxRepo))
+
+		err := inboxService.MarkRead(ctx, notificationID)
+		assert.Nil(t, err)
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"testing"
 
+	"tigerhall_kittens/internal/keyset"
+	"tigerhall_kittens/internal/secretbox"
 	"tigerhall_kittens/test_helpers"
 )
 
@@ -13,5 +15,20 @@ func TestMain(m *testing.M) {
 	test_helpers.LoadEnvForTest()
 	test_helpers.InitializeLogger()
 	test_helpers.SetupPostgresConnection(context.Background(), test_helpers.EnvConfig)
+
+	keys, err := keyset.Generate("test")
+	if err != nil {
+		panic(err)
+	}
+	keyset.Set(keys)
+
+	secretKey, err := secretbox.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	if err := secretbox.Set(secretKey); err != nil {
+		panic(err)
+	}
+
 	os.Exit(m.Run())
 }
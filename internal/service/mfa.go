@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	"tigerhall_kittens/internal/secretbox"
+	"tigerhall_kittens/internal/totp"
+)
+
+const (
+	RECOVERY_CODE_LENGTH = 10
+	// 32 symbols, so that every random byte maps onto them evenly
+	recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+)
+
+type MFAConfig struct {
+	// Issuer is the name authenticator apps list the account under
+	Issuer        string
+	ChallengeTTL  time.Duration
+	RecoveryCodes int
+}
+
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth:// URI of the secret, usually shown as a QR code
+	URI string `json:"uri"`
+}
+
+type ConfirmMFAReq struct {
+	Code string `json:"code"`
+	IP   string `json:"-"`
+}
+
+// ConfirmMFAResponse holds the recovery codes, which are only ever shown here. An enrollment
+// made with the token of an MFA login also finishes that login.
+type ConfirmMFAResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	*LoginUserResponse
+}
+
+// VerifyMFAReq finishes an MFA login with either a TOTP code or a recovery code
+type VerifyMFAReq struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+	IP           string `json:"-"`
+}
+
+type SetRoleMFAPolicyReq struct {
+	Role        model.Role `json:"-"`
+	MFARequired bool       `json:"mfa_required"`
+}
+
+// mfaChallenge returns the challenge finishing the login of a user with MFA enabled, or with a
+// role requiring it, and nil for everyone else
+func (t *authService) mfaChallenge(ctx context.Context, user *model.User, req LoginUserReq) (*LoginUserResponse, error) {
+	purpose := TokenPurposeMFA
+	if user.MFAEnabledAt == nil {
+		required, err := t.mfaRepo.IsMFARequired(ctx, user.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		if !required {
+			return nil, nil
+		}
+		purpose = TokenPurposeMFAEnrollment
+	}
+
+	token, err := generatePurposeToken(user, t.mfa.ChallengeTTL, purpose)
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate MFA token", logger.Field("user_id", user.ID))
+		return nil, ErrTokenGenerationFailed
+	}
+
+	t.audit(ctx, model.AuditEventLoginMFARequired, &user.ID, nil, req, string(purpose))
+
+	return &LoginUserResponse{
+		MFAToken:              token,
+		MFARequired:           purpose == TokenPurposeMFA,
+		MFAEnrollmentRequired: purpose == TokenPurposeMFAEnrollment,
+	}, nil
+}
+
+// EnrollMFA generates a TOTP secret for the user in ctx, it only takes effect once a code for
+// it is confirmed with ConfirmMFA. Enrolling again replaces an unconfirmed secret.
+func (t *authService) EnrollMFA(ctx context.Context) (*MFAEnrollment, error) {
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: uuid.MustParse(ctx.Value("userID").(string))})
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate TOTP secret", logger.Field("user_id", user.ID))
+		return nil, ErrTokenGenerationFailed
+	}
+
+	sealed, err := secretbox.Seal(secret)
+	if err != nil {
+		logger.E(ctx, err, "Failed to seal TOTP secret", logger.Field("user_id", user.ID))
+		return nil, ErrTokenGenerationFailed
+	}
+
+	err = t.mfaRepo.SetPendingSecret(ctx, user.ID, sealed)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(t.mfa.Issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmMFA enables MFA for the user in ctx with a code of the pending secret and returns a new
+// set of recovery codes. When the user authenticated with the token of an MFA login their role
+// required, the login is finished as well.
+func (t *authService) ConfirmMFA(ctx context.Context, req ConfirmMFAReq) (*ConfirmMFAResponse, error) {
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: uuid.MustParse(ctx.Value("userID").(string))})
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	secret, err := openTOTPSecret(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(secret, req.Code, time.Now(), 0)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	recoveryCodes, rows, err := newRecoveryCodes(user.ID, t.mfa.RecoveryCodes)
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate recovery codes", logger.Field("user_id", user.ID))
+		return nil, ErrTokenGenerationFailed
+	}
+
+	err = t.mfaRepo.EnableMFA(ctx, user.ID, step, rows)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	loginReq := LoginUserReq{Username: user.Username, IP: req.IP}
+	t.audit(ctx, model.AuditEventMFAEnabled, &user.ID, nil, loginReq, "")
+
+	resp := &ConfirmMFAResponse{RecoveryCodes: recoveryCodes}
+	if purpose, _ := ctx.Value("tokenPurpose").(string); TokenPurpose(purpose) == TokenPurposeMFAEnrollment {
+		resp.LoginUserResponse, err = t.startSession(ctx, user, loginReq, "mfa enrolled")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// VerifyMFA exchanges the token of an MFA login and a TOTP or recovery code for the tokens of a
+// session. Wrong codes count as failed logins of the user.
+func (t *authService) VerifyMFA(ctx context.Context, req VerifyMFAReq) (*LoginUserResponse, error) {
+	claims, err := t.Authenticate(ctx, req.MFAToken, TokenPurposeMFA)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: claims.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	loginReq := LoginUserReq{Username: user.Username, IP: req.IP}
+	if err := t.countLoginAttempt(ctx, loginReq); err != nil {
+		return nil, err
+	}
+
+	reason, err := t.useSecondFactor(ctx, user, req)
+	if errors.Is(err, ErrInvalidMFACode) {
+		logger.W(ctx, "Invalid MFA code", logger.Field("user_id", user.ID))
+		t.audit(ctx, model.AuditEventLoginFailed, &user.ID, nil, loginReq, "invalid mfa code")
+		return nil, err
+	}
+
+	if err != nil {
+		t.forgiveLoginAttempt(ctx, t.loginThrottleKeys(loginReq))
+		return nil, err
+	}
+
+	t.loginSucceeded(ctx, loginReq)
+
+	return t.startSession(ctx, user, loginReq, reason)
+}
+
+// useSecondFactor uses up the code of req and returns which kind of code it was
+func (t *authService) useSecondFactor(ctx context.Context, user *model.User, req VerifyMFAReq) (string, error) {
+	if user.MFAEnabledAt == nil {
+		return "", ErrInvalidMFAToken
+	}
+
+	if req.RecoveryCode != "" {
+		err := t.mfaRepo.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(req.RecoveryCode)))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrInvalidMFACode
+		}
+
+		return "recovery code", err
+	}
+
+	secret, err := openTOTPSecret(ctx, user)
+	if err != nil {
+		return "", err
+	}
+
+	step, ok := totp.Validate(secret, req.Code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return "", ErrInvalidMFACode
+	}
+
+	// a concurrent login with the same code got there first
+	err = t.mfaRepo.UseTOTPStep(ctx, user.ID, step)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrInvalidMFACode
+	}
+
+	return "totp", err
+}
+
+func (t *authService) ListRoleMFAPolicies(ctx context.Context) ([]model.RoleMFAPolicy, error) {
+	return t.mfaRepo.ListRolePolicies(ctx)
+}
+
+// SetRoleMFAPolicy requires, or stops requiring, MFA for every user of a role. Users of the role
+// without MFA have to enroll on their next login, sessions already started are not affected.
+func (t *authService) SetRoleMFAPolicy(ctx context.Context, req SetRoleMFAPolicyReq) error {
+	if !req.Role.Valid() {
+		return fmt.Errorf("%w : %s", ErrInvalidRole, req.Role)
+	}
+
+	err := t.mfaRepo.SetRolePolicy(ctx, &model.RoleMFAPolicy{
+		Role:        req.Role,
+		MFARequired: req.MFARequired,
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	actorID := uuid.MustParse(ctx.Value("userID").(string))
+	t.audit(ctx, model.AuditEventMFAPolicyChanged, nil, &actorID, LoginUserReq{},
+		fmt.Sprintf("%s mfa_required=%t", req.Role, req.MFARequired))
+
+	return nil
+}
+
+// newRecoveryCodes returns count recovery codes formatted as xxxxx-xxxxx and the rows storing their hashes
+func newRecoveryCodes(userID uuid.UUID, count int) ([]string, []*model.MFARecoveryCode, error) {
+	codes := make([]string, 0, count)
+	rows := make([]*model.MFARecoveryCode, 0, count)
+
+	for i := 0; i < count; i++ {
+		raw := make([]byte, RECOVERY_CODE_LENGTH)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		for j := range raw {
+			raw[j] = recoveryCodeAlphabet[int(raw[j])%len(recoveryCodeAlphabet)]
+		}
+
+		code := string(raw)
+		codes = append(codes, code[:RECOVERY_CODE_LENGTH/2]+"-"+code[RECOVERY_CODE_LENGTH/2:])
+		rows = append(rows, &model.MFARecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  hashToken(code),
+			CreatedAt: time.Now(),
+		})
+	}
+
+	return codes, rows, nil
+}
+
+// normalizeRecoveryCode accepts a recovery code however it was typed in
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// openTOTPSecret opens the TOTP secret of user, it is kept sealed so that reading the database does
+// not reveal it
+func openTOTPSecret(ctx context.Context, user *model.User) (string, error) {
+	secret, err := secretbox.Open(user.TOTPSecret)
+	if err != nil {
+		logger.E(ctx, err, "Failed to open TOTP secret", logger.Field("user_id", user.ID))
+		return "", err
+	}
+
+	return secret, nil
+}
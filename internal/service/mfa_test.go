@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+	"tigerhall_kittens/internal/secretbox"
+	"tigerhall_kittens/internal/throttle"
+	"tigerhall_kittens/internal/totp"
+)
+
+var testMFAConfig = MFAConfig{Issuer: "Tigerhall Kittens", ChallengeTTL: 5 * time.Minute, RecoveryCodes: 10}
+
+func mfaUser(t *testing.T) *model.User {
+	secret, err := totp.GenerateSecret()
+	assert.Nil(t, err)
+	sealed, err := secretbox.Seal(secret)
+	assert.Nil(t, err)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	enabledAt := time.Now().Add(-time.Hour)
+
+	return &model.User{
+		ID:              uuid.New(),
+		Username:        "ranger_ravi",
+		Password:        string(hashedPassword),
+		Role:            model.RoleResearcher,
+		EmailVerifiedAt: &enabledAt,
+		TOTPSecret:      sealed,
+		MFAEnabledAt:    &enabledAt,
+	}
+}
+
+// mfaCode returns the current code of the sealed secret of user
+func mfaCode(t *testing.T, user *model.User) string {
+	secret, err := secretbox.Open(user.TOTPSecret)
+	assert.Nil(t, err)
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	assert.Nil(t, err)
+
+	return code
+}
+
+func TestAuthService_LoginUser_MFA(t *testing.T) {
+	t.Run("should return an MFA challenge instead of tokens for users with MFA", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := mfaUser(t)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: user.Username}).Return(user, nil)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil).AnyTimes()
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithMFARepo(mock_repository.NewMockMFARepo(ctrl)),
+			WithMFAConfig(testMFAConfig),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
+		)
+
+		resp, err := authService.LoginUser(ctx, LoginUserReq{Username: user.Username, Password: "password"})
+		assert.Nil(t, err)
+		assert.True(t, resp.MFARequired)
+		assert.Empty(t, resp.AccessToken)
+		assert.Empty(t, resp.RefreshToken)
+
+		_, err = authService.Authenticate(ctx, resp.MFAToken)
+		assert.Equal(t, ErrInvalidAccessToken, err)
+
+		claims, err := authService.Authenticate(ctx, resp.MFAToken, TokenPurposeMFA)
+		assert.Nil(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+	})
+
+	t.Run("should require enrollment when the role requires MFA", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := mfaUser(t)
+		user.MFAEnabledAt = nil
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: user.Username}).Return(user, nil)
+
+		mockMFARepo := mock_repository.NewMockMFARepo(ctrl)
+		mockMFARepo.EXPECT().IsMFARequired(ctx, model.RoleResearcher).Return(true, nil)
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithMFARepo(mockMFARepo),
+			WithMFAConfig(testMFAConfig),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), testLoginPolicies),
+		)
+
+		resp, err := authService.LoginUser(ctx, LoginUserReq{Username: user.Username, Password: "password"})
+		assert.Nil(t, err)
+		assert.True(t, resp.MFAEnrollmentRequired)
+		assert.False(t, resp.MFARequired)
+		assert.Empty(t, resp.AccessToken)
+	})
+}
+
+func TestAuthService_VerifyMFA(t *testing.T) {
+	setup := func(t *testing.T, ctrl *gomock.Controller, user *model.User, mockMFARepo repository.MFARepo) (AuthService, string) {
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(gomock.Any(), repository.GetUserOpts{ID: user.ID}).Return(user, nil).AnyTimes()
+
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().CreateToken(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		authService := NewAuthService(
+			WithUserRepoForAuthService(mockUserRepo),
+			WithRefreshTokenRepo(mockRefreshTokenRepo),
+			WithMFARepo(mockMFARepo),
+			WithMFAConfig(testMFAConfig),
+			WithTokenTTLs(15*time.Minute, time.Hour),
+			WithAuditRepo(anyAuditRepo(ctrl)),
+			WithLoginThrottle(throttle.NewMemoryStore(time.Hour), LoginThrottleConfig{
+				Username: throttle.Policy{FreeFailures: 5, LockoutFailures: 2, LockoutDuration: time.Hour},
+				IP:       testLoginPolicies.IP,
+			}),
+		)
+
+		mfaToken, err := generatePurposeToken(user, time.Minute, TokenPurposeMFA)
+		assert.Nil(t, err)
+
+		return authService, mfaToken
+	}
+
+	t.Run("should start a session for a valid code and use up its step", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := mfaUser(t)
+		code := mfaCode(t, user)
+
+		mockMFARepo := mock_repository.NewMockMFARepo(ctrl)
+		mockMFARepo.EXPECT().UseTOTPStep(ctx, user.ID, gomock.Any()).Return(nil)
+
+		authService, mfaToken := setup(t, ctrl, user, mockMFARepo)
+
+		resp, err := authService.VerifyMFA(ctx, VerifyMFAReq{MFAToken: mfaToken, Code: code})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.NotEmpty(t, resp.RefreshToken)
+	})
+
+	t.Run("should reject a code whose step was already used", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := mfaUser(t)
+		code := mfaCode(t, user)
+
+		mockMFARepo := mock_repository.NewMockMFARepo(ctrl)
+		mockMFARepo.EXPECT().UseTOTPStep(ctx, user.ID, gomock.Any()).Return(gorm.ErrRecordNotFound)
+
+		authService, mfaToken := setup(t, ctrl, user, mockMFARepo)
+
+		_, err := authService.VerifyMFA(ctx, VerifyMFAReq{MFAToken: mfaToken, Code: code})
+		assert.Equal(t, ErrInvalidMFACode, err)
+	})
+
+	t.Run("should accept a recovery code however it is typed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := mfaUser(t)
+
+		mockMFARepo := mock_repository.NewMockMFARepo(ctrl)
+		mockMFARepo.EXPECT().UseRecoveryCode(ctx, user.ID, hashToken("abcde23456")).Return(nil)
+
+		authService, mfaToken := setup(t, ctrl, user, mockMFARepo)
+
+		resp, err := authService.VerifyMFA(ctx, VerifyMFAReq{MFAToken: mfaToken, RecoveryCode: " ABCDE-23456"})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+	})
+
+	t.Run("should count wrong codes as failed logins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := mfaUser(t)
+
+		authService, mfaToken := setup(t, ctrl, user, mock_repository.NewMockMFARepo(ctrl))
+
+		for i := 0; i < 2; i++ {
+			_, err := authService.VerifyMFA(ctx, VerifyMFAReq{MFAToken: mfaToken, Code: "000000x"})
+			assert.Equal(t, ErrInvalidMFACode, err)
+		}
+
+		_, err := authService.VerifyMFA(ctx, VerifyMFAReq{MFAToken: mfaToken, Code: "000000x"})
+		assert.True(t, errors.Is(err, ErrAccountLocked))
+	})
+
+	t.Run("should reject access tokens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := mfaUser(t)
+		authService, _ := setup(t, ctrl, user, mock_repository.NewMockMFARepo(ctrl))
+		accessToken, _ := generateJWTToken(user, time.Minute)
+
+		_, err := authService.VerifyMFA(context.Background(), VerifyMFAReq{MFAToken: accessToken, Code: "123456"})
+		assert.Equal(t, ErrInvalidMFAToken, err)
+	})
+}
+
+func TestAuthService_EnrollMFA(t *testing.T) {
+	t.Run("should save a sealed pending secret and return its otpauth URI", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := mfaUser(t)
+		user.MFAEnabledAt = nil
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		var saved string
+		mockMFARepo := mock_repository.NewMockMFARepo(ctrl)
+		mockMFARepo.EXPECT().SetPendingSecret(ctx, user.ID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uuid.UUID, secret string) error {
+				saved = secret
+				return nil
+			})
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo), WithMFARepo(mockMFARepo), WithMFAConfig(testMFAConfig))
+
+		enrollment, err := authService.EnrollMFA(ctx)
+		assert.Nil(t, err)
+		assert.NotEqual(t, enrollment.Secret, saved)
+		opened, err := secretbox.Open(saved)
+		assert.Nil(t, err)
+		assert.Equal(t, enrollment.Secret, opened)
+		assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Tigerhall%20Kittens:ranger_ravi?"))
+	})
+
+	t.Run("should return already enabled error for users with MFA", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := mfaUser(t)
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		authService := NewAuthService(WithUserRepoForAuthService(mockUserRepo), WithMFARepo(mock_repository.NewMockMFARepo(ctrl)))
+
+		_, err := authService.EnrollMFA(ctx)
+		assert.Equal(t, ErrMFAAlreadyEnabled, err)
+	})
+}
+
+func TestAuthService_ConfirmMFA(t *testing.T) {
+	setup := func(ctrl *gomock.Controller, ctx context.Context, user *model.User) AuthService {
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockMFARepo := mock_repository.NewMockMFARepo(ctrl)
+		mockMFARepo.EXPECT().EnableMFA(ctx, user.ID, totp.Step(time.Now()), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uuid.UUID, _ int64, codes []*model.MFARecoveryCode) error {
+				assert.Len(t, codes, testMFAConfig.RecoveryCodes)
+				return nil
+			})
+
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().CreateToken(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+		return NewAuthService(WithUserRepoForAuthService(mockUserRepo), WithMFARepo(mockMFARepo), WithMFAConfig(testMFAConfig),
+			WithRefreshTokenRepo(mockRefreshTokenRepo), WithAuditRepo(anyAuditRepo(ctrl)))
+	}
+
+	t.Run("should enable MFA and return recovery codes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := mfaUser(t)
+		user.MFAEnabledAt = nil
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+		code := mfaCode(t, user)
+
+		resp, err := setup(ctrl, ctx, user).ConfirmMFA(ctx, ConfirmMFAReq{Code: code})
+		assert.Nil(t, err)
+		assert.Len(t, resp.RecoveryCodes, testMFAConfig.RecoveryCodes)
+		assert.Regexp(t, "^[a-z2-9]{5}-[a-z2-9]{5}$", resp.RecoveryCodes[0])
+		assert.Nil(t, resp.LoginUserResponse)
+	})
+
+	t.Run("should finish the login of an enrollment required by the role", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := mfaUser(t)
+		user.MFAEnabledAt = nil
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+		ctx = context.WithValue(ctx, "tokenPurpose", string(TokenPurposeMFAEnrollment))
+		code := mfaCode(t, user)
+
+		resp, err := setup(ctrl, ctx, user).ConfirmMFA(ctx, ConfirmMFAReq{Code: code})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+	})
+}
+
+func TestAuthService_SetRoleMFAPolicy(t *testing.T) {
+	t.Run("should return invalid role error for unknown roles", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		authService := NewAuthService(WithMFARepo(mock_repository.NewMockMFARepo(ctrl)))
+
+		err := authService.SetRoleMFAPolicy(context.Background(), SetRoleMFAPolicyReq{Role: "ranger", MFARequired: true})
+		assert.True(t, errors.Is(err, ErrInvalidRole))
+	})
+}
@@ -7,9 +7,11 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
 	service "tigerhall_kittens/internal/service"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 )
 
 // MockAuthService is a mock of AuthService interface.
@@ -35,6 +37,100 @@ func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// Authenticate mocks base method.
+func (m *MockAuthService) Authenticate(ctx context.Context, token string, purposes ...service.TokenPurpose) (*service.Claims, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, token}
+	for _, a := range purposes {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Authenticate", varargs...)
+	ret0, _ := ret[0].(*service.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAuthServiceMockRecorder) Authenticate(ctx, token interface{}, purposes ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, token}, purposes...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthService)(nil).Authenticate), varargs...)
+}
+
+// ConfirmMFA mocks base method.
+func (m *MockAuthService) ConfirmMFA(ctx context.Context, req service.ConfirmMFAReq) (*service.ConfirmMFAResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmMFA", ctx, req)
+	ret0, _ := ret[0].(*service.ConfirmMFAResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmMFA indicates an expected call of ConfirmMFA.
+func (mr *MockAuthServiceMockRecorder) ConfirmMFA(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMFA", reflect.TypeOf((*MockAuthService)(nil).ConfirmMFA), ctx, req)
+}
+
+// EnrollMFA mocks base method.
+func (m *MockAuthService) EnrollMFA(ctx context.Context) (*service.MFAEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollMFA", ctx)
+	ret0, _ := ret[0].(*service.MFAEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollMFA indicates an expected call of EnrollMFA.
+func (mr *MockAuthServiceMockRecorder) EnrollMFA(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollMFA", reflect.TypeOf((*MockAuthService)(nil).EnrollMFA), ctx)
+}
+
+// FinishOIDCLogin mocks base method.
+func (m *MockAuthService) FinishOIDCLogin(ctx context.Context, req service.OIDCCallbackReq) (*service.LoginUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishOIDCLogin", ctx, req)
+	ret0, _ := ret[0].(*service.LoginUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishOIDCLogin indicates an expected call of FinishOIDCLogin.
+func (mr *MockAuthServiceMockRecorder) FinishOIDCLogin(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishOIDCLogin", reflect.TypeOf((*MockAuthService)(nil).FinishOIDCLogin), ctx, req)
+}
+
+// ListOIDCProviders mocks base method.
+func (m *MockAuthService) ListOIDCProviders(ctx context.Context) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOIDCProviders", ctx)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// ListOIDCProviders indicates an expected call of ListOIDCProviders.
+func (mr *MockAuthServiceMockRecorder) ListOIDCProviders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOIDCProviders", reflect.TypeOf((*MockAuthService)(nil).ListOIDCProviders), ctx)
+}
+
+// ListRoleMFAPolicies mocks base method.
+func (m *MockAuthService) ListRoleMFAPolicies(ctx context.Context) ([]model.RoleMFAPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoleMFAPolicies", ctx)
+	ret0, _ := ret[0].([]model.RoleMFAPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoleMFAPolicies indicates an expected call of ListRoleMFAPolicies.
+func (mr *MockAuthServiceMockRecorder) ListRoleMFAPolicies(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoleMFAPolicies", reflect.TypeOf((*MockAuthService)(nil).ListRoleMFAPolicies), ctx)
+}
+
 // LoginUser mocks base method.
 func (m *MockAuthService) LoginUser(ctx context.Context, req service.LoginUserReq) (*service.LoginUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -49,3 +145,90 @@ func (mr *MockAuthServiceMockRecorder) LoginUser(ctx, req interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockAuthService)(nil).LoginUser), ctx, req)
 }
+
+// Logout mocks base method.
+func (m *MockAuthService) Logout(ctx context.Context, req service.RefreshTokenReq) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthServiceMockRecorder) Logout(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthService)(nil).Logout), ctx, req)
+}
+
+// RefreshToken mocks base method.
+func (m *MockAuthService) RefreshToken(ctx context.Context, req service.RefreshTokenReq) (*service.LoginUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, req)
+	ret0, _ := ret[0].(*service.LoginUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockAuthServiceMockRecorder) RefreshToken(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockAuthService)(nil).RefreshToken), ctx, req)
+}
+
+// SetRoleMFAPolicy mocks base method.
+func (m *MockAuthService) SetRoleMFAPolicy(ctx context.Context, req service.SetRoleMFAPolicyReq) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRoleMFAPolicy", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRoleMFAPolicy indicates an expected call of SetRoleMFAPolicy.
+func (mr *MockAuthServiceMockRecorder) SetRoleMFAPolicy(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoleMFAPolicy", reflect.TypeOf((*MockAuthService)(nil).SetRoleMFAPolicy), ctx, req)
+}
+
+// StartOIDCLogin mocks base method.
+func (m *MockAuthService) StartOIDCLogin(ctx context.Context, provider string) (*service.OIDCAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartOIDCLogin", ctx, provider)
+	ret0, _ := ret[0].(*service.OIDCAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartOIDCLogin indicates an expected call of StartOIDCLogin.
+func (mr *MockAuthServiceMockRecorder) StartOIDCLogin(ctx, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartOIDCLogin", reflect.TypeOf((*MockAuthService)(nil).StartOIDCLogin), ctx, provider)
+}
+
+// UnlockUser mocks base method.
+func (m *MockAuthService) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockAuthServiceMockRecorder) UnlockUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockAuthService)(nil).UnlockUser), ctx, userID)
+}
+
+// VerifyMFA mocks base method.
+func (m *MockAuthService) VerifyMFA(ctx context.Context, req service.VerifyMFAReq) (*service.LoginUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMFA", ctx, req)
+	ret0, _ := ret[0].(*service.LoginUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockAuthServiceMockRecorder) VerifyMFA(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockAuthService)(nil).VerifyMFA), ctx, req)
+}
@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/geofence.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	model "tigerhall_kittens/internal/model"
+	repository "tigerhall_kittens/internal/repository"
+	service "tigerhall_kittens/internal/service"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockGeofenceService is a mock of GeofenceService interface.
+type MockGeofenceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockGeofenceServiceMockRecorder
+}
+
+// MockGeofenceServiceMockRecorder is the mock recorder for MockGeofenceService.
+type MockGeofenceServiceMockRecorder struct {
+	mock *MockGeofenceService
+}
+
+// NewMockGeofenceService creates a new mock instance.
+func NewMockGeofenceService(ctrl *gomock.Controller) *MockGeofenceService {
+	mock := &MockGeofenceService{ctrl: ctrl}
+	mock.recorder = &MockGeofenceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGeofenceService) EXPECT() *MockGeofenceServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateGeofence mocks base method.
+func (m *MockGeofenceService) CreateGeofence(ctx context.Context, req service.GeofenceReq) (*model.Geofence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGeofence", ctx, req)
+	ret0, _ := ret[0].(*model.Geofence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGeofence indicates an expected call of CreateGeofence.
+func (mr *MockGeofenceServiceMockRecorder) CreateGeofence(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGeofence", reflect.TypeOf((*MockGeofenceService)(nil).CreateGeofence), ctx, req)
+}
+
+// DeleteGeofence mocks base method.
+func (m *MockGeofenceService) DeleteGeofence(ctx context.Context, geofenceID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGeofence", ctx, geofenceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGeofence indicates an expected call of DeleteGeofence.
+func (mr *MockGeofenceServiceMockRecorder) DeleteGeofence(ctx, geofenceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGeofence", reflect.TypeOf((*MockGeofenceService)(nil).DeleteGeofence), ctx, geofenceID)
+}
+
+// GetGeofence mocks base method.
+func (m *MockGeofenceService) GetGeofence(ctx context.Context, geofenceID uuid.UUID) (*model.Geofence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGeofence", ctx, geofenceID)
+	ret0, _ := ret[0].(*model.Geofence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGeofence indicates an expected call of GetGeofence.
+func (mr *MockGeofenceServiceMockRecorder) GetGeofence(ctx, geofenceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGeofence", reflect.TypeOf((*MockGeofenceService)(nil).GetGeofence), ctx, geofenceID)
+}
+
+// ListGeofences mocks base method.
+func (m *MockGeofenceService) ListGeofences(ctx context.Context, opts repository.ListGeofencesOpts) ([]model.Geofence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGeofences", ctx, opts)
+	ret0, _ := ret[0].([]model.Geofence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGeofences indicates an expected call of ListGeofences.
+func (mr *MockGeofenceServiceMockRecorder) ListGeofences(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGeofences", reflect.TypeOf((*MockGeofenceService)(nil).ListGeofences), ctx, opts)
+}
+
+// UpdateGeofence mocks base method.
+func (m *MockGeofenceService) UpdateGeofence(ctx context.Context, geofenceID uuid.UUID, req service.GeofenceReq) (*model.Geofence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGeofence", ctx, geofenceID, req)
+	ret0, _ := ret[0].(*model.Geofence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateGeofence indicates an expected call of UpdateGeofence.
+func (mr *MockGeofenceServiceMockRecorder) UpdateGeofence(ctx, geofenceID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGeofence", reflect.TypeOf((*MockGeofenceService)(nil).UpdateGeofence), ctx, geofenceID, req)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/inbox.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	service "tigerhall_kittens/internal/service"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockInboxService is a mock of InboxService interface.
+type MockInboxService struct {
+	ctrl     *gomock.Controller
+	recorder *MockInboxServiceMockRecorder
+}
+
+// MockInboxServiceMockRecorder is the mock recorder for MockInboxService.
+type MockInboxServiceMockRecorder struct {
+	mock *MockInboxService
+}
+
+// NewMockInboxService creates a new mock instance.
+func NewMockInboxService(ctrl *gomock.Controller) *MockInboxService {
+	mock := &MockInboxService{ctrl: ctrl}
+	mock.recorder = &MockInboxServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInboxService) EXPECT() *MockInboxServiceMockRecorder {
+	return m.recorder
+}
+
+// ListNotifications mocks base method.
+func (m *MockInboxService) ListNotifications(ctx context.Context, req service.ListInboxReq) (*service.InboxPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", ctx, req)
+	ret0, _ := ret[0].(*service.InboxPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockInboxServiceMockRecorder) ListNotifications(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockInboxService)(nil).ListNotifications), ctx, req)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockInboxService) MarkAllRead(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockInboxServiceMockRecorder) MarkAllRead(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockInboxService)(nil).MarkAllRead), ctx)
+}
+
+// MarkRead mocks base method.
+func (m *MockInboxService) MarkRead(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockInboxServiceMockRecorder) MarkRead(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockInboxService)(nil).MarkRead), ctx, id)
+}
+
+// UnreadCount mocks base method.
+func (m *MockInboxService) UnreadCount(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnreadCount", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnreadCount indicates an expected call of UnreadCount.
+func (mr *MockInboxServiceMockRecorder) UnreadCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnreadCount", reflect.TypeOf((*MockInboxService)(nil).UnreadCount), ctx)
+}
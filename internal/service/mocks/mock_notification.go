@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/notification.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	notification_worker "tigerhall_kittens/cmd/notification_worker"
+	model "tigerhall_kittens/internal/model"
+	repository "tigerhall_kittens/internal/repository"
+	service "tigerhall_kittens/internal/service"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockNotificationService is a mock of NotificationService interface.
+type MockNotificationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationServiceMockRecorder
+}
+
+// MockNotificationServiceMockRecorder is the mock recorder for MockNotificationService.
+type MockNotificationServiceMockRecorder struct {
+	mock *MockNotificationService
+}
+
+// NewMockNotificationService creates a new mock instance.
+func NewMockNotificationService(ctrl *gomock.Controller) *MockNotificationService {
+	mock := &MockNotificationService{ctrl: ctrl}
+	mock.recorder = &MockNotificationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationService) EXPECT() *MockNotificationServiceMockRecorder {
+	return m.recorder
+}
+
+// ListOutboxNotifications mocks base method.
+func (m *MockNotificationService) ListOutboxNotifications(ctx context.Context, opts repository.ListOutboxOpts) ([]model.OutboxNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutboxNotifications", ctx, opts)
+	ret0, _ := ret[0].([]model.OutboxNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutboxNotifications indicates an expected call of ListOutboxNotifications.
+func (mr *MockNotificationServiceMockRecorder) ListOutboxNotifications(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutboxNotifications", reflect.TypeOf((*MockNotificationService)(nil).ListOutboxNotifications), ctx, opts)
+}
+
+// ListTemplates mocks base method.
+func (m *MockNotificationService) ListTemplates(ctx context.Context) []service.NotificationTemplate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTemplates", ctx)
+	ret0, _ := ret[0].([]service.NotificationTemplate)
+	return ret0
+}
+
+// ListTemplates indicates an expected call of ListTemplates.
+func (mr *MockNotificationServiceMockRecorder) ListTemplates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTemplates", reflect.TypeOf((*MockNotificationService)(nil).ListTemplates), ctx)
+}
+
+// PreviewTemplate mocks base method.
+func (m *MockNotificationService) PreviewTemplate(ctx context.Context, name, locale string) (*notification_worker.Content, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewTemplate", ctx, name, locale)
+	ret0, _ := ret[0].(*notification_worker.Content)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewTemplate indicates an expected call of PreviewTemplate.
+func (mr *MockNotificationServiceMockRecorder) PreviewTemplate(ctx, name, locale interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewTemplate", reflect.TypeOf((*MockNotificationService)(nil).PreviewTemplate), ctx, name, locale)
+}
+
+// ReplayNotification mocks base method.
+func (m *MockNotificationService) ReplayNotification(ctx context.Context, notificationID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayNotification", ctx, notificationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayNotification indicates an expected call of ReplayNotification.
+func (mr *MockNotificationServiceMockRecorder) ReplayNotification(ctx, notificationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayNotification", reflect.TypeOf((*MockNotificationService)(nil).ReplayNotification), ctx, notificationID)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/preferences.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	reflect "reflect"
+	service "tigerhall_kittens/internal/service"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPreferencesService is a mock of PreferencesService interface.
+type MockPreferencesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreferencesServiceMockRecorder
+}
+
+// MockPreferencesServiceMockRecorder is the mock recorder for MockPreferencesService.
+type MockPreferencesServiceMockRecorder struct {
+	mock *MockPreferencesService
+}
+
+// NewMockPreferencesService creates a new mock instance.
+func NewMockPreferencesService(ctrl *gomock.Controller) *MockPreferencesService {
+	mock := &MockPreferencesService{ctrl: ctrl}
+	mock.recorder = &MockPreferencesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPreferencesService) EXPECT() *MockPreferencesServiceMockRecorder {
+	return m.recorder
+}
+
+// GetPreferences mocks base method.
+func (m *MockPreferencesService) GetPreferences(ctx context.Context) (*service.PreferencesRes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx)
+	ret0, _ := ret[0].(*service.PreferencesRes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockPreferencesServiceMockRecorder) GetPreferences(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockPreferencesService)(nil).GetPreferences), ctx)
+}
+
+// MuteTiger mocks base method.
+func (m *MockPreferencesService) MuteTiger(ctx context.Context, tigerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteTiger", ctx, tigerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteTiger indicates an expected call of MuteTiger.
+func (mr *MockPreferencesServiceMockRecorder) MuteTiger(ctx, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteTiger", reflect.TypeOf((*MockPreferencesService)(nil).MuteTiger), ctx, tigerID)
+}
+
+// UnmuteTiger mocks base method.
+func (m *MockPreferencesService) UnmuteTiger(ctx context.Context, tigerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteTiger", ctx, tigerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteTiger indicates an expected call of UnmuteTiger.
+func (mr *MockPreferencesServiceMockRecorder) UnmuteTiger(ctx, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteTiger", reflect.TypeOf((*MockPreferencesService)(nil).UnmuteTiger), ctx, tigerID)
+}
+
+// UpdatePreferences mocks base method.
+func (m *MockPreferencesService) UpdatePreferences(ctx context.Context, req service.UpdatePreferencesReq) (*service.PreferencesRes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", ctx, req)
+	ret0, _ := ret[0].(*service.PreferencesRes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences.
+func (mr *MockPreferencesServiceMockRecorder) UpdatePreferences(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockPreferencesService)(nil).UpdatePreferences), ctx, req)
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 	model "tigerhall_kittens/internal/model"
 	repository "tigerhall_kittens/internal/repository"
+	service "tigerhall_kittens/internal/service"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -50,6 +51,20 @@ func (mr *MockTigerServiceMockRecorder) CreateTiger(ctx, tiger interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTiger", reflect.TypeOf((*MockTigerService)(nil).CreateTiger), ctx, tiger)
 }
 
+// DeleteTiger mocks base method.
+func (m *MockTigerService) DeleteTiger(ctx context.Context, tigerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTiger", ctx, tigerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTiger indicates an expected call of DeleteTiger.
+func (mr *MockTigerServiceMockRecorder) DeleteTiger(ctx, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTiger", reflect.TypeOf((*MockTigerService)(nil).DeleteTiger), ctx, tigerID)
+}
+
 // GetTiger mocks base method.
 func (m *MockTigerService) GetTiger(ctx context.Context, opts repository.GetTigerOpts) (*model.Tiger, error) {
 	m.ctrl.T.Helper()
@@ -79,3 +94,48 @@ func (mr *MockTigerServiceMockRecorder) ListTigers(ctx, opts interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTigers", reflect.TypeOf((*MockTigerService)(nil).ListTigers), ctx, opts)
 }
+
+// RecomputeLastSeen mocks base method.
+func (m *MockTigerService) RecomputeLastSeen(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecomputeLastSeen", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecomputeLastSeen indicates an expected call of RecomputeLastSeen.
+func (mr *MockTigerServiceMockRecorder) RecomputeLastSeen(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecomputeLastSeen", reflect.TypeOf((*MockTigerService)(nil).RecomputeLastSeen), ctx)
+}
+
+// RestoreTiger mocks base method.
+func (m *MockTigerService) RestoreTiger(ctx context.Context, tigerID uint) (*model.Tiger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTiger", ctx, tigerID)
+	ret0, _ := ret[0].(*model.Tiger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreTiger indicates an expected call of RestoreTiger.
+func (mr *MockTigerServiceMockRecorder) RestoreTiger(ctx, tigerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTiger", reflect.TypeOf((*MockTigerService)(nil).RestoreTiger), ctx, tigerID)
+}
+
+// UpdateTiger mocks base method.
+func (m *MockTigerService) UpdateTiger(ctx context.Context, tigerID uint, version int, req service.UpdateTigerReq) (*model.Tiger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTiger", ctx, tigerID, version, req)
+	ret0, _ := ret[0].(*model.Tiger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTiger indicates an expected call of UpdateTiger.
+func (mr *MockTigerServiceMockRecorder) UpdateTiger(ctx, tigerID, version, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTiger", reflect.TypeOf((*MockTigerService)(nil).UpdateTiger), ctx, tigerID, version, req)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+type NotificationTemplate struct {
+	Name    string   `json:"name"`
+	Locales []string `json:"locales"`
+}
+
+type NotificationService interface {
+	ListOutboxNotifications(ctx context.Context, opts repository.ListOutboxOpts) ([]model.OutboxNotification, error)
+	ReplayNotification(ctx context.Context, notificationID uuid.UUID) error
+	ListTemplates(ctx context.Context) []NotificationTemplate
+	PreviewTemplate(ctx context.Context, name, locale string) (*notification_worker.Content, error)
+}
+
+type notificationService struct {
+	outboxRepo repository.OutboxRepo
+}
+
+type NotificationServiceOption func(service *notificationService)
+
+func NewNotificationService(options ...NotificationServiceOption) NotificationService {
+	service := &notificationService{outboxRepo: repository.NewOutboxRepo()}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+func WithOutboxRepo(repo repository.OutboxRepo) NotificationServiceOption {
+	return func(s *notificationService) {
+		s.outboxRepo = repo
+	}
+}
+
+func (t *notificationService) ListOutboxNotifications(ctx context.Context, opts repository.ListOutboxOpts) ([]model.OutboxNotification, error) {
+	notifications, err := t.outboxRepo.GetNotifications(ctx, opts)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching outbox notifications", logger.Field("opts", opts))
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// ReplayNotification puts a dead-lettered notification back in the outbox. Account notifications
+// carrying a one-time token are refused, the token was dropped when they were given up on.
+func (t *notificationService) ReplayNotification(ctx context.Context, notificationID uuid.UUID) error {
+	notification, err := t.outboxRepo.GetNotification(ctx, notificationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Notification does not exist", logger.Field("notification_id", notificationID))
+		return ErrNotificationNotReplayable
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if notification_worker.CarriesSealedToken(notification.Subject) {
+		logger.W(ctx, "Notification carried a one-time token, not replaying it", logger.Field("notification_id", notificationID))
+		return ErrNotificationTokenDropped
+	}
+
+	err = t.outboxRepo.ReplayNotification(ctx, notificationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Notification is not dead-lettered", logger.Field("notification_id", notificationID))
+		return ErrNotificationNotReplayable
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while replaying notification", logger.Field("notification_id", notificationID))
+		return err
+	}
+
+	return nil
+}
+
+func (t *notificationService) ListTemplates(ctx context.Context) []NotificationTemplate {
+	var templates []NotificationTemplate
+	for _, name := range notification_worker.TemplateNames() {
+		templates = append(templates, NotificationTemplate{
+			Name:    name,
+			Locales: notification_worker.TemplateLocales(name),
+		})
+	}
+
+	return templates
+}
+
+// PreviewTemplate renders a notification template with sample data, falling back
+// to the default locale the same way real notifications do
+func (t *notificationService) PreviewTemplate(ctx context.Context, name, locale string) (*notification_worker.Content, error) {
+	content, err := notification_worker.PreviewTemplate(name, locale)
+	if errors.Is(err, notification_worker.ErrTemplateNotFound) {
+		logger.W(ctx, "Notification template does not exist", logger.Field("template", name))
+		return nil, ErrNotificationTemplateNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while rendering notification template", logger.Field("template", name), logger.Field("locale", locale))
+		return nil, err
+	}
+
+	return content, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestNotificationService_ListOutboxNotifications(t *testing.T) {
+	opts := repository.ListOutboxOpts{
+		Status: model.OutboxStatusDead,
+		Limit:  10,
+	}
+
+	t.Run("should return error when repo returns error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		expectedErr := errors.New("some db error")
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetNotifications(ctx, opts).Return(nil, expectedErr)
+
+		notificationService := NewNotificationService(WithOutboxRepo(mockOutboxRepo))
+
+		notifications, actualErr := notificationService.ListOutboxNotifications(ctx, opts)
+		assert.Nil(t, notifications)
+		assert.Equal(t, expectedErr, actualErr)
+	})
+
+	t.Run("should return notifications", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		mockNotifications := []model.OutboxNotification{
+			{ID: uuid.New(), Status: model.OutboxStatusDead, Attempts: 8},
+		}
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetNotifications(ctx, opts).Return(mockNotifications, nil)
+
+		notificationService := NewNotificationService(WithOutboxRepo(mockOutboxRepo))
+
+		notifications, actualErr := notificationService.ListOutboxNotifications(ctx, opts)
+		assert.Nil(t, actualErr)
+		assert.Equal(t, mockNotifications, notifications)
+	})
+}
+
+func TestNotificationService_ReplayNotification(t *testing.T) {
+	notificationID := uuid.New()
+	dead := &model.OutboxNotification{
+		ID:      notificationID,
+		Subject: notification_worker.EmailNotificationSubjectTigerSighting,
+		Status:  model.OutboxStatusDead,
+	}
+
+	t.Run("should return not replayable error when notification does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetNotification(ctx, notificationID).Return(nil, gorm.ErrRecordNotFound)
+
+		notificationService := NewNotificationService(WithOutboxRepo(mockOutboxRepo))
+
+		actualErr := notificationService.ReplayNotification(ctx, notificationID)
+		assert.Equal(t, ErrNotificationNotReplayable, actualErr)
+	})
+
+	t.Run("should return not replayable error when notification is not dead-lettered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetNotification(ctx, notificationID).Return(dead, nil)
+		mockOutboxRepo.EXPECT().ReplayNotification(ctx, notificationID).Return(gorm.ErrRecordNotFound)
+
+		notificationService := NewNotificationService(WithOutboxRepo(mockOutboxRepo))
+
+		actualErr := notificationService.ReplayNotification(ctx, notificationID)
+		assert.Equal(t, ErrNotificationNotReplayable, actualErr)
+	})
+
+	t.Run("should refuse a notification whose one-time token was dropped", func(t *testing.T) {
+		for _, subject := range []string{
+			notification_worker.EmailNotificationSubjectVerifyEmail,
+			notification_worker.EmailNotificationSubjectResetPassword,
+			notification_worker.EmailNotificationSubjectConfirmEmailChange,
+		} {
+			ctrl := gomock.NewController(t)
+			ctx := context.Background()
+
+			stripped := &model.OutboxNotification{
+				ID:      notificationID,
+				Subject: subject,
+				Payload: []byte(`{"user_id":"` + notificationID.String() + `"}`),
+				Status:  model.OutboxStatusDead,
+			}
+
+			mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+			mockOutboxRepo.EXPECT().GetNotification(ctx, notificationID).Return(stripped, nil)
+
+			notificationService := NewNotificationService(WithOutboxRepo(mockOutboxRepo))
+
+			actualErr := notificationService.ReplayNotification(ctx, notificationID)
+			assert.Equal(t, ErrNotificationTokenDropped, actualErr, subject)
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("should return error when repo returns any other error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		expectedErr := errors.New("some db error")
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetNotification(ctx, notificationID).Return(dead, nil)
+		mockOutboxRepo.EXPECT().ReplayNotification(ctx, notificationID).Return(expectedErr)
+
+		notificationService := NewNotificationService(WithOutboxRepo(mockOutboxRepo))
+
+		actualErr := notificationService.ReplayNotification(ctx, notificationID)
+		assert.Equal(t, expectedErr, actualErr)
+	})
+
+	t.Run("should replay notification", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockOutboxRepo := mock_repository.NewMockOutboxRepo(ctrl)
+		mockOutboxRepo.EXPECT().GetNotification(ctx, notificationID).Return(dead, nil)
+		mockOutboxRepo.EXPECT().ReplayNotification(ctx, notificationID).Return(nil)
+
+		notificationService := NewNotificationService(WithOutboxRepo(mockOutboxRepo))
+
+		actualErr := notificationService.ReplayNotification(ctx, notificationID)
+		assert.Nil(t, actualErr)
+	})
+}
+
+func TestNotificationService_PreviewTemplate(t *testing.T) {
+	t.Run("should return template not found error for unknown template", func(t *testing.T) {
+		notificationService := NewNotificationService()
+
+		content, actualErr := notificationService.PreviewTemplate(context.Background(), "unknown", "en")
+		assert.Nil(t, content)
+		assert.Equal(t, ErrNotificationTemplateNotFound, actualErr)
+	})
+
+	t.Run("should render template with sample data", func(t *testing.T) {
+		notificationService := NewNotificationService()
+
+		content, actualErr := notificationService.PreviewTemplate(context.Background(), "tiger_sighting", "en")
+		assert.Nil(t, actualErr)
+		assert.Contains(t, content.Subject, "Machli")
+		assert.NotEmpty(t, content.Text)
+		assert.NotEmpty(t, content.HTML)
+	})
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/oidc"
+	"tigerhall_kittens/internal/repository"
+)
+
+const MAX_PROVISIONED_USERNAME = 30
+
+// OIDCAuthorization is where the user is sent to sign in at their identity provider, which sends
+// them back to the redirect URL of the provider with the code and state of OIDCCallbackReq
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type OIDCCallbackReq struct {
+	Provider string `json:"-"`
+	Code     string `json:"code"`
+	State    string `json:"state"`
+	IP       string `json:"-"`
+}
+
+// ListOIDCProviders returns the names of the identity providers users can sign in with
+func (t *authService) ListOIDCProviders(ctx context.Context) []string {
+	names := make([]string, 0, len(t.oidcProviders))
+	for name := range t.oidcProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// StartOIDCLogin starts an authorization code login with PKCE at a provider. The state, nonce and
+// code verifier are kept until the callback, which has to come back within the login TTL.
+func (t *authService) StartOIDCLogin(ctx context.Context, providerName string) (*OIDCAuthorization, error) {
+	provider, ok := t.oidcProviders[providerName]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := newOpaqueToken()
+		if err != nil {
+			logger.E(ctx, err, "Failed to generate OIDC login secrets")
+			return nil, ErrTokenGenerationFailed
+		}
+		secrets[i] = secret
+	}
+	state, nonce, codeVerifier := secrets[0], secrets[1], secrets[2]
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(codeVerifier))
+	if err != nil {
+		logger.E(ctx, err, "Failed to start OIDC login", logger.Field("provider", providerName))
+		return nil, fmt.Errorf("%w : %s", ErrOIDCLoginFailed, err.Error())
+	}
+
+	now := time.Now()
+	err = t.oidcRepo.CreateLogin(ctx, &model.OIDCLogin{
+		ID:           uuid.New(),
+		Provider:     providerName,
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(t.oidcLoginTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCAuthorization{AuthorizationURL: authURL}, nil
+}
+
+// FinishOIDCLogin redeems the code a provider sent the user back with and logs in the user of
+// the identity it proves, like LoginUser does after checking a password. Users are found by the
+// identity, then by its email, and are provisioned when neither is known. Only verified emails
+// are trusted for finding or creating accounts.
+func (t *authService) FinishOIDCLogin(ctx context.Context, req OIDCCallbackReq) (*LoginUserResponse, error) {
+	provider, ok := t.oidcProviders[req.Provider]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+
+	login, err := t.oidcRepo.UseLogin(ctx, req.Provider, hashToken(req.State))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		logger.W(ctx, "OIDC login failed", logger.Field("provider", req.Provider), logger.Field("error", err.Error()))
+		return nil, fmt.Errorf("%w : %s", ErrOIDCLoginFailed, err.Error())
+	}
+
+	user, err := t.oidcUser(ctx, req.Provider, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	loginReq := LoginUserReq{Username: user.Username, IP: req.IP}
+	if user.Machine {
+		logger.W(ctx, "Login of a machine account", logger.Field("user_id", user.ID))
+		t.audit(ctx, model.AuditEventLoginFailed, &user.ID, nil, loginReq, "machine account")
+		return nil, fmt.Errorf("%w : machine accounts can not log in", ErrOIDCLoginFailed)
+	}
+
+	if user.SuspendedAt != nil {
+		logger.W(ctx, "Login of a suspended user", logger.Field("user_id", user.ID))
+		t.audit(ctx, model.AuditEventLoginFailed, &user.ID, nil, loginReq, "account suspended")
+		return nil, ErrAccountSuspended
+	}
+
+	challenge, err := t.mfaChallenge(ctx, user, loginReq)
+	if challenge != nil || err != nil {
+		return challenge, err
+	}
+
+	return t.startSession(ctx, user, loginReq, "oidc:"+req.Provider)
+}
+
+// oidcUser returns the user an identity belongs to, linking or provisioning it on its first login
+func (t *authService) oidcUser(ctx context.Context, provider string, identity *oidc.Identity) (*model.User, error) {
+	linked, err := t.oidcRepo.GetIdentity(ctx, provider, identity.Subject)
+	if err == nil {
+		user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: linked.UserID})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w : account was deleted", ErrOIDCLoginFailed)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		logger.W(ctx, "OIDC login without a verified email", logger.Field("provider", provider))
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	userIdentity := &model.UserIdentity{
+		ID:        uuid.New(),
+		Provider:  provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: time.Now(),
+	}
+
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{Email: identity.Email})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return t.provisionUser(ctx, identity, userIdentity)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	userIdentity.UserID = user.ID
+
+	// whoever signed up with an address they never verified does not get to keep the account
+	claimPasswordHash := ""
+	if user.EmailVerifiedAt == nil {
+		if claimPasswordHash, err = unusablePasswordHash(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.oidcRepo.LinkIdentity(ctx, userIdentity, claimPasswordHash); err != nil {
+		return nil, err
+	}
+
+	t.audit(ctx, model.AuditEventIdentityLinked, &user.ID, nil, LoginUserReq{Username: user.Username}, provider)
+
+	return user, nil
+}
+
+func (t *authService) provisionUser(ctx context.Context, identity *oidc.Identity, userIdentity *model.UserIdentity) (*model.User, error) {
+	username, err := t.provisionedUsername(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := unusablePasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &model.User{
+		ID:              uuid.New(),
+		Username:        username,
+		Password:        passwordHash,
+		Email:           identity.Email,
+		Locale:          notification_worker.DefaultLocale,
+		Role:            model.DefaultRole,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		EmailVerifiedAt: &now,
+	}
+	userIdentity.UserID = user.ID
+
+	if err := t.oidcRepo.ProvisionUser(ctx, user, userIdentity); err != nil {
+		return nil, ErrCreatingUser
+	}
+
+	t.audit(ctx, model.AuditEventUserProvisioned, &user.ID, nil, LoginUserReq{Username: user.Username}, userIdentity.Provider)
+
+	return user, nil
+}
+
+// provisionedUsername derives a username from the local part of an email, with a random suffix
+// when it is taken
+func (t *authService) provisionedUsername(ctx context.Context, email string) (string, error) {
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+
+	var username strings.Builder
+	for _, r := range local {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' {
+			username.WriteRune(r)
+		}
+	}
+
+	base := username.String()
+	if len(base) > MAX_PROVISIONED_USERNAME {
+		base = base[:MAX_PROVISIONED_USERNAME]
+	}
+
+	if base == "" {
+		base = "user"
+	}
+
+	_, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{Username: base})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return base, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	suffix, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	return base + "-" + strings.ToLower(suffix[:6]), nil
+}
+
+// unusablePasswordHash hashes a random password nobody knows, accounts signing in with an
+// identity provider can still set a password with a password reset
+func unusablePasswordHash() (string, error) {
+	password, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/oidc"
+	"tigerhall_kittens/internal/oidc/oidctest"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+var wwfUser = oidctest.User{Subject: "248289761001", Email: "Ravi.K@wwf.example.org", EmailVerified: true, Name: "Ravi"}
+
+func oidcProviders(t *testing.T) (oidc.Providers, *oidctest.Provider) {
+	mock, err := oidctest.NewProvider()
+	assert.Nil(t, err)
+	t.Cleanup(mock.Close)
+
+	return oidc.Providers{
+		"wwf": oidc.NewProvider(oidc.ProviderConfig{
+			Name:         "wwf",
+			Issuer:       mock.Issuer(),
+			ClientID:     oidctest.ClientID,
+			ClientSecret: oidctest.ClientSecret,
+			RedirectURL:  "https://kittens.example.org/sso/callback",
+		}, http.DefaultClient),
+	}, mock
+}
+
+// signIn starts a login, signs user in at the mock provider and returns the callback it redirects
+// back with. The login is handed out once by the OIDC repo mock.
+func signIn(t *testing.T, ctx context.Context, authService AuthService, mockOIDCRepo *mock_repository.MockOIDCRepo, mock *oidctest.Provider, user oidctest.User) OIDCCallbackReq {
+	var login *model.OIDCLogin
+	mockOIDCRepo.EXPECT().CreateLogin(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, l *model.OIDCLogin) error {
+		login = l
+		return nil
+	})
+
+	authorization, err := authService.StartOIDCLogin(ctx, "wwf")
+	assert.Nil(t, err)
+
+	code, state, err := mock.Authorize(authorization.AuthorizationURL, user)
+	assert.Nil(t, err)
+	assert.Equal(t, hashToken(state), login.StateHash)
+	assert.NotContains(t, authorization.AuthorizationURL, login.CodeVerifier)
+
+	mockOIDCRepo.EXPECT().UseLogin(ctx, "wwf", hashToken(state)).Return(login, nil)
+
+	return OIDCCallbackReq{Provider: "wwf", Code: code, State: state, IP: "10.0.0.1"}
+}
+
+func TestAuthService_FinishOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+
+	newService := func(ctrl *gomock.Controller, providers oidc.Providers, userRepo repository.UserRepo, oidcRepo repository.OIDCRepo, auditRepo repository.AuditRepo) AuthService {
+		mockRefreshTokenRepo := mock_repository.NewMockRefreshTokenRepo(ctrl)
+		mockRefreshTokenRepo.EXPECT().CreateToken(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+		return NewAuthService(
+			WithUserRepoForAuthService(userRepo),
+			WithRefreshTokenRepo(mockRefreshTokenRepo),
+			WithTokenTTLs(15*time.Minute, time.Hour),
+			WithMFARepo(noMFARepo(ctrl)),
+			WithAuditRepo(auditRepo),
+			WithOIDCRepo(oidcRepo),
+			WithOIDCProviders(providers, 10*time.Minute),
+		)
+	}
+
+	t.Run("should provision a user for an unknown identity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		providers, mock := oidcProviders(t)
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: wwfUser.Email}).Return(nil, gorm.ErrRecordNotFound)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Username: "ravi.k"}).Return(nil, gorm.ErrRecordNotFound)
+
+		mockOIDCRepo := mock_repository.NewMockOIDCRepo(ctrl)
+		mockOIDCRepo.EXPECT().GetIdentity(ctx, "wwf", wwfUser.Subject).Return(nil, gorm.ErrRecordNotFound)
+
+		var provisioned *model.User
+		mockOIDCRepo.EXPECT().ProvisionUser(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, user *model.User, identity *model.UserIdentity) error {
+				provisioned = user
+				assert.Equal(t, "ravi.k", user.Username)
+				assert.Equal(t, wwfUser.Email, user.Email)
+				assert.Equal(t, model.DefaultRole, user.Role)
+				assert.NotNil(t, user.EmailVerifiedAt)
+				assert.NotEmpty(t, user.Password)
+				assert.Equal(t, user.ID, identity.UserID)
+				assert.Equal(t, "wwf", identity.Provider)
+				assert.Equal(t, wwfUser.Subject, identity.Subject)
+				return nil
+			})
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventUserProvisioned, event.Type)
+			assert.Equal(t, "wwf", event.Reason)
+			return nil
+		})
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventLoginSucceeded, event.Type)
+			assert.Equal(t, "oidc:wwf", event.Reason)
+			assert.Equal(t, "10.0.0.1", event.IP)
+			return nil
+		})
+
+		authService := newService(ctrl, providers, mockUserRepo, mockOIDCRepo, mockAuditRepo)
+		req := signIn(t, ctx, authService, mockOIDCRepo, mock, wwfUser)
+
+		resp, err := authService.FinishOIDCLogin(ctx, req)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.NotEmpty(t, resp.RefreshToken)
+
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: provisioned.ID}).Return(provisioned, nil).AnyTimes()
+
+		claims, err := authService.Authenticate(ctx, resp.AccessToken)
+		assert.Nil(t, err)
+		assert.Equal(t, provisioned.ID, claims.UserID)
+	})
+
+	t.Run("should log in the user an identity is linked to", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		providers, mock := oidcProviders(t)
+		verifiedAt := time.Now().Add(-time.Hour)
+		user := &model.User{ID: uuid.New(), Username: "ranger_ravi", Role: model.RoleResearcher, EmailVerifiedAt: &verifiedAt}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil).AnyTimes()
+
+		mockOIDCRepo := mock_repository.NewMockOIDCRepo(ctrl)
+		mockOIDCRepo.EXPECT().GetIdentity(ctx, "wwf", wwfUser.Subject).Return(&model.UserIdentity{UserID: user.ID}, nil)
+
+		authService := newService(ctrl, providers, mockUserRepo, mockOIDCRepo, anyAuditRepo(ctrl))
+		req := signIn(t, ctx, authService, mockOIDCRepo, mock, wwfUser)
+
+		resp, err := authService.FinishOIDCLogin(ctx, req)
+		assert.Nil(t, err)
+
+		claims, err := authService.Authenticate(ctx, resp.AccessToken)
+		assert.Nil(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.Equal(t, model.RoleResearcher, claims.Role)
+	})
+
+	t.Run("should link the identity to the user with its verified email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		providers, mock := oidcProviders(t)
+		verifiedAt := time.Now().Add(-time.Hour)
+		user := &model.User{ID: uuid.New(), Username: "ranger_ravi", Email: wwfUser.Email, Role: model.RoleResearcher, EmailVerifiedAt: &verifiedAt}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: wwfUser.Email}).Return(user, nil)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil).AnyTimes()
+
+		mockOIDCRepo := mock_repository.NewMockOIDCRepo(ctrl)
+		mockOIDCRepo.EXPECT().GetIdentity(ctx, "wwf", wwfUser.Subject).Return(nil, gorm.ErrRecordNotFound)
+		mockOIDCRepo.EXPECT().LinkIdentity(ctx, gomock.Any(), "").DoAndReturn(func(_ context.Context, identity *model.UserIdentity, _ string) error {
+			assert.Equal(t, user.ID, identity.UserID)
+			return nil
+		})
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventIdentityLinked, event.Type)
+			assert.Equal(t, user.ID, *event.UserID)
+			return nil
+		})
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).Return(nil)
+
+		authService := newService(ctrl, providers, mockUserRepo, mockOIDCRepo, mockAuditRepo)
+		req := signIn(t, ctx, authService, mockOIDCRepo, mock, wwfUser)
+
+		_, err := authService.FinishOIDCLogin(ctx, req)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should take the password of an unverified account over when linking to it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		providers, mock := oidcProviders(t)
+		user := &model.User{ID: uuid.New(), Username: "squatter", Password: "known", Email: wwfUser.Email, Role: model.DefaultRole}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: wwfUser.Email}).Return(user, nil)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil).AnyTimes()
+
+		mockOIDCRepo := mock_repository.NewMockOIDCRepo(ctrl)
+		mockOIDCRepo.EXPECT().GetIdentity(ctx, "wwf", wwfUser.Subject).Return(nil, gorm.ErrRecordNotFound)
+		mockOIDCRepo.EXPECT().LinkIdentity(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *model.UserIdentity, claimPasswordHash string) error {
+			assert.True(t, strings.HasPrefix(claimPasswordHash, "$2a$"))
+			return nil
+		})
+
+		authService := newService(ctrl, providers, mockUserRepo, mockOIDCRepo, anyAuditRepo(ctrl))
+		req := signIn(t, ctx, authService, mockOIDCRepo, mock, wwfUser)
+
+		_, err := authService.FinishOIDCLogin(ctx, req)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should refuse an identity without a verified email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		providers, mock := oidcProviders(t)
+
+		mockOIDCRepo := mock_repository.NewMockOIDCRepo(ctrl)
+		mockOIDCRepo.EXPECT().GetIdentity(ctx, "wwf", "unverified").Return(nil, gorm.ErrRecordNotFound)
+
+		authService := newService(ctrl, providers, mock_repository.NewMockUserRepo(ctrl), mockOIDCRepo, anyAuditRepo(ctrl))
+		req := signIn(t, ctx, authService, mockOIDCRepo, mock, oidctest.User{Subject: "unverified", Email: wwfUser.Email})
+
+		_, err := authService.FinishOIDCLogin(ctx, req)
+		assert.Equal(t, ErrOIDCEmailNotVerified, err)
+	})
+
+	t.Run("should refuse an unknown or used state", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		providers, _ := oidcProviders(t)
+
+		mockOIDCRepo := mock_repository.NewMockOIDCRepo(ctrl)
+		mockOIDCRepo.EXPECT().UseLogin(ctx, "wwf", hashToken("forged")).Return(nil, gorm.ErrRecordNotFound)
+
+		authService := newService(ctrl, providers, mock_repository.NewMockUserRepo(ctrl), mockOIDCRepo, anyAuditRepo(ctrl))
+
+		_, err := authService.FinishOIDCLogin(ctx, OIDCCallbackReq{Provider: "wwf", Code: "code", State: "forged"})
+		assert.Equal(t, ErrInvalidOIDCState, err)
+	})
+
+	t.Run("should refuse an unknown provider", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		providers, _ := oidcProviders(t)
+		authService := newService(ctrl, providers, mock_repository.NewMockUserRepo(ctrl), mock_repository.NewMockOIDCRepo(ctrl), anyAuditRepo(ctrl))
+
+		_, err := authService.StartOIDCLogin(ctx, "okta")
+		assert.Equal(t, ErrOIDCProviderNotFound, err)
+
+		_, err = authService.FinishOIDCLogin(ctx, OIDCCallbackReq{Provider: "okta", Code: "code", State: "state"})
+		assert.Equal(t, ErrOIDCProviderNotFound, err)
+	})
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+type UpdatePreferencesReq struct {
+	EmailEnabled    bool               `json:"email_enabled"`
+	WebhookEnabled  bool               `json:"webhook_enabled"`
+	InAppEnabled    bool               `json:"in_app_enabled"`
+	Delivery        model.DeliveryMode `json:"delivery"`
+	QuietHoursStart *string            `json:"quiet_hours_start"`
+	QuietHoursEnd   *string            `json:"quiet_hours_end"`
+	TimeZone        string             `json:"time_zone"`
+}
+
+type PreferencesRes struct {
+	*model.NotificationPreferences
+	MutedTigerIDs []uint `json:"muted_tiger_ids"`
+}
+
+type PreferencesService interface {
+	GetPreferences(ctx context.Context) (*PreferencesRes, error)
+	UpdatePreferences(ctx context.Context, req UpdatePreferencesReq) (*PreferencesRes, error)
+	MuteTiger(ctx context.Context, tigerID uint) error
+	UnmuteTiger(ctx context.Context, tigerID uint) error
+}
+
+type preferencesService struct {
+	tigerService    TigerService
+	preferencesRepo repository.PreferencesRepo
+}
+
+type PreferencesServiceOption func(service *preferencesService)
+
+func NewPreferencesService(options ...PreferencesServiceOption) PreferencesService {
+	service := &preferencesService{
+		tigerService:    NewTigerService(),
+		preferencesRepo: repository.NewPreferencesRepo(),
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+func WithPreferencesRepo(repo repository.PreferencesRepo) PreferencesServiceOption {
+	return func(s *preferencesService) {
+		s.preferencesRepo = repo
+	}
+}
+
+func WithTigerServiceForPreferences(tigerService TigerService) PreferencesServiceOption {
+	return func(s *preferencesService) {
+		s.tigerService = tigerService
+	}
+}
+
+// GetPreferences returns the notification preferences of the logged-in user along with the tigers they muted
+func (t *preferencesService) GetPreferences(ctx context.Context) (*PreferencesRes, error) {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	preferences, err := t.preferencesRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching notification preferences", logger.Field("user_id", userID))
+		return nil, err
+	}
+
+	mutedTigerIDs, err := t.preferencesRepo.GetMutedTigers(ctx, userID)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching muted tigers", logger.Field("user_id", userID))
+		return nil, err
+	}
+
+	return &PreferencesRes{NotificationPreferences: preferences, MutedTigerIDs: mutedTigerIDs}, nil
+}
+
+// UpdatePreferences replaces the notification preferences of the logged-in user
+func (t *preferencesService) UpdatePreferences(ctx context.Context, req UpdatePreferencesReq) (*PreferencesRes, error) {
+	if err := validatePreferences(&req); err != nil {
+		logger.W(ctx, "Invalid notification preferences", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+	now := time.Now()
+
+	err := t.preferencesRepo.SavePreferences(ctx, &model.NotificationPreferences{
+		UserID:          userID,
+		EmailEnabled:    req.EmailEnabled,
+		WebhookEnabled:  req.WebhookEnabled,
+		InAppEnabled:    req.InAppEnabled,
+		Delivery:        req.Delivery,
+		QuietHoursStart: req.QuietHoursStart,
+		QuietHoursEnd:   req.QuietHoursEnd,
+		TimeZone:        req.TimeZone,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		logger.E(ctx, err, "Error while saving notification preferences", logger.Field("user_id", userID))
+		return nil, err
+	}
+
+	return t.GetPreferences(ctx)
+}
+
+// MuteTiger stops notifications about a tiger for the logged-in user
+func (t *preferencesService) MuteTiger(ctx context.Context, tigerID uint) error {
+	_, err := t.tigerService.GetTiger(ctx, repository.GetTigerOpts{TigerID: tigerID})
+	if errors.Is(err, ErrTigerNotFound) {
+		return ErrTigerDoesNotExist
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching tiger details", logger.Field("tiger_id", tigerID))
+		return ErrFetchingTigerDetails
+	}
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+	if err := t.preferencesRepo.MuteTiger(ctx, userID, tigerID); err != nil {
+		logger.E(ctx, err, "Error while muting tiger", logger.Field("tiger_id", tigerID), logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+func (t *preferencesService) UnmuteTiger(ctx context.Context, tigerID uint) error {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	err := t.preferencesRepo.UnmuteTiger(ctx, userID, tigerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Tiger is not muted", logger.Field("tiger_id", tigerID), logger.Field("user_id", userID))
+		return ErrTigerNotMuted
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while unmuting tiger", logger.Field("tiger_id", tigerID), logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// validatePreferences checks req and fills in the defaults for an empty delivery mode and time zone
+func validatePreferences(req *UpdatePreferencesReq) error {
+	switch req.Delivery {
+	case "":
+		req.Delivery = model.DeliveryImmediate
+	case model.DeliveryImmediate, model.DeliveryHourlyDigest, model.DeliveryDailyDigest:
+	default:
+		return fmt.Errorf("%w : delivery must be one of immediate, hourly and daily", ErrInvalidPreferences)
+	}
+
+	if req.TimeZone == "" {
+		req.TimeZone = "UTC"
+	}
+
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		return fmt.Errorf("%w : unknown time_zone %q", ErrInvalidPreferences, req.TimeZone)
+	}
+
+	if (req.QuietHoursStart == nil) != (req.QuietHoursEnd == nil) {
+		return fmt.Errorf("%w : quiet_hours_start and quiet_hours_end must be set together", ErrInvalidPreferences)
+	}
+
+	for _, quietHours := range []*string{req.QuietHoursStart, req.QuietHoursEnd} {
+		if quietHours == nil {
+			continue
+		}
+
+		if _, err := time.Parse("15:04", *quietHours); err != nil {
+			return fmt.Errorf("%w : quiet hours must be formatted as HH:MM", ErrInvalidPreferences)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestPreferencesService_UpdatePreferences(t *testing.T) {
+	userID := uuid.New()
+	start, end, invalid := "22:00", "06:00", "10pm"
+
+	invalidReqs := map[string]UpdatePreferencesReq{
+		"unknown delivery mode": {Delivery: "weekly"},
+		"unknown time zone":     {TimeZone: "Mars/Olympus_Mons"},
+		"half open quiet hours": {QuietHoursStart: &start},
+		"malformed quiet hours": {QuietHoursStart: &invalid, QuietHoursEnd: &end},
+	}
+
+	for name, req := range invalidReqs {
+		req := req
+		t.Run("should return invalid preferences error for "+name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+			preferencesService := NewPreferencesService(WithPreferencesRepo(mock_repository.NewMockPreferencesRepo(ctrl)))
+
+			preferences, actualErr := preferencesService.UpdatePreferences(ctx, req)
+			assert.Nil(t, preferences)
+			assert.True(t, errors.Is(actualErr, ErrInvalidPreferences))
+		})
+	}
+
+	t.Run("should save preferences with defaults filled in", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().SavePreferences(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, preferences *model.NotificationPreferences) error {
+				assert.Equal(t, userID, preferences.UserID)
+				assert.Equal(t, model.DeliveryImmediate, preferences.Delivery)
+				assert.Equal(t, "UTC", preferences.TimeZone)
+				assert.Equal(t, &start, preferences.QuietHoursStart)
+				return nil
+			})
+		mockPreferencesRepo.EXPECT().GetPreferences(ctx, userID).Return(model.DefaultNotificationPreferences(userID), nil)
+		mockPreferencesRepo.EXPECT().GetMutedTigers(ctx, userID).Return([]uint{3}, nil)
+
+		preferencesService := NewPreferencesService(WithPreferencesRepo(mockPreferencesRepo))
+
+		preferences, actualErr := preferencesService.UpdatePreferences(ctx, UpdatePreferencesReq{
+			EmailEnabled:    true,
+			QuietHoursStart: &start,
+			QuietHoursEnd:   &end,
+		})
+		assert.Nil(t, actualErr)
+		assert.Equal(t, []uint{3}, preferences.MutedTigerIDs)
+	})
+}
+
+func TestPreferencesService_UnmuteTiger(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("should return tiger not muted error when tiger was not muted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockPreferencesRepo := mock_repository.NewMockPreferencesRepo(ctrl)
+		mockPreferencesRepo.EXPECT().UnmuteTiger(ctx, userID, uint(1)).Return(gorm.ErrRecordNotFound)
+
+		preferencesService := NewPreferencesService(WithPreferencesRepo(mockPreferencesRepo))
+
+		actualErr := preferencesService.UnmuteTiger(ctx, 1)
+		assert.Equal(t, ErrTigerNotMuted, actualErr)
+	})
+}
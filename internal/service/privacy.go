@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+type EraseAccountReq struct {
+	// Password confirms the erasure, accounts without one are erased by an admin
+	Password string `json:"password"`
+}
+
+// RequestDataExport queues an export of the personal data of the logged-in user, which the
+// notification worker builds and mails them about. An export still being built is returned
+// instead of queueing another one.
+func (t *userService) RequestDataExport(ctx context.Context, ip string) (*model.DataExport, error) {
+	user, err := t.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := t.dataExportRepo.GetExport(ctx, repository.GetDataExportOpts{UserID: user.ID, Pending: true})
+	if err == nil {
+		return pending, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	export := &model.DataExport{
+		ID:            uuid.New(),
+		UserID:        user.ID,
+		Status:        model.DataExportStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := t.dataExportRepo.CreateExport(ctx, export); err != nil {
+		return nil, err
+	}
+
+	t.audit(ctx, model.AuditEventDataExportRequested, user, ip, export.ID.String())
+
+	return export, nil
+}
+
+// GetDataExport returns an export of the logged-in user, without its archive
+func (t *userService) GetDataExport(ctx context.Context, exportID uuid.UUID) (*model.DataExport, error) {
+	return t.dataExport(ctx, repository.GetDataExportOpts{ID: exportID})
+}
+
+// DownloadDataExport returns a ready export of the logged-in user along with its archive
+func (t *userService) DownloadDataExport(ctx context.Context, exportID uuid.UUID) (*model.DataExport, error) {
+	export, err := t.dataExport(ctx, repository.GetDataExportOpts{ID: exportID, WithArchive: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Status != model.DataExportStatusReady {
+		return nil, ErrDataExportNotReady
+	}
+
+	return export, nil
+}
+
+// dataExport looks up an export of the logged-in user, expired exports are gone even before the
+// worker deletes them
+func (t *userService) dataExport(ctx context.Context, opts repository.GetDataExportOpts) (*model.DataExport, error) {
+	opts.UserID = uuid.MustParse(ctx.Value("userID").(string))
+
+	export, err := t.dataExportRepo.GetExport(ctx, opts)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDataExportNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(time.Now()) {
+		return nil, ErrDataExportNotFound
+	}
+
+	return export, nil
+}
+
+// EraseAccount erases the personal data of the logged-in user once their password is confirmed,
+// see EraseUser
+func (t *userService) EraseAccount(ctx context.Context, req EraseAccountReq) error {
+	user, err := t.currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		logger.W(ctx, "Incorrect password to erase account", logger.Field("user_id", user.ID))
+		return ErrIncorrectPassword
+	}
+
+	erased, err := t.userRepo.EraseUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	t.audit(ctx, model.AuditEventUserErased, erased, "", "")
+
+	return nil
+}
+
+// EraseUser anonymises a user, deleted or not, and deletes everything else about them. Their
+// sightings are kept as scientific records under the tombstone user. Admins can not erase
+// themselves.
+func (t *userService) EraseUser(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.MustParse(ctx.Value("userID").(string)) {
+		return ErrCannotManageOwnAccount
+	}
+
+	erased, err := t.userRepo.EraseUser(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "User does not exist or is already erased", logger.Field("user_id", userID))
+		return ErrUserNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	t.adminAudit(ctx, model.AuditEventUserErased, erased, "")
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestUserService_RequestDataExport(t *testing.T) {
+	t.Run("should return the export still being built", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+		pending := &model.DataExport{ID: uuid.New(), UserID: user.ID, Status: model.DataExportStatusPending}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockDataExportRepo := mock_repository.NewMockDataExportRepo(ctrl)
+		mockDataExportRepo.EXPECT().GetExport(ctx, repository.GetDataExportOpts{UserID: user.ID, Pending: true}).Return(pending, nil)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithDataExportRepo(mockDataExportRepo))
+
+		export, err := userService.RequestDataExport(ctx, "10.0.0.1")
+		assert.Nil(t, err)
+		assert.Equal(t, pending, export)
+	})
+
+	t.Run("should queue an export and audit it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		mockDataExportRepo := mock_repository.NewMockDataExportRepo(ctrl)
+		mockDataExportRepo.EXPECT().GetExport(ctx, repository.GetDataExportOpts{UserID: user.ID, Pending: true}).Return(nil, gorm.ErrRecordNotFound)
+		mockDataExportRepo.EXPECT().CreateExport(ctx, gomock.Any()).Return(nil)
+
+		var exportID uuid.UUID
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventDataExportRequested, event.Type)
+			assert.Equal(t, "10.0.0.1", event.IP)
+			exportID = uuid.MustParse(event.Reason)
+			return nil
+		})
+
+		userService := NewUserService(
+			WithUserRepo(mockUserRepo),
+			WithDataExportRepo(mockDataExportRepo),
+			WithAuditRepoForUserService(mockAuditRepo),
+		)
+
+		export, err := userService.RequestDataExport(ctx, "10.0.0.1")
+		assert.Nil(t, err)
+		assert.Equal(t, exportID, export.ID)
+		assert.Equal(t, user.ID, export.UserID)
+		assert.Equal(t, model.DataExportStatusPending, export.Status)
+	})
+}
+
+func TestUserService_DownloadDataExport(t *testing.T) {
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), "userID", userID.String())
+	exportID := uuid.New()
+	opts := repository.GetDataExportOpts{ID: exportID, UserID: userID, WithArchive: true}
+
+	t.Run("should return a ready export with its archive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expiresAt := time.Now().Add(time.Hour)
+		ready := &model.DataExport{ID: exportID, UserID: userID, Status: model.DataExportStatusReady, Archive: []byte("zip"), ExpiresAt: &expiresAt}
+
+		mockDataExportRepo := mock_repository.NewMockDataExportRepo(ctrl)
+		mockDataExportRepo.EXPECT().GetExport(ctx, opts).Return(ready, nil)
+
+		export, err := NewUserService(WithDataExportRepo(mockDataExportRepo)).DownloadDataExport(ctx, exportID)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("zip"), export.Archive)
+	})
+
+	t.Run("should return error when the export is not ready yet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDataExportRepo := mock_repository.NewMockDataExportRepo(ctrl)
+		mockDataExportRepo.EXPECT().GetExport(ctx, opts).Return(&model.DataExport{ID: exportID, Status: model.DataExportStatusPending}, nil)
+
+		_, err := NewUserService(WithDataExportRepo(mockDataExportRepo)).DownloadDataExport(ctx, exportID)
+		assert.Equal(t, ErrDataExportNotReady, err)
+	})
+
+	t.Run("should return error when the export has expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expiresAt := time.Now().Add(-time.Minute)
+		mockDataExportRepo := mock_repository.NewMockDataExportRepo(ctrl)
+		mockDataExportRepo.EXPECT().GetExport(ctx, opts).Return(&model.DataExport{ID: exportID, Status: model.DataExportStatusReady, ExpiresAt: &expiresAt}, nil)
+
+		_, err := NewUserService(WithDataExportRepo(mockDataExportRepo)).DownloadDataExport(ctx, exportID)
+		assert.Equal(t, ErrDataExportNotFound, err)
+	})
+
+	t.Run("should return error when the export belongs to someone else", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDataExportRepo := mock_repository.NewMockDataExportRepo(ctrl)
+		mockDataExportRepo.EXPECT().GetExport(ctx, opts).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewUserService(WithDataExportRepo(mockDataExportRepo)).DownloadDataExport(ctx, exportID)
+		assert.Equal(t, ErrDataExportNotFound, err)
+	})
+}
+
+func TestUserService_EraseAccount(t *testing.T) {
+	t.Run("should return error when the password is incorrect", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		err := NewUserService(WithUserRepo(mockUserRepo)).EraseAccount(ctx, EraseAccountReq{Password: "wrong"})
+		assert.Equal(t, ErrIncorrectPassword, err)
+	})
+
+	t.Run("should erase the account and audit it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+		erased := &model.User{ID: user.ID, Username: "erased-" + user.ID.String()}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+		mockUserRepo.EXPECT().EraseUser(ctx, user.ID).Return(erased, nil)
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventUserErased, event.Type)
+			assert.Equal(t, erased.Username, event.Username)
+			return nil
+		})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithAuditRepoForUserService(mockAuditRepo))
+
+		assert.Nil(t, userService.EraseAccount(ctx, EraseAccountReq{Password: "password"}))
+	})
+}
+
+func TestUserService_EraseUser(t *testing.T) {
+	t.Run("should not let an admin erase themselves", func(t *testing.T) {
+		ctx := adminContext()
+		adminID := uuid.MustParse(ctx.Value("userID").(string))
+
+		err := NewUserService().EraseUser(ctx, adminID)
+		assert.Equal(t, ErrCannotManageOwnAccount, err)
+	})
+
+	t.Run("should return error when the user is already erased", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := adminContext()
+		userID := uuid.New()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().EraseUser(ctx, userID).Return(nil, gorm.ErrRecordNotFound)
+
+		err := NewUserService(WithUserRepo(mockUserRepo)).EraseUser(ctx, userID)
+		assert.Equal(t, ErrUserNotFound, err)
+	})
+}
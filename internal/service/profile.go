@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+const (
+	MAX_DISPLAY_NAME  = 100
+	MAX_ORGANISATION  = 100
+	MAX_EMAIL_ADDRESS = 255
+)
+
+// Profile is what a user sees and edits of their own account
+type Profile struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	// PendingEmail is a new address waiting for the user to confirm it
+	PendingEmail *string    `json:"pending_email,omitempty"`
+	DisplayName  string     `json:"display_name"`
+	Organisation string     `json:"organisation"`
+	TimeZone     string     `json:"time_zone"`
+	Locale       string     `json:"locale"`
+	Role         model.Role `json:"role"`
+	MFAEnabled   bool       `json:"mfa_enabled"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// UpdateProfileReq changes the fields that are set. A new email only replaces the current one once
+// the user confirms it with the token mailed to it.
+type UpdateProfileReq struct {
+	DisplayName  *string `json:"display_name,omitempty"`
+	Email        *string `json:"email,omitempty"`
+	Organisation *string `json:"organisation,omitempty"`
+	TimeZone     *string `json:"time_zone,omitempty"`
+	IP           string  `json:"-"`
+}
+
+type ChangePasswordReq struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+	IP              string `json:"-"`
+}
+
+func newProfile(user *model.User) *Profile {
+	return &Profile{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		PendingEmail: user.PendingEmail,
+		DisplayName:  user.DisplayName,
+		Organisation: user.Organisation,
+		TimeZone:     user.TimeZone,
+		Locale:       user.Locale,
+		Role:         user.Role,
+		MFAEnabled:   user.MFAEnabledAt != nil,
+		CreatedAt:    user.CreatedAt,
+	}
+}
+
+// GetProfile returns the profile of the logged-in user
+func (t *userService) GetProfile(ctx context.Context) (*Profile, error) {
+	user, err := t.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newProfile(user), nil
+}
+
+// UpdateProfile validates and applies the changes to the profile of the logged-in user. Nothing is
+// changed unless every field is valid.
+func (t *userService) UpdateProfile(ctx context.Context, req UpdateProfileReq) (*Profile, error) {
+	user, err := t.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, changed, err := profileChanges(user, req)
+	if err != nil {
+		return nil, err
+	}
+
+	newEmail := ""
+	if req.Email != nil {
+		if newEmail, err = t.emailChange(ctx, user, *req.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(changed) > 0 {
+		if user, err = t.userRepo.UpdateProfile(ctx, user.ID, opts); err != nil {
+			return nil, err
+		}
+
+		t.audit(ctx, model.AuditEventProfileUpdated, user, req.IP, strings.Join(changed, ","))
+	}
+
+	if newEmail != "" {
+		token, notification, err := t.newEmailChangeToken(user, newEmail)
+		if err != nil {
+			logger.E(ctx, err, "Failed to generate email change token", logger.Field("user_id", user.ID))
+			return nil, ErrTokenGenerationFailed
+		}
+
+		if err := t.userRepo.RequestEmailChange(ctx, user.ID, newEmail, token, notification); err != nil {
+			return nil, err
+		}
+		user.PendingEmail = &newEmail
+
+		t.audit(ctx, model.AuditEventEmailChangeRequested, user, req.IP, newEmail)
+	}
+
+	return newProfile(user), nil
+}
+
+// ChangePassword sets a new password for the logged-in user after checking their current one, and
+// signs them out of every session
+func (t *userService) ChangePassword(ctx context.Context, req ChangePasswordReq) error {
+	user, err := t.currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)) != nil {
+		logger.W(ctx, "Password change with an incorrect current password", logger.Field("user_id", user.ID))
+		return ErrIncorrectPassword
+	}
+
+	if len(req.NewPassword) < MIN_PASSWORD_LENGTH {
+		return fmt.Errorf("%w : password must be at least %d characters", ErrInvalidUserDetails, MIN_PASSWORD_LENGTH)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		logger.E(ctx, err, "Failed to hash password")
+		return err
+	}
+
+	if err := t.userRepo.ChangePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	t.audit(ctx, model.AuditEventPasswordChanged, user, req.IP, "")
+
+	return nil
+}
+
+// confirmEmailChange makes the pending email the token was mailed to the email of its user
+func (t *userService) confirmEmailChange(ctx context.Context, token string) error {
+	user, err := t.userTokenRepo.ConfirmEmailChange(ctx, hashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidVerificationToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	t.audit(ctx, model.AuditEventEmailChanged, user, "", user.Email)
+
+	return nil
+}
+
+func (t *userService) currentUser(ctx context.Context) (*model.User, error) {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: userID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// profileChanges validates the fields of req other than the email and returns the ones that differ
+// from the profile of user
+func profileChanges(user *model.User, req UpdateProfileReq) (repository.UpdateProfileOpts, []string, error) {
+	var opts repository.UpdateProfileOpts
+	var changed []string
+
+	if req.DisplayName != nil {
+		displayName := strings.TrimSpace(*req.DisplayName)
+		if utf8.RuneCountInString(displayName) > MAX_DISPLAY_NAME {
+			return opts, nil, fmt.Errorf("%w : display_name must be at most %d characters", ErrInvalidUserDetails, MAX_DISPLAY_NAME)
+		}
+
+		if displayName != user.DisplayName {
+			opts.DisplayName = &displayName
+			changed = append(changed, "display_name")
+		}
+	}
+
+	if req.Organisation != nil {
+		organisation := strings.TrimSpace(*req.Organisation)
+		if utf8.RuneCountInString(organisation) > MAX_ORGANISATION {
+			return opts, nil, fmt.Errorf("%w : organisation must be at most %d characters", ErrInvalidUserDetails, MAX_ORGANISATION)
+		}
+
+		if organisation != user.Organisation {
+			opts.Organisation = &organisation
+			changed = append(changed, "organisation")
+		}
+	}
+
+	if req.TimeZone != nil {
+		timeZone := strings.TrimSpace(*req.TimeZone)
+		if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "" {
+			return opts, nil, fmt.Errorf("%w : unknown time_zone %q", ErrInvalidUserDetails, timeZone)
+		}
+
+		if timeZone != user.TimeZone {
+			opts.TimeZone = &timeZone
+			changed = append(changed, "time_zone")
+		}
+	}
+
+	return opts, changed, nil
+}
+
+// emailChange validates a requested email and returns it when it has to be confirmed, it is empty
+// when the address is the current or already pending one
+func (t *userService) emailChange(ctx context.Context, user *model.User, email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") || len(email) > MAX_EMAIL_ADDRESS {
+		return "", fmt.Errorf("%w : a valid email is required", ErrInvalidUserDetails)
+	}
+
+	if strings.EqualFold(email, user.Email) || (user.PendingEmail != nil && *user.PendingEmail == email) {
+		return "", nil
+	}
+
+	_, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{Email: email})
+	if err == nil {
+		return "", ErrEmailTaken
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	return email, nil
+}
+
+// newEmailChangeToken returns the email change token row for the user and the outbox notification
+// mailing the token to the new address
+func (t *userService) newEmailChangeToken(user *model.User, email string) (*model.UserToken, *model.OutboxNotification, error) {
+	return newUserToken(user, model.UserTokenPurposeEmailChange, t.verification.TTL,
+		func(token string, expiresAt time.Time) (*model.OutboxNotification, error) {
+			return notification_worker.NewOutboxNotification(notification_worker.EmailNotificationSubjectConfirmEmailChange, user.ID,
+				notification_worker.ConfirmEmailChangeEmail{
+					UserID:     user.ID,
+					Username:   user.Username,
+					Recipient:  email,
+					Token:      notification_worker.SealedToken(token),
+					ConfirmURL: notification_worker.SealedToken(tokenURL(t.verification.URL, token)),
+					ExpiresAt:  expiresAt,
+				})
+		})
+}
+
+func (t *userService) audit(ctx context.Context, eventType model.AuditEventType, user *model.User, ip, reason string) {
+	_ = t.auditRepo.CreateEvent(ctx, &model.AuditEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		UserID:    &user.ID,
+		Username:  user.Username,
+		IP:        ip,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+}
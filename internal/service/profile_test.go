@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func profileUser() *model.User {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+
+	return &model.User{
+		ID:          uuid.New(),
+		Username:    "ranger_ravi",
+		Password:    string(hashedPassword),
+		Email:       "ravi@example.com",
+		DisplayName: "Ravi",
+		TimeZone:    "UTC",
+		Role:        model.RoleResearcher,
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	verification := VerificationConfig{TTL: time.Hour, URL: "https://tigerhall.io/verify-email"}
+
+	t.Run("should update the changed fields and audit them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+		mockUserRepo.EXPECT().UpdateProfile(ctx, user.ID, repository.UpdateProfileOpts{
+			Organisation: stringPtr("WWF India"),
+			TimeZone:     stringPtr("Asia/Kolkata"),
+		}).DoAndReturn(func(_ context.Context, _ uuid.UUID, opts repository.UpdateProfileOpts) (*model.User, error) {
+			updated := *user
+			updated.Organisation = *opts.Organisation
+			updated.TimeZone = *opts.TimeZone
+			return &updated, nil
+		})
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventProfileUpdated, event.Type)
+			assert.Equal(t, "organisation,time_zone", event.Reason)
+			assert.Equal(t, "10.0.0.1", event.IP)
+			return nil
+		})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithAuditRepoForUserService(mockAuditRepo))
+
+		profile, err := userService.UpdateProfile(ctx, UpdateProfileReq{
+			DisplayName:  stringPtr(" Ravi "),
+			Organisation: stringPtr("WWF India"),
+			TimeZone:     stringPtr("Asia/Kolkata"),
+			IP:           "10.0.0.1",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "Ravi", profile.DisplayName)
+		assert.Equal(t, "WWF India", profile.Organisation)
+		assert.Equal(t, "Asia/Kolkata", profile.TimeZone)
+	})
+
+	t.Run("should change nothing when a field is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil).Times(3)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo))
+
+		_, err := userService.UpdateProfile(ctx, UpdateProfileReq{DisplayName: stringPtr("Ravi K"), TimeZone: stringPtr("Mars/Olympus_Mons")})
+		assert.True(t, errors.Is(err, ErrInvalidUserDetails))
+
+		_, err = userService.UpdateProfile(ctx, UpdateProfileReq{DisplayName: stringPtr(strings.Repeat("r", MAX_DISPLAY_NAME+1))})
+		assert.True(t, errors.Is(err, ErrInvalidUserDetails))
+
+		_, err = userService.UpdateProfile(ctx, UpdateProfileReq{DisplayName: stringPtr("Ravi K"), Email: stringPtr("not-an-email")})
+		assert.True(t, errors.Is(err, ErrInvalidUserDetails))
+	})
+
+	t.Run("should mail a confirmation to a new email and keep the current one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+		newEmail := "ravi@wwf.example.org"
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: newEmail}).Return(nil, gorm.ErrRecordNotFound)
+		mockUserRepo.EXPECT().RequestEmailChange(ctx, user.ID, newEmail, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uuid.UUID, _ string, token *model.UserToken, notification *model.OutboxNotification) error {
+				assert.Equal(t, model.UserTokenPurposeEmailChange, token.Purpose)
+				assert.Equal(t, notification_worker.EmailNotificationSubjectConfirmEmailChange, notification.Subject)
+
+				var payload notification_worker.ConfirmEmailChangeEmail
+				assert.Nil(t, json.Unmarshal(notification.Payload, &payload))
+				assert.Equal(t, newEmail, payload.Recipient)
+				assert.NotContains(t, string(notification.Payload), string(payload.Token))
+				assert.Equal(t, hashToken(string(payload.Token)), token.TokenHash)
+				assert.Equal(t, "https://tigerhall.io/verify-email?token="+string(payload.Token), string(payload.ConfirmURL))
+				return nil
+			})
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventEmailChangeRequested, event.Type)
+			assert.Equal(t, newEmail, event.Reason)
+			return nil
+		})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithAuditRepoForUserService(mockAuditRepo),
+			WithVerificationConfig(verification))
+
+		profile, err := userService.UpdateProfile(ctx, UpdateProfileReq{Email: stringPtr(newEmail)})
+		assert.Nil(t, err)
+		assert.Equal(t, "ravi@example.com", profile.Email)
+		assert.Equal(t, newEmail, *profile.PendingEmail)
+	})
+
+	t.Run("should refuse an email of another account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: "taken@example.com"}).Return(&model.User{ID: uuid.New()}, nil)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo))
+
+		_, err := userService.UpdateProfile(ctx, UpdateProfileReq{Email: stringPtr("taken@example.com")})
+		assert.Equal(t, ErrEmailTaken, err)
+	})
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	t.Run("should refuse an incorrect current password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo))
+
+		err := userService.ChangePassword(ctx, ChangePasswordReq{CurrentPassword: "incorrect", NewPassword: "new-password"})
+		assert.Equal(t, ErrIncorrectPassword, err)
+	})
+
+	t.Run("should set the new password and audit it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := profileUser()
+		ctx := context.WithValue(context.Background(), "userID", user.ID.String())
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+		mockUserRepo.EXPECT().ChangePassword(ctx, user.ID, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, passwordHash string) error {
+			assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte("new-password")))
+			return nil
+		})
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventPasswordChanged, event.Type)
+			return nil
+		})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithAuditRepoForUserService(mockAuditRepo))
+
+		err := userService.ChangePassword(ctx, ChangePasswordReq{CurrentPassword: "password", NewPassword: "new-password"})
+		assert.Nil(t, err)
+	})
+}
@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 
 	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/feed"
 	"tigerhall_kittens/internal/logger"
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
@@ -31,6 +33,7 @@ type sightingService struct {
 	tigerService         TigerService
 	sightingRepo         repository.SightingRepo
 	sightingEmailNotifer notification_worker.SightingEmailNotifer
+	feedBroker           feed.Broker
 }
 
 type SightingServiceOption func(service *sightingService)
@@ -40,6 +43,7 @@ func NewSightingService(options ...SightingServiceOption) SightingService {
 		tigerService:         NewTigerService(),
 		sightingRepo:         repository.NewSightingRepo(),
 		sightingEmailNotifer: notification_worker.NewSightingEmailNotifer(),
+		feedBroker:           feed.Get(),
 	}
 
 	for _, option := range options {
@@ -55,25 +59,36 @@ func WithSightingRepo(repo repository.SightingRepo) SightingServiceOption {
 	}
 }
 
+func WithTigerService(tigerService TigerService) SightingServiceOption {
+	return func(s *sightingService) {
+		s.tigerService = tigerService
+	}
+}
+
 func WithsightingEmailNotifer(emailNotifer notification_worker.SightingEmailNotifer) SightingServiceOption {
 	return func(s *sightingService) {
 		s.sightingEmailNotifer = emailNotifer
 	}
 }
 
+func WithFeedBroker(broker feed.Broker) SightingServiceOption {
+	return func(s *sightingService) {
+		s.feedBroker = broker
+	}
+}
+
 func (t *sightingService) ReportSighting(ctx context.Context, reportSightingReq ReportSightingReq) error {
 	// TODO: cache this
 	tiger, err := t.tigerService.GetTiger(ctx, repository.GetTigerOpts{TigerID: reportSightingReq.TigerID})
+	if errors.Is(err, ErrTigerNotFound) {
+		return ErrTigerDoesNotExist
+	}
+
 	if err != nil {
 		logger.E(ctx, err, "Error while fetching tiger details", logger.Field("tiger_id", reportSightingReq.TigerID))
 		return ErrFetchingTigerDetails
 	}
 
-	if *tiger == (model.Tiger{}) {
-		logger.W(ctx, "Tiger does not exist", logger.Field("tiger_id", reportSightingReq.TigerID))
-		return ErrTigerDoesNotExist
-	}
-
 	sightings, err := t.sightingRepo.GetSightings(ctx, repository.GetSightingOpts{
 		TigerID:       reportSightingReq.TigerID,
 		Lat:           reportSightingReq.Lat,
@@ -106,16 +121,22 @@ func (t *sightingService) ReportSighting(ctx context.Context, reportSightingReq
 		ImageURL:         reportSightingReq.ImageURL,
 	}
 
-	if err := t.sightingRepo.ReportSighting(ctx, sighting); err != nil {
+	notifications, geofenceAlert, err := t.sightingEmailNotifer.BuildSightingNotifications(ctx, tiger, sighting)
+	if err != nil {
+		logger.E(ctx, err, "Error while preparing email notification for sightings", logger.Field("tiger_id", reportSightingReq.TigerID), logger.Field("user_id", userID))
+		return ErrSendingEmailNotification
+	}
+
+	// notifications, along with alerts for the geofences the sighting falls in, are queued in the
+	// same transaction as the sighting, the worker picks them up from the outbox
+	if err := t.sightingRepo.ReportSighting(ctx, sighting, notifications, geofenceAlert); err != nil {
 		logger.E(ctx, err, "Failed to create reportSightingReq")
 		return err
 	}
 
-	err = t.sightingEmailNotifer.ReportSightingToAllUsers(ctx, reportSightingReq.TigerID)
-	if err != nil {
-		logger.E(ctx, err, "Error while sending email notification for sightings", logger.Field("tiger_id", reportSightingReq.TigerID), logger.Field("user_id", userID))
-		// TODO: should we ignore this error in case of failure in notification?
-		return ErrSendingEmailNotification
+	// the sighting is committed by now, a feed that misses it is not worth failing the request for
+	if err := t.feedBroker.Publish(ctx, *sighting); err != nil {
+		logger.E(ctx, err, "Error while publishing sighting to the feed", logger.Field("sighting_id", sighting.ID))
 	}
 
 	return nil
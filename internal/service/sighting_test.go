@@ -9,8 +9,10 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 
 	mock_notification_worker "tigerhall_kittens/cmd/notification_worker/mocks"
+	mock_feed "tigerhall_kittens/internal/feed/mocks"
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
 	mock_repository "tigerhall_kittens/internal/repository/mocks"
@@ -83,15 +85,49 @@ func TestSightingService_ReportSighting(t *testing.T) {
 	lon := 2.2
 
 	userID := uuid.New()
+	otherUserID := uuid.New()
 
 	reportSightingReq := ReportSightingReq{
 		TigerID:   tigerOneID,
 		Lat:       lat,
 		Lon:       lon,
-		Timestamp: time.Now().String(),
+		Timestamp: time.Now().Format(time.RFC3339),
 		ImageURL:  "imageurl.com",
 	}
 
+	getSightingOpts := repository.GetSightingOpts{
+		TigerID:       tigerOneID,
+		RangeInMeters: DEFAULT_SIGHTING_RANGE_IN_METERS,
+		Lat:           lat,
+		Lon:           lon,
+	}
+
+	mockTigerService := func(ctrl *gomock.Controller, ctx context.Context) TigerService {
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().GetTiger(ctx, repository.GetTigerOpts{TigerID: tigerOneID}).
+			Return(&model.Tiger{ID: tigerOneID, Name: "tiger 1"}, nil)
+
+		return NewTigerService(WithTigerRepo(mockTigerRepo))
+	}
+
+	t.Run("should return error when tiger does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().GetTiger(ctx, repository.GetTigerOpts{TigerID: tigerOneID}).Return(nil, gorm.ErrRecordNotFound)
+
+		sightingService := NewSightingService(
+			WithTigerService(NewTigerService(WithTigerRepo(mockTigerRepo))),
+			WithSightingRepo(mock_repository.NewMockSightingRepo(ctrl)),
+		)
+
+		actualErr := sightingService.ReportSighting(ctx, reportSightingReq)
+		assert.Equal(t, ErrTigerDoesNotExist, actualErr)
+	})
+
 	t.Run("should return error when error in fetching existing sightings", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -99,28 +135,15 @@ func TestSightingService_ReportSighting(t *testing.T) {
 		ctx := context.Background()
 
 		expectedErr := ErrFetchingExistingSightings
-		getSightingOpts := repository.GetSightingOpts{
-			TigerID:       tigerOneID,
-			RangeInMeters: DEFAULT_SIGHTING_RANGE_IN_METERS,
-			Lat:           lat,
-			Lon:           lon,
-		}
 
 		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
-		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(nil, expectedErr)
+		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(nil, errors.New("some db error"))
 
 		sightingService := NewSightingService(
+			WithTigerService(mockTigerService(ctrl, ctx)),
 			WithSightingRepo(mockSightingRepo),
 		)
 
-		reportSightingReq := ReportSightingReq{
-			TigerID:   tigerOneID,
-			Lat:       lat,
-			Lon:       lon,
-			Timestamp: time.Now().String(),
-			ImageURL:  "imageurl.com",
-		}
-
 		actualErr := sightingService.ReportSighting(ctx, reportSightingReq)
 		assert.Equal(t, expectedErr, actualErr)
 	})
@@ -143,16 +166,11 @@ func TestSightingService_ReportSighting(t *testing.T) {
 			},
 		}
 
-		getSightingOpts := repository.GetSightingOpts{
-			TigerID:       tigerOneID,
-			RangeInMeters: DEFAULT_SIGHTING_RANGE_IN_METERS,
-			Lat:           lat,
-			Lon:           lon,
-		}
 		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
 		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(existingSightingsForSameTigerInDefaultRange, nil)
 
 		sightingService := NewSightingService(
+			WithTigerService(mockTigerService(ctrl, ctx)),
 			WithSightingRepo(mockSightingRepo),
 		)
 
@@ -160,103 +178,128 @@ func TestSightingService_ReportSighting(t *testing.T) {
 		assert.Equal(t, expectedErr, actualErr)
 	})
 
-	t.Run("should return error when report sighting fails", func(t *testing.T) {
+	t.Run("should not store sighting when preparing email notifications fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, "userID", userID.String())
 
-		expectedErr := errors.New("something went wrong while reporting")
-
-		// no existing sightings for the tiger
-		var existingSightingsForSameTigerInDefaultRange []model.Sighting
+		expectedErr := ErrSendingEmailNotification
 
-		getSightingOpts := repository.GetSightingOpts{
-			TigerID:       tigerOneID,
-			RangeInMeters: DEFAULT_SIGHTING_RANGE_IN_METERS,
-			Lat:           lat,
-			Lon:           lon,
-		}
 		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
-		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(existingSightingsForSameTigerInDefaultRange, nil)
+		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(nil, nil)
 
-		mockSightingRepo.EXPECT().ReportSighting(ctx, gomock.Any()).Return(expectedErr)
+		mockEmailNotifer := mock_notification_worker.NewMockSightingEmailNotifer(ctrl)
+		mockEmailNotifer.EXPECT().
+			BuildSightingNotifications(ctx, gomock.Any(), gomock.Any()).
+			Return(nil, nil, errors.New("some error"))
 
 		sightingService := NewSightingService(
+			WithTigerService(mockTigerService(ctrl, ctx)),
 			WithSightingRepo(mockSightingRepo),
+			WithsightingEmailNotifer(mockEmailNotifer),
+			WithFeedBroker(mock_feed.NewMockBroker(ctrl)),
 		)
 
 		actualErr := sightingService.ReportSighting(ctx, reportSightingReq)
 		assert.Equal(t, expectedErr, actualErr)
 	})
 
-	t.Run("should return error when reporting sighting through email to other users fail", func(t *testing.T) {
+	t.Run("should return error when report sighting fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, "userID", userID.String())
 
-		expectedErr := ErrSendingEmailNotification
-
-		// no existing sightings for the tiger
-		var existingSightingsForSameTigerInDefaultRange []model.Sighting
+		expectedErr := errors.New("something went wrong while reporting")
 
-		getSightingOpts := repository.GetSightingOpts{
-			TigerID:       tigerOneID,
-			RangeInMeters: DEFAULT_SIGHTING_RANGE_IN_METERS,
-			Lat:           lat,
-			Lon:           lon,
-		}
 		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
-		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(existingSightingsForSameTigerInDefaultRange, nil)
-
-		mockSightingRepo.EXPECT().ReportSighting(ctx, gomock.Any()).Return(nil)
+		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(nil, nil)
+		mockSightingRepo.EXPECT().ReportSighting(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(expectedErr)
 
 		mockEmailNotifer := mock_notification_worker.NewMockSightingEmailNotifer(ctrl)
-		mockEmailNotifer.EXPECT().
-			ReportSightingToAllUsers(ctx, reportSightingReq.TigerID).
-			Return(expectedErr)
+		mockEmailNotifer.EXPECT().BuildSightingNotifications(ctx, gomock.Any(), gomock.Any()).Return(nil, nil, nil)
 
 		sightingService := NewSightingService(
+			WithTigerService(mockTigerService(ctrl, ctx)),
 			WithSightingRepo(mockSightingRepo),
 			WithsightingEmailNotifer(mockEmailNotifer),
+			WithFeedBroker(mock_feed.NewMockBroker(ctrl)),
 		)
 
 		actualErr := sightingService.ReportSighting(ctx, reportSightingReq)
 		assert.Equal(t, expectedErr, actualErr)
 	})
 
-	t.Run("should return nil error when reporting is successful", func(t *testing.T) {
+	t.Run("should store sighting together with its notifications and geofence alerts", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		ctx := context.Background()
 		ctx = context.WithValue(ctx, "userID", userID.String())
 
-		// no existing sightings for the tiger
-		var existingSightingsForSameTigerInDefaultRange []model.Sighting
-
-		getSightingOpts := repository.GetSightingOpts{
-			TigerID:       tigerOneID,
-			RangeInMeters: DEFAULT_SIGHTING_RANGE_IN_METERS,
-			Lat:           lat,
-			Lon:           lon,
+		notifications := []model.OutboxNotification{
+			{ID: uuid.New(), UserID: otherUserID, Status: model.OutboxStatusPending},
 		}
-		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
-		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(existingSightingsForSameTigerInDefaultRange, nil)
 
-		mockSightingRepo.EXPECT().ReportSighting(ctx, gomock.Any()).Return(nil)
+		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
+		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(nil, nil)
 
 		mockEmailNotifer := mock_notification_worker.NewMockSightingEmailNotifer(ctrl)
 		mockEmailNotifer.EXPECT().
-			ReportSightingToAllUsers(ctx, reportSightingReq.TigerID).
-			Return(nil)
+			BuildSightingNotifications(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tiger *model.Tiger, sighting *model.Sighting) ([]model.OutboxNotification, repository.GeofenceAlertBuilder, error) {
+				assert.Equal(t, "tiger 1", tiger.Name)
+				assert.Equal(t, tigerOneID, sighting.TigerID)
+				assert.Equal(t, userID, sighting.ReportedByUserID)
+				return notifications, func(geofence model.Geofence) (*model.OutboxNotification, error) {
+					return &model.OutboxNotification{UserID: geofence.UserID}, nil
+				}, nil
+			})
+
+		mockSightingRepo.EXPECT().ReportSighting(ctx, gomock.Any(), notifications, gomock.Not(gomock.Nil())).Return(nil)
+
+		mockFeedBroker := mock_feed.NewMockBroker(ctrl)
+		mockFeedBroker.EXPECT().Publish(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, sighting model.Sighting) error {
+			assert.Equal(t, tigerOneID, sighting.TigerID)
+			return nil
+		})
+
+		sightingService := NewSightingService(
+			WithTigerService(mockTigerService(ctrl, ctx)),
+			WithSightingRepo(mockSightingRepo),
+			WithsightingEmailNotifer(mockEmailNotifer),
+			WithFeedBroker(mockFeedBroker),
+		)
+
+		actualErr := sightingService.ReportSighting(ctx, reportSightingReq)
+		assert.Nil(t, actualErr)
+	})
+
+	t.Run("should not fail a stored sighting when publishing to the feed fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, "userID", userID.String())
+
+		mockSightingRepo := mock_repository.NewMockSightingRepo(ctrl)
+		mockSightingRepo.EXPECT().GetSightings(ctx, getSightingOpts).Return(nil, nil)
+		mockSightingRepo.EXPECT().ReportSighting(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		mockEmailNotifer := mock_notification_worker.NewMockSightingEmailNotifer(ctrl)
+		mockEmailNotifer.EXPECT().BuildSightingNotifications(ctx, gomock.Any(), gomock.Any()).Return(nil, nil, nil)
+
+		mockFeedBroker := mock_feed.NewMockBroker(ctrl)
+		mockFeedBroker.EXPECT().Publish(ctx, gomock.Any()).Return(errors.New("feed is down"))
 
 		sightingService := NewSightingService(
+			WithTigerService(mockTigerService(ctrl, ctx)),
 			WithSightingRepo(mockSightingRepo),
 			WithsightingEmailNotifer(mockEmailNotifer),
+			WithFeedBroker(mockFeedBroker),
 		)
 
 		actualErr := sightingService.ReportSighting(ctx, reportSightingReq)
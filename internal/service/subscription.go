@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+type SubscriptionService interface {
+	Subscribe(ctx context.Context, tigerID uint) error
+	Unsubscribe(ctx context.Context, tigerID uint) error
+	ListSubscriptions(ctx context.Context, opts repository.ListSubscriptionsOpts) ([]model.TigerSubscription, error)
+}
+
+type subscriptionService struct {
+	tigerService     TigerService
+	subscriptionRepo repository.SubscriptionRepo
+}
+
+type SubscriptionServiceOption func(service *subscriptionService)
+
+func NewSubscriptionService(options ...SubscriptionServiceOption) SubscriptionService {
+	service := &subscriptionService{
+		tigerService:     NewTigerService(),
+		subscriptionRepo: repository.NewSubscriptionRepo(),
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+func WithSubscriptionRepo(repo repository.SubscriptionRepo) SubscriptionServiceOption {
+	return func(s *subscriptionService) {
+		s.subscriptionRepo = repo
+	}
+}
+
+func WithTigerServiceForSubscriptions(tigerService TigerService) SubscriptionServiceOption {
+	return func(s *subscriptionService) {
+		s.tigerService = tigerService
+	}
+}
+
+// Subscribe subscribes the logged-in user to every future sighting of the tiger
+func (t *subscriptionService) Subscribe(ctx context.Context, tigerID uint) error {
+	_, err := t.tigerService.GetTiger(ctx, repository.GetTigerOpts{TigerID: tigerID})
+	if errors.Is(err, ErrTigerNotFound) {
+		return ErrTigerDoesNotExist
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching tiger details", logger.Field("tiger_id", tigerID))
+		return ErrFetchingTigerDetails
+	}
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	err = t.subscriptionRepo.Subscribe(ctx, &model.TigerSubscription{
+		UserID:    userID,
+		TigerID:   tigerID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		logger.E(ctx, err, "Error while subscribing to tiger", logger.Field("tiger_id", tigerID), logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+func (t *subscriptionService) Unsubscribe(ctx context.Context, tigerID uint) error {
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+
+	err := t.subscriptionRepo.Unsubscribe(ctx, userID, tigerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "User is not subscribed to tiger", logger.Field("tiger_id", tigerID), logger.Field("user_id", userID))
+		return ErrSubscriptionNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while unsubscribing from tiger", logger.Field("tiger_id", tigerID), logger.Field("user_id", userID))
+		return err
+	}
+
+	return nil
+}
+
+// ListSubscriptions lists the subscriptions of the logged-in user
+func (t *subscriptionService) ListSubscriptions(ctx context.Context, opts repository.ListSubscriptionsOpts) ([]model.TigerSubscription, error) {
+	opts.UserID = uuid.MustParse(ctx.Value("userID").(string))
+
+	subscriptions, err := t.subscriptionRepo.GetSubscriptions(ctx, opts)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching subscriptions", logger.Field("opts", opts))
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
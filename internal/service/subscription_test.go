@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestSubscriptionService_Subscribe(t *testing.T) {
+	var tigerID uint = 1
+	userID := uuid.New()
+
+	t.Run("should return tiger does not exist error for unknown tiger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().GetTiger(ctx, repository.GetTigerOpts{TigerID: tigerID}).Return(nil, gorm.ErrRecordNotFound)
+
+		subscriptionService := NewSubscriptionService(
+			WithTigerServiceForSubscriptions(NewTigerService(WithTigerRepo(mockTigerRepo))),
+			WithSubscriptionRepo(mock_repository.NewMockSubscriptionRepo(ctrl)),
+		)
+
+		actualErr := subscriptionService.Subscribe(ctx, tigerID)
+		assert.Equal(t, ErrTigerDoesNotExist, actualErr)
+	})
+
+	t.Run("should subscribe logged-in user to tiger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().GetTiger(ctx, repository.GetTigerOpts{TigerID: tigerID}).Return(&model.Tiger{ID: tigerID, Name: "Machli"}, nil)
+
+		mockSubscriptionRepo := mock_repository.NewMockSubscriptionRepo(ctrl)
+		mockSubscriptionRepo.EXPECT().Subscribe(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, subscription *model.TigerSubscription) error {
+				assert.Equal(t, userID, subscription.UserID)
+				assert.Equal(t, tigerID, subscription.TigerID)
+				return nil
+			})
+
+		subscriptionService := NewSubscriptionService(
+			WithTigerServiceForSubscriptions(NewTigerService(WithTigerRepo(mockTigerRepo))),
+			WithSubscriptionRepo(mockSubscriptionRepo),
+		)
+
+		actualErr := subscriptionService.Subscribe(ctx, tigerID)
+		assert.Nil(t, actualErr)
+	})
+}
+
+func TestSubscriptionService_Unsubscribe(t *testing.T) {
+	var tigerID uint = 1
+	userID := uuid.New()
+
+	t.Run("should return subscription not found error when user is not subscribed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockSubscriptionRepo := mock_repository.NewMockSubscriptionRepo(ctrl)
+		mockSubscriptionRepo.EXPECT().Unsubscribe(ctx, userID, tigerID).Return(gorm.ErrRecordNotFound)
+
+		subscriptionService := NewSubscriptionService(WithSubscriptionRepo(mockSubscriptionRepo))
+
+		actualErr := subscriptionService.Unsubscribe(ctx, tigerID)
+		assert.Equal(t, ErrSubscriptionNotFound, actualErr)
+	})
+
+	t.Run("should return error when repo returns any other error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+		expectedErr := errors.New("some db error")
+
+		mockSubscriptionRepo := mock_repository.NewMockSubscriptionRepo(ctrl)
+		mockSubscriptionRepo.EXPECT().Unsubscribe(ctx, userID, tigerID).Return(expectedErr)
+
+		subscriptionService := NewSubscriptionService(WithSubscriptionRepo(mockSubscriptionRepo))
+
+		actualErr := subscriptionService.Unsubscribe(ctx, tigerID)
+		assert.Equal(t, expectedErr, actualErr)
+	})
+}
+
+func TestSubscriptionService_ListSubscriptions(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("should list subscriptions of logged-in user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+		subscriptions := []model.TigerSubscription{{UserID: userID, TigerID: 1}}
+
+		mockSubscriptionRepo := mock_repository.NewMockSubscriptionRepo(ctrl)
+		mockSubscriptionRepo.EXPECT().GetSubscriptions(ctx, repository.ListSubscriptionsOpts{
+			UserID: userID,
+			Limit:  10,
+		}).Return(subscriptions, nil)
+
+		subscriptionService := NewSubscriptionService(WithSubscriptionRepo(mockSubscriptionRepo))
+
+		actual, actualErr := subscriptionService.ListSubscriptions(ctx, repository.ListSubscriptionsOpts{Limit: 10})
+		assert.Nil(t, actualErr)
+		assert.Equal(t, subscriptions, actual)
+	})
+}
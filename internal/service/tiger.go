@@ -2,6 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
 	"tigerhall_kittens/internal/logger"
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
@@ -11,6 +18,17 @@ type TigerService interface {
 	GetTiger(ctx context.Context, opts repository.GetTigerOpts) (*model.Tiger, error)
 	ListTigers(ctx context.Context, opts repository.ListTigersOpts) ([]model.Tiger, error)
 	CreateTiger(ctx context.Context, tiger *model.Tiger) error
+	UpdateTiger(ctx context.Context, tigerID uint, version int, req UpdateTigerReq) (*model.Tiger, error)
+	DeleteTiger(ctx context.Context, tigerID uint) error
+	RestoreTiger(ctx context.Context, tigerID uint) (*model.Tiger, error)
+	RecomputeLastSeen(ctx context.Context) (int64, error)
+}
+
+// UpdateTigerReq holds the fields to change, fields left out are kept. The last seen fields follow
+// the sightings of the tiger and can not be changed here.
+type UpdateTigerReq struct {
+	Name        *string    `json:"name"`
+	DateOfBirth *time.Time `json:"date_of_birth"`
 }
 
 type tigerService struct {
@@ -37,6 +55,11 @@ func WithTigerRepo(repo repository.TigerRepo) TigerServiceOption {
 
 func (t *tigerService) GetTiger(ctx context.Context, opts repository.GetTigerOpts) (*model.Tiger, error) {
 	tiger, err := t.tigerRepo.GetTiger(ctx, opts)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Tiger does not exist", logger.Field("tiger_id", opts.TigerID))
+		return nil, ErrTigerNotFound
+	}
+
 	if err != nil {
 		logger.E(ctx, err, "Error while fetching tiger", logger.Field("opts", opts))
 		return nil, err
@@ -64,3 +87,91 @@ func (t *tigerService) CreateTiger(ctx context.Context, tiger *model.Tiger) erro
 
 	return nil
 }
+
+// UpdateTiger changes the tiger if it is still at version, so an update based on a stale read
+// fails with ErrTigerVersionMismatch instead of overwriting a newer change
+func (t *tigerService) UpdateTiger(ctx context.Context, tigerID uint, version int, req UpdateTigerReq) (*model.Tiger, error) {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > 255 {
+			return nil, fmt.Errorf("%w : name must be between 1 and 255 characters", ErrInvalidTigerDetails)
+		}
+		req.Name = &name
+	}
+
+	if req.DateOfBirth != nil && req.DateOfBirth.After(time.Now()) {
+		return nil, fmt.Errorf("%w : date of birth can not be in the future", ErrInvalidTigerDetails)
+	}
+
+	tiger, err := t.tigerRepo.UpdateTiger(ctx, tigerID, version, repository.UpdateTigerOpts{
+		Name:        req.Name,
+		DateOfBirth: req.DateOfBirth,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, t.updateConflict(ctx, tigerID, version)
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while updating tiger", logger.Field("tiger_id", tigerID))
+		return nil, err
+	}
+
+	return tiger, nil
+}
+
+// updateConflict tells apart an update of a missing tiger from one based on a stale version
+func (t *tigerService) updateConflict(ctx context.Context, tigerID uint, version int) error {
+	tiger, err := t.tigerRepo.GetTiger(ctx, repository.GetTigerOpts{TigerID: tigerID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTigerNotFound
+	}
+
+	if err != nil {
+		return ErrFetchingTigerDetails
+	}
+
+	logger.I(ctx, "Tiger was updated since it was read",
+		logger.Field("tiger_id", tigerID),
+		logger.Field("version", version),
+		logger.Field("current_version", tiger.Version))
+
+	return ErrTigerVersionMismatch
+}
+
+// DeleteTiger soft deletes the tiger, it can no longer be sighted until an admin restores it
+func (t *tigerService) DeleteTiger(ctx context.Context, tigerID uint) error {
+	err := t.tigerRepo.DeleteTiger(ctx, tigerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTigerNotFound
+	}
+
+	return err
+}
+
+// RestoreTiger brings back a deleted tiger
+func (t *tigerService) RestoreTiger(ctx context.Context, tigerID uint) (*model.Tiger, error) {
+	tiger, err := t.tigerRepo.RestoreTiger(ctx, tigerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Tiger does not exist or is not deleted", logger.Field("tiger_id", tigerID))
+		return nil, ErrTigerNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tiger, nil
+}
+
+// RecomputeLastSeen rebuilds the last seen fields of every tiger from its sightings, reporting a
+// sighting keeps them up to date so this only repairs tigers that drifted
+func (t *tigerService) RecomputeLastSeen(ctx context.Context) (int64, error) {
+	updated, err := t.tigerRepo.RecomputeLastSeen(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.I(ctx, "Recomputed last seen of tigers", logger.Field("updated", updated))
+
+	return updated, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
@@ -110,3 +111,92 @@ func TestTigerService_CreateTiger(t *testing.T) {
 		assert.Nil(t, actualErr)
 	})
 }
+
+func TestTigerService_UpdateTiger(t *testing.T) {
+	name := "Machli"
+
+	t.Run("should update the tiger at the given version", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		updated := &model.Tiger{ID: 7, Name: name, Version: 4}
+
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().UpdateTiger(ctx, uint(7), 3, repository.UpdateTigerOpts{Name: &name}).Return(updated, nil)
+
+		tiger, err := NewTigerService(WithTigerRepo(mockTigerRepo)).UpdateTiger(ctx, 7, 3, UpdateTigerReq{Name: stringPtr(" Machli ")})
+		assert.Nil(t, err)
+		assert.Equal(t, updated, tiger)
+	})
+
+	t.Run("should return error when the tiger was changed since the version", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().UpdateTiger(ctx, uint(7), 3, gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+		mockTigerRepo.EXPECT().GetTiger(ctx, repository.GetTigerOpts{TigerID: 7}).Return(&model.Tiger{ID: 7, Version: 5}, nil)
+
+		_, err := NewTigerService(WithTigerRepo(mockTigerRepo)).UpdateTiger(ctx, 7, 3, UpdateTigerReq{Name: &name})
+		assert.Equal(t, ErrTigerVersionMismatch, err)
+	})
+
+	t.Run("should return error when the tiger does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().UpdateTiger(ctx, uint(7), 3, gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
+		mockTigerRepo.EXPECT().GetTiger(ctx, repository.GetTigerOpts{TigerID: 7}).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewTigerService(WithTigerRepo(mockTigerRepo)).UpdateTiger(ctx, 7, 3, UpdateTigerReq{Name: &name})
+		assert.Equal(t, ErrTigerNotFound, err)
+	})
+
+	t.Run("should return error when the name is blank or the tiger is not born yet", func(t *testing.T) {
+		tigerService := NewTigerService(WithTigerRepo(mock_repository.NewMockTigerRepo(gomock.NewController(t))))
+		tomorrow := time.Now().Add(24 * time.Hour)
+
+		_, err := tigerService.UpdateTiger(context.Background(), 7, 3, UpdateTigerReq{Name: stringPtr("  ")})
+		assert.ErrorIs(t, err, ErrInvalidTigerDetails)
+
+		_, err = tigerService.UpdateTiger(context.Background(), 7, 3, UpdateTigerReq{DateOfBirth: &tomorrow})
+		assert.ErrorIs(t, err, ErrInvalidTigerDetails)
+	})
+}
+
+func TestTigerService_RestoreTiger(t *testing.T) {
+	t.Run("should return error when the tiger is not deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().RestoreTiger(ctx, uint(7)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := NewTigerService(WithTigerRepo(mockTigerRepo)).RestoreTiger(ctx, 7)
+		assert.Equal(t, ErrTigerNotFound, err)
+	})
+}
+
+func TestTigerService_RecomputeLastSeen(t *testing.T) {
+	t.Run("should return how many tigers were updated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockTigerRepo := mock_repository.NewMockTigerRepo(ctrl)
+		mockTigerRepo.EXPECT().RecomputeLastSeen(ctx).Return(int64(3), nil)
+
+		updated, err := NewTigerService(WithTigerRepo(mockTigerRepo)).RecomputeLastSeen(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), updated)
+	})
+}
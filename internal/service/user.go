@@ -3,12 +3,17 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"tigerhall_kittens/cmd/notification_worker"
+	"tigerhall_kittens/internal/config"
 	"tigerhall_kittens/internal/logger"
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
@@ -18,20 +23,109 @@ type CreateUserReq struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Email    string `json:"email"`
+	Locale   string `json:"locale,omitempty"`
+	// Role defaults to model.DefaultRole
+	Role model.Role `json:"role,omitempty"`
+	// Machine creates a machine account for service API keys, it can not log in
+	Machine bool `json:"machine,omitempty"`
+}
+
+type SignUpReq struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+	Locale   string `json:"locale,omitempty"`
+}
+
+type VerifyEmailReq struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationReq struct {
+	Email string `json:"email"`
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordReq struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+const MIN_PASSWORD_LENGTH = 8
+
+type VerificationConfig struct {
+	TTL time.Duration
+	// URL is the page verification emails link to, the token is appended as ?token=
+	URL          string
+	ResendLimit  int
+	ResendWindow time.Duration
+}
+
+type PasswordResetConfig struct {
+	TTL time.Duration
+	// URL is the page password reset emails link to, the token is appended as ?token=
+	URL string
+	// Limit caps the reset emails sent to an account over Window
+	Limit  int
+	Window time.Duration
 }
 
 type UserService interface {
 	CreateUser(ctx context.Context, user *CreateUserReq) error
+	SignUp(ctx context.Context, req *SignUpReq) error
+	VerifyEmail(ctx context.Context, req VerifyEmailReq) error
+	ResendVerification(ctx context.Context, req ResendVerificationReq) error
+	ForgotPassword(ctx context.Context, req ForgotPasswordReq) error
+	ResetPassword(ctx context.Context, req ResetPasswordReq) error
+	GetProfile(ctx context.Context) (*Profile, error)
+	UpdateProfile(ctx context.Context, req UpdateProfileReq) (*Profile, error)
+	ChangePassword(ctx context.Context, req ChangePasswordReq) error
+	ListUsers(ctx context.Context, req ListUsersReq) (*UserPage, error)
+	SuspendUser(ctx context.Context, userID uuid.UUID, req SuspendUserReq) error
+	ReactivateUser(ctx context.Context, userID uuid.UUID) error
+	ChangeRole(ctx context.Context, userID uuid.UUID, req ChangeRoleReq) error
+	RevokeSessions(ctx context.Context, userID uuid.UUID) error
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	RequestDataExport(ctx context.Context, ip string) (*model.DataExport, error)
+	GetDataExport(ctx context.Context, exportID uuid.UUID) (*model.DataExport, error)
+	DownloadDataExport(ctx context.Context, exportID uuid.UUID) (*model.DataExport, error)
+	EraseAccount(ctx context.Context, req EraseAccountReq) error
+	EraseUser(ctx context.Context, userID uuid.UUID) error
 }
 
 type userService struct {
-	userRepo repository.UserRepo
+	userRepo       repository.UserRepo
+	userTokenRepo  repository.UserTokenRepo
+	auditRepo      repository.AuditRepo
+	dataExportRepo repository.DataExportRepo
+	verification   VerificationConfig
+	passwordReset  PasswordResetConfig
 }
 
 type UserServiceOption func(service *userService)
 
 func NewUserService(options ...UserServiceOption) UserService {
-	service := &userService{userRepo: repository.NewUserRepo()}
+	service := &userService{
+		userRepo:       repository.NewUserRepo(),
+		userTokenRepo:  repository.NewUserTokenRepo(),
+		auditRepo:      repository.NewAuditRepo(),
+		dataExportRepo: repository.NewDataExportRepo(),
+		verification: VerificationConfig{
+			TTL:          config.Env.EmailVerificationTTL,
+			URL:          config.Env.EmailVerificationURL,
+			ResendLimit:  config.Env.EmailVerificationResendLimit,
+			ResendWindow: config.Env.EmailVerificationResendWindow,
+		},
+		passwordReset: PasswordResetConfig{
+			TTL:    config.Env.PasswordResetTTL,
+			URL:    config.Env.PasswordResetURL,
+			Limit:  config.Env.PasswordResetLimit,
+			Window: config.Env.PasswordResetWindow,
+		},
+	}
 
 	for _, option := range options {
 		option(service)
@@ -46,39 +140,297 @@ func WithUserRepo(repo repository.UserRepo) UserServiceOption {
 	}
 }
 
+func WithUserTokenRepo(repo repository.UserTokenRepo) UserServiceOption {
+	return func(s *userService) {
+		s.userTokenRepo = repo
+	}
+}
+
+func WithDataExportRepo(repo repository.DataExportRepo) UserServiceOption {
+	return func(s *userService) {
+		s.dataExportRepo = repo
+	}
+}
+
+func WithAuditRepoForUserService(repo repository.AuditRepo) UserServiceOption {
+	return func(s *userService) {
+		s.auditRepo = repo
+	}
+}
+
+func WithVerificationConfig(cfg VerificationConfig) UserServiceOption {
+	return func(s *userService) {
+		s.verification = cfg
+	}
+}
+
+func WithPasswordResetConfig(cfg PasswordResetConfig) UserServiceOption {
+	return func(s *userService) {
+		s.passwordReset = cfg
+	}
+}
+
+// CreateUser creates an account on behalf of an admin, its email address counts as verified
 func (t *userService) CreateUser(ctx context.Context, createUserReq *CreateUserReq) error {
+	role := createUserReq.Role
+	if role == "" {
+		role = model.DefaultRole
+	}
+
+	if !role.Valid() {
+		return fmt.Errorf("%w : %s", ErrInvalidRole, role)
+	}
+
+	user, err := t.newUser(ctx, createUserReq.Username, createUserReq.Email, createUserReq.Password, createUserReq.Locale)
+	if err != nil {
+		return err
+	}
+
+	verifiedAt := time.Now()
+	user.Role = role
+	user.Machine = createUserReq.Machine
+	user.EmailVerifiedAt = &verifiedAt
+
+	if err := t.userRepo.CreateUser(ctx, user); err != nil {
+		return ErrCreatingUser
+	}
+
+	return nil
+}
+
+// SignUp creates a pending account with the default role and mails it a verification token, the
+// account can not log in until the token is confirmed with VerifyEmail
+func (t *userService) SignUp(ctx context.Context, req *SignUpReq) error {
+	if req.Username == "" || !strings.Contains(req.Email, "@") {
+		return fmt.Errorf("%w : username and a valid email are required", ErrInvalidUserDetails)
+	}
+
+	if len(req.Password) < MIN_PASSWORD_LENGTH {
+		return fmt.Errorf("%w : password must be at least %d characters", ErrInvalidUserDetails, MIN_PASSWORD_LENGTH)
+	}
+
+	user, err := t.newUser(ctx, req.Username, req.Email, req.Password, req.Locale)
+	if err != nil {
+		return err
+	}
+	user.Role = model.DefaultRole
+
+	token, notification, err := t.newVerificationToken(user)
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate verification token", logger.Field("email", req.Email))
+		return ErrTokenGenerationFailed
+	}
+
+	if err := t.userRepo.SignUpUser(ctx, user, token, notification); err != nil {
+		return ErrCreatingUser
+	}
+
+	return nil
+}
+
+// VerifyEmail confirms the address a verification or email change token was mailed to
+func (t *userService) VerifyEmail(ctx context.Context, req VerifyEmailReq) error {
+	_, err := t.userTokenRepo.VerifyEmail(ctx, hashToken(req.Token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return t.confirmEmailChange(ctx, req.Token)
+	}
+
+	return err
+}
+
+// ResendVerification mails a new verification token to a pending account, retiring the previous
+// one. Sending is limited per account over a sliding window. Unknown and already verified addresses
+// and accounts that reached the limit are ignored without an error, the response must not tell
+// which addresses have a pending account.
+func (t *userService) ResendVerification(ctx context.Context, req ResendVerificationReq) error {
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{Email: req.Email})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	sent, err := t.userTokenRepo.CountTokens(ctx, user.ID, model.UserTokenPurposeEmailVerification,
+		time.Now().Add(-t.verification.ResendWindow))
+	if err != nil {
+		return err
+	}
+
+	if sent >= int64(t.verification.ResendLimit) {
+		logger.W(ctx, "Verification resend limit reached", logger.Field("user_id", user.ID))
+		return nil
+	}
+
+	token, notification, err := t.newVerificationToken(user)
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate verification token", logger.Field("user_id", user.ID))
+		return ErrTokenGenerationFailed
+	}
+
+	return t.userTokenRepo.CreateToken(ctx, token, notification)
+}
+
+// ForgotPassword mails a password reset token to the account with the email address, retiring
+// the previous one. Unknown addresses and accounts that reached the limit are ignored without an
+// error, the response must not tell which addresses have an account.
+func (t *userService) ForgotPassword(ctx context.Context, req ForgotPasswordReq) error {
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{Email: req.Email})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	sent, err := t.userTokenRepo.CountTokens(ctx, user.ID, model.UserTokenPurposePasswordReset,
+		time.Now().Add(-t.passwordReset.Window))
+	if err != nil {
+		return err
+	}
+
+	if sent >= int64(t.passwordReset.Limit) {
+		logger.W(ctx, "Password reset limit reached", logger.Field("user_id", user.ID))
+		return nil
+	}
+
+	token, notification, err := t.newPasswordResetToken(user)
+	if err != nil {
+		logger.E(ctx, err, "Failed to generate password reset token", logger.Field("user_id", user.ID))
+		return ErrTokenGenerationFailed
+	}
+
+	return t.userTokenRepo.CreateToken(ctx, token, notification)
+}
+
+// ResetPassword sets a new password for the account the reset token was sent to and signs it out
+// of every session. The token can only be used once.
+func (t *userService) ResetPassword(ctx context.Context, req ResetPasswordReq) error {
+	if len(req.Password) < MIN_PASSWORD_LENGTH {
+		return fmt.Errorf("%w : password must be at least %d characters", ErrInvalidUserDetails, MIN_PASSWORD_LENGTH)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.E(ctx, err, "Failed to hash password")
+		return err
+	}
+
+	_, err = t.userTokenRepo.ResetPassword(ctx, hashToken(req.Token), string(hashedPassword))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidResetToken
+	}
+
+	return err
+}
+
+// newUser builds a user with a hashed password, failing if the username or email is taken
+func (t *userService) newUser(ctx context.Context, username, email, password, locale string) (*model.User, error) {
 	// TODO: refactor to use FirstOrCreate
-	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{Email: createUserReq.Email, Username: createUserReq.Username})
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{Email: email, Username: username})
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logger.W(ctx, "Error while checking existing users", logger.Field("email", createUserReq.Email))
-		return err
+		logger.W(ctx, "Error while checking existing users", logger.Field("email", email))
+		return nil, err
 	}
 
 	if user != nil {
 		logger.D(ctx, "User already exists",
-			logger.Field("email", createUserReq.Email),
-			logger.Field("username", createUserReq.Username))
-		return ErrUserAlreadyExistsWithSameEmailUsername
+			logger.Field("email", email),
+			logger.Field("username", username))
+		return nil, ErrUserAlreadyExistsWithSameEmailUsername
+	}
+
+	if locale == "" {
+		locale = notification_worker.DefaultLocale
 	}
 
 	user = &model.User{
 		ID:        uuid.New(),
-		Email:     createUserReq.Email,
-		Username:  createUserReq.Username,
+		Email:     email,
+		Username:  username,
+		Locale:    locale,
 		CreatedAt: time.Now(),
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(createUserReq.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		logger.E(ctx, err, "Failed to hash password")
-		return err
+		return nil, err
 	}
 
 	user.Password = string(hashedPassword)
 
-	if err := t.userRepo.CreateUser(ctx, user); err != nil {
-		return ErrCreatingUser
+	return user, nil
+}
+
+// newVerificationToken returns the verification token row for the user and the outbox
+// notification mailing the token to them
+func (t *userService) newVerificationToken(user *model.User) (*model.UserToken, *model.OutboxNotification, error) {
+	return newUserToken(user, model.UserTokenPurposeEmailVerification, t.verification.TTL,
+		func(token string, expiresAt time.Time) (*model.OutboxNotification, error) {
+			return notification_worker.NewOutboxNotification(notification_worker.EmailNotificationSubjectVerifyEmail, user.ID,
+				notification_worker.VerifyEmail{
+					UserID:    user.ID,
+					Username:  user.Username,
+					Token:     notification_worker.SealedToken(token),
+					VerifyURL: notification_worker.SealedToken(tokenURL(t.verification.URL, token)),
+					ExpiresAt: expiresAt,
+				})
+		})
+}
+
+// newPasswordResetToken returns the password reset token row for the user and the outbox
+// notification mailing the token to them
+func (t *userService) newPasswordResetToken(user *model.User) (*model.UserToken, *model.OutboxNotification, error) {
+	return newUserToken(user, model.UserTokenPurposePasswordReset, t.passwordReset.TTL,
+		func(token string, expiresAt time.Time) (*model.OutboxNotification, error) {
+			return notification_worker.NewOutboxNotification(notification_worker.EmailNotificationSubjectResetPassword, user.ID,
+				notification_worker.ResetPasswordEmail{
+					UserID:    user.ID,
+					Username:  user.Username,
+					Token:     notification_worker.SealedToken(token),
+					ResetURL:  notification_worker.SealedToken(tokenURL(t.passwordReset.URL, token)),
+					ExpiresAt: expiresAt,
+				})
+		})
+}
+
+// newUserToken generates a token for purpose and returns the row storing its hash along with the
+// notification mail builds to send the token to the user
+func newUserToken(user *model.User, purpose model.UserTokenPurpose, ttl time.Duration,
+	mail func(token string, expiresAt time.Time) (*model.OutboxNotification, error)) (*model.UserToken, *model.OutboxNotification, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return nil
+	expiresAt := time.Now().Add(ttl)
+
+	notification, err := mail(token, expiresAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &model.UserToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	}, notification, nil
+}
+
+// tokenURL links to page with the token, it is empty when no page is configured
+func tokenURL(page, token string) string {
+	if page == "" {
+		return ""
+	}
+
+	return page + "?token=" + url.QueryEscape(token)
 }
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+const MAX_SUSPENSION_REASON = 500
+
+type ListUsersReq struct {
+	// Query matches part of the username, email or display name
+	Query  string
+	Role   model.Role
+	Status model.UserStatus
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+	Limit  int
+}
+
+// ManagedUser is an account as admins see it
+type ManagedUser struct {
+	*Profile
+	Status      model.UserStatus `json:"status"`
+	Machine     bool             `json:"machine,omitempty"`
+	SuspendedAt *time.Time       `json:"suspended_at,omitempty"`
+	DeletedAt   *time.Time       `json:"deleted_at,omitempty"`
+}
+
+type UserPage struct {
+	Users []ManagedUser `json:"users"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+type SuspendUserReq struct {
+	Reason string `json:"reason"`
+}
+
+type ChangeRoleReq struct {
+	Role model.Role `json:"role"`
+}
+
+func newManagedUser(user *model.User) ManagedUser {
+	managed := ManagedUser{
+		Profile:     newProfile(user),
+		Status:      user.Status(),
+		Machine:     user.Machine,
+		SuspendedAt: user.SuspendedAt,
+	}
+
+	if user.DeletedAt.Valid {
+		managed.DeletedAt = &user.DeletedAt.Time
+	}
+
+	return managed
+}
+
+// ListUsers returns a page of users matching the filters, newest first. One extra row is fetched
+// to tell whether another page follows.
+func (t *userService) ListUsers(ctx context.Context, req ListUsersReq) (*UserPage, error) {
+	if req.Role != "" && !req.Role.Valid() {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidRole, req.Role)
+	}
+
+	if req.Status != "" && !req.Status.Valid() {
+		return nil, fmt.Errorf("%w : unknown status %q", ErrInvalidUserDetails, req.Status)
+	}
+
+	opts := repository.ListUsersOpts{
+		Query:  strings.TrimSpace(req.Query),
+		Role:   req.Role,
+		Status: req.Status,
+		Limit:  req.Limit + 1,
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		opts.After = cursor
+	}
+
+	users, err := t.userRepo.ListUsers(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &UserPage{Users: []ManagedUser{}}
+	if len(users) > req.Limit {
+		users = users[:req.Limit]
+		last := users[req.Limit-1]
+		page.NextCursor = encodeCursor(repository.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	for i := range users {
+		page.Users = append(page.Users, newManagedUser(&users[i]))
+	}
+
+	return page, nil
+}
+
+// SuspendUser stops a user from logging in or using their tokens and API keys until they are
+// reactivated. Admins can not suspend themselves.
+func (t *userService) SuspendUser(ctx context.Context, userID uuid.UUID, req SuspendUserReq) error {
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > MAX_SUSPENSION_REASON {
+		return fmt.Errorf("%w : reason must be at most %d characters", ErrInvalidUserDetails, MAX_SUSPENSION_REASON)
+	}
+
+	user, err := t.managedUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.SuspendedAt != nil {
+		return fmt.Errorf("%w : already suspended", ErrInvalidUserStatus)
+	}
+
+	if err := t.userRepo.SuspendUser(ctx, userID); err != nil {
+		return err
+	}
+
+	t.adminAudit(ctx, model.AuditEventUserSuspended, user, reason)
+
+	return nil
+}
+
+func (t *userService) ReactivateUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := t.managedUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.SuspendedAt == nil {
+		return fmt.Errorf("%w : not suspended", ErrInvalidUserStatus)
+	}
+
+	if err := t.userRepo.ReactivateUser(ctx, userID); err != nil {
+		return err
+	}
+
+	t.adminAudit(ctx, model.AuditEventUserReactivated, user, "")
+
+	return nil
+}
+
+// ChangeRole gives a user another role, it applies to their next request. Admins can not change
+// their own role so that there is always an admin left.
+func (t *userService) ChangeRole(ctx context.Context, userID uuid.UUID, req ChangeRoleReq) error {
+	if !req.Role.Valid() {
+		return fmt.Errorf("%w : %s", ErrInvalidRole, req.Role)
+	}
+
+	user, err := t.managedUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == req.Role {
+		return nil
+	}
+
+	if err := t.userRepo.SetRole(ctx, userID, req.Role); err != nil {
+		return err
+	}
+
+	t.adminAudit(ctx, model.AuditEventRoleChanged, user, string(req.Role))
+
+	return nil
+}
+
+// RevokeSessions signs a user out everywhere, their refresh tokens, access tokens and personal API
+// keys stop working
+func (t *userService) RevokeSessions(ctx context.Context, userID uuid.UUID) error {
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: userID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := t.userRepo.RevokeSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	t.adminAudit(ctx, model.AuditEventSessionsRevoked, user, "")
+
+	return nil
+}
+
+// DeleteUser soft deletes a user, who is signed out and can no longer be found or log in. Their
+// sightings and other records are kept.
+func (t *userService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := t.managedUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := t.userRepo.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	t.adminAudit(ctx, model.AuditEventUserDeleted, user, "")
+
+	return nil
+}
+
+// managedUser returns a user an admin is about to change, which can not be the admin themselves
+func (t *userService) managedUser(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	if userID == uuid.MustParse(ctx.Value("userID").(string)) {
+		return nil, ErrCannotManageOwnAccount
+	}
+
+	user, err := t.userRepo.GetUser(ctx, repository.GetUserOpts{ID: userID})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "User does not exist", logger.Field("user_id", userID))
+		return nil, ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// adminAudit records an admin, taken from ctx, acting on a user
+func (t *userService) adminAudit(ctx context.Context, eventType model.AuditEventType, user *model.User, reason string) {
+	actorID := uuid.MustParse(ctx.Value("userID").(string))
+
+	_ = t.auditRepo.CreateEvent(ctx, &model.AuditEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		UserID:    &user.ID,
+		ActorID:   &actorID,
+		Username:  user.Username,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+}
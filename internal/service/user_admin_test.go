@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func adminContext() context.Context {
+	return context.WithValue(context.Background(), "userID", uuid.New().String())
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	t.Run("should return a cursor to the next page when there are more users", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := adminContext()
+		// the cursor keeps the time in UTC without the monotonic clock reading
+		now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		users := []model.User{
+			{ID: uuid.New(), Username: "a", CreatedAt: now, EmailVerifiedAt: &now},
+			{ID: uuid.New(), Username: "b", CreatedAt: now.Add(-time.Minute), SuspendedAt: &now},
+			{ID: uuid.New(), Username: "c", CreatedAt: now.Add(-time.Hour)},
+		}
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		gomock.InOrder(
+			mockUserRepo.EXPECT().ListUsers(ctx, repository.ListUsersOpts{
+				Query: "ravi",
+				Role:  model.RoleReporter,
+				Limit: 3,
+			}).Return(users, nil),
+			mockUserRepo.EXPECT().ListUsers(ctx, repository.ListUsersOpts{
+				After: &repository.Cursor{CreatedAt: users[1].CreatedAt, ID: users[1].ID},
+				Limit: 3,
+			}).Return(users[2:], nil),
+		)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo))
+
+		page, err := userService.ListUsers(ctx, ListUsersReq{Query: " ravi ", Role: model.RoleReporter, Limit: 2})
+		assert.Nil(t, err)
+		assert.Len(t, page.Users, 2)
+		assert.Equal(t, model.UserStatusActive, page.Users[0].Status)
+		assert.Equal(t, model.UserStatusSuspended, page.Users[1].Status)
+		assert.NotEmpty(t, page.NextCursor)
+
+		page, err = userService.ListUsers(ctx, ListUsersReq{Cursor: page.NextCursor, Limit: 2})
+		assert.Nil(t, err)
+		assert.Len(t, page.Users, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("should reject unknown roles and statuses", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userService := NewUserService(WithUserRepo(mock_repository.NewMockUserRepo(ctrl)))
+
+		_, err := userService.ListUsers(adminContext(), ListUsersReq{Role: "ranger", Limit: 20})
+		assert.ErrorIs(t, err, ErrInvalidRole)
+
+		_, err = userService.ListUsers(adminContext(), ListUsersReq{Status: "banned", Limit: 20})
+		assert.ErrorIs(t, err, ErrInvalidUserDetails)
+	})
+}
+
+func TestUserService_SuspendUser(t *testing.T) {
+	t.Run("should suspend the user and audit the reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := adminContext()
+		user := profileUser()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+		mockUserRepo.EXPECT().SuspendUser(ctx, user.ID).Return(nil)
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventUserSuspended, event.Type)
+			assert.Equal(t, &user.ID, event.UserID)
+			assert.Equal(t, ctx.Value("userID"), event.ActorID.String())
+			assert.Equal(t, "poaching reports", event.Reason)
+			return nil
+		})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithAuditRepoForUserService(mockAuditRepo))
+
+		err := userService.SuspendUser(ctx, user.ID, SuspendUserReq{Reason: " poaching reports "})
+		assert.Nil(t, err)
+	})
+
+	t.Run("should not suspend the admin themselves", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := adminContext()
+		userService := NewUserService(WithUserRepo(mock_repository.NewMockUserRepo(ctrl)))
+
+		err := userService.SuspendUser(ctx, uuid.MustParse(ctx.Value("userID").(string)), SuspendUserReq{})
+		assert.Equal(t, ErrCannotManageOwnAccount, err)
+	})
+
+	t.Run("should reject users that are already suspended or missing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := adminContext()
+		now := time.Now()
+		user := profileUser()
+		user.SuspendedAt = &now
+		missingID := uuid.New()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: missingID}).Return(nil, gorm.ErrRecordNotFound)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo))
+
+		err := userService.SuspendUser(ctx, user.ID, SuspendUserReq{})
+		assert.ErrorIs(t, err, ErrInvalidUserStatus)
+
+		err = userService.SuspendUser(ctx, missingID, SuspendUserReq{})
+		assert.Equal(t, ErrUserNotFound, err)
+	})
+}
+
+func TestUserService_ChangeRole(t *testing.T) {
+	t.Run("should change the role and audit the new one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := adminContext()
+		user := profileUser()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil).Times(2)
+		mockUserRepo.EXPECT().SetRole(ctx, user.ID, model.RoleAdmin).Return(nil)
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventRoleChanged, event.Type)
+			assert.Equal(t, string(model.RoleAdmin), event.Reason)
+			return nil
+		})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithAuditRepoForUserService(mockAuditRepo))
+
+		err := userService.ChangeRole(ctx, user.ID, ChangeRoleReq{Role: model.RoleAdmin})
+		assert.Nil(t, err)
+
+		// the user already has the role, nothing changes
+		err = userService.ChangeRole(ctx, user.ID, ChangeRoleReq{Role: model.RoleResearcher})
+		assert.Nil(t, err)
+	})
+
+	t.Run("should reject unknown roles", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userService := NewUserService(WithUserRepo(mock_repository.NewMockUserRepo(ctrl)))
+
+		err := userService.ChangeRole(adminContext(), uuid.New(), ChangeRoleReq{Role: "ranger"})
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+}
+
+func TestUserService_DeleteUser(t *testing.T) {
+	t.Run("should soft delete the user and audit it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := adminContext()
+		user := profileUser()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{ID: user.ID}).Return(user, nil)
+		mockUserRepo.EXPECT().DeleteUser(ctx, user.ID).Return(nil)
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventUserDeleted, event.Type)
+			return nil
+		})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithAuditRepoForUserService(mockAuditRepo))
+
+		err := userService.DeleteUser(ctx, user.ID)
+		assert.Nil(t, err)
+	})
+}
@@ -2,13 +2,18 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
+	"tigerhall_kittens/cmd/notification_worker"
 	"tigerhall_kittens/internal/model"
 	"tigerhall_kittens/internal/repository"
 	mock_repository "tigerhall_kittens/internal/repository/mocks"
@@ -111,4 +116,311 @@ func TestUserService_CreateUser(t *testing.T) {
 		actualErr := tigerService.CreateUser(ctx, &createUserReq)
 		assert.Equal(t, nil, actualErr)
 	})
+
+	t.Run("should create users with the default role", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, getUserOpts).Return(nil, nil)
+		mockUserRepo.EXPECT().CreateUser(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, user *model.User) error {
+			assert.Equal(t, model.DefaultRole, user.Role)
+			assert.NotNil(t, user.EmailVerifiedAt)
+			return nil
+		})
+
+		userService := NewUserService(
+			WithUserRepo(mockUserRepo),
+		)
+
+		actualErr := userService.CreateUser(ctx, &createUserReq)
+		assert.Nil(t, actualErr)
+	})
+
+	t.Run("should return invalid role error for unknown roles", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		userService := NewUserService(
+			WithUserRepo(mock_repository.NewMockUserRepo(ctrl)),
+		)
+
+		req := createUserReq
+		req.Role = "ranger"
+
+		actualErr := userService.CreateUser(ctx, &req)
+		assert.True(t, errors.Is(actualErr, ErrInvalidRole))
+	})
+}
+
+func TestUserService_SignUp(t *testing.T) {
+	signUpReq := SignUpReq{
+		Username: "ranger_ravi",
+		Password: "tiger_tracks",
+		Email:    "ravi@example.com",
+	}
+
+	verification := VerificationConfig{TTL: time.Hour, URL: "https://tigerhall.io/verify-email", ResendLimit: 3, ResendWindow: time.Hour}
+
+	t.Run("should return invalid user details error for a short password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userService := NewUserService(WithUserRepo(mock_repository.NewMockUserRepo(ctrl)))
+
+		req := signUpReq
+		req.Password = "short"
+
+		err := userService.SignUp(context.Background(), &req)
+		assert.True(t, errors.Is(err, ErrInvalidUserDetails))
+	})
+
+	t.Run("should create a pending user and mail it a verification token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: signUpReq.Email, Username: signUpReq.Username}).Return(nil, gorm.ErrRecordNotFound)
+		mockUserRepo.EXPECT().SignUpUser(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, user *model.User, token *model.UserToken, notification *model.OutboxNotification) error {
+				assert.Nil(t, user.EmailVerifiedAt)
+				assert.Equal(t, model.DefaultRole, user.Role)
+				assert.Equal(t, user.ID, token.UserID)
+				assert.Equal(t, model.UserTokenPurposeEmailVerification, token.Purpose)
+				assert.Equal(t, notification_worker.EmailNotificationSubjectVerifyEmail, notification.Subject)
+
+				var payload notification_worker.VerifyEmail
+				assert.Nil(t, json.Unmarshal(notification.Payload, &payload))
+				assert.NotContains(t, string(notification.Payload), string(payload.Token))
+				assert.Equal(t, hashToken(string(payload.Token)), token.TokenHash)
+				assert.Equal(t, verification.URL+"?token="+string(payload.Token), string(payload.VerifyURL))
+				return nil
+			})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithVerificationConfig(verification))
+
+		err := userService.SignUp(ctx, &signUpReq)
+		assert.Nil(t, err)
+	})
+}
+
+func TestUserService_VerifyEmail(t *testing.T) {
+	t.Run("should return invalid verification token error for a used or expired token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserTokenRepo := mock_repository.NewMockUserTokenRepo(ctrl)
+		mockUserTokenRepo.EXPECT().VerifyEmail(ctx, hashToken("token")).Return(uuid.Nil, gorm.ErrRecordNotFound)
+		mockUserTokenRepo.EXPECT().ConfirmEmailChange(ctx, hashToken("token")).Return(nil, gorm.ErrRecordNotFound)
+
+		userService := NewUserService(WithUserTokenRepo(mockUserTokenRepo))
+
+		err := userService.VerifyEmail(ctx, VerifyEmailReq{Token: "token"})
+		assert.Equal(t, ErrInvalidVerificationToken, err)
+	})
+
+	t.Run("should confirm an email change with its token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		user := &model.User{ID: uuid.New(), Username: "ranger_ravi", Email: "ravi@wwf.example.org"}
+
+		mockUserTokenRepo := mock_repository.NewMockUserTokenRepo(ctrl)
+		mockUserTokenRepo.EXPECT().VerifyEmail(ctx, hashToken("token")).Return(uuid.Nil, gorm.ErrRecordNotFound)
+		mockUserTokenRepo.EXPECT().ConfirmEmailChange(ctx, hashToken("token")).Return(user, nil)
+
+		mockAuditRepo := mock_repository.NewMockAuditRepo(ctrl)
+		mockAuditRepo.EXPECT().CreateEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *model.AuditEvent) error {
+			assert.Equal(t, model.AuditEventEmailChanged, event.Type)
+			assert.Equal(t, user.ID, *event.UserID)
+			assert.Equal(t, user.Email, event.Reason)
+			return nil
+		})
+
+		userService := NewUserService(WithUserTokenRepo(mockUserTokenRepo), WithAuditRepoForUserService(mockAuditRepo))
+
+		err := userService.VerifyEmail(ctx, VerifyEmailReq{Token: "token"})
+		assert.Nil(t, err)
+	})
+}
+
+func TestUserService_ResendVerification(t *testing.T) {
+	verification := VerificationConfig{TTL: time.Hour, ResendLimit: 3, ResendWindow: time.Hour}
+	req := ResendVerificationReq{Email: "ravi@example.com"}
+	pendingUser := &model.User{ID: uuid.New(), Email: req.Email, Username: "ranger_ravi"}
+
+	t.Run("should ignore unknown and verified addresses", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		verifiedAt := time.Now()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		gomock.InOrder(
+			mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: req.Email}).Return(nil, gorm.ErrRecordNotFound),
+			mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: req.Email}).Return(&model.User{ID: uuid.New(), EmailVerifiedAt: &verifiedAt}, nil),
+		)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithUserTokenRepo(mock_repository.NewMockUserTokenRepo(ctrl)),
+			WithVerificationConfig(verification))
+
+		assert.Nil(t, userService.ResendVerification(ctx, req))
+		assert.Nil(t, userService.ResendVerification(ctx, req))
+	})
+
+	t.Run("should ignore resends over the limit without an error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: req.Email}).Return(pendingUser, nil)
+
+		mockUserTokenRepo := mock_repository.NewMockUserTokenRepo(ctrl)
+		mockUserTokenRepo.EXPECT().CountTokens(ctx, pendingUser.ID, model.UserTokenPurposeEmailVerification, gomock.Any()).Return(int64(3), nil)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithUserTokenRepo(mockUserTokenRepo),
+			WithVerificationConfig(verification))
+
+		err := userService.ResendVerification(ctx, req)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should mail a new token to a pending account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: req.Email}).Return(pendingUser, nil)
+
+		mockUserTokenRepo := mock_repository.NewMockUserTokenRepo(ctrl)
+		mockUserTokenRepo.EXPECT().CountTokens(ctx, pendingUser.ID, model.UserTokenPurposeEmailVerification, gomock.Any()).Return(int64(1), nil)
+		mockUserTokenRepo.EXPECT().CreateToken(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, token *model.UserToken, notification *model.OutboxNotification) error {
+				assert.Equal(t, pendingUser.ID, token.UserID)
+				assert.Equal(t, pendingUser.ID, notification.UserID)
+				return nil
+			})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithUserTokenRepo(mockUserTokenRepo),
+			WithVerificationConfig(verification))
+
+		assert.Nil(t, userService.ResendVerification(ctx, req))
+	})
+}
+
+func TestUserService_ForgotPassword(t *testing.T) {
+	passwordReset := PasswordResetConfig{TTL: time.Hour, URL: "https://tigerhall.io/reset-password", Limit: 3, Window: time.Hour}
+	req := ForgotPasswordReq{Email: "ravi@example.com"}
+	user := &model.User{ID: uuid.New(), Email: req.Email, Username: "ranger_ravi"}
+
+	t.Run("should ignore unknown addresses and accounts over the limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		gomock.InOrder(
+			mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: req.Email}).Return(nil, gorm.ErrRecordNotFound),
+			mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: req.Email}).Return(user, nil),
+		)
+
+		mockUserTokenRepo := mock_repository.NewMockUserTokenRepo(ctrl)
+		mockUserTokenRepo.EXPECT().CountTokens(ctx, user.ID, model.UserTokenPurposePasswordReset, gomock.Any()).Return(int64(3), nil)
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithUserTokenRepo(mockUserTokenRepo),
+			WithPasswordResetConfig(passwordReset))
+
+		assert.Nil(t, userService.ForgotPassword(ctx, req))
+		assert.Nil(t, userService.ForgotPassword(ctx, req))
+	})
+
+	t.Run("should mail a reset link and only store the hash of its token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserRepo := mock_repository.NewMockUserRepo(ctrl)
+		mockUserRepo.EXPECT().GetUser(ctx, repository.GetUserOpts{Email: req.Email}).Return(user, nil)
+
+		mockUserTokenRepo := mock_repository.NewMockUserTokenRepo(ctrl)
+		mockUserTokenRepo.EXPECT().CountTokens(ctx, user.ID, model.UserTokenPurposePasswordReset, gomock.Any()).Return(int64(0), nil)
+		mockUserTokenRepo.EXPECT().CreateToken(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, token *model.UserToken, notification *model.OutboxNotification) error {
+				var email notification_worker.ResetPasswordEmail
+				assert.Nil(t, json.Unmarshal(notification.Payload, &email))
+
+				assert.Equal(t, model.UserTokenPurposePasswordReset, token.Purpose)
+				assert.NotContains(t, string(notification.Payload), string(email.Token))
+				assert.Equal(t, hashToken(string(email.Token)), token.TokenHash)
+				assert.Equal(t, passwordReset.URL+"?token="+string(email.Token), string(email.ResetURL))
+				assert.Equal(t, notification_worker.EmailNotificationSubjectResetPassword, notification.Subject)
+				return nil
+			})
+
+		userService := NewUserService(WithUserRepo(mockUserRepo), WithUserTokenRepo(mockUserTokenRepo),
+			WithPasswordResetConfig(passwordReset))
+
+		assert.Nil(t, userService.ForgotPassword(ctx, req))
+	})
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	t.Run("should return invalid user details error for a short password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userService := NewUserService(WithUserTokenRepo(mock_repository.NewMockUserTokenRepo(ctrl)))
+
+		err := userService.ResetPassword(context.Background(), ResetPasswordReq{Token: "token", Password: "short"})
+		assert.True(t, errors.Is(err, ErrInvalidUserDetails))
+	})
+
+	t.Run("should return invalid reset token error for a used or expired token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserTokenRepo := mock_repository.NewMockUserTokenRepo(ctrl)
+		mockUserTokenRepo.EXPECT().ResetPassword(ctx, hashToken("token"), gomock.Any()).Return(uuid.Nil, gorm.ErrRecordNotFound)
+
+		userService := NewUserService(WithUserTokenRepo(mockUserTokenRepo))
+
+		err := userService.ResetPassword(ctx, ResetPasswordReq{Token: "token", Password: "new password"})
+		assert.Equal(t, ErrInvalidResetToken, err)
+	})
+
+	t.Run("should store a hash of the new password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockUserTokenRepo := mock_repository.NewMockUserTokenRepo(ctrl)
+		mockUserTokenRepo.EXPECT().ResetPassword(ctx, hashToken("token"), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, passwordHash string) (uuid.UUID, error) {
+				assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte("new password")))
+				return uuid.New(), nil
+			})
+
+		userService := NewUserService(WithUserTokenRepo(mockUserTokenRepo))
+
+		assert.Nil(t, userService.ResetPassword(ctx, ResetPasswordReq{Token: "token", Password: "new password"}))
+	})
 }
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+	"tigerhall_kittens/internal/model"
+	"tigerhall_kittens/internal/repository"
+)
+
+const MIN_WEBHOOK_SECRET_LENGTH = 16
+
+// WebhookReq describes a webhook subscription. A secret is generated when Secret is empty on
+// create, and left as it is when empty on update.
+type WebhookReq struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+type WebhookDeliveryRes struct {
+	*model.WebhookDelivery
+	Attempts []model.WebhookDeliveryAttempt `json:"attempts_log"`
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req WebhookReq) (*model.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, webhookID uuid.UUID, req WebhookReq) (*model.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error
+	GetWebhook(ctx context.Context, webhookID uuid.UUID) (*model.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context, opts repository.ListWebhooksOpts) ([]model.WebhookSubscription, error)
+	ListDeliveries(ctx context.Context, opts repository.ListWebhookDeliveriesOpts) ([]model.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, deliveryID uuid.UUID) (*WebhookDeliveryRes, error)
+	Redeliver(ctx context.Context, deliveryID uuid.UUID) error
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepo
+}
+
+type WebhookServiceOption func(service *webhookService)
+
+func NewWebhookService(options ...WebhookServiceOption) WebhookService {
+	service := &webhookService{webhookRepo: repository.NewWebhookRepo()}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+func WithWebhookRepo(repo repository.WebhookRepo) WebhookServiceOption {
+	return func(s *webhookService) {
+		s.webhookRepo = repo
+	}
+}
+
+func (t *webhookService) CreateWebhook(ctx context.Context, req WebhookReq) (*model.WebhookSubscription, error) {
+	if req.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			logger.E(ctx, err, "Error while generating webhook secret")
+			return nil, err
+		}
+		req.Secret = secret
+	}
+
+	userID := uuid.MustParse(ctx.Value("userID").(string))
+	now := time.Now()
+	subscription := &model.WebhookSubscription{
+		ID:              uuid.New(),
+		Active:          true,
+		CreatedByUserID: &userID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := applyWebhookReq(subscription, req); err != nil {
+		logger.W(ctx, "Invalid webhook", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	if err := t.webhookRepo.CreateSubscription(ctx, subscription); err != nil {
+		logger.E(ctx, err, "Error while creating webhook")
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (t *webhookService) UpdateWebhook(ctx context.Context, webhookID uuid.UUID, req WebhookReq) (*model.WebhookSubscription, error) {
+	subscription, err := t.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyWebhookReq(subscription, req); err != nil {
+		logger.W(ctx, "Invalid webhook", logger.Field("error", err.Error()))
+		return nil, err
+	}
+
+	err = t.webhookRepo.UpdateSubscription(ctx, subscription)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while updating webhook", logger.Field("webhook_id", webhookID))
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (t *webhookService) DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error {
+	err := t.webhookRepo.DeleteSubscription(ctx, webhookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Webhook does not exist", logger.Field("webhook_id", webhookID))
+		return ErrWebhookNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while deleting webhook", logger.Field("webhook_id", webhookID))
+		return err
+	}
+
+	return nil
+}
+
+func (t *webhookService) GetWebhook(ctx context.Context, webhookID uuid.UUID) (*model.WebhookSubscription, error) {
+	subscription, err := t.webhookRepo.GetSubscription(ctx, webhookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Webhook does not exist", logger.Field("webhook_id", webhookID))
+		return nil, ErrWebhookNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching webhook", logger.Field("webhook_id", webhookID))
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (t *webhookService) ListWebhooks(ctx context.Context, opts repository.ListWebhooksOpts) ([]model.WebhookSubscription, error) {
+	subscriptions, err := t.webhookRepo.GetSubscriptions(ctx, opts)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching webhooks", logger.Field("opts", opts))
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// ListDeliveries lists the delivery log of a webhook, newest first
+func (t *webhookService) ListDeliveries(ctx context.Context, opts repository.ListWebhookDeliveriesOpts) ([]model.WebhookDelivery, error) {
+	if _, err := t.GetWebhook(ctx, opts.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := t.webhookRepo.GetDeliveries(ctx, opts)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching webhook deliveries", logger.Field("opts", opts))
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// GetDelivery returns a delivery along with the log of every attempt at it
+func (t *webhookService) GetDelivery(ctx context.Context, deliveryID uuid.UUID) (*WebhookDeliveryRes, error) {
+	delivery, err := t.webhookRepo.GetDelivery(ctx, deliveryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Webhook delivery does not exist", logger.Field("delivery_id", deliveryID))
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching webhook delivery", logger.Field("delivery_id", deliveryID))
+		return nil, err
+	}
+
+	attempts, err := t.webhookRepo.GetDeliveryAttempts(ctx, deliveryID)
+	if err != nil {
+		logger.E(ctx, err, "Error while fetching webhook delivery attempts", logger.Field("delivery_id", deliveryID))
+		return nil, err
+	}
+
+	return &WebhookDeliveryRes{WebhookDelivery: delivery, Attempts: attempts}, nil
+}
+
+// Redeliver sends a delivery again, delivered and dead-lettered ones included
+func (t *webhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) error {
+	err := t.webhookRepo.Redeliver(ctx, deliveryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.W(ctx, "Webhook delivery does not exist", logger.Field("delivery_id", deliveryID))
+		return ErrWebhookDeliveryNotFound
+	}
+
+	if err != nil {
+		logger.E(ctx, err, "Error while redelivering webhook", logger.Field("delivery_id", deliveryID))
+		return err
+	}
+
+	return nil
+}
+
+func applyWebhookReq(subscription *model.WebhookSubscription, req WebhookReq) error {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w : url must be an absolute http(s) url", ErrInvalidWebhook)
+	}
+
+	if len(req.Events) == 0 {
+		return fmt.Errorf("%w : at least one event is required", ErrInvalidWebhook)
+	}
+
+	seen := map[string]bool{}
+	var events []string
+	for _, event := range req.Events {
+		if !isWebhookEvent(event) {
+			return fmt.Errorf("%w : unknown event %q", ErrInvalidWebhook, event)
+		}
+
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+
+	if req.Secret != "" {
+		if len(req.Secret) < MIN_WEBHOOK_SECRET_LENGTH {
+			return fmt.Errorf("%w : secret must be at least %d characters", ErrInvalidWebhook, MIN_WEBHOOK_SECRET_LENGTH)
+		}
+		subscription.Secret = req.Secret
+	}
+
+	subscription.URL = req.URL
+	subscription.Events = events
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+
+	return nil
+}
+
+func isWebhookEvent(event string) bool {
+	for _, known := range model.WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
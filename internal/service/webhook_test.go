@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/model"
+	mock_repository "tigerhall_kittens/internal/repository/mocks"
+)
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("should return invalid webhook error for unknown events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		webhookService := NewWebhookService(WithWebhookRepo(mock_repository.NewMockWebhookRepo(ctrl)))
+
+		_, err := webhookService.CreateWebhook(ctx, WebhookReq{URL: "https://ngo.example.org/hooks", Events: []string{"tiger.deleted"}})
+		assert.True(t, errors.Is(err, ErrInvalidWebhook))
+	})
+
+	t.Run("should return invalid webhook error for a relative url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		webhookService := NewWebhookService(WithWebhookRepo(mock_repository.NewMockWebhookRepo(ctrl)))
+
+		_, err := webhookService.CreateWebhook(ctx, WebhookReq{URL: "/hooks", Events: []string{model.WebhookEventTigerCreated}})
+		assert.True(t, errors.Is(err, ErrInvalidWebhook))
+	})
+
+	t.Run("should create an active webhook with a generated secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.WithValue(context.Background(), "userID", userID.String())
+
+		mockWebhookRepo := mock_repository.NewMockWebhookRepo(ctrl)
+		mockWebhookRepo.EXPECT().CreateSubscription(ctx, gomock.Any()).Return(nil)
+
+		webhookService := NewWebhookService(WithWebhookRepo(mockWebhookRepo))
+
+		webhook, err := webhookService.CreateWebhook(ctx, WebhookReq{
+			URL:    "https://ngo.example.org/hooks",
+			Events: []string{model.WebhookEventSightingCreated, model.WebhookEventSightingCreated, model.WebhookEventTigerUpdated},
+		})
+		assert.Nil(t, err)
+		assert.True(t, webhook.Active)
+		assert.Len(t, webhook.Secret, 64)
+		assert.Equal(t, []string{model.WebhookEventSightingCreated, model.WebhookEventTigerUpdated}, []string(webhook.Events))
+		assert.Equal(t, userID, *webhook.CreatedByUserID)
+	})
+}
+
+func TestWebhookService_UpdateWebhook(t *testing.T) {
+	webhookID := uuid.New()
+
+	t.Run("should keep the secret when none is given", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		active := false
+
+		mockWebhookRepo := mock_repository.NewMockWebhookRepo(ctrl)
+		mockWebhookRepo.EXPECT().GetSubscription(ctx, webhookID).Return(&model.WebhookSubscription{
+			ID: webhookID, URL: "https://ngo.example.org/hooks", Secret: "0123456789abcdef", Active: true,
+		}, nil)
+		mockWebhookRepo.EXPECT().UpdateSubscription(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, webhook *model.WebhookSubscription) error {
+				assert.Equal(t, "0123456789abcdef", webhook.Secret)
+				assert.Equal(t, "https://ngo.example.org/v2/hooks", webhook.URL)
+				assert.False(t, webhook.Active)
+				return nil
+			})
+
+		webhookService := NewWebhookService(WithWebhookRepo(mockWebhookRepo))
+
+		_, err := webhookService.UpdateWebhook(ctx, webhookID, WebhookReq{
+			URL:    "https://ngo.example.org/v2/hooks",
+			Events: []string{model.WebhookEventTigerCreated},
+			Active: &active,
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return webhook not found error for unknown webhook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockWebhookRepo := mock_repository.NewMockWebhookRepo(ctrl)
+		mockWebhookRepo.EXPECT().GetSubscription(ctx, webhookID).Return(nil, gorm.ErrRecordNotFound)
+
+		webhookService := NewWebhookService(WithWebhookRepo(mockWebhookRepo))
+
+		_, err := webhookService.UpdateWebhook(ctx, webhookID, WebhookReq{})
+		assert.Equal(t, ErrWebhookNotFound, err)
+	})
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	deliveryID := uuid.New()
+
+	t.Run("should return delivery not found error for unknown delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockWebhookRepo := mock_repository.NewMockWebhookRepo(ctrl)
+		mockWebhookRepo.EXPECT().Redeliver(ctx, deliveryID).Return(gorm.ErrRecordNotFound)
+
+		webhookService := NewWebhookService(WithWebhookRepo(mockWebhookRepo))
+
+		err := webhookService.Redeliver(ctx, deliveryID)
+		assert.Equal(t, ErrWebhookDeliveryNotFound, err)
+	})
+
+	t.Run("should queue the delivery again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		mockWebhookRepo := mock_repository.NewMockWebhookRepo(ctrl)
+		mockWebhookRepo.EXPECT().Redeliver(ctx, deliveryID).Return(nil)
+
+		webhookService := NewWebhookService(WithWebhookRepo(mockWebhookRepo))
+
+		err := webhookService.Redeliver(ctx, deliveryID)
+		assert.Nil(t, err)
+	})
+}
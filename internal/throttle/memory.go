@@ -0,0 +1,89 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mu          sync.Mutex
+	forgetAfter time.Duration
+	attempts    map[string]Attempts
+	lastSweep   time.Time
+}
+
+// NewMemoryStore returns a store for a single instance, other instances do not see its failures
+func NewMemoryStore(forgetAfter time.Duration) Store {
+	return &memoryStore{
+		forgetAfter: forgetAfter,
+		attempts:    map[string]Attempts{},
+	}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.getLocked(key, time.Now()), nil
+}
+
+func (s *memoryStore) Attempt(ctx context.Context, key string, policy Policy, now time.Time) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+
+	attempts := s.getLocked(key, now)
+	if wait, _ := policy.Wait(attempts, now); wait > 0 {
+		return attempts, nil
+	}
+
+	s.attempts[key] = Attempts{Failures: attempts.Failures + 1, LastFailure: now}
+
+	return attempts, nil
+}
+
+func (s *memoryStore) Forgive(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempts, ok := s.attempts[key]; ok && attempts.Failures > 0 {
+		attempts.Failures--
+		s.attempts[key] = attempts
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+
+	return nil
+}
+
+func (s *memoryStore) getLocked(key string, now time.Time) Attempts {
+	attempts := s.attempts[key]
+	if now.Sub(attempts.LastFailure) > s.forgetAfter {
+		return Attempts{}
+	}
+
+	return attempts
+}
+
+// sweepLocked drops forgotten keys, at most once every forgetAfter
+func (s *memoryStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < s.forgetAfter {
+		return
+	}
+	s.lastSweep = now
+
+	for key, attempts := range s.attempts {
+		if now.Sub(attempts.LastFailure) > s.forgetAfter {
+			delete(s.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	policy := Policy{FreeFailures: 5, LockoutFailures: 2, LockoutDuration: time.Hour}
+
+	t.Run("should count attempts per key", func(t *testing.T) {
+		store := NewMemoryStore(time.Hour)
+		now := time.Now()
+
+		attempts, err := store.Attempt(ctx, "username:ravi", Policy{}, now)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, attempts.Failures)
+
+		attempts, _ = store.Attempt(ctx, "username:ravi", Policy{}, now)
+		assert.Equal(t, 1, attempts.Failures)
+		assert.Equal(t, now, attempts.LastFailure)
+
+		attempts, _ = store.Get(ctx, "username:ravi")
+		assert.Equal(t, 2, attempts.Failures)
+
+		attempts, _ = store.Get(ctx, "ip:10.0.0.1")
+		assert.Equal(t, 0, attempts.Failures)
+	})
+
+	t.Run("should not count attempts the policy makes wait", func(t *testing.T) {
+		store := NewMemoryStore(time.Hour)
+		now := time.Now()
+
+		for i := 0; i < 3; i++ {
+			_, _ = store.Attempt(ctx, "username:ravi", policy, now)
+		}
+
+		attempts, _ := store.Attempt(ctx, "username:ravi", policy, now)
+		assert.Equal(t, 2, attempts.Failures)
+	})
+
+	t.Run("should take back a forgiven attempt", func(t *testing.T) {
+		store := NewMemoryStore(time.Hour)
+
+		_, _ = store.Attempt(ctx, "ip:10.0.0.1", policy, time.Now())
+		assert.Nil(t, store.Forgive(ctx, "ip:10.0.0.1"))
+		assert.Nil(t, store.Forgive(ctx, "ip:10.0.0.1"))
+
+		attempts, _ := store.Get(ctx, "ip:10.0.0.1")
+		assert.Equal(t, 0, attempts.Failures)
+	})
+
+	t.Run("should start over once the failures are forgotten", func(t *testing.T) {
+		store := NewMemoryStore(time.Minute)
+
+		_, _ = store.Attempt(ctx, "username:ravi", policy, time.Now().Add(-2*time.Minute))
+
+		attempts, _ := store.Get(ctx, "username:ravi")
+		assert.Equal(t, 0, attempts.Failures)
+
+		attempts, _ = store.Attempt(ctx, "username:ravi", policy, time.Now())
+		assert.Equal(t, 0, attempts.Failures)
+	})
+
+	t.Run("should forget the failures of a reset key", func(t *testing.T) {
+		store := NewMemoryStore(time.Hour)
+
+		_, _ = store.Attempt(ctx, "username:ravi", policy, time.Now())
+		assert.Nil(t, store.Reset(ctx, "username:ravi"))
+
+		attempts, _ := store.Get(ctx, "username:ravi")
+		assert.Equal(t, 0, attempts.Failures)
+	})
+}
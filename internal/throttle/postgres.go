@@ -0,0 +1,121 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"tigerhall_kittens/internal/logger"
+)
+
+type postgresStore struct {
+	DB          *gorm.DB
+	forgetAfter time.Duration
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewPostgresStore returns a store for deployments running more than one instance, the failures
+// are kept in the login_throttles table so that every instance counts them
+func NewPostgresStore(db *gorm.DB, forgetAfter time.Duration) Store {
+	return &postgresStore{DB: db, forgetAfter: forgetAfter}
+}
+
+func (s *postgresStore) Get(ctx context.Context, key string) (Attempts, error) {
+	var attempts Attempts
+
+	err := s.DB.WithContext(ctx).
+		Raw("SELECT failures, last_failure FROM login_throttles WHERE key = ? AND last_failure >= ?",
+			key, time.Now().Add(-s.forgetAfter)).
+		Scan(&attempts).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while getting failed logins")
+		return Attempts{}, err
+	}
+
+	return attempts, nil
+}
+
+// Attempt locks the row of key for the check and the count, so that concurrent logins on
+// different instances wait on each other
+func (s *postgresStore) Attempt(ctx context.Context, key string, policy Policy, now time.Time) (Attempts, error) {
+	s.sweep(ctx, now)
+
+	var attempts Attempts
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the row is created first, a key without one would have nothing to lock
+		err := tx.Exec("INSERT INTO login_throttles (key, failures, last_failure) VALUES (?, 0, ?) ON CONFLICT (key) DO NOTHING",
+			key, now).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Raw("SELECT failures, last_failure FROM login_throttles WHERE key = ? FOR UPDATE", key).Scan(&attempts)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("login attempt was not recorded")
+		}
+
+		if now.Sub(attempts.LastFailure) > s.forgetAfter {
+			attempts = Attempts{}
+		}
+
+		if wait, _ := policy.Wait(attempts, now); wait > 0 {
+			return nil
+		}
+
+		return tx.Exec("UPDATE login_throttles SET failures = ?, last_failure = ? WHERE key = ?",
+			attempts.Failures+1, now, key).Error
+	})
+	if err != nil {
+		logger.E(ctx, err, "Error while recording login attempt")
+		return Attempts{}, err
+	}
+
+	return attempts, nil
+}
+
+func (s *postgresStore) Forgive(ctx context.Context, key string) error {
+	err := s.DB.WithContext(ctx).
+		Exec("UPDATE login_throttles SET failures = failures - 1 WHERE key = ? AND failures > 0", key).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while forgiving login attempt")
+		return err
+	}
+
+	return nil
+}
+
+func (s *postgresStore) Reset(ctx context.Context, key string) error {
+	err := s.DB.WithContext(ctx).Exec("DELETE FROM login_throttles WHERE key = ?", key).Error
+	if err != nil {
+		logger.E(ctx, err, "Error while resetting failed logins")
+		return err
+	}
+
+	return nil
+}
+
+// sweep deletes forgotten keys, at most once every forgetAfter per instance
+func (s *postgresStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < s.forgetAfter {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	err := s.DB.WithContext(ctx).Exec("DELETE FROM login_throttles WHERE last_failure < ?", now.Add(-s.forgetAfter)).Error
+	if err != nil {
+		logger.W(ctx, "Error while deleting forgotten failed logins", logger.Field("error", err.Error()))
+	}
+}
@@ -0,0 +1,90 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultForgetAfter = 15 * time.Minute
+
+// Attempts are the recent logins of a key, e.g. a username or a client IP, that were not forgiven
+type Attempts struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Store keeps the failed logins of every key. Failures are forgotten once forgetAfter, given
+// when the store is created, has passed since the last one.
+type Store interface {
+	Get(ctx context.Context, key string) (Attempts, error)
+	// Attempt counts a login of key at now, unless policy makes it wait, and returns the attempts
+	// before it. Checking and counting is a single step, so that concurrent logins each see the
+	// ones before them.
+	Attempt(ctx context.Context, key string, policy Policy, now time.Time) (Attempts, error)
+	// Forgive takes back one attempt of key, for a login that turned out to be valid
+	Forgive(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Policy decides how long a key has to wait after its failed logins
+type Policy struct {
+	// FreeFailures are not delayed, every failure after them doubles the delay starting at
+	// BaseDelay, up to MaxDelay
+	FreeFailures int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	// LockoutFailures locks the key out for LockoutDuration after its last failure
+	LockoutFailures int
+	LockoutDuration time.Duration
+}
+
+// Wait returns how long the key has to wait before its next login is tried and whether it is locked out
+func (p Policy) Wait(attempts Attempts, now time.Time) (time.Duration, bool) {
+	if p.LockoutFailures > 0 && attempts.Failures >= p.LockoutFailures {
+		if wait := attempts.LastFailure.Add(p.LockoutDuration).Sub(now); wait > 0 {
+			return wait, true
+		}
+
+		return 0, false
+	}
+
+	if attempts.Failures <= p.FreeFailures {
+		return 0, false
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeFailures + 1; i < attempts.Failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if wait := attempts.LastFailure.Add(delay).Sub(now); wait > 0 {
+		return wait, false
+	}
+
+	return 0, false
+}
+
+var (
+	mu    sync.RWMutex
+	store Store = NewMemoryStore(DefaultForgetAfter)
+)
+
+// Get returns the store failed logins are kept in, an in-process one unless Set replaced it
+func Get() Store {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return store
+}
+
+func Set(s Store) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	store = s
+}
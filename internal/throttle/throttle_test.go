@@ -0,0 +1,42 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Wait(t *testing.T) {
+	policy := Policy{FreeFailures: 2, BaseDelay: time.Second, MaxDelay: 8 * time.Second, LockoutFailures: 10, LockoutDuration: time.Hour}
+	now := time.Now()
+
+	t.Run("should not delay the free failures", func(t *testing.T) {
+		wait, locked := policy.Wait(Attempts{Failures: 2, LastFailure: now}, now)
+		assert.Equal(t, time.Duration(0), wait)
+		assert.False(t, locked)
+	})
+
+	t.Run("should double the delay with every failure up to the maximum", func(t *testing.T) {
+		for failures, expected := range map[int]time.Duration{3: time.Second, 4: 2 * time.Second, 5: 4 * time.Second, 9: 8 * time.Second} {
+			wait, locked := policy.Wait(Attempts{Failures: failures, LastFailure: now}, now)
+			assert.Equal(t, expected, wait)
+			assert.False(t, locked)
+		}
+	})
+
+	t.Run("should not delay once the delay has passed", func(t *testing.T) {
+		wait, _ := policy.Wait(Attempts{Failures: 3, LastFailure: now.Add(-time.Second)}, now)
+		assert.Equal(t, time.Duration(0), wait)
+	})
+
+	t.Run("should lock out for the lockout duration", func(t *testing.T) {
+		wait, locked := policy.Wait(Attempts{Failures: 10, LastFailure: now.Add(-time.Minute)}, now)
+		assert.Equal(t, 59*time.Minute, wait)
+		assert.True(t, locked)
+
+		wait, locked = policy.Wait(Attempts{Failures: 10, LastFailure: now.Add(-time.Hour)}, now)
+		assert.Equal(t, time.Duration(0), wait)
+		assert.False(t, locked)
+	})
+}
@@ -0,0 +1,64 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the SHA1 secret of the RFC 6238 test vectors
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	t.Run("should match the RFC 6238 test vectors", func(t *testing.T) {
+		// the RFC lists 8 digit codes, these are their last 6 digits
+		for unix, expected := range map[int64]string{
+			59:          "287082",
+			1111111109:  "081804",
+			1111111111:  "050471",
+			1234567890:  "005924",
+			2000000000:  "279037",
+			20000000000: "353130",
+		} {
+			code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+			assert.Nil(t, err)
+			assert.Equal(t, expected, code)
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	t.Run("should accept codes of the neighbouring steps", func(t *testing.T) {
+		previous, _ := Code(rfcSecret, Step(now)-1)
+
+		step, ok := Validate(rfcSecret, previous, now, 0)
+		assert.True(t, ok)
+		assert.Equal(t, Step(now)-1, step)
+
+		tooOld, _ := Code(rfcSecret, Step(now)-2)
+		_, ok = Validate(rfcSecret, tooOld, now, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("should not accept a code of a step that was already used", func(t *testing.T) {
+		code, _ := Code(rfcSecret, Step(now))
+
+		_, ok := Validate(rfcSecret, code, now, Step(now))
+		assert.False(t, ok)
+	})
+}
+
+func TestURI(t *testing.T) {
+	t.Run("should build an otpauth URI", func(t *testing.T) {
+		uri := URI("Tigerhall Kittens", "ranger_ravi", "JBSWY3DPEHPK3PXP")
+
+		assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Tigerhall%20Kittens:ranger_ravi?"))
+		assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+		assert.Contains(t, uri, "issuer=Tigerhall+Kittens")
+	})
+}
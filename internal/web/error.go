@@ -19,18 +19,37 @@ type ErrorFunc func(desc string) ErrorInterface
 
 const (
 	UnauthorizedRequest = "unauthorized"
+	Forbidden           = "forbidden"
+	EmailNotVerified    = "email_not_verified"
+	TooManyRequests     = "too_many_requests"
 	BadRequest          = "bad_request"
 	InternalServerError = "internal_server_error"
 	NotFound            = "not_found"
+	PreconditionFailed  = "precondition_failed"
 )
 
 var (
 	ErrUnauthorizedRequest = func(desc string) ErrorInterface {
 		return newError(UnauthorizedRequest, desc, "", http.StatusUnauthorized)
 	}
+	ErrForbidden = func(desc string) ErrorInterface {
+		return newError(Forbidden, desc, "", http.StatusForbidden)
+	}
+	ErrEmailNotVerified = func(desc string) ErrorInterface {
+		return newError(EmailNotVerified, desc, "", http.StatusForbidden)
+	}
+	ErrTooManyRequests = func(desc string) ErrorInterface {
+		return newError(TooManyRequests, desc, "", http.StatusTooManyRequests)
+	}
 	ErrBadRequest = func(desc string) ErrorInterface {
 		return newError(BadRequest, desc, "", http.StatusBadRequest)
 	}
+	ErrNotFound = func(desc string) ErrorInterface {
+		return newError(NotFound, desc, "", http.StatusNotFound)
+	}
+	ErrPreconditionFailed = func(desc string) ErrorInterface {
+		return newError(PreconditionFailed, desc, "", http.StatusPreconditionFailed)
+	}
 	ErrInternalServerError = func(desc string) ErrorInterface {
 		return newError(InternalServerError, desc, "", http.StatusInternalServerError)
 	}
@@ -1,14 +1,16 @@
 package web
 
 import (
+	"net"
 	"net/http"
 	"strings"
 )
 
 type Request struct {
 	*http.Request
-	pathParams map[string]string
-	params     map[string]string
+	pathParams      map[string]string
+	params          map[string]string
+	responseHeaders http.Header
 }
 
 type ValidationErrorInterface interface {
@@ -41,6 +43,18 @@ func (r *Request) GetPathParam(key string) string {
 	return ""
 }
 
+// SetResponseHeader sets a header the response to the request is written with
+func (r *Request) SetResponseHeader(key, value string) {
+	if r.responseHeaders == nil {
+		r.responseHeaders = http.Header{}
+	}
+	r.responseHeaders.Set(key, value)
+}
+
+func (r *Request) ResponseHeaders() http.Header {
+	return r.responseHeaders
+}
+
 func (r *Request) QueryParams() map[string]string {
 	if r.params != nil {
 		return r.params
@@ -51,3 +65,13 @@ func (r *Request) QueryParams() map[string]string {
 	}
 	return r.params
 }
+
+// ClientIP is the address the request came from. Forwarding headers are not trusted, behind a
+// proxy this is the proxy's address.
+func (r *Request) ClientIP() string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}